@@ -46,6 +46,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = s.CheckGithubConnectivity(); err != nil {
+		mlog.Error("GitHub connectivity check failed", mlog.Err(err))
+		os.Exit(1)
+	}
+
 	mlog.Info("Starting Job Server")
 	s.RefreshMembers()
 
@@ -80,6 +85,12 @@ func main() {
 	if err != nil {
 		mlog.Error("failed adding CheckTestServerLifeTime cron", mlog.Err(err))
 	}
+	if s.Config.SpinmintReconcileIntervalMinutes > 0 {
+		_, err = c.AddFunc(fmt.Sprintf("@every %dm", s.Config.SpinmintReconcileIntervalMinutes), s.ReconcileSpinmints)
+		if err != nil {
+			mlog.Error("failed adding ReconcileSpinmints cron", mlog.Err(err))
+		}
+	}
 	_, err = c.AddFunc("@every 30m", func() {
 		err2 := s.AutoMergePR()
 		if err2 != nil {
@@ -50,6 +50,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = s.CheckGithubConnectivity(); err != nil {
+		mlog.Error("GitHub connectivity check failed", mlog.Err(err))
+		os.Exit(1)
+	}
+
 	mlog.Info("Starting Mattermod Server")
 	s.Start()
 
@@ -126,4 +126,48 @@ func TestMetrics(t *testing.T) {
 		require.NoError(t, data.(prometheus.Counter).Write(m))
 		require.Equal(t, float64(1), m.Counter.GetValue())
 	})
+
+	t.Run("Should store metrics for spinmint events", func(t *testing.T) {
+		m := &prometheusModels.Metric{}
+		data, err := provider.spinmintEvents.GetMetricWithLabelValues("created", "mattermost-server", "t3.large")
+		require.NoError(t, err)
+		require.NoError(t, data.(prometheus.Counter).Write(m))
+		require.Equal(t, float64(0), m.Counter.GetValue())
+		provider.IncreaseSpinmintEvents("created", "mattermost-server", "t3.large")
+		data, err = provider.spinmintEvents.GetMetricWithLabelValues("created", "mattermost-server", "t3.large")
+		require.NoError(t, err)
+		require.NoError(t, data.(prometheus.Counter).Write(m))
+		require.Equal(t, float64(1), m.Counter.GetValue())
+	})
+
+	t.Run("Should store metrics for spinmint wait duration", func(t *testing.T) {
+		m := &prometheusModels.Metric{}
+		labels := prometheus.Labels{"wait": "instance", "repo": "mattermost-server", "size": "t3.large"}
+		data, err := provider.spinmintWaitDuration.GetMetricWith(labels)
+		require.NoError(t, err)
+		require.NoError(t, data.(prometheus.Histogram).Write(m))
+		require.Equal(t, uint64(0), m.Histogram.GetSampleCount())
+		provider.ObserveSpinmintWaitDuration("instance", "mattermost-server", "t3.large", 90)
+		data, err = provider.spinmintWaitDuration.GetMetricWith(labels)
+		require.NoError(t, err)
+		require.NoError(t, data.(prometheus.Histogram).Write(m))
+		require.Equal(t, uint64(1), m.Histogram.GetSampleCount())
+		require.InDelta(t, 90, m.Histogram.GetSampleSum(), 0.001)
+	})
+
+	t.Run("Should store metrics for build waits", func(t *testing.T) {
+		m := &prometheusModels.Metric{}
+		provider.ObserveBuildWaitDuration("build", "mattermost-server", "jenkins", 600)
+		data, err := provider.buildWaitDuration.GetMetricWith(prometheus.Labels{"wait": "build", "repo": "mattermost-server", "ci_provider": "jenkins"})
+		require.NoError(t, err)
+		require.NoError(t, data.(prometheus.Histogram).Write(m))
+		require.Equal(t, uint64(1), m.Histogram.GetSampleCount())
+		require.InDelta(t, 600, m.Histogram.GetSampleSum(), 0.001)
+
+		provider.IncreaseBuildWaitOutcomes("build", "mattermost-server", "jenkins", "timeout")
+		counter, err := provider.buildWaitOutcomes.GetMetricWithLabelValues("build", "mattermost-server", "jenkins", "timeout")
+		require.NoError(t, err)
+		require.NoError(t, counter.Write(m))
+		require.Equal(t, float64(1), m.Counter.GetValue())
+	})
 }
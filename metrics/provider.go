@@ -11,10 +11,12 @@ import (
 )
 
 const (
-	metricsNamespace = "mattermod"
-	httpNamespace    = "requests"
-	cronNamespace    = "cron"
-	githubNamespace  = "github"
+	metricsNamespace  = "mattermod"
+	httpNamespace     = "requests"
+	cronNamespace     = "cron"
+	githubNamespace   = "github"
+	spinmintNamespace = "spinmint"
+	buildsNamespace   = "builds"
 
 	defaultPrometheusTimeoutSeconds = 60
 )
@@ -36,6 +38,12 @@ type PrometheusProvider struct {
 	githubCacheMisses *prometheus.CounterVec
 
 	rateLimiterErrors prometheus.Counter
+
+	spinmintEvents       *prometheus.CounterVec
+	spinmintWaitDuration *prometheus.HistogramVec
+
+	buildWaitDuration *prometheus.HistogramVec
+	buildWaitOutcomes *prometheus.CounterVec
 }
 
 // NewPrometheusProvider creates a new prometheus metrics provider
@@ -148,6 +156,52 @@ func NewPrometheusProvider() *PrometheusProvider {
 	)
 	provider.Registry.MustRegister(provider.rateLimiterErrors)
 
+	provider.spinmintEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: spinmintNamespace,
+			Name:      "events",
+			Help:      "Number of spinmint lifecycle events by type, repository and size.",
+		},
+		[]string{"event", "repo", "size"},
+	)
+	provider.Registry.MustRegister(provider.spinmintEvents)
+
+	provider.spinmintWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: spinmintNamespace,
+			Name:      "wait_duration_seconds",
+			Help:      "Duration of the waits for spinmints to be running and reachable.",
+			Buckets:   []float64{15, 30, 60, 120, 300, 600, 900, 1800},
+		},
+		[]string{"wait", "repo", "size"},
+	)
+	provider.Registry.MustRegister(provider.spinmintWaitDuration)
+
+	provider.buildWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: buildsNamespace,
+			Name:      "wait_duration_seconds",
+			Help:      "Duration of the waits for PR builds and their docker images.",
+			Buckets:   []float64{60, 300, 600, 900, 1200, 1800, 2700, 3600},
+		},
+		[]string{"wait", "repo", "ci_provider"},
+	)
+	provider.Registry.MustRegister(provider.buildWaitDuration)
+
+	provider.buildWaitOutcomes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: buildsNamespace,
+			Name:      "wait_outcomes",
+			Help:      "Number of waits for PR builds and their docker images by outcome.",
+		},
+		[]string{"wait", "repo", "ci_provider", "outcome"},
+	)
+	provider.Registry.MustRegister(provider.buildWaitOutcomes)
+
 	return provider
 }
 
@@ -191,6 +245,22 @@ func (p *PrometheusProvider) IncreaseRateLimiterErrors() {
 	p.rateLimiterErrors.Add(1)
 }
 
+func (p *PrometheusProvider) IncreaseSpinmintEvents(event, repo, size string) {
+	p.spinmintEvents.WithLabelValues(event, repo, size).Add(1)
+}
+
+func (p *PrometheusProvider) ObserveSpinmintWaitDuration(wait, repo, size string, elapsed float64) {
+	p.spinmintWaitDuration.With(prometheus.Labels{"wait": wait, "repo": repo, "size": size}).Observe(elapsed)
+}
+
+func (p *PrometheusProvider) ObserveBuildWaitDuration(wait, repo, ciProvider string, elapsed float64) {
+	p.buildWaitDuration.With(prometheus.Labels{"wait": wait, "repo": repo, "ci_provider": ciProvider}).Observe(elapsed)
+}
+
+func (p *PrometheusProvider) IncreaseBuildWaitOutcomes(wait, repo, ciProvider, outcome string) {
+	p.buildWaitOutcomes.WithLabelValues(wait, repo, ciProvider, outcome).Add(1)
+}
+
 // Handler returns the handler that would be used by the metrics server to expose
 // the metrics.
 func (p *PrometheusProvider) Handler() Handler {
@@ -29,6 +29,7 @@ type PullRequest struct {
 	CreatedAt           time.Time
 	Merged              *bool
 	MergeCommitSHA      string `db:"-"`
+	Draft               bool   `db:"-"`
 	MaintainerCanModify *bool
 	MilestoneNumber     *int64
 	MilestoneTitle      *string
@@ -3,10 +3,73 @@
 
 package model
 
+import (
+	"time"
+)
+
+// States of a spinmint, from its creation until it is destroyed.
+const (
+	SpinmintStateCreating = "creating"
+	SpinmintStateStable   = "stable"
+	SpinmintStateFailed   = "failed"
+	SpinmintStateDeleting = "deleting"
+)
+
 type Spinmint struct {
 	InstanceID string `db:"InstanceId"`
 	RepoOwner  string
 	RepoName   string
 	Number     int
-	CreatedAt  int64
+	CreatedAt  time.Time
+	CreatedBy  string
+	SnapshotID string      `db:"SnapshotId"`
+	DeletedAt  *time.Time  // DeletedAt is set when the spinmint was soft deleted.
+	Labels     StringArray // Labels are the PR labels that triggered the creation of the spinmint.
+	Region     string      // Region is the configured spinmint region the instance runs in. The default region has no name.
+	Subdomain  string      // Subdomain is the DNS name the spinmint was renamed to. It is empty if the instance ID is used.
+	State      string      // State is one of the SpinmintState values. Spinmints created before states were tracked have none.
+	// StateChangedAt is when the spinmint moved to its State. Spinmints created before it was tracked have none.
+	StateChangedAt *time.Time
+	// CredentialsCommentURL is the PR comment the credentials of the test server were posted in.
+	// It is carried over when the spinmint is recreated so they are not posted again.
+	CredentialsCommentURL string
+}
+
+// GetSubdomain returns the DNS name of the spinmint in its region's domain.
+func (s *Spinmint) GetSubdomain() string {
+	if s.Subdomain != "" {
+		return s.Subdomain
+	}
+	return s.InstanceID
+}
+
+// GetStateChangedAt returns when the spinmint moved to its state, or its creation time if unknown.
+func (s *Spinmint) GetStateChangedAt() time.Time {
+	if s.StateChangedAt != nil {
+		return *s.StateChangedAt
+	}
+	return s.CreatedAt
+}
+
+// SpinmintHistory is kept for every destroyed spinmint to report on usage.
+type SpinmintHistory struct {
+	InstanceID   string `db:"InstanceId"`
+	RepoOwner    string
+	RepoName     string
+	Number       int
+	InstanceType string
+	CreatedAt    time.Time
+	DestroyedAt  time.Time
+}
+
+// SpinmintAction is an entry of the log of what mattermod did with the spinmints of a PR.
+type SpinmintAction struct {
+	ID         int64 `db:"Id"`
+	RepoOwner  string
+	RepoName   string
+	Number     int
+	Action     string
+	InstanceID string `db:"InstanceId"`
+	Sha        string
+	CreatedAt  time.Time
 }
@@ -0,0 +1,12 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import "time"
+
+// NowUTC returns the current time in UTC truncated to seconds, which is the
+// precision times are stored with.
+func NowUTC() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
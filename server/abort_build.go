@@ -0,0 +1,119 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/go-circleci"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+)
+
+const (
+	msgBuildAborted    = "The build for this PR was aborted."
+	msgNoBuildToAbort  = "There is no running build for this PR to abort."
+	msgBuildAbortError = "Error trying to abort the build. Please do it manually."
+)
+
+func (s *Server) handleAbortBuild(ctx context.Context, commenter string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if commenter != pr.Username && !s.IsOrgMember(commenter) {
+		msg = msgCommenterPermission
+		return nil
+	}
+
+	var aborted bool
+	var err error
+	repo, ok := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
+	if ok && repo.JenkinsServer != "" {
+		aborted, err = s.abortJenkinsBuild(ctx, repo, pr)
+	} else {
+		aborted, err = s.abortCircleCIBuilds(ctx, pr)
+	}
+	if err != nil {
+		msg = msgBuildAbortError
+		return err
+	}
+
+	if !aborted {
+		msg = msgNoBuildToAbort
+		return nil
+	}
+
+	msg = msgBuildAborted
+	return nil
+}
+
+// abortJenkinsBuild stops the Jenkins build the PR build link points to.
+func (s *Server) abortJenkinsBuild(ctx context.Context, repo *Repository, pr *model.PullRequest) (bool, error) {
+	if pr.BuildLink == "" {
+		return false, nil
+	}
+
+	credentials, ok := s.Config.JenkinsCredentials[repo.JenkinsServer]
+	if !ok {
+		return false, errors.New("jenkins server credentials are not configured")
+	}
+
+	jobName, jobNumber, err := parseJenkinsBuildLink(repo, pr)
+	if err != nil {
+		return false, err
+	}
+
+	stopURL := fmt.Sprintf("%s/job/%s/%d/stop", strings.TrimSuffix(credentials.URL, "/"), jobName, jobNumber)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stopURL, http.NoBody)
+	if err != nil {
+		return false, err
+	}
+	req.SetBasicAuth(credentials.Username, credentials.APIToken)
+
+	r, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer closeBody(r)
+
+	if r.StatusCode >= http.StatusBadRequest {
+		return false, errors.Errorf("failed to stop Jenkins build %s #%d: got http status %s", jobName, jobNumber, r.Status)
+	}
+
+	mlog.Info("Aborted Jenkins build", mlog.String("job", jobName), mlog.Int64("build", jobNumber), mlog.Int("pr", pr.Number))
+	return true, nil
+}
+
+// abortCircleCIBuilds cancels the running CircleCI builds of the PR branch.
+func (s *Server) abortCircleCIBuilds(ctx context.Context, pr *model.PullRequest) (bool, error) {
+	branch := "pull/" + strconv.Itoa(pr.Number)
+	if strings.Split(pr.FullName, "/")[0] == s.Config.Org {
+		branch = pr.Ref
+	}
+
+	builds, err := s.CircleCiClient.ListRecentBuildsForProjectWithContext(ctx, circleci.VcsTypeGithub, pr.RepoOwner, pr.RepoName, branch, "running", -1, 0)
+	if err != nil {
+		return false, fmt.Errorf("could not list the CircleCI builds for branch %s: %w", branch, err)
+	}
+
+	for _, build := range builds {
+		if _, err := s.CircleCiClient.CancelBuildWithContext(ctx, circleci.VcsTypeGithub, pr.RepoOwner, pr.RepoName, build.BuildNum); err != nil {
+			return false, fmt.Errorf("could not cancel CircleCI build %d: %w", build.BuildNum, err)
+		}
+		mlog.Info("Aborted CircleCI build", mlog.Int("build", build.BuildNum), mlog.Int("pr", pr.Number))
+	}
+
+	return len(builds) > 0, nil
+}
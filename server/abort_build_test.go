@@ -0,0 +1,95 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/go-circleci"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleAbortBuild(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	circleCIService := mocks.NewMockCircleCIService(ctrl)
+
+	s := &Server{
+		Config: &Config{
+			Org: "mattertest",
+		},
+		GithubClient: &GithubClient{
+			Issues: is,
+		},
+		CircleCiClient: circleCIService,
+		OrgMembers:     []string{"maintainer"},
+	}
+
+	pr := &model.PullRequest{
+		RepoOwner: "mattertest",
+		RepoName:  "mattermost-webapp",
+		FullName:  "contributor/mattermost-webapp",
+		Number:    123,
+		Username:  "contributor",
+		Ref:       "feature",
+	}
+
+	expectComment := func(msg string) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+	}
+
+	t.Run("random user", func(t *testing.T) {
+		expectComment(msgCommenterPermission)
+		require.NoError(t, s.handleAbortBuild(ctx, "someone", pr))
+	})
+
+	t.Run("no running builds", func(t *testing.T) {
+		circleCIService.EXPECT().ListRecentBuildsForProjectWithContext(ctx, circleci.VcsTypeGithub, pr.RepoOwner, pr.RepoName, "pull/123", "running", -1, 0).Return(nil, nil)
+		expectComment(msgNoBuildToAbort)
+		require.NoError(t, s.handleAbortBuild(ctx, "contributor", pr))
+	})
+
+	t.Run("cancels running CircleCI builds", func(t *testing.T) {
+		circleCIService.EXPECT().ListRecentBuildsForProjectWithContext(ctx, circleci.VcsTypeGithub, pr.RepoOwner, pr.RepoName, "pull/123", "running", -1, 0).
+			Return([]*circleci.Build{{BuildNum: 7}, {BuildNum: 8}}, nil)
+		circleCIService.EXPECT().CancelBuildWithContext(ctx, circleci.VcsTypeGithub, pr.RepoOwner, pr.RepoName, 7).Return(nil, nil)
+		circleCIService.EXPECT().CancelBuildWithContext(ctx, circleci.VcsTypeGithub, pr.RepoOwner, pr.RepoName, 8).Return(nil, nil)
+		expectComment(msgBuildAborted)
+		require.NoError(t, s.handleAbortBuild(ctx, "maintainer", pr))
+	})
+
+	t.Run("stops the Jenkins build", func(t *testing.T) {
+		var stopPath string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			stopPath = r.URL.Path
+		}))
+		defer ts.Close()
+
+		s.Config.Repositories = []*Repository{{Owner: "mattertest", Name: serverRepoName, JenkinsServer: "jenkins"}}
+		s.Config.JenkinsCredentials = map[string]*JenkinsCredentials{"jenkins": {URL: ts.URL}}
+		jenkinsPR := &model.PullRequest{
+			RepoOwner: "mattertest",
+			RepoName:  serverRepoName,
+			Number:    123,
+			Username:  "contributor",
+			BuildLink: "https://build.example.com/job/mp/job/mattermost-server/job/PR-123/4/display/redirect",
+		}
+
+		is.EXPECT().CreateComment(ctx, jenkinsPR.RepoOwner, jenkinsPR.RepoName, jenkinsPR.Number, &github.IssueComment{Body: github.String(msgBuildAborted)}).Return(nil, nil, nil)
+		require.NoError(t, s.handleAbortBuild(ctx, "contributor", jenkinsPR))
+		require.Equal(t, "/job/mp/job/mattermost-server/job/PR-123/4/stop", stopPath)
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+type buildStatusResponse struct {
+	RepoOwner        string `json:"repo_owner"`
+	RepoName         string `json:"repo_name"`
+	Number           int    `json:"number"`
+	Sha              string `json:"sha"`
+	BuildStatus      string `json:"build_status"`
+	BuildConclusion  string `json:"build_conclusion"`
+	BuildLink        string `json:"build_link"`
+	JenkinsJobName   string `json:"jenkins_job_name,omitempty"`
+	JenkinsJobNumber int64  `json:"jenkins_job_number,omitempty"`
+}
+
+// getPRBuildStatus returns the build status mattermod knows for a PR of the org.
+func (s *Server) getPRBuildStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	number, err := strconv.Atoi(vars["number"])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	pr, err := s.Store.PullRequest().Get(s.Config.Org, vars["repo"], number)
+	if err != nil {
+		mlog.Error("Unable to get the PR", mlog.String("repo", vars["repo"]), mlog.Int("pr", number), mlog.Err(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if pr == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	resp := buildStatusResponse{
+		RepoOwner:       pr.RepoOwner,
+		RepoName:        pr.RepoName,
+		Number:          pr.Number,
+		Sha:             pr.Sha,
+		BuildStatus:     pr.BuildStatus,
+		BuildConclusion: pr.BuildConclusion,
+		BuildLink:       pr.BuildLink,
+	}
+	if pr.BuildLink != "" {
+		// Only Jenkins build links can be parsed, the others are returned as they are.
+		repo, _ := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
+		if jobName, jobNumber, err2 := parseJenkinsBuildLink(repo, pr); err2 == nil {
+			resp.JenkinsJobName = jobName
+			resp.JenkinsJobNumber = jobNumber
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		mlog.Error("Failed to write the build status", mlog.Err(err))
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-mattermod/model"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPRBuildStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	prs := stmock.NewMockPullRequestStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().PullRequest().Return(prs).AnyTimes()
+
+	s := &Server{
+		Config: &Config{Org: "mattertest"},
+		Store:  ss,
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/api/prs/{repo}/{number:[0-9]+}/build", s.getPRBuildStatus).Methods(http.MethodGet)
+
+	t.Run("jenkins build", func(t *testing.T) {
+		prs.EXPECT().Get("mattertest", serverRepoName, 1234).Return(&model.PullRequest{
+			RepoOwner:       "mattertest",
+			RepoName:        serverRepoName,
+			Number:          1234,
+			Sha:             "abcdef",
+			BuildStatus:     "completed",
+			BuildConclusion: "success",
+			BuildLink:       "https://build.example.com/job/mp/job/mattermost-server/job/PR-1234/5/display/redirect",
+		}, nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/prs/mattermost-server/1234/build", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp buildStatusResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "success", resp.BuildConclusion)
+		assert.Equal(t, "mp/job/mattermost-server/job/PR-1234", resp.JenkinsJobName)
+		assert.EqualValues(t, 5, resp.JenkinsJobNumber)
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		prs.EXPECT().Get("mattertest", "mattermost-webapp", 1).Return(nil, nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/prs/mattermost-webapp/1/build", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
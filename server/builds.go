@@ -7,21 +7,137 @@ import (
 	"time"
 
 	jenkins "github.com/cpanato/golang-jenkins"
+	"github.com/google/go-github/v33/github"
 	"github.com/heroku/docker-registry-client/registry"
 	"github.com/mattermost/mattermost-mattermod/model"
 	"github.com/mattermost/mattermost-server/v5/mlog"
 	"github.com/pkg/errors"
 )
 
+const jenkinsMaxAttempts = 5
+
+// CI providers a repository can be built with.
+const (
+	ciProviderJenkins  = "jenkins"
+	ciProviderCircleCI = "circleci"
+	ciProviderGitLab   = "gitlab"
+)
+
+// jenkinsInitialBackoff is the wait before the first retry of a failed Jenkins call.
+var jenkinsInitialBackoff = 2 * time.Second
+
+// buildPollInterval is the wait between two checks of the build status of a PR.
+var buildPollInterval = 30 * time.Second
+
+// imagePollInterval is the wait between two checks of the docker image of a PR build.
+var imagePollInterval = 10 * time.Second
+
+// Waits for a PR build, and their outcomes, kept in the metrics. A failure is a failed build,
+// while an error is mattermod not being able to tell, like when the store or GitHub fails.
+const (
+	buildWaitBuild = "build"
+	buildWaitImage = "image"
+
+	buildWaitSuccess  = "success"
+	buildWaitFailure  = "failure"
+	buildWaitError    = "error"
+	buildWaitTimeout  = "timeout"
+	buildWaitCanceled = "canceled"
+)
+
+// getBuildWaitDoneOutcome tells a wait that ran out of time from one that was called off,
+// like the build wait when the image wait fails alongside it.
+func getBuildWaitDoneOutcome(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return buildWaitCanceled
+	}
+	return buildWaitTimeout
+}
+
+// newDockerRegistry connects to the registry the images of the builds are published to.
+var newDockerRegistry = registry.New
+
+// defaultDockerImage is the image the builds are published as unless the repository sets DockerImage.
+const defaultDockerImage = "mattermost/mattermost-enterprise-edition"
+
+// dockerImage is a docker image reference split into its parts.
+type dockerImage struct {
+	Registry   string // Registry is the host of the registry, empty for DockerRegistryURL.
+	Repository string
+	Tag        string // Tag is empty if the reference has none.
+}
+
+// String returns the reference without its tag.
+func (i *dockerImage) String() string {
+	if i.Registry == "" {
+		return i.Repository
+	}
+	return i.Registry + "/" + i.Repository
+}
+
+// parseDockerImage splits ref, like "mattermost/mattermost-enterprise-edition" or
+// "registry.internal:5000/mattermost/enterprise:tag". As with docker, the first component is
+// a registry host if it contains a dot or a port, or is localhost.
+func parseDockerImage(ref string) (*dockerImage, error) {
+	if ref == "" || strings.Contains(ref, "@") {
+		return nil, errors.Errorf("invalid docker image %q, expected [host/]repository[:tag]", ref)
+	}
+
+	image := &dockerImage{Repository: ref}
+	if i := strings.Index(ref, "/"); i > 0 {
+		if host := ref[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			image.Registry, image.Repository = host, ref[i+1:]
+		}
+	}
+	if i := strings.LastIndex(image.Repository, ":"); i >= 0 {
+		image.Repository, image.Tag = image.Repository[:i], image.Repository[i+1:]
+		if image.Tag == "" {
+			return nil, errors.Errorf("invalid docker image %q, the tag is empty", ref)
+		}
+	}
+	if image.Repository == "" || strings.HasPrefix(image.Repository, "/") || strings.HasSuffix(image.Repository, "/") {
+		return nil, errors.Errorf("invalid docker image %q, the repository is empty", ref)
+	}
+	return image, nil
+}
+
+// getDockerImage returns the image the builds of repo are published as.
+func getDockerImage(repo *Repository) (*dockerImage, error) {
+	if repo == nil || repo.DockerImage == "" {
+		return parseDockerImage(defaultDockerImage)
+	}
+	return parseDockerImage(repo.DockerImage)
+}
+
+// getDockerRegistryURL returns the registry image is published to.
+func (s *Server) getDockerRegistryURL(image *dockerImage) string {
+	if image.Registry == "" {
+		return s.Config.DockerRegistryURL
+	}
+	return "https://" + image.Registry
+}
+
+// maxUpdateChecksFailures is how many times in a row refreshing the PR from GitHub
+// can fail before the wait for the build gives up.
+const maxUpdateChecksFailures = 5
+
 // Builds implements buildsInterface for working with external CI/CD systems.
 type Builds struct{}
 
 type buildsInterface interface {
 	getInstallationVersion(pr *model.PullRequest) string
-	waitForImage(ctx context.Context, s *Server, reg *registry.Registry, pr *model.PullRequest) (*model.PullRequest, error)
+	waitForImage(ctx context.Context, s *Server, reg *registry.Registry, image *dockerImage, pr *model.PullRequest) (*model.PullRequest, error)
 	buildJenkinsClient(s *Server, pr *model.PullRequest) (*Repository, *jenkins.Jenkins, error)
 	waitForBuild(ctx context.Context, s *Server, client *jenkins.Jenkins, pr *model.PullRequest) (*model.PullRequest, error)
-	checkBuildLink(ctx context.Context, s *Server, pr *model.PullRequest) (string, error)
+}
+
+// observeBuildWait keeps how long wait took since start, and its outcome, in the metrics.
+func (s *Server) observeBuildWait(wait, repoName, ciProvider, outcome string, start time.Time) {
+	if s.Metrics == nil {
+		return
+	}
+	s.Metrics.ObserveBuildWaitDuration(wait, repoName, ciProvider, time.Since(start).Seconds())
+	s.Metrics.IncreaseBuildWaitOutcomes(wait, repoName, ciProvider, outcome)
 }
 
 func (b *Builds) getInstallationVersion(pr *model.PullRequest) string {
@@ -30,6 +146,10 @@ func (b *Builds) getInstallationVersion(pr *model.PullRequest) string {
 
 func (b *Builds) buildJenkinsClient(s *Server, pr *model.PullRequest) (*Repository, *jenkins.Jenkins, error) {
 	repo, ok := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
+	if ok && getCIProvider(repo, pr.RepoName) != ciProviderJenkins {
+		// The build is followed through the GitHub checks, no client is needed.
+		return repo, nil, nil
+	}
 	if !ok || repo.JenkinsServer == "" {
 		return repo, nil, errors.New("jenkins server is not configured")
 	}
@@ -46,92 +166,172 @@ func (b *Builds) buildJenkinsClient(s *Server, pr *model.PullRequest) (*Reposito
 	return repo, client, nil
 }
 
-func (b *Builds) waitForImage(ctx context.Context, s *Server, reg *registry.Registry, pr *model.PullRequest) (*model.PullRequest, error) {
+// getCIProvider returns the CI system building the repository named repoName.
+func getCIProvider(repo *Repository, repoName string) string {
+	if repo != nil && repo.CIProvider != "" {
+		return strings.ToLower(repo.CIProvider)
+	}
+	// mattermost-webapp moved to CircleCI before the provider was configurable.
+	if repoName == "mattermost-webapp" {
+		return ciProviderCircleCI
+	}
+	return ciProviderJenkins
+}
+
+// getCheckRunBuildResult tells if the build reported through a check run, as CircleCI does,
+// is done. An error is returned if it failed.
+func getCheckRunBuildResult(status, conclusion string) (bool, error) {
+	switch status {
+	case "queued", "in_progress":
+		return false, nil
+	case "completed":
+		if conclusion == "success" {
+			return true, nil
+		}
+		return false, errors.New("build failed")
+	default:
+		return false, errors.Errorf("unknown build status %s", status)
+	}
+}
+
+// getGitLabBuildResult tells if the GitLab pipeline reported through the commit status is done.
+// An error is returned if it failed. Both the GitHub status states and the GitLab pipeline states are known.
+func getGitLabBuildResult(state string) (bool, error) {
+	switch state {
+	case "", "pending", "created", "waiting_for_resource", "preparing", "scheduled", "running":
+		return false, nil
+	case "success":
+		return true, nil
+	case "failure", "failed", "error":
+		return false, errors.New("build failed")
+	case "canceled":
+		return false, errors.New("build canceled")
+	default:
+		return false, errors.Errorf("unknown build status %s", state)
+	}
+}
+
+// waitForImage waits until the registry has image tagged with the version of the PR build,
+// or with the tag of image if it has one.
+func (b *Builds) waitForImage(ctx context.Context, s *Server, reg *registry.Registry, image *dockerImage, pr *model.PullRequest) (*model.PullRequest, error) {
+	repo, _ := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
+	ciProvider := getCIProvider(repo, pr.RepoName)
+	start, repoName, outcome := time.Now(), pr.RepoName, buildWaitFailure
+	defer func() { s.observeBuildWait(buildWaitImage, repoName, ciProvider, outcome, start) }()
+
 	for {
 		select {
 		case <-ctx.Done():
+			outcome = getBuildWaitDoneOutcome(ctx)
+			if outcome == buildWaitCanceled {
+				return pr, errors.New("canceled waiting for image to publish")
+			}
 			return pr, errors.New("timed out waiting for image to publish")
-		case <-time.After(10 * time.Second):
+		case <-time.After(imagePollInterval):
 			var err error
 			pr, err = s.Store.PullRequest().Get(pr.RepoOwner, pr.RepoName, pr.Number)
 			if err != nil {
+				outcome = buildWaitError
 				return nil, errors.Wrap(err, "unable to get updated PR from Mattermod database")
 			}
 
 			// Update the PR in case the build link has changed because of a new commit
-			desiredTag := b.getInstallationVersion(pr)
-			image := "mattermost/mattermost-enterprise-edition"
+			desiredTag := image.Tag
+			if desiredTag == "" {
+				desiredTag = b.getInstallationVersion(pr)
+			}
 
-			_, err = reg.ManifestDigest(image, desiredTag)
+			_, err = reg.ManifestDigest(image.Repository, desiredTag)
 			if err != nil && !strings.Contains(err.Error(), "status=404") {
+				outcome = buildWaitError
 				return pr, errors.Wrap(err, "unable to fetch tag from docker registry")
 			}
 
 			if err == nil {
-				mlog.Info("docker tag found, image was uploaded", mlog.String("image", image), mlog.String("tag", desiredTag))
+				mlog.Info("docker tag found, image was uploaded", mlog.String("image", image.String()), mlog.String("tag", desiredTag))
+				outcome = buildWaitSuccess
 				return pr, nil
 			}
 
-			mlog.Info("docker tag for the build not found. waiting a bit more...", mlog.String("image", image), mlog.String("tag", desiredTag), mlog.String("repo", pr.RepoName), mlog.Int("number", pr.Number))
+			mlog.Info("docker tag for the build not found. waiting a bit more...", mlog.String("image", image.String()), mlog.String("tag", desiredTag), mlog.String("repo", pr.RepoName), mlog.Int("number", pr.Number))
 		}
 	}
 }
 
 func (b *Builds) waitForBuild(ctx context.Context, s *Server, client *jenkins.Jenkins, pr *model.PullRequest) (*model.PullRequest, error) {
+	repo, _ := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
+	ciProvider := getCIProvider(repo, pr.RepoName)
+	start, repoName, outcome := time.Now(), pr.RepoName, buildWaitFailure
+	defer func() { s.observeBuildWait(buildWaitBuild, repoName, ciProvider, outcome, start) }()
+
+	updateChecksFailures := 0
 	for {
 		select {
 		case <-ctx.Done():
+			outcome = getBuildWaitDoneOutcome(ctx)
+			if outcome == buildWaitCanceled {
+				return pr, errors.New("canceled waiting for build to finish")
+			}
 			return pr, errors.New("timed out waiting for build to finish")
-		case <-time.After(30 * time.Second):
+		case <-time.After(buildPollInterval):
 			var err error
 			pr, err = s.Store.PullRequest().Get(pr.RepoOwner, pr.RepoName, pr.Number)
 			if err != nil {
+				outcome = buildWaitError
 				return nil, errors.Wrap(err, "unable to get updated PR from Mattermod database")
 			}
 
 			// Update the PR in case the build link has changed because of a new commit
-			pr, err = s.GetUpdateChecks(ctx, pr.RepoOwner, pr.RepoName, pr.Number)
+			updatedPR, err := s.GetUpdateChecks(ctx, pr.RepoOwner, pr.RepoName, pr.Number)
 			if err != nil {
-				return pr, errors.Wrap(err, "unable to get updated PR from GitHub")
+				// GitHub errors are usually transient, so keep waiting with what we know.
+				updateChecksFailures++
+				if updateChecksFailures >= maxUpdateChecksFailures {
+					outcome = buildWaitError
+					return pr, errors.Wrapf(err, "unable to get updated PR from GitHub after %d attempts", updateChecksFailures)
+				}
+				mlog.Warn("Unable to get updated PR from GitHub, will retry", mlog.Int("pr", pr.Number), mlog.Int("failures", updateChecksFailures), mlog.Err(err))
+				continue
 			}
+			updateChecksFailures = 0
+			pr = updatedPR
 			mlog.Info("Current PR Status", mlog.String("repo_name", pr.RepoName), mlog.String("build_status", pr.BuildStatus), mlog.String("build_conclusion", pr.BuildConclusion))
 
-			if pr.RepoName == "mattermost-webapp" {
-				switch pr.BuildStatus {
-				case "in_progress":
-					mlog.Info("Build in CircleCI is still in progress")
-				case "completed":
-					if pr.BuildConclusion == "success" {
-						mlog.Info("Build in CircleCI succeed")
-						return pr, nil
-					}
-					return pr, errors.New("build failed")
-				default:
-					return pr, errors.Errorf("unknown build status %s", pr.BuildStatus)
+			if ciProvider != ciProviderJenkins {
+				var done bool
+				if ciProvider == ciProviderGitLab {
+					done, err = getGitLabBuildResult(pr.BuildStatus)
+				} else {
+					done, err = getCheckRunBuildResult(pr.BuildStatus, pr.BuildConclusion)
+				}
+				if err != nil {
+					return pr, err
+				}
+				if !done {
+					mlog.Info("Build is still in progress", mlog.String("ci_provider", ciProvider))
+					continue
 				}
+				mlog.Info("Build succeeded", mlog.String("ci_provider", ciProvider))
+				outcome = buildWaitSuccess
+				return pr, nil
 			} else {
 				if pr.BuildLink == "" {
 					mlog.Info("No build link found; skipping...")
 				} else {
 					mlog.Info("BuildLink for PR", mlog.Int("pr", pr.Number), mlog.String("repo_owner", pr.RepoOwner), mlog.String("repo_name", pr.RepoName), mlog.String("buildlink", pr.BuildLink))
-					// Doing this because the lib we are using does not support folders :(
-					var jobNumber int64
-					var jobName string
-
-					parts := strings.Split(pr.BuildLink, "/")
-					// Doing this because the lib we are using does not support folders :(
-					switch pr.RepoName {
-					case serverRepoName:
-						jobNumber, _ = strconv.ParseInt(parts[len(parts)-3], 10, 32)
-						jobName = parts[len(parts)-6]     //mattermost-server
-						subJobName := parts[len(parts)-4] //PR-XXXX
-						jobName = "mp/job/" + jobName + "/job/" + subJobName
-					default:
-						return pr, errors.Errorf("unsupported repository %s", pr.RepoName)
+					jobName, jobNumber, err2 := parseJenkinsBuildLink(repo, pr)
+					if err2 != nil {
+						outcome = buildWaitError
+						return pr, err2
 					}
 
-					job, err := client.GetJob(jobName)
+					var job jenkins.Job
+					err = retryJenkinsCall(ctx, func() (errCall error) {
+						job, errCall = client.GetJob(jobName)
+						return errCall
+					})
 					if err != nil {
+						outcome = buildWaitError
 						return pr, errors.Wrapf(err, "failed to get Jenkins job %s", jobName)
 					}
 
@@ -139,14 +339,20 @@ func (b *Builds) waitForBuild(ctx context.Context, s *Server, client *jenkins.Je
 					// This time is in the Jenkins job Name because it returns just the name
 					job.Name = jobName
 
-					build, err := client.GetBuild(job, int(jobNumber))
+					var build jenkins.Build
+					err = retryJenkinsCall(ctx, func() (errCall error) {
+						build, errCall = client.GetBuild(job, int(jobNumber))
+						return errCall
+					})
 					if err != nil {
-						return pr, errors.Wrapf(err, "failed to get Jenkins build %d", build.Number)
+						outcome = buildWaitError
+						return pr, errors.Wrapf(err, "failed to get Jenkins build %d of job %s", jobNumber, jobName)
 					}
 
 					switch {
 					case !build.Building && build.Result == "SUCCESS":
 						mlog.Info("build for PR succeeded!", mlog.Int("build_number", build.Number), mlog.Int("pr", pr.Number), mlog.String("repo_owner", pr.RepoOwner), mlog.String("repo_name", pr.RepoName))
+						outcome = buildWaitSuccess
 						return pr, nil
 					case build.Result == "FAILURE" || build.Result == "ABORTED":
 						return pr, errors.Errorf("build %d failed with status %q", build.Number, build.Result)
@@ -161,39 +367,83 @@ func (b *Builds) waitForBuild(ctx context.Context, s *Server, client *jenkins.Je
 	}
 }
 
-func (b *Builds) checkBuildLink(ctx context.Context, s *Server, pr *model.PullRequest) (string, error) {
-	repo, _ := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
-	for {
-		combined, _, err := s.GithubClient.Repositories.GetCombinedStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil)
-		if err != nil {
-			return "", err
+// retryJenkinsCall runs fn until it succeeds, doubling the wait between attempts,
+// so a brief Jenkins hiccup does not end the wait for a build.
+// The last error is returned once jenkinsMaxAttempts is reached.
+func retryJenkinsCall(ctx context.Context, fn func() error) error {
+	backoff := jenkinsInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
 		}
-		for _, status := range combined.Statuses {
-			if *status.Context == repo.BuildStatusContext {
-				if *status.TargetURL != "" {
-					return *status.TargetURL, nil
-				}
-			}
-		}
-
-		// for the repos using circleci we have the checks now
-		checks, _, err := s.GithubClient.Checks.ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil)
-		if err != nil {
-			return "", err
-		}
-		for _, status := range checks.CheckRuns {
-			if *status.Name == repo.BuildStatusContext {
-				return status.GetHTMLURL(), nil
-			}
+		if attempt >= jenkinsMaxAttempts {
+			return errors.Wrapf(err, "giving up after %d attempts", attempt)
 		}
+		mlog.Warn("Jenkins call failed, retrying", mlog.Int("attempt", attempt), mlog.Err(err))
 
 		select {
 		case <-ctx.Done():
-			if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, "Timed out waiting for build link. Please check the logs."); err != nil {
-				mlog.Warn("Error while commenting", mlog.Err(err))
-			}
-			return "", errors.New("timed out waiting the build link")
-		case <-time.After(10 * time.Second):
+			return errors.Wrap(err, "timed out retrying the Jenkins call")
+		case <-time.After(backoff):
 		}
+		backoff *= 2
+	}
+}
+
+// isStaleCheckRun is true if the check run ran on another commit than sha,
+// in which case its link is not the build of sha.
+func isStaleCheckRun(checkRun *github.CheckRun, sha string) bool {
+	return checkRun.GetHeadSHA() != "" && checkRun.GetHeadSHA() != sha
+}
+
+// defaultJenkinsJobPathTemplate is the Jenkins job building the PRs of mattermost-server
+// when the repository has no JenkinsJobPathTemplate.
+const defaultJenkinsJobPathTemplate = "mp/job/{repo}/job/PR-{number}"
+
+// getJenkinsJobName renders the Jenkins job building the PR from the JenkinsJobPathTemplate of the repo.
+func getJenkinsJobName(repo *Repository, pr *model.PullRequest) (string, error) {
+	template := ""
+	if repo != nil {
+		template = repo.JenkinsJobPathTemplate
+	}
+	if template == "" {
+		if pr.RepoName != serverRepoName {
+			return "", errors.Errorf("unsupported repository %s", pr.RepoName)
+		}
+		template = defaultJenkinsJobPathTemplate
+	}
+	return strings.NewReplacer("{repo}", pr.RepoName, "{number}", strconv.Itoa(pr.Number)).Replace(template), nil
+}
+
+// parseJenkinsBuildLink returns the Jenkins job name and build number the build link of a PR points to.
+// The job name comes from the configuration of the repo, only the build number is read from the link.
+func parseJenkinsBuildLink(repo *Repository, pr *model.PullRequest) (string, int64, error) {
+	jobName, err := getJenkinsJobName(repo, pr)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The job "a/job/b" is at "/job/a/job/b/" and its builds right below.
+	jobPath := "/job/" + jobName + "/"
+	i := strings.Index(pr.BuildLink, jobPath)
+	if i < 0 {
+		return "", 0, errors.Errorf("unexpected build link format: %s is not a build of the Jenkins job %s", pr.BuildLink, jobName)
+	}
+	buildNumber := strings.SplitN(pr.BuildLink[i+len(jobPath):], "/", 2)[0]
+	jobNumber, err := strconv.ParseInt(buildNumber, 10, 32)
+	if err != nil || jobNumber <= 0 {
+		return "", 0, errors.Errorf("unexpected build link format: invalid build number %q in %s", buildNumber, pr.BuildLink)
+	}
+
+	return jobName, jobNumber, nil
+}
+
+// getCheckRunLink returns the HTML URL of a check run, or its details URL
+// for CI systems that only populate the latter.
+func getCheckRunLink(checkRun *github.CheckRun) string {
+	if link := checkRun.GetHTMLURL(); link != "" {
+		return link
 	}
+	return checkRun.GetDetailsURL()
 }
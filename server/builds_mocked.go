@@ -22,14 +22,10 @@ func (b *MockedBuilds) buildJenkinsClient(s *Server, pr *model.PullRequest) (*Re
 	return nil, nil, nil
 }
 
-func (b *MockedBuilds) waitForImage(ctx context.Context, s *Server, reg *registry.Registry, pr *model.PullRequest) (*model.PullRequest, error) {
+func (b *MockedBuilds) waitForImage(ctx context.Context, s *Server, reg *registry.Registry, image *dockerImage, pr *model.PullRequest) (*model.PullRequest, error) {
 	return pr, nil
 }
 
 func (b *MockedBuilds) waitForBuild(ctx context.Context, s *Server, client *jenkins.Jenkins, pr *model.PullRequest) (*model.PullRequest, error) {
 	return pr, nil
 }
-
-func (b *MockedBuilds) checkBuildLink(ctx context.Context, s *Server, pr *model.PullRequest) (string, error) {
-	return "mocked", nil
-}
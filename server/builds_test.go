@@ -0,0 +1,415 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJenkinsBuildLink(t *testing.T) {
+	pr := &model.PullRequest{RepoName: serverRepoName, Number: 1234, BuildLink: "https://build.example.com/job/mp/job/mattermost-server/job/PR-1234/5/display/redirect"}
+	jobName, jobNumber, err := parseJenkinsBuildLink(nil, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "mp/job/mattermost-server/job/PR-1234", jobName)
+	assert.EqualValues(t, 5, jobNumber)
+
+	t.Run("templated path", func(t *testing.T) {
+		repo := &Repository{JenkinsJobPathTemplate: "pr/job/{repo}-{number}"}
+		webappPR := &model.PullRequest{RepoName: "mattermost-webapp", Number: 42, BuildLink: "https://build.example.com/job/pr/job/mattermost-webapp-42/7/"}
+		jobName, jobNumber, err = parseJenkinsBuildLink(repo, webappPR)
+		require.NoError(t, err)
+		assert.Equal(t, "pr/job/mattermost-webapp-42", jobName)
+		assert.EqualValues(t, 7, jobNumber)
+	})
+
+	t.Run("unsupported repository", func(t *testing.T) {
+		_, _, err = parseJenkinsBuildLink(&Repository{}, &model.PullRequest{RepoName: "mattermost-webapp", BuildLink: "https://build.example.com/job/mattermost-webapp/5/display/redirect"})
+		require.Error(t, err)
+	})
+
+	t.Run("malformed links", func(t *testing.T) {
+		for _, link := range []string{
+			"",
+			"https://build.example.com",
+			"PR-1234/5/display/redirect",
+			"https://build.example.com/job/mp/job/mattermost-server/job/PR-1234/latest/display/redirect",
+			"https://build.example.com/job/mp/job/mattermost-server/job/PR-1234/",
+			"https://build.example.com/job/mp/job/mattermost-server/job/PR-1234/0/",
+			"https://build.example.com/job/mp/job/mattermost-server/job/PR-999/5/display/redirect",
+		} {
+			_, _, err = parseJenkinsBuildLink(nil, &model.PullRequest{RepoName: serverRepoName, Number: 1234, BuildLink: link})
+			require.Error(t, err, link)
+			assert.Contains(t, err.Error(), "unexpected build link format")
+		}
+	})
+}
+
+func TestGetCheckRunLink(t *testing.T) {
+	assert.Equal(t, "https://html", getCheckRunLink(&github.CheckRun{
+		HTMLURL:    github.String("https://html"),
+		DetailsURL: github.String("https://details"),
+	}))
+	assert.Equal(t, "https://details", getCheckRunLink(&github.CheckRun{
+		DetailsURL: github.String("https://details"),
+	}))
+	assert.Equal(t, "", getCheckRunLink(&github.CheckRun{}))
+}
+
+func TestGetCIProvider(t *testing.T) {
+	assert.Equal(t, ciProviderJenkins, getCIProvider(nil, serverRepoName))
+	assert.Equal(t, ciProviderJenkins, getCIProvider(&Repository{}, serverRepoName))
+	assert.Equal(t, ciProviderCircleCI, getCIProvider(&Repository{}, "mattermost-webapp"))
+	assert.Equal(t, ciProviderCircleCI, getCIProvider(&Repository{CIProvider: "CircleCI"}, serverRepoName))
+	assert.Equal(t, ciProviderJenkins, getCIProvider(&Repository{CIProvider: ciProviderJenkins}, "mattermost-webapp"))
+	assert.Equal(t, ciProviderGitLab, getCIProvider(&Repository{CIProvider: "GitLab"}, serverRepoName))
+}
+
+func TestGetCheckRunBuildResult(t *testing.T) {
+	for _, tc := range []struct {
+		status, conclusion string
+		done, failed       bool
+	}{
+		{"in_progress", "", false, false},
+		{"completed", "success", true, false},
+		{"completed", "failure", false, true},
+		{"queued", "", false, false},
+		{"waiting", "", false, true},
+	} {
+		done, err := getCheckRunBuildResult(tc.status, tc.conclusion)
+		assert.Equal(t, tc.done, done, tc.status)
+		assert.Equal(t, tc.failed, err != nil, tc.status)
+	}
+}
+
+func TestGetGitLabBuildResult(t *testing.T) {
+	for _, tc := range []struct {
+		state        string
+		done, failed bool
+	}{
+		{"", false, false},
+		{"pending", false, false},
+		{"running", false, false},
+		{"success", true, false},
+		{"failed", false, true},
+		{"failure", false, true},
+		{"error", false, true},
+		{"canceled", false, true},
+		{"unexpected", false, true},
+	} {
+		done, err := getGitLabBuildResult(tc.state)
+		assert.Equal(t, tc.done, done, tc.state)
+		assert.Equal(t, tc.failed, err != nil, tc.state)
+	}
+}
+
+func TestBuildJenkinsClient(t *testing.T) {
+	s := &Server{Config: &Config{
+		Repositories: []*Repository{
+			{Owner: "mattertest", Name: serverRepoName, JenkinsServer: "jenkins"},
+			{Owner: "mattertest", Name: "mattermost-mobile", CIProvider: ciProviderCircleCI},
+			{Owner: "mattertest", Name: "mattermost-redux"},
+		},
+		JenkinsCredentials: map[string]*JenkinsCredentials{"jenkins": {URL: "https://build.example.com"}},
+	}}
+	b := &Builds{}
+
+	t.Run("jenkins repository", func(t *testing.T) {
+		repo, client, err := b.buildJenkinsClient(s, &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName})
+		require.NoError(t, err)
+		assert.Equal(t, serverRepoName, repo.Name)
+		assert.NotNil(t, client)
+	})
+
+	t.Run("repository built by another provider", func(t *testing.T) {
+		repo, client, err := b.buildJenkinsClient(s, &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-mobile"})
+		require.NoError(t, err)
+		assert.Equal(t, "mattermost-mobile", repo.Name)
+		assert.Nil(t, client)
+	})
+
+	t.Run("jenkins repository without a server", func(t *testing.T) {
+		_, _, err := b.buildJenkinsClient(s, &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-redux"})
+		require.EqualError(t, err, "jenkins server is not configured")
+	})
+}
+
+func TestRetryJenkinsCall(t *testing.T) {
+	defer func(backoff time.Duration) { jenkinsInitialBackoff = backoff }(jenkinsInitialBackoff)
+	jenkinsInitialBackoff = time.Millisecond
+
+	t.Run("recovers from a transient error", func(t *testing.T) {
+		calls := 0
+		err := retryJenkinsCall(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return errors.New("connection reset")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after the max attempts", func(t *testing.T) {
+		calls := 0
+		err := retryJenkinsCall(context.Background(), func() error {
+			calls++
+			return errors.New("job not found")
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "job not found")
+		assert.Equal(t, jenkinsMaxAttempts, calls)
+	})
+}
+
+func TestWaitForBuildUpdateChecksFailures(t *testing.T) {
+	defer func(interval time.Duration) { buildPollInterval = interval }(buildPollInterval)
+	buildPollInterval = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	prs := stmock.NewMockPullRequestStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().PullRequest().Return(prs).AnyTimes()
+	prService := mocks.NewMockPullRequestsService(ctrl)
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+
+	s := &Server{
+		Config:       &Config{},
+		Store:        ss,
+		GithubClient: &GithubClient{PullRequests: prService},
+		Metrics:      metricsMock,
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, BuildStatus: "pending"}
+
+	metricsMock.EXPECT().ObserveBuildWaitDuration(buildWaitBuild, serverRepoName, ciProviderJenkins, gomock.Any())
+	metricsMock.EXPECT().IncreaseBuildWaitOutcomes(buildWaitBuild, serverRepoName, ciProviderJenkins, buildWaitError)
+
+	prs.EXPECT().Get(pr.RepoOwner, pr.RepoName, pr.Number).Return(pr, nil).Times(maxUpdateChecksFailures)
+	prService.EXPECT().Get(gomock.Any(), pr.RepoOwner, pr.RepoName, pr.Number).Return(nil, nil, errors.New("502 bad gateway")).Times(maxUpdateChecksFailures)
+
+	b := &Builds{}
+	npr, err := b.waitForBuild(context.Background(), s, nil, pr)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502 bad gateway")
+	assert.Equal(t, pr, npr)
+}
+
+func TestWaitForBuildCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	s := &Server{Config: &Config{}, Metrics: metricsMock}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123}
+
+	// Neither is counted as a failed build.
+	metricsMock.EXPECT().ObserveBuildWaitDuration(buildWaitBuild, serverRepoName, ciProviderJenkins, gomock.Any()).Times(2)
+	metricsMock.EXPECT().IncreaseBuildWaitOutcomes(buildWaitBuild, serverRepoName, ciProviderJenkins, buildWaitCanceled)
+	metricsMock.EXPECT().IncreaseBuildWaitOutcomes(buildWaitBuild, serverRepoName, ciProviderJenkins, buildWaitTimeout)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	b := &Builds{}
+	npr, err := b.waitForBuild(ctx, s, nil, pr)
+	require.EqualError(t, err, "canceled waiting for build to finish")
+	assert.Equal(t, pr, npr)
+	assert.Less(t, int64(time.Since(start)), int64(buildPollInterval))
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	_, err = b.waitForBuild(ctx, s, nil, pr)
+	require.EqualError(t, err, "timed out waiting for build to finish")
+}
+
+func TestWaitForBuildOutcomes(t *testing.T) {
+	defer func(interval time.Duration) { buildPollInterval = interval }(buildPollInterval)
+	buildPollInterval = time.Millisecond
+
+	for _, tc := range []struct {
+		name        string
+		buildStatus string
+		outcome     string
+	}{
+		{name: "success", buildStatus: "success", outcome: buildWaitSuccess},
+		{name: "failure", buildStatus: "failed", outcome: buildWaitFailure},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+			prs := stmock.NewMockPullRequestStore(ctrl)
+			ss := stmock.NewMockStore(ctrl)
+			ss.EXPECT().PullRequest().Return(prs).AnyTimes()
+			prService := mocks.NewMockPullRequestsService(ctrl)
+			rs := mocks.NewMockRepositoriesService(ctrl)
+			cs := mocks.NewMockChecksService(ctrl)
+			is := mocks.NewMockIssuesService(ctrl)
+			metricsMock := mocks.NewMockMetricsProvider(ctrl)
+
+			s := &Server{
+				Config: &Config{Repositories: []*Repository{
+					{Owner: "mattertest", Name: serverRepoName, CIProvider: ciProviderGitLab, BuildStatusContext: "ci"},
+				}},
+				Store:        ss,
+				GithubClient: &GithubClient{PullRequests: prService, Repositories: rs, Checks: cs, Issues: is},
+				Metrics:      metricsMock,
+			}
+			pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, Sha: "sha"}
+
+			prs.EXPECT().Get(pr.RepoOwner, pr.RepoName, pr.Number).Return(pr, nil)
+			prService.EXPECT().Get(ctx, pr.RepoOwner, pr.RepoName, pr.Number).Return(&github.PullRequest{
+				Number: github.Int(pr.Number),
+				Base: &github.PullRequestBranch{Repo: &github.Repository{
+					Owner: &github.User{Login: github.String(pr.RepoOwner)},
+					Name:  github.String(pr.RepoName),
+				}},
+				Head: &github.PullRequestBranch{SHA: github.String(pr.Sha)},
+			}, nil, nil)
+			rs.EXPECT().GetCombinedStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil).Return(&github.CombinedStatus{
+				Statuses: []*github.RepoStatus{{Context: github.String("ci"), State: github.String(tc.buildStatus)}},
+			}, nil, nil)
+			cs.EXPECT().ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil).Return(&github.ListCheckRunsResults{}, nil, nil)
+			is.EXPECT().ListLabelsByIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, nil).Return(nil, nil, nil)
+			prs.EXPECT().Save(gomock.Any()).Return(nil, nil).AnyTimes()
+
+			metricsMock.EXPECT().ObserveBuildWaitDuration(buildWaitBuild, serverRepoName, ciProviderGitLab, gomock.Any())
+			metricsMock.EXPECT().IncreaseBuildWaitOutcomes(buildWaitBuild, serverRepoName, ciProviderGitLab, tc.outcome)
+
+			b := &Builds{}
+			_, err := b.waitForBuild(ctx, s, nil, pr)
+			if tc.outcome == buildWaitSuccess {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, "build failed")
+			}
+		})
+	}
+}
+
+func TestWaitForImageCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	s := &Server{Config: &Config{}, Metrics: metricsMock}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123}
+
+	metricsMock.EXPECT().ObserveBuildWaitDuration(buildWaitImage, serverRepoName, ciProviderJenkins, gomock.Any())
+	metricsMock.EXPECT().IncreaseBuildWaitOutcomes(buildWaitImage, serverRepoName, ciProviderJenkins, buildWaitCanceled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	image, err := getDockerImage(nil)
+	require.NoError(t, err)
+
+	b := &Builds{}
+	_, err = b.waitForImage(ctx, s, nil, image, pr)
+	require.EqualError(t, err, "canceled waiting for image to publish")
+}
+
+func TestWaitForBuildAfterForcePush(t *testing.T) {
+	defer func(interval time.Duration) { buildPollInterval = interval }(buildPollInterval)
+	buildPollInterval = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	prs := stmock.NewMockPullRequestStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().PullRequest().Return(prs).AnyTimes()
+	prService := mocks.NewMockPullRequestsService(ctrl)
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	cs := mocks.NewMockChecksService(ctrl)
+	is := mocks.NewMockIssuesService(ctrl)
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+
+	s := &Server{
+		Config: &Config{Repositories: []*Repository{
+			{Owner: "mattertest", Name: serverRepoName, CIProvider: ciProviderCircleCI, BuildStatusContext: "ci"},
+		}},
+		Store:        ss,
+		GithubClient: &GithubClient{PullRequests: prService, Repositories: rs, Checks: cs, Issues: is},
+		Metrics:      metricsMock,
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, Sha: "oldsha"}
+
+	prs.EXPECT().Get(pr.RepoOwner, pr.RepoName, pr.Number).Return(pr, nil).Times(2)
+	prService.EXPECT().Get(ctx, pr.RepoOwner, pr.RepoName, pr.Number).Return(&github.PullRequest{
+		Number: github.Int(pr.Number),
+		Base: &github.PullRequestBranch{Repo: &github.Repository{
+			Owner: &github.User{Login: github.String(pr.RepoOwner)},
+			Name:  github.String(pr.RepoName),
+		}},
+		Head: &github.PullRequestBranch{SHA: github.String("newsha")},
+	}, nil, nil).Times(2)
+	rs.EXPECT().GetCombinedStatus(ctx, pr.RepoOwner, pr.RepoName, "newsha", nil).Return(&github.CombinedStatus{}, nil, nil).Times(2)
+	// The failed check run of the previous commit is discarded on every check.
+	stale := &github.CheckRun{Name: github.String("ci"), HeadSHA: github.String("oldsha"), Status: github.String("completed"),
+		Conclusion: github.String("failure"), HTMLURL: github.String("https://build/old")}
+	gomock.InOrder(
+		cs.EXPECT().ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, "newsha", nil).Return(&github.ListCheckRunsResults{
+			CheckRuns: []*github.CheckRun{stale, {Name: github.String("ci"), HeadSHA: github.String("newsha"), Status: github.String("in_progress")}},
+		}, nil, nil),
+		cs.EXPECT().ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, "newsha", nil).Return(&github.ListCheckRunsResults{
+			CheckRuns: []*github.CheckRun{stale, {Name: github.String("ci"), HeadSHA: github.String("newsha"), Status: github.String("completed"),
+				Conclusion: github.String("success"), HTMLURL: github.String("https://build/new")}},
+		}, nil, nil),
+	)
+	is.EXPECT().ListLabelsByIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, nil).Return(nil, nil, nil).Times(2)
+	prs.EXPECT().Save(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	metricsMock.EXPECT().ObserveBuildWaitDuration(buildWaitBuild, serverRepoName, ciProviderCircleCI, gomock.Any())
+	metricsMock.EXPECT().IncreaseBuildWaitOutcomes(buildWaitBuild, serverRepoName, ciProviderCircleCI, buildWaitSuccess)
+
+	b := &Builds{}
+	npr, err := b.waitForBuild(ctx, s, nil, pr)
+	require.NoError(t, err)
+	assert.Equal(t, "newsha", npr.Sha)
+	assert.Equal(t, "https://build/new", npr.BuildLink)
+}
+
+func TestParseDockerImage(t *testing.T) {
+	for ref, expected := range map[string]*dockerImage{
+		"mattermost/mattermost-enterprise-edition":         {Repository: "mattermost/mattermost-enterprise-edition"},
+		"mattermost/mattermost-enterprise-edition:5.35.0":  {Repository: "mattermost/mattermost-enterprise-edition", Tag: "5.35.0"},
+		"registry.internal/mattermost/enterprise":          {Registry: "registry.internal", Repository: "mattermost/enterprise"},
+		"registry.internal/mattermost/enterprise:tag":      {Registry: "registry.internal", Repository: "mattermost/enterprise", Tag: "tag"},
+		"registry.internal:5000/mattermost/enterprise:tag": {Registry: "registry.internal:5000", Repository: "mattermost/enterprise", Tag: "tag"},
+		"localhost/mattermost":                             {Registry: "localhost", Repository: "mattermost"},
+		"mattermost":                                       {Repository: "mattermost"},
+	} {
+		t.Run(ref, func(t *testing.T) {
+			image, err := parseDockerImage(ref)
+			require.NoError(t, err)
+			assert.Equal(t, expected, image)
+		})
+	}
+
+	image, err := parseDockerImage("registry.internal:5000/mattermost/enterprise:tag")
+	require.NoError(t, err)
+	assert.Equal(t, "registry.internal:5000/mattermost/enterprise", image.String())
+
+	for _, ref := range []string{"", "mattermost/enterprise:", "registry.internal/", "registry.internal/mattermost/enterprise@sha256:abc"} {
+		_, err := parseDockerImage(ref)
+		assert.Error(t, err, ref)
+	}
+}
@@ -34,10 +34,16 @@ type CircleCIService interface {
 	TriggerPipelineWithContext(ctx context.Context, vcsType circleci.VcsType, account, repo, branch, tag string, params map[string]interface{}) (*circleci.Pipeline, error)
 	// GetPipelineWorkflowWithContext returns a list of paginated workflows by pipeline ID
 	GetPipelineWorkflowWithContext(ctx context.Context, pipelineID, pageToken string) (*circleci.WorkflowList, error)
+	// CancelBuildWithContext cancels the given build.
+	CancelBuildWithContext(ctx context.Context, vcsType circleci.VcsType, account, repo string, buildNum int) (*circleci.Build, error)
 }
 
 func (s *Server) triggerCircleCIIfNeeded(ctx context.Context, pr *model.PullRequest) error {
 	mlog.Info("Checking if need trigger CircleCI", mlog.String("repo", pr.RepoName), mlog.Int("pr", pr.Number), mlog.String("fullname", pr.FullName))
+	if s.isDraftSkipped(pr) {
+		mlog.Info("Not triggering CircleCI for a draft PR", mlog.String("repo", pr.RepoName), mlog.Int("pr", pr.Number))
+		return nil
+	}
 	repoInfo := strings.Split(pr.FullName, "/")
 	if repoInfo[0] == s.Config.Org {
 		// It is from upstream mattermost repo don't need to trigger the circleci because org members
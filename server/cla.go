@@ -10,12 +10,109 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v33/github"
 	"github.com/mattermost/mattermost-mattermod/model"
 	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
 )
 
+const msgCLARecheck = "Checking the CLA of @%s again. The `%s` status will be updated shortly."
+
+// claRecheckMaxIntervals caps the wait for the pushes to settle when CLARecheckMaxDelaySeconds is unset.
+const claRecheckMaxIntervals = 5
+
+// claFetchAttempts bounds how many times the signed CLA list is fetched before the check gives up.
+const claFetchAttempts = 3
+
+// claFetchRetryWait is the wait between two attempts at fetching the signed CLA list.
+var claFetchRetryWait = 2 * time.Second
+
+// claFetchFailedRecheckDelay is the wait before checking the CLA again once the signed CLA list
+// could not be fetched at all. It doubles after every failed recheck of the same commit.
+var claFetchFailedRecheckDelay = 5 * time.Minute
+
+// claFetchFailedRechecks caps how many times the CLA of a commit is checked again on its own
+// after the signed CLA list could not be fetched.
+const claFetchFailedRechecks = 5
+
+// claFetchFailuresTTL is how long the failed fetches of a commit are remembered.
+const claFetchFailuresTTL = 24 * time.Hour
+
+// claFetchFailures counts the failed signed CLA list fetches of every commit, so that the failure
+// is only commented once per commit and its rechecks back off and stop.
+type claFetchFailures struct {
+	mu     sync.Mutex
+	counts map[string]*claFetchFailure
+}
+
+// claFetchFailure is the number of failed fetches of a commit and when the last one happened.
+type claFetchFailure struct {
+	count int
+	at    time.Time
+}
+
+func newCLAFetchFailures() *claFetchFailures {
+	return &claFetchFailures{counts: make(map[string]*claFetchFailure)}
+}
+
+// add records a failed fetch for the commit of the PR and returns how many it had so far.
+// A nil tracker counts every failure as the first one.
+func (f *claFetchFailures) add(pr *model.PullRequest) int {
+	if f == nil {
+		return 1
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	for key, failure := range f.counts {
+		if now.Sub(failure.at) > claFetchFailuresTTL {
+			delete(f.counts, key)
+		}
+	}
+
+	key := fmt.Sprintf("%s/%s#%d@%s", pr.RepoOwner, pr.RepoName, pr.Number, pr.Sha)
+	failure, ok := f.counts[key]
+	if !ok {
+		failure = &claFetchFailure{}
+		f.counts[key] = failure
+	}
+	failure.count++
+	failure.at = now
+	return failure.count
+}
+
+// checkCLAOnPush checks the CLA for a new commit of the PR. With a recheck interval
+// configured, the check waits for the pushes to settle and only runs for the last commit.
+func (s *Server) checkCLAOnPush(ctx context.Context, pr *model.PullRequest) {
+	if s.Config.CLARecheckIntervalSeconds <= 0 || s.prDebouncer == nil {
+		if _, err := s.handleCheckCLA(ctx, pr); err != nil {
+			mlog.Error("Unable to check CLA", mlog.Err(err))
+		}
+		return
+	}
+
+	interval := time.Duration(s.Config.CLARecheckIntervalSeconds) * time.Second
+	maxDelay := time.Duration(s.Config.CLARecheckMaxDelaySeconds) * time.Second
+	if maxDelay <= 0 {
+		maxDelay = claRecheckMaxIntervals * interval
+	}
+	s.prDebouncer.call(getPRDebounceKey(pr, "cla"), interval, maxDelay, func() { s.recheckCLA(pr) })
+}
+
+// recheckCLA checks the CLA of the PR from a debounced call, outside of the event that scheduled it.
+func (s *Server) recheckCLA(pr *model.PullRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout*time.Second)
+	defer cancel()
+	if _, err := s.handleCheckCLA(ctx, pr); err != nil {
+		mlog.Error("Unable to check CLA", mlog.Err(err))
+	}
+}
+
 // handleCheckCLA checks if the author of a pull request has signed the CLA and sets a status accordingly.
 // Returns true, if the user hasn't signed yet.
 func (s *Server) handleCheckCLA(ctx context.Context, pr *model.PullRequest) (bool, error) {
@@ -38,24 +135,25 @@ func (s *Server) handleCheckCLA(ctx context.Context, pr *model.PullRequest) (boo
 		status := &github.RepoStatus{
 			State:       github.String(stateSuccess),
 			Description: github.String(fmt.Sprintf("%s excluded", username)),
-			TargetURL:   github.String(s.Config.SignedCLAURL),
-			Context:     github.String(s.Config.CLAGithubStatusContext),
+			TargetURL:   github.String(s.getCLAURL(pr)),
+			Context:     github.String(s.getCLAStatusContext(pr)),
 		}
 		mlog.Debug("will succeed CLA status for excluded user", mlog.String("user", username))
 		return false, s.createRepoStatus(ctx, pr, status)
 	}
 
-	body, err := s.getCSV(ctx)
+	signers, err := s.getCLASigners(ctx, s.getCLAURL(pr))
 	if err != nil {
-		return false, nil
+		s.setCLAFetchFailed(ctx, pr)
+		return false, errors.Wrap(err, "unable to get the signed CLA list")
 	}
 
-	if !isNameInCLAList(strings.Split(string(body), "\n"), username) {
+	if !isNameInCLAList(signers, username) {
 		status := &github.RepoStatus{
 			State:       github.String(stateError),
 			Description: github.String(fmt.Sprintf("%v needs to sign the CLA", username)),
-			TargetURL:   github.String(s.Config.SignedCLAURL),
-			Context:     github.String(s.Config.CLAGithubStatusContext),
+			TargetURL:   github.String(s.getCLAURL(pr)),
+			Context:     github.String(s.getCLAStatusContext(pr)),
 		}
 		mlog.Debug("will post error on CLA", mlog.String("user", username))
 		return true, s.createRepoStatus(ctx, pr, status)
@@ -64,53 +162,146 @@ func (s *Server) handleCheckCLA(ctx context.Context, pr *model.PullRequest) (boo
 	status := &github.RepoStatus{
 		State:       github.String(stateSuccess),
 		Description: github.String(fmt.Sprintf("%s authorized", username)),
-		TargetURL:   github.String(s.Config.SignedCLAURL),
-		Context:     github.String(s.Config.CLAGithubStatusContext),
+		TargetURL:   github.String(s.getCLAURL(pr)),
+		Context:     github.String(s.getCLAStatusContext(pr)),
 	}
 	mlog.Debug("will post success on CLA", mlog.String("user", username))
 	return false, s.createRepoStatus(ctx, pr, status)
 }
 
-func (s *Server) getCSV(ctx context.Context) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Config.SignedCLAURL, http.NoBody)
+// handleCheckCLACommand re-checks the CLA of the PR author against a freshly fetched list,
+// so a contributor who signed after opening the PR doesn't have to push a new commit.
+func (s *Server) handleCheckCLACommand(ctx context.Context, pr *model.PullRequest) error {
+	s.claSigners.forget(s.getCLAURL(pr))
+
+	msg := fmt.Sprintf(msgCLARecheck, pr.Username, s.getCLAStatusContext(pr))
+	if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+		mlog.Warn("Error while commenting", mlog.Err(err))
+	}
+
+	_, err := s.handleCheckCLA(ctx, pr)
+	return err
+}
+
+// setCLAFetchFailed leaves the CLA status pending when the signed CLA list could not be fetched,
+// so a contributor doesn't assume the check passed or that the CLA is missing, and checks again
+// after claFetchFailedRecheckDelay, doubled for every failure of the same commit, up to
+// claFetchFailedRechecks times. A push to the PR in the meantime replaces that check with its own.
+// CLAFetchFailedMessage is only commented for the first failure of a commit.
+func (s *Server) setCLAFetchFailed(ctx context.Context, pr *model.PullRequest) {
+	failures := s.claFailures.add(pr)
+	description := "CLA check temporarily unavailable, comment /check-cla to retry"
+	if s.prDebouncer != nil && failures <= claFetchFailedRechecks {
+		delay := claFetchFailedRecheckDelay << uint(failures-1)
+		s.prDebouncer.call(getPRDebounceKey(pr, "cla"), delay, 0, func() { s.recheckCLA(pr) })
+		description = "CLA check temporarily unavailable, will retry"
+	}
+
+	status := &github.RepoStatus{
+		State:       github.String(statePending),
+		Description: github.String(description),
+		TargetURL:   github.String(s.getCLAURL(pr)),
+		Context:     github.String(s.getCLAStatusContext(pr)),
+	}
+	if err := s.createRepoStatus(ctx, pr, status); err != nil {
+		mlog.Warn("Unable to set the CLA status", mlog.Int("pr", pr.Number), mlog.Err(err))
+	}
+
+	if s.Config.CLAFetchFailedMessage != "" && failures == 1 {
+		if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.CLAFetchFailedMessage); err != nil {
+			mlog.Warn("Error while commenting", mlog.Err(err))
+		}
+	}
+}
+
+// getCLAURL returns the signed CLA list the author of the PR is checked against.
+func (s *Server) getCLAURL(pr *model.PullRequest) string {
+	if repo, ok := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName); ok && repo.CLAURL != "" {
+		return repo.CLAURL
+	}
+	return s.Config.SignedCLAURL
+}
+
+// getCLAStatusContext returns the status context the CLA check of the PR is reported with.
+func (s *Server) getCLAStatusContext(pr *model.PullRequest) string {
+	if repo, ok := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName); ok && repo.CLAStatusContext != "" {
+		return repo.CLAStatusContext
+	}
+	return s.Config.CLAGithubStatusContext
+}
+
+// getCLASigners returns the usernames of the signed CLA list at url, cached for CLACacheTTLSeconds.
+// Fetching the list is attempted up to claFetchAttempts times.
+func (s *Server) getCLASigners(ctx context.Context, url string) (map[string]struct{}, error) {
+	return s.claSigners.get(url, time.Duration(s.Config.CLACacheTTLSeconds)*time.Second, func() (map[string]struct{}, error) {
+		for attempt := 1; ; attempt++ {
+			signers, err := s.fetchCLASigners(ctx, url)
+			if err == nil {
+				return signers, nil
+			}
+			if attempt >= claFetchAttempts {
+				return nil, errors.Wrapf(err, "giving up after %d attempts", attempt)
+			}
+			mlog.Warn("Unable to get the signed CLA list, retrying", mlog.String("url", url), mlog.Int("attempt", attempt), mlog.Err(err))
+
+			select {
+			case <-ctx.Done():
+				return nil, errors.Wrap(ctx.Err(), "timed out retrying to get the signed CLA list")
+			case <-time.After(claFetchRetryWait):
+			}
+		}
+	})
+}
+
+func (s *Server) fetchCLASigners(ctx context.Context, url string) (map[string]struct{}, error) {
+	body, contentType, err := s.getCSV(ctx, url)
 	if err != nil {
 		return nil, err
 	}
-	r, err := http.DefaultClient.Do(req) //nolint
+	return parseCLASigners(body, contentType)
+}
+
+// getCSV fetches the signed CLA list at url and returns it with its content type.
+func (s *Server) getCSV(ctx context.Context, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, "", err
+	}
+	r, err := httpClient.Do(req)
 	if err != nil {
 		s.logToMattermost(ctx, "unable to get CLA google csv file Error: ```"+err.Error()+"```")
-		return nil, err
+		return nil, "", err
 	}
 	defer closeBody(r)
 
+	if r.StatusCode != http.StatusOK {
+		err = errors.Errorf("unexpected http status %s", r.Status)
+		s.logToMattermost(ctx, "unable to get CLA google csv file Error: ```"+err.Error()+"```")
+		return nil, "", err
+	}
+
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		s.logToMattermost(ctx, "unable to read CLA google csv file Error: ```"+err.Error()+"```")
-		return nil, err
+		return nil, "", err
 	}
-	return body, nil
+	return body, r.Header.Get("Content-Type"), nil
 }
 
-func isNameInCLAList(usersWhoSignedCLA []string, authorToTrim string) bool {
-	for _, userToTrim := range usersWhoSignedCLA {
-		user := strings.ToLower(strings.TrimSpace(userToTrim))
-		author := strings.ToLower(authorToTrim)
-		if user == author {
-			return true
-		}
-	}
-	return false
+func isNameInCLAList(usersWhoSignedCLA map[string]struct{}, author string) bool {
+	_, ok := usersWhoSignedCLA[strings.ToLower(strings.TrimSpace(author))]
+	return ok
 }
 
 func (s *Server) createCLAPendingStatus(ctx context.Context, pr *model.PullRequest) {
 	status := &github.RepoStatus{
 		State:       github.String(statePending),
 		Description: github.String("Checking if " + pr.Username + " signed CLA"),
-		TargetURL:   github.String(s.Config.SignedCLAURL),
-		Context:     github.String(s.Config.CLAGithubStatusContext),
+		TargetURL:   github.String(s.getCLAURL(pr)),
+		Context:     github.String(s.getCLAStatusContext(pr)),
 	}
 	err := s.createRepoStatus(ctx, pr, status)
 	if err != nil {
-		s.logToMattermost(ctx, "failed to create status for PR: "+strconv.Itoa(pr.Number)+" Context: "+s.Config.CLAGithubStatusContext+" Error: ```"+err.Error()+"```")
+		s.logToMattermost(ctx, "failed to create status for PR: "+strconv.Itoa(pr.Number)+" Context: "+s.getCLAStatusContext(pr)+" Error: ```"+err.Error()+"```")
 	}
 }
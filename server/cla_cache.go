@@ -0,0 +1,103 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"encoding/json"
+	"mime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// claCache keeps the usernames of the signed CLA lists so they are not fetched for every event.
+// Concurrent misses for the same list share a single fetch.
+type claCache struct {
+	mu      sync.Mutex
+	lists   map[string]*claList
+	fetches singleflight.Group
+}
+
+// claList is a signed CLA list as last fetched.
+type claList struct {
+	signers   map[string]struct{}
+	fetchedAt time.Time
+}
+
+func newCLACache() *claCache {
+	return &claCache{lists: make(map[string]*claList)}
+}
+
+// get returns the cached signers of the list at url, fetching them again once they are older
+// than ttl. If the fetch fails, the last good list is returned. A nil cache always fetches.
+func (c *claCache) get(url string, ttl time.Duration, fetch func() (map[string]struct{}, error)) (map[string]struct{}, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	c.mu.Lock()
+	list, ok := c.lists[url]
+	c.mu.Unlock()
+	if ok && time.Since(list.fetchedAt) < ttl {
+		return list.signers, nil
+	}
+
+	// The fetch is slow and retried, so it runs without holding the lock on the other lists.
+	signers, err, _ := c.fetches.Do(url, func() (interface{}, error) {
+		fetched, errFetch := fetch()
+		if errFetch != nil {
+			return nil, errFetch
+		}
+		c.mu.Lock()
+		c.lists[url] = &claList{signers: fetched, fetchedAt: time.Now()}
+		c.mu.Unlock()
+		return fetched, nil
+	})
+	if err != nil {
+		if ok {
+			mlog.Warn("Unable to refresh the signed CLA list, using the last one", mlog.String("url", url), mlog.Err(err))
+			return list.signers, nil
+		}
+		return nil, err
+	}
+	return signers.(map[string]struct{}), nil
+}
+
+// forget drops the cached list at url, so the next get fetches it rather than
+// reusing the list or joining a fetch already in flight.
+func (c *claCache) forget(url string) {
+	if c == nil {
+		return
+	}
+
+	c.fetches.Forget(url)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.lists, url)
+}
+
+// parseCLASigners returns the lowercased usernames of the signed CLA list. A JSON list is
+// an array of usernames, any other content type has one username per line.
+func parseCLASigners(body []byte, contentType string) (map[string]struct{}, error) {
+	var users []string
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/json" {
+		if err := json.Unmarshal(body, &users); err != nil {
+			return nil, errors.Wrap(err, "unable to parse the signed CLA list")
+		}
+	} else {
+		users = strings.Split(string(body), "\n")
+	}
+
+	signers := make(map[string]struct{})
+	for _, user := range users {
+		if user = strings.ToLower(strings.TrimSpace(user)); user != "" {
+			signers[user] = struct{}{}
+		}
+	}
+	return signers, nil
+}
@@ -1,19 +1,433 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIsNameInCLAList(t *testing.T) {
-	usersWhoSignedCLA := []string{"a0", "b"}
+	usersWhoSignedCLA, err := parseCLASigners([]byte("a0\nb\n"), "text/csv")
+	require.NoError(t, err)
 	author := "A0"
 	assert.True(t, isNameInCLAList(usersWhoSignedCLA, author))
 }
 
 func TestIsNotNameInCLAList(t *testing.T) {
-	usersWhoSignedCLA := []string{"a", "b"}
+	usersWhoSignedCLA, err := parseCLASigners([]byte("a\nb\n"), "text/csv")
+	require.NoError(t, err)
 	author := "c"
 	assert.False(t, isNameInCLAList(usersWhoSignedCLA, author))
 }
+
+func TestParseCLASigners(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body        string
+		contentType string
+	}{
+		"lines":        {body: " bobby \r\nalice-bob\n\n", contentType: "text/csv; charset=utf-8"},
+		"no type":      {body: "bobby\nalice-bob"},
+		"json":         {body: `["Bobby", " alice-bob "]`, contentType: "application/json"},
+		"json charset": {body: `["bobby","alice-bob"]`, contentType: "application/json; charset=utf-8"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			usersWhoSignedCLA, err := parseCLASigners([]byte(tc.body), tc.contentType)
+			require.NoError(t, err)
+			assert.Len(t, usersWhoSignedCLA, 2)
+			assert.True(t, isNameInCLAList(usersWhoSignedCLA, "Bobby"))
+			assert.True(t, isNameInCLAList(usersWhoSignedCLA, "alice-bob"))
+			// Prefixes and suffixes of signers did not sign.
+			for _, author := range []string{"bob", "obby", "bobbyy", "alice", "bob-", "-bob", ""} {
+				assert.False(t, isNameInCLAList(usersWhoSignedCLA, author), author)
+			}
+		})
+	}
+
+	_, err := parseCLASigners([]byte("bobby\nalice"), "application/json")
+	require.Error(t, err)
+}
+
+func TestGetCLASigners(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["bobby"]`))
+	}))
+	defer ts.Close()
+
+	s := &Server{Config: &Config{SignedCLAURL: ts.URL}}
+	usersWhoSignedCLA, err := s.getCLASigners(context.Background(), ts.URL)
+	require.NoError(t, err)
+	assert.True(t, isNameInCLAList(usersWhoSignedCLA, "bobby"))
+	assert.False(t, isNameInCLAList(usersWhoSignedCLA, "bob"))
+}
+
+func TestCLACache(t *testing.T) {
+	fetches := 0
+	var fetchErr error
+	fetch := func() (map[string]struct{}, error) {
+		fetches++
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return parseCLASigners([]byte("alice"), "")
+	}
+
+	t.Run("nil cache always fetches", func(t *testing.T) {
+		var c *claCache
+		_, err := c.get("list", time.Hour, fetch)
+		require.NoError(t, err)
+		_, err = c.get("list", time.Hour, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("hit and miss", func(t *testing.T) {
+		fetches = 0
+		c := newCLACache()
+		signers, err := c.get("list", time.Hour, fetch)
+		require.NoError(t, err)
+		assert.True(t, isNameInCLAList(signers, "alice"))
+		_, err = c.get("list", time.Hour, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, 1, fetches)
+
+		c.lists["list"].fetchedAt = time.Now().Add(-2 * time.Hour)
+		_, err = c.get("list", time.Hour, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fetches)
+
+		_, err = c.get("other list", time.Hour, fetch)
+		require.NoError(t, err)
+		assert.Equal(t, 3, fetches)
+	})
+
+	t.Run("refresh failure keeps the last list", func(t *testing.T) {
+		fetches = 0
+		fetchErr = nil
+		c := newCLACache()
+		_, err := c.get("list", 0, fetch)
+		require.NoError(t, err)
+
+		fetchErr = errors.New("connection refused")
+		signers, err := c.get("list", 0, fetch)
+		require.NoError(t, err)
+		assert.True(t, isNameInCLAList(signers, "alice"))
+		assert.Equal(t, 2, fetches)
+
+		_, err = newCLACache().get("list", 0, fetch)
+		require.Error(t, err)
+	})
+
+	t.Run("concurrent misses share a fetch", func(t *testing.T) {
+		c := newCLACache()
+		var calls int32
+		release := make(chan struct{})
+		slowFetch := func() (map[string]struct{}, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return parseCLASigners([]byte("alice"), "")
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				signers, err := c.get("list", time.Hour, slowFetch)
+				assert.NoError(t, err)
+				assert.True(t, isNameInCLAList(signers, "alice"))
+			}()
+		}
+
+		// Another list is served while the fetch is in flight.
+		c.mu.Lock()
+		c.lists["other list"] = &claList{signers: map[string]struct{}{"bob": {}}, fetchedAt: time.Now()}
+		c.mu.Unlock()
+		signers, err := c.get("other list", time.Hour, fetch)
+		require.NoError(t, err)
+		assert.True(t, isNameInCLAList(signers, "bob"))
+
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestHandleCheckCLAFetchFailure(t *testing.T) {
+	defer func(wait time.Duration) { claFetchRetryWait = wait }(claFetchRetryWait)
+	claFetchRetryWait = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+
+	malformedCalls := 0
+	malformed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		malformedCalls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["bobby",`))
+	}))
+	defer malformed.Close()
+
+	flakyCalls := 0
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flakyCalls++
+		if flakyCalls < claFetchAttempts {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("contributor"))
+	}))
+	defer flaky.Close()
+
+	ctx := context.Background()
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	is := mocks.NewMockIssuesService(ctrl)
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123, Username: "contributor", Sha: "sha"}
+	newServer := func(claURL string) *Server {
+		return &Server{
+			Config: &Config{
+				SignedCLAURL:           claURL,
+				CLAGithubStatusContext: "cla/mattermost",
+				CLAFetchFailedMessage:  "Couldn't verify the CLA",
+			},
+			GithubClient: &GithubClient{Repositories: rs, Issues: is},
+		}
+	}
+
+	for name, claURL := range map[string]string{
+		"unreachable source": unreachable.URL,
+		"malformed body":     malformed.URL,
+	} {
+		t.Run(name, func(t *testing.T) {
+			gomock.InOrder(
+				rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).Return(nil, nil, nil),
+				rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, &github.RepoStatus{
+					State:       github.String(statePending),
+					Description: github.String("CLA check temporarily unavailable, will retry"),
+					TargetURL:   github.String(claURL),
+					Context:     github.String("cla/mattermost"),
+				}).Return(nil, nil, nil),
+			)
+			is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String("Couldn't verify the CLA")}).Return(nil, nil, nil)
+
+			s := newServer(claURL)
+			s.prDebouncer = newDebouncer()
+			defer s.prDebouncer.stop()
+			commentNeeded, err := s.handleCheckCLA(ctx, pr)
+			require.Error(t, err)
+			assert.False(t, commentNeeded)
+			assert.Contains(t, s.prDebouncer.timers, getPRDebounceKey(pr, "cla"), "the check is scheduled again")
+		})
+	}
+	assert.Equal(t, claFetchAttempts, malformedCalls)
+
+	t.Run("no recheck without a debouncer", func(t *testing.T) {
+		gomock.InOrder(
+			rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).Return(nil, nil, nil),
+			rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _, _ string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+					assert.Equal(t, "CLA check temporarily unavailable, comment /check-cla to retry", status.GetDescription())
+					return nil, nil, nil
+				}),
+		)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).Return(nil, nil, nil)
+
+		_, err := newServer(unreachable.URL).handleCheckCLA(ctx, pr)
+		require.Error(t, err)
+	})
+
+	t.Run("recovers within the retries", func(t *testing.T) {
+		gomock.InOrder(
+			rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).Return(nil, nil, nil),
+			rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _, _ string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+					assert.Equal(t, stateSuccess, status.GetState())
+					return nil, nil, nil
+				}),
+		)
+
+		commentNeeded, err := newServer(flaky.URL).handleCheckCLA(ctx, pr)
+		require.NoError(t, err)
+		assert.False(t, commentNeeded)
+		assert.Equal(t, claFetchAttempts, flakyCalls)
+	})
+}
+
+func TestSetCLAFetchFailedRechecksAreCapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	is := mocks.NewMockIssuesService(ctrl)
+	s := &Server{
+		Config: &Config{
+			SignedCLAURL:           "https://cla.example.com",
+			CLAGithubStatusContext: "cla/mattermost",
+			CLAFetchFailedMessage:  "Couldn't verify the CLA",
+		},
+		GithubClient: &GithubClient{Repositories: rs, Issues: is},
+		prDebouncer:  newDebouncer(),
+		claFailures:  newCLAFetchFailures(),
+	}
+	defer s.prDebouncer.stop()
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123, Username: "contributor", Sha: "sha"}
+
+	var descriptions []string
+	rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _, _ string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+			descriptions = append(descriptions, status.GetDescription())
+			return nil, nil, nil
+		}).AnyTimes()
+	// The failure is only commented once per commit.
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String("Couldn't verify the CLA")}).Return(nil, nil, nil).Times(2)
+
+	for i := 0; i <= claFetchFailedRechecks; i++ {
+		s.setCLAFetchFailed(ctx, pr)
+	}
+	require.Len(t, descriptions, claFetchFailedRechecks+1)
+	for _, description := range descriptions[:claFetchFailedRechecks] {
+		assert.Equal(t, "CLA check temporarily unavailable, will retry", description)
+	}
+	assert.Equal(t, "CLA check temporarily unavailable, comment /check-cla to retry", descriptions[claFetchFailedRechecks])
+
+	pushed := *pr
+	pushed.Sha = "new-sha"
+	s.setCLAFetchFailed(ctx, &pushed)
+	assert.Equal(t, "CLA check temporarily unavailable, will retry", descriptions[len(descriptions)-1])
+}
+
+func TestHandleCheckCLAPerRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newList := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	globalList := newList("contributor")
+	defer globalList.Close()
+	repoList := newList("someone-else")
+	defer repoList.Close()
+
+	ctx := context.Background()
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	s := &Server{
+		Config: &Config{
+			SignedCLAURL:           globalList.URL,
+			CLAGithubStatusContext: "cla/mattermost",
+			Repositories: []*Repository{
+				{Owner: "mattertest", Name: "mattermost-server"},
+				{Owner: "mattertest", Name: "mattermost-plugin", CLAURL: repoList.URL, CLAStatusContext: "cla/plugin"},
+			},
+		},
+		GithubClient: &GithubClient{Repositories: rs},
+		claSigners:   newCLACache(),
+	}
+
+	for name, tc := range map[string]struct {
+		repoName      string
+		url           string
+		context       string
+		state         string
+		commentNeeded bool
+	}{
+		"global":       {repoName: "mattermost-server", url: globalList.URL, context: "cla/mattermost", state: stateSuccess},
+		"unconfigured": {repoName: "mattermost-webapp", url: globalList.URL, context: "cla/mattermost", state: stateSuccess},
+		"override":     {repoName: "mattermost-plugin", url: repoList.URL, context: "cla/plugin", state: stateError, commentNeeded: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: tc.repoName, Number: 123, Username: "contributor", Sha: "sha"}
+			gomock.InOrder(
+				rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, &github.RepoStatus{
+					State:       github.String(statePending),
+					Description: github.String("Checking if " + pr.Username + " signed CLA"),
+					TargetURL:   github.String(tc.url),
+					Context:     github.String(tc.context),
+				}).Return(nil, nil, nil),
+				rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _, _, _ string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+						assert.Equal(t, tc.state, status.GetState())
+						assert.Equal(t, tc.url, status.GetTargetURL())
+						assert.Equal(t, tc.context, status.GetContext())
+						return nil, nil, nil
+					}),
+			)
+
+			commentNeeded, err := s.handleCheckCLA(ctx, pr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.commentNeeded, commentNeeded)
+		})
+	}
+}
+
+func TestHandleCheckCLACommand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fetches := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		// The contributor signed after the first check.
+		if fetches > 1 {
+			_, _ = w.Write([]byte("contributor"))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	is := mocks.NewMockIssuesService(ctrl)
+	s := &Server{
+		Config: &Config{
+			SignedCLAURL:           ts.URL,
+			CLAGithubStatusContext: "cla/mattermost",
+			CLACacheTTLSeconds:     3600,
+		},
+		GithubClient: &GithubClient{Repositories: rs, Issues: is},
+		claSigners:   newCLACache(),
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123, Username: "contributor", Sha: "sha"}
+
+	expectState := func(state string) {
+		gomock.InOrder(
+			rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).Return(nil, nil, nil),
+			rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _, _, _ string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+					assert.Equal(t, state, status.GetState())
+					return nil, nil, nil
+				}),
+		)
+	}
+
+	expectState(stateError)
+	commentNeeded, err := s.handleCheckCLA(ctx, pr)
+	require.NoError(t, err)
+	assert.True(t, commentNeeded)
+
+	// The cached list is still fresh, but the command fetches it again.
+	expectState(stateSuccess)
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{
+		Body: github.String("Checking the CLA of @contributor again. The `cla/mattermost` status will be updated shortly."),
+	}).Return(nil, nil, nil)
+	require.NoError(t, s.handleCheckCLACommand(ctx, pr))
+	assert.Equal(t, 2, fetches)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+const (
+	// roleSubmitter allows the author of the PR to run a command.
+	roleSubmitter = "submitter"
+	// roleOrgMember allows members of the configured organization to run a command.
+	roleOrgMember = "org_member"
+
+	msgCommandNotAuthorized = "@%s you are not authorized to run `/%s` on this PR."
+)
+
+// defaultCommandPermissions apply to the commands without configured permissions.
+var defaultCommandPermissions = map[string]*CommandPermission{
+	// Anyone else re-checking the CLA would only add noise to the PR.
+	"check-cla": {Roles: []string{roleSubmitter, roleOrgMember}},
+	// Syncing rewrites the stored PR and posts its stored fields, which is for maintainers.
+	"pr-sync": {Roles: []string{roleOrgMember}},
+}
+
+// commandPermissionFallbacks are the commands which, without configured permissions, use those
+// configured for another command, like the spinmint subcommands which used to share "spinmint".
+var commandPermissionFallbacks = map[string]string{
+	"spinmint-transfer":      "spinmint",
+	"spinmint-set":           "spinmint",
+	"spinmint-force-upgrade": "spinmint",
+	"spinmint-reinit":        "spinmint",
+}
+
+// CommandPermission restricts who is allowed to run a comment command.
+// A commenter is authorized if they match any of the roles, organizations or users.
+type CommandPermission struct {
+	Roles []string // Roles can be "submitter" and/or "org_member".
+	Orgs  []string // Orgs are GitHub organizations whose members are allowed.
+	Users []string // Users are GitHub usernames that are allowed.
+}
+
+// isCommandAuthorized checks the configured permissions of the permission key, e.g. "spinmint-set",
+// and replies on the PR with the command, e.g. "spinmint set", when the commenter is not allowed
+// to run it. Commands without configured or default permissions are allowed for everyone.
+func (s *Server) isCommandAuthorized(ctx context.Context, permission, command, commenter string, pr *model.PullRequest) bool {
+	perm, ok := s.Config.CommandPermissions[permission]
+	if !ok {
+		perm, ok = s.Config.CommandPermissions[commandPermissionFallbacks[permission]]
+	}
+	if !ok {
+		perm = defaultCommandPermissions[permission]
+	}
+	if perm == nil {
+		return true
+	}
+
+	if s.hasCommandPermission(ctx, perm, commenter, pr) {
+		return true
+	}
+
+	mlog.Info("Commenter is not authorized to run command", mlog.String("command", command), mlog.String("permission", permission), mlog.String("commenter", commenter), mlog.Int("pr", pr.Number))
+	msg := fmt.Sprintf(msgCommandNotAuthorized, commenter, command)
+	if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+		mlog.Warn("Error while commenting", mlog.Err(err))
+	}
+	return false
+}
+
+func (s *Server) hasCommandPermission(ctx context.Context, perm *CommandPermission, commenter string, pr *model.PullRequest) bool {
+	if contains(perm.Users, commenter) {
+		return true
+	}
+
+	for _, role := range perm.Roles {
+		switch role {
+		case roleSubmitter:
+			if commenter == pr.Username {
+				return true
+			}
+		case roleOrgMember:
+			if s.IsOrgMember(commenter) {
+				return true
+			}
+		default:
+			mlog.Warn("Unknown role in command permissions", mlog.String("role", role))
+		}
+	}
+
+	for _, org := range perm.Orgs {
+		isMember, _, err := s.GithubClient.Organizations.IsMember(ctx, org, commenter)
+		if err != nil {
+			mlog.Warn("Unable to check organization membership", mlog.String("org", org), mlog.String("user", commenter), mlog.Err(err))
+			continue
+		}
+		if isMember {
+			return true
+		}
+	}
+
+	return false
+}
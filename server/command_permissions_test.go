@@ -0,0 +1,104 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCommandAuthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	is := mocks.NewMockIssuesService(ctrl)
+	os := mocks.NewMockOrganizationsService(ctrl)
+
+	s := &Server{
+		Config: &Config{
+			CommandPermissions: map[string]*CommandPermission{
+				"cherry-pick":   {Roles: []string{roleOrgMember}},
+				"update-branch": {Roles: []string{roleSubmitter}, Users: []string{"release-bot"}},
+				"spinmint":      {Orgs: []string{"partners"}},
+			},
+		},
+		GithubClient: &GithubClient{
+			Issues:        is,
+			Organizations: os,
+		},
+		OrgMembers: []string{"member"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermod", Number: 1, Username: "author"}
+	ctx := context.Background()
+
+	t.Run("command without permissions", func(t *testing.T) {
+		assert.True(t, s.isCommandAuthorized(ctx, "autoassign", "autoassign", "someone", pr))
+	})
+
+	t.Run("default permissions", func(t *testing.T) {
+		assert.True(t, s.isCommandAuthorized(ctx, "check-cla", "check-cla", "author", pr))
+		assert.True(t, s.isCommandAuthorized(ctx, "check-cla", "check-cla", "member", pr))
+
+		is.EXPECT().CreateComment(gomock.Any(), "mattertest", "mattermod", 1, &github.IssueComment{
+			Body: github.String("@someone you are not authorized to run `/check-cla` on this PR."),
+		}).Return(nil, nil, nil)
+		assert.False(t, s.isCommandAuthorized(ctx, "check-cla", "check-cla", "someone", pr))
+
+		assert.True(t, s.isCommandAuthorized(ctx, "pr-sync", "pr sync", "member", pr))
+		is.EXPECT().CreateComment(gomock.Any(), "mattertest", "mattermod", 1, &github.IssueComment{
+			Body: github.String("@author you are not authorized to run `/pr sync` on this PR."),
+		}).Return(nil, nil, nil)
+		assert.False(t, s.isCommandAuthorized(ctx, "pr-sync", "pr sync", "author", pr))
+	})
+
+	t.Run("configured permissions replace the default ones", func(t *testing.T) {
+		s.Config.CommandPermissions["check-cla"] = nil
+		defer delete(s.Config.CommandPermissions, "check-cla")
+		assert.True(t, s.isCommandAuthorized(ctx, "check-cla", "check-cla", "someone", pr))
+	})
+
+	t.Run("org member role", func(t *testing.T) {
+		assert.True(t, s.isCommandAuthorized(ctx, "cherry-pick", "cherry-pick", "member", pr))
+	})
+
+	t.Run("submitter role and users", func(t *testing.T) {
+		assert.True(t, s.isCommandAuthorized(ctx, "update-branch", "update-branch", "author", pr))
+		assert.True(t, s.isCommandAuthorized(ctx, "update-branch", "update-branch", "release-bot", pr))
+	})
+
+	t.Run("org membership", func(t *testing.T) {
+		os.EXPECT().IsMember(gomock.Any(), "partners", "partner").Return(true, nil, nil)
+		assert.True(t, s.isCommandAuthorized(ctx, "spinmint", "spinmint ping", "partner", pr))
+	})
+
+	t.Run("spinmint subcommands fall back to the spinmint permissions", func(t *testing.T) {
+		os.EXPECT().IsMember(gomock.Any(), "partners", "partner").Return(true, nil, nil)
+		assert.True(t, s.isCommandAuthorized(ctx, "spinmint-set", "spinmint set", "partner", pr))
+
+		s.Config.CommandPermissions["spinmint-reinit"] = &CommandPermission{Roles: []string{roleOrgMember}}
+		defer delete(s.Config.CommandPermissions, "spinmint-reinit")
+		assert.True(t, s.isCommandAuthorized(ctx, "spinmint-reinit", "spinmint reinit", "member", pr))
+		os.EXPECT().IsMember(gomock.Any(), "partners", "someone").Return(false, nil, nil)
+		is.EXPECT().CreateComment(gomock.Any(), "mattertest", "mattermod", 1, &github.IssueComment{
+			Body: github.String("@someone you are not authorized to run `/spinmint transfer` on this PR."),
+		}).Return(nil, nil, nil)
+		assert.False(t, s.isCommandAuthorized(ctx, "spinmint-transfer", "spinmint transfer", "someone", pr))
+		is.EXPECT().CreateComment(gomock.Any(), "mattertest", "mattermod", 1, &github.IssueComment{
+			Body: github.String("@partner you are not authorized to run `/spinmint reinit` on this PR."),
+		}).Return(nil, nil, nil)
+		assert.False(t, s.isCommandAuthorized(ctx, "spinmint-reinit", "spinmint reinit", "partner", pr))
+	})
+
+	t.Run("not authorized", func(t *testing.T) {
+		is.EXPECT().CreateComment(gomock.Any(), "mattertest", "mattermod", 1, gomock.AssignableToTypeOf(&github.IssueComment{})).
+			Return(nil, nil, nil)
+		assert.False(t, s.isCommandAuthorized(ctx, "cherry-pick", "cherry-pick", "author", pr))
+	})
+}
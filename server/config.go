@@ -5,8 +5,10 @@ package server
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -14,13 +16,21 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	secretFilePrefix = "file://"
+	secretEnvPrefix  = "env://"
+)
+
 const (
 	// In seconds
-	defaultRequestTimeout       = 60
-	defaultEETaskTimeout        = 300
-	defaultCronTaskTimeout      = 600
-	defaultBuildMobileTimeout   = 7200
-	defaultBuildSpinmintTimeout = 2700
+	defaultRequestTimeout          = 60
+	defaultEETaskTimeout           = 300
+	defaultCronTaskTimeout         = 600
+	defaultBuildMobileTimeout      = 7200
+	defaultBuildSpinmintTimeout    = 2700
+	defaultSpinmintCreationTimeout = 900
+	defaultSpinmintRequestTimeout  = 30
+	defaultSpinmintStuckTTL        = 7200
 )
 
 type LabelResponse struct {
@@ -33,11 +43,21 @@ type Repository struct {
 	Name                       string
 	BuildStatusContext         string
 	JenkinsServer              string
+	DockerImage                string // DockerImage is the image the builds are published as, waited for with SpinmintWaitForImage. A host, as in "registry.internal/mattermost/enterprise", replaces DockerRegistryURL and a tag replaces the build version. Defaults to mattermost/mattermost-enterprise-edition.
+	JenkinsJobPathTemplate     string // JenkinsJobPathTemplate is the Jenkins job building the PRs, with {repo} and {number} replaced, such as "mp/job/{repo}/job/PR-{number}".
+	InstallationWaitSeconds    int    // InstallationWaitSeconds overrides SpinmintCreationTimeoutSeconds for the spinmints of the repo.
+	BuildWaitSeconds           int    // BuildWaitSeconds bounds the wait for the build of a PR before setting up its spinmint. Defaults to two hours.
+	CIProvider                 string // CIProvider is the CI system building the repo, "jenkins" by default. With any other provider, such as "circleci" or "gitlab", builds are followed through the BuildStatusContext check and no Jenkins server is needed.
 	InstanceSetupScript        string
 	InstanceSetupUpgradeScript string
+	InstancePostSetupScript    string // InstancePostSetupScript is an optional script run on the spinmint after the standard setup, once Mattermost answers, for repo specific initialization.
 	JobName                    string
 	GreetingTeam               string   // GreetingTeam is the GitHub team responsible for triaging non-member PRs for this repo.
 	GreetingLabels             []string // GreetingLabels are the labels applied automatically to non-member PRs for this repo.
+	BotUsername                string   // BotUsername is the GitHub login mattermod comments as on this repo. Defaults to Username.
+	GithubAccessToken          string   // GithubAccessToken is the token of BotUsername, used to post and clean up the comments on this repo. Defaults to GithubAccessToken.
+	CLAURL                     string   // CLAURL is the signed CLA list the contributors of this repo are checked against. Defaults to SignedCLAURL.
+	CLAStatusContext           string   // CLAStatusContext is the status context of the CLA check on this repo. Defaults to CLAGithubStatusContext.
 }
 
 type JenkinsCredentials struct {
@@ -73,8 +93,10 @@ type Config struct {
 	AutoAssignerTeamID          int64
 	CircleCIToken               string
 
-	TickRateMinutes        int
-	SpinmintExpirationHour int
+	TickRateMinutes                  int
+	SpinmintExpirationHour           int
+	SpinmintReconcileIntervalMinutes int // SpinmintReconcileIntervalMinutes is how often the job server cleans up the spinmints stuck in setup. Zero disables it.
+	SpinmintStuckTTLMinutes          int // SpinmintStuckTTLMinutes is how long a spinmint may stay in setup before it is destroyed. Defaults to two hours.
 
 	DriverName string
 	DataSource string
@@ -83,6 +105,7 @@ type Config struct {
 
 	BlockPRMergeLabels []string
 	AutoPRMergeLabel   string
+	SkipDraftPRBuilds  bool // SkipDraftPRBuilds defers CircleCI builds and spinmints of draft PRs until they are ready for review.
 
 	SetupSpinmintTag                   string
 	SetupSpinmintMessage               string
@@ -90,7 +113,89 @@ type Config struct {
 	SetupSpinmintFailedMessage         string
 	DestroyedSpinmintMessage           string
 	DestroyedExpirationSpinmintMessage string
+	SpinmintReapedMessage              string // SpinmintReapedMessage replaces the destroyed messages when set. REAP_REASON is filled in, and RECREATE_INSTRUCTIONS for expired spinmints, whose PR is still open.
 	SpinmintsUseHTTPS                  bool
+	SpinmintFilestoreBucketPrefix      string // SpinmintFilestoreBucketPrefix namespaces the S3 objects of spinmints created by this mattermod.
+	SpinmintCreationTimeoutSeconds     int    // SpinmintCreationTimeoutSeconds bounds the wait for a new spinmint instance to be running.
+	SpinmintRequestTimeoutSeconds      int    // SpinmintRequestTimeoutSeconds bounds each HTTP request made to a spinmint or the events sink.
+	SpinmintCheckRunName               string // SpinmintCheckRunName names a GitHub check run following the spinmint of the PR commit, with its URL and login once ready. Check runs need mattermod to authenticate as a GitHub App. Unset disables it.
+	SpinmintRequestMaxRetries          int    // SpinmintRequestMaxRetries is how many times a spinmint request failing with a network error or a 502, 503 or 504 is retried. 0 disables retries.
+	SpinmintEventsURL                  string // SpinmintEventsURL receives spinmint lifecycle events as JSON when set.
+	SpinmintEventsAuthToken            string // SpinmintEventsAuthToken is sent as a bearer token to the events sink when set.
+	SpinmintEventsTLSCertFile          string // SpinmintEventsTLSCertFile and SpinmintEventsTLSKeyFile are the client certificate presented to an events sink requiring mutual TLS.
+	SpinmintEventsTLSKeyFile           string
+	SpinmintLicenseFile                string // SpinmintLicenseFile is the path to a Mattermost license uploaded to every spinmint.
+	SpinmintHTTPSReadinessProbe        bool   // SpinmintHTTPSReadinessProbe waits for the spinmint to answer pings over HTTPS before reporting it ready.
+	SpinmintReadinessCheck             string // SpinmintReadinessCheck confirms a running spinmint is reachable before reporting it ready: "tcp" connects to it, "resolve" looks up its hostname, "http" expects its ping to answer OK and "https" does so over HTTPS. It takes precedence over SpinmintHTTPSReadinessProbe.
+	SpinmintBannerText                 string // SpinmintBannerText is shown as a system banner on every spinmint. REPO_NAME and PR_NUMBER are filled in.
+
+	// SpinmintSmokeTest are API calls run as the system admin against every reachable spinmint before it is
+	// reported ready, to catch servers that are up but broken. A failing spinmint is destroyed unless
+	// SpinmintKeepFailedSmokeTest keeps it for debugging.
+	SpinmintSmokeTest           []*SpinmintSmokeTestStep
+	SpinmintKeepFailedSmokeTest bool
+
+	SpinmintReportTimings bool // SpinmintReportTimings adds how long each phase of the setup took to the comment of a ready spinmint.
+
+	// SpinmintServerConfig is a partial Mattermost config, like {"LdapSettings": {"Enable": true}}, merged over
+	// the settings of every new spinmint once it answers pings. The settings it leaves out keep the values of the setup script.
+	SpinmintServerConfig map[string]interface{}
+
+	SpinmintFailureStates []string // SpinmintFailureStates are extra EC2 instance states that end the wait for a spinmint as failed.
+	SpinmintSeedUserCount int      // SpinmintSeedUserCount is the number of users created by the spinmint sample data. The sample data default is used if unset.
+	SpinmintSeedWorkers   int      // SpinmintSeedWorkers caps how many sample data users and teams are created at once. The sample data default is used if unset.
+
+	SpinmintCredentials *SpinmintCredentials // SpinmintCredentials are the accounts created on every spinmint, and posted with its URL.
+
+	// The spinmint status labels are kept on the PR to show the state of its spinmint. Unset ones are not used.
+	SpinmintProvisioningLabel string
+	SpinmintReadyLabel        string
+	SpinmintFailedLabel       string
+
+	SetupSpinmintImportTag  string // SetupSpinmintImportTag marks PRs whose spinmint restores imported data. No sample data is created for them.
+	SetupSpinmintMinimalTag string // SetupSpinmintMinimalTag marks PRs whose spinmint starts clean, with only the system admin and a team. The setup script enables neither LDAP nor EnableTesting on any spinmint, so there is nothing else to leave out; SpinmintServerConfig still applies to them.
+
+	SpinmintCapacity int // SpinmintCapacity is the number of spinmints expected to run at once. New spinmints report how many are in use when set. SpinmintMaxCount replaces it when set.
+	// SpinmintCapacityWarningThreshold is the fraction of the capacity, like 0.8, above which a new spinmint
+	// warns that capacity is nearly full. Zero disables the warning.
+	SpinmintCapacityWarningThreshold float64
+	// SpinmintMaxCount caps the spinmints running at once across all repositories, counting the new ones
+	// still waiting for their build. No new spinmint is created once it is reached, unlike SpinmintCapacity
+	// which only reports. The capacity notes and warnings then use it instead of SpinmintCapacity. Zero disables the cap.
+	SpinmintMaxCount int
+
+	SpinmintReaperConcurrency int // SpinmintReaperConcurrency bounds how many expired spinmints are destroyed at once. Defaults to 5.
+
+	SetupSpinmintRecreateOnPushTag string // SetupSpinmintRecreateOnPushTag makes new commits destroy and recreate the spinmints of the PR.
+
+	SpinmintSoftDelete bool // SpinmintSoftDelete keeps the records of destroyed spinmints, marked as deleted, for auditing.
+
+	// The sender of the emails sent by spinmints. Unset ones keep the Mattermost defaults.
+	SpinmintFeedbackName   string
+	SpinmintFeedbackEmail  string
+	SpinmintReplyToAddress string
+
+	SetupSpinmintSpotTag string // SetupSpinmintSpotTag runs the spinmint of labeled PRs on a cheaper spot instance, which can be interrupted.
+	SpinmintSpotMaxPrice string // SpinmintSpotMaxPrice is the maximum hourly price for spot spinmints. The on-demand price is used if unset.
+
+	SpinmintSettableConfigPaths []string // SpinmintSettableConfigPaths are the settings, or whole sections, maintainers can change with /spinmint set.
+
+	SpinmintDeploymentEnvironment string // SpinmintDeploymentEnvironment names the GitHub deployments created for spinmints. Unset creates no deployments.
+
+	// SpinmintRegions are the other regions PRs can place their spinmints in, with a label made of
+	// SpinmintRegionLabelPrefix and the region name.
+	SpinmintRegions           map[string]*SpinmintRegion
+	SpinmintRegionLabelPrefix string
+
+	SpinmintForkApproval bool // SpinmintForkApproval holds back the spinmints of fork PRs until a maintainer approves each commit with /spinmint approve.
+
+	// SpinmintSizeAliases maps friendly sizes, like "large", to the EC2 instance types used for them.
+	// PRs pick one with a label made of SpinmintSizeLabelPrefix and the size, or with /spinmint create size=<size>.
+	SpinmintSizeAliases     map[string]string
+	SpinmintSizeLabelPrefix string
+	// SpinmintAllowedInstanceTypes lists the EC2 instance types spinmints may use, so that a typo
+	// in a size alias or in AWSInstanceType is caught before launching. An empty list allows any type.
+	SpinmintAllowedInstanceTypes []string
 
 	SetupSpinmintUpgradeTag         string
 	SetupSpinmintUpgradeMessage     string
@@ -121,6 +226,11 @@ type Config struct {
 
 	CLAExclusionsList      []string
 	CLAGithubStatusContext string
+	CLAFetchFailedMessage  string // CLAFetchFailedMessage is commented on the PR when the signed CLA list can't be fetched.
+
+	CLARecheckIntervalSeconds int // CLARecheckIntervalSeconds coalesces the CLA checks of quick successive pushes to a PR. Unset checks on every push.
+	CLARecheckMaxDelaySeconds int // CLARecheckMaxDelaySeconds caps how long pushes can postpone a CLA check. Unset caps it at five intervals.
+	CLACacheTTLSeconds        int // CLACacheTTLSeconds is how long the signed CLA list is kept before fetching it again. Unset fetches it for every check.
 
 	SignedCLAURL     string
 	PRWelcomeMessage string
@@ -130,12 +240,17 @@ type Config struct {
 
 	IssueLabelsToCleanUp []string
 
+	CommandPermissions map[string]*CommandPermission // CommandPermissions maps a comment command, e.g. "cherry-pick" or "spinmint-set", to who may run it.
+
 	JenkinsCredentials map[string]*JenkinsCredentials
 
 	DockerRegistryURL string
 	DockerUsername    string
 	DockerPassword    string
 
+	SpinmintWaitForImage      bool // SpinmintWaitForImage waits for the docker image of the commit in DockerRegistryURL before setting up a spinmint.
+	SpinmintParallelImageWait bool // SpinmintParallelImageWait waits for the image while the build runs, for pipelines publishing it before the build ends.
+
 	BlockListPathsGlobal  []string
 	BlockListPathsPerRepo map[string][]string // BlockListPathsPerRepo is a per repository list of blocked files
 
@@ -154,6 +269,10 @@ type Config struct {
 	AWSDnsSuffix     string
 	AWSSubNetID      string
 
+	// AWSDnsZoneFallbacks are alternate hosted zones of the default region. Spinmints get a record in each of them
+	// as well, and are reported under the first zone, AWSDnsSuffix included, they are reachable through.
+	AWSDnsZoneFallbacks []*SpinmintDNSZone
+
 	MattermostWebhookURL    string
 	MattermostWebhookFooter string
 
@@ -208,9 +327,103 @@ func GetConfig(fileName string) (*Config, error) {
 		return config, errors.Wrap(err, "unable to decode config file")
 	}
 
+	if err = config.resolveSecrets(); err != nil {
+		return config, errors.Wrap(err, "unable to resolve config secrets")
+	}
+
+	if err = config.validate(); err != nil {
+		return config, errors.Wrap(err, "invalid config")
+	}
+
 	return config, nil
 }
 
+// validate rejects the settings mattermod would otherwise only trip over when using them.
+func (c *Config) validate() error {
+	if c.SpinmintCredentials != nil {
+		if err := c.SpinmintCredentials.validate(); err != nil {
+			return errors.Wrap(err, "invalid SpinmintCredentials")
+		}
+	}
+	if err := validateSpinmintReadinessCheck(c.SpinmintReadinessCheck); err != nil {
+		return errors.Wrap(err, "invalid SpinmintReadinessCheck")
+	}
+	for name, region := range c.SpinmintRegions {
+		if err := region.validate(); err != nil {
+			return errors.Wrapf(err, "invalid SpinmintRegions %q", name)
+		}
+	}
+	for i, zone := range c.AWSDnsZoneFallbacks {
+		if err := zone.validate(); err != nil {
+			return errors.Wrapf(err, "invalid AWSDnsZoneFallbacks %d", i)
+		}
+	}
+	return nil
+}
+
+// resolveSecrets replaces the secrets given as a file:// or env:// reference with
+// the content of the file or the environment variable.
+func (c *Config) resolveSecrets() error {
+	secrets := []*string{
+		&c.DataSource,
+		&c.GithubAccessToken,
+		&c.GithubAccessTokenCherryPick,
+		&c.GitHubWebhookSecret,
+		&c.CircleCIToken,
+		&c.DockerPassword,
+		&c.AWSCredentials.ID,
+		&c.AWSCredentials.Secret,
+		&c.AWSCredentials.Token,
+		&c.MattermostWebhookURL,
+		&c.SpinmintEventsAuthToken,
+	}
+	for _, credentials := range c.JenkinsCredentials {
+		if credentials != nil {
+			secrets = append(secrets, &credentials.APIToken)
+		}
+	}
+	if c.SpinmintCredentials != nil {
+		secrets = append(secrets, &c.SpinmintCredentials.AdminPassword, &c.SpinmintCredentials.UserPassword)
+	}
+	for _, repo := range c.Repositories {
+		if repo != nil {
+			secrets = append(secrets, &repo.GithubAccessToken)
+		}
+	}
+
+	for _, secret := range secrets {
+		value, err := resolveSecret(*secret)
+		if err != nil {
+			return err
+		}
+		*secret = value
+	}
+	return nil
+}
+
+// resolveSecret returns the value a secret reference points to. Values without a
+// file:// or env:// prefix are returned as they are.
+func resolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretFilePrefix):
+		path := strings.TrimPrefix(value, secretFilePrefix)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to read secret file %s", path)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Errorf("secret environment variable %s is not set", name)
+		}
+		return secret, nil
+	default:
+		return value, nil
+	}
+}
+
 func GetRepository(repositories []*Repository, owner, name string) (*Repository, bool) {
 	for _, repo := range repositories {
 		if repo.Owner == owner && repo.Name == name {
@@ -0,0 +1,120 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mattermod-secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tokenFile := filepath.Join(dir, "github-token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+	os.Setenv("MATTERMOD_TEST_JENKINS_TOKEN", "env-token")
+	defer os.Unsetenv("MATTERMOD_TEST_JENKINS_TOKEN")
+
+	cfg := &Config{
+		GithubAccessToken: "file://" + tokenFile,
+		CircleCIToken:     "inline-token",
+		JenkinsCredentials: map[string]*JenkinsCredentials{
+			"jenkins": {URL: "https://jenkins.example.com", APIToken: "env://MATTERMOD_TEST_JENKINS_TOKEN"},
+		},
+	}
+	require.NoError(t, cfg.resolveSecrets())
+	assert.Equal(t, "file-token", cfg.GithubAccessToken)
+	assert.Equal(t, "inline-token", cfg.CircleCIToken)
+	assert.Equal(t, "env-token", cfg.JenkinsCredentials["jenkins"].APIToken)
+
+	cfg.AWSCredentials.Secret = "env://MATTERMOD_TEST_UNSET_SECRET"
+	assert.Error(t, cfg.resolveSecrets())
+
+	cfg.AWSCredentials.Secret = "file://" + filepath.Join(dir, "missing")
+	assert.Error(t, cfg.resolveSecrets())
+}
+
+func TestValidateConfigSpinmintCredentials(t *testing.T) {
+	for name, tc := range map[string]struct {
+		credentials *SpinmintCredentials
+		valid       bool
+	}{
+		"not set":       {nil, true},
+		"sample data":   {&SpinmintCredentials{}, true},
+		"full admin":    {&SpinmintCredentials{AdminUsername: "admin", AdminPassword: "Passw0rd!", AdminEmail: "admin@example.com"}, true},
+		"no email":      {&SpinmintCredentials{UserUsername: "user", UserPassword: "Passw0rd!"}, false},
+		"no password":   {&SpinmintCredentials{AdminUsername: "admin", AdminEmail: "admin@example.com"}, false},
+		"no username":   {&SpinmintCredentials{UserPassword: "Passw0rd!", UserEmail: "user@example.com"}, false},
+		"quote":         {&SpinmintCredentials{AdminUsername: "admin", AdminPassword: "Pass\"w0rd", AdminEmail: "admin@example.com"}, false},
+		"dollar":        {&SpinmintCredentials{UserUsername: "user", UserPassword: "Pa$$w0rd", UserEmail: "user@example.com"}, false},
+		"sed delimiter": {&SpinmintCredentials{UserUsername: "user", UserPassword: "Pass|w0rd", UserEmail: "user@example.com"}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := &Config{SpinmintCredentials: tc.credentials}
+			if tc.valid {
+				assert.NoError(t, cfg.validate())
+			} else {
+				assert.Error(t, cfg.validate())
+			}
+		})
+	}
+}
+
+func TestValidateConfigSpinmintRegions(t *testing.T) {
+	for name, tc := range map[string]struct {
+		region *SpinmintRegion
+		valid  bool
+	}{
+		"complete":          {&SpinmintRegion{AWSRegion: "us-west-2", AWSImageID: "ami-west", AWSSecurityGroup: "sg-west", AWSSubNetID: "subnet-west"}, true},
+		"no settings":       {nil, false},
+		"no region":         {&SpinmintRegion{AWSImageID: "ami-west", AWSSecurityGroup: "sg-west", AWSSubNetID: "subnet-west"}, false},
+		"no image":          {&SpinmintRegion{AWSRegion: "us-west-2", AWSSecurityGroup: "sg-west", AWSSubNetID: "subnet-west"}, false},
+		"no security group": {&SpinmintRegion{AWSRegion: "us-west-2", AWSImageID: "ami-west", AWSSubNetID: "subnet-west"}, false},
+		"no subnet":         {&SpinmintRegion{AWSRegion: "us-west-2", AWSImageID: "ami-west", AWSSecurityGroup: "sg-west"}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := &Config{SpinmintRegions: map[string]*SpinmintRegion{"us-west": tc.region}}
+			if tc.valid {
+				assert.NoError(t, cfg.validate())
+			} else {
+				assert.Error(t, cfg.validate())
+			}
+		})
+	}
+}
+
+func TestValidateConfigDNSZoneFallbacks(t *testing.T) {
+	for name, tc := range map[string]struct {
+		zone  *SpinmintDNSZone
+		valid bool
+	}{
+		"complete":       {&SpinmintDNSZone{AWSHostedZoneID: "Z2", AWSDnsSuffix: "spinmint-backup.com"}, true},
+		"no settings":    {nil, false},
+		"no hosted zone": {&SpinmintDNSZone{AWSDnsSuffix: "spinmint-backup.com"}, false},
+		"no DNS suffix":  {&SpinmintDNSZone{AWSHostedZoneID: "Z2"}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := &Config{AWSDnsZoneFallbacks: []*SpinmintDNSZone{tc.zone}}
+			if tc.valid {
+				assert.NoError(t, cfg.validate())
+			} else {
+				assert.Error(t, cfg.validate())
+			}
+		})
+	}
+}
+
+func TestValidateConfigSpinmintReadinessCheck(t *testing.T) {
+	for _, check := range []string{"", "tcp", "resolve", "HTTP", "https"} {
+		assert.NoError(t, (&Config{SpinmintReadinessCheck: check}).validate(), check)
+	}
+	assert.Error(t, (&Config{SpinmintReadinessCheck: "ping"}).validate())
+}
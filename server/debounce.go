@@ -0,0 +1,99 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+)
+
+// debouncer coalesces bursts of calls for the same key into the last one.
+type debouncer struct {
+	mu      sync.Mutex
+	timers  map[string]*debouncedCall
+	stopped bool
+}
+
+type debouncedCall struct {
+	timer *time.Timer
+	first time.Time
+}
+
+func newDebouncer() *debouncer {
+	return &debouncer{
+		timers: make(map[string]*debouncedCall),
+	}
+}
+
+// getPRDebounceKey returns the key debouncing the action for the PR.
+func getPRDebounceKey(pr *model.PullRequest, action string) string {
+	return getPRDebounceKeyPrefix(pr) + action
+}
+
+func getPRDebounceKeyPrefix(pr *model.PullRequest) string {
+	return fmt.Sprintf("%s/%s#%d/", pr.RepoOwner, pr.RepoName, pr.Number)
+}
+
+// call runs fn once delay has passed without another call for the same key, or once maxDelay
+// has passed since the first pending call for the key, so a steady burst can't postpone it forever.
+// A pending fn for the key is dropped in favor of the new one. An unset maxDelay doesn't cap the delay.
+func (d *debouncer) call(key string, delay, maxDelay time.Duration, fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+
+	first := time.Now()
+	if pending, ok := d.timers[key]; ok {
+		pending.timer.Stop()
+		first = pending.first
+	}
+	if maxDelay > 0 {
+		if remaining := time.Until(first.Add(maxDelay)); remaining < delay {
+			delay = remaining
+		}
+	}
+
+	call := &debouncedCall{first: first}
+	call.timer = time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		if d.timers[key] == call {
+			delete(d.timers, key)
+		}
+		d.mu.Unlock()
+		fn()
+	})
+	d.timers[key] = call
+}
+
+// cancelPR drops the pending calls of every action of the PR.
+func (d *debouncer) cancelPR(pr *model.PullRequest) {
+	prefix := getPRDebounceKeyPrefix(pr)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, pending := range d.timers {
+		if strings.HasPrefix(key, prefix) {
+			pending.timer.Stop()
+			delete(d.timers, key)
+		}
+	}
+}
+
+// stop drops the pending calls and ignores the later ones.
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, pending := range d.timers {
+		pending.timer.Stop()
+		delete(d.timers, key)
+	}
+	d.stopped = true
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebouncer(t *testing.T) {
+	d := newDebouncer()
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, call)
+		}
+	}
+
+	d.call("pr-1", 50*time.Millisecond, 0, record("pr-1 first"))
+	d.call("pr-1", 50*time.Millisecond, 0, record("pr-1 second"))
+	d.call("pr-2", 50*time.Millisecond, 0, record("pr-2"))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []string{"pr-1 second", "pr-2"}, calls)
+	assert.Empty(t, d.timers)
+}
+
+func TestDebouncerMaxDelay(t *testing.T) {
+	d := newDebouncer()
+	defer d.stop()
+
+	fired := make(chan struct{}, 1)
+	start := time.Now()
+	for time.Since(start) < 300*time.Millisecond {
+		d.call("pr-1", 50*time.Millisecond, 100*time.Millisecond, func() { fired <- struct{}{} })
+		select {
+		case <-fired:
+			assert.Less(t, int64(time.Since(start)), int64(250*time.Millisecond))
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("the calls postponed fn past its max delay")
+}
+
+func TestDebouncerCancel(t *testing.T) {
+	d := newDebouncer()
+	pr1 := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 1}
+	pr12 := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 12}
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(call string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, call)
+		}
+	}
+
+	d.call(getPRDebounceKey(pr1, "cla"), 50*time.Millisecond, 0, record("pr-1 cla"))
+	d.call(getPRDebounceKey(pr12, "cla"), 50*time.Millisecond, 0, record("pr-12 cla"))
+	d.cancelPR(pr1)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) == 1
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []string{"pr-12 cla"}, calls)
+	mu.Unlock()
+
+	d.call(getPRDebounceKey(pr1, "cla"), 10*time.Millisecond, 0, record("pr-1 cla"))
+	d.stop()
+	d.call(getPRDebounceKey(pr12, "cla"), 10*time.Millisecond, 0, record("pr-12 cla"))
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"pr-12 cla"}, calls)
+	assert.Empty(t, d.timers)
+}
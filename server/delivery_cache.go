@@ -0,0 +1,63 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// deliveryCacheTTL is how long a webhook delivery ID is remembered.
+// GitHub redeliveries on timeout happen well within this window.
+const deliveryCacheTTL = time.Hour
+
+// deliveryCache keeps the IDs of recently processed webhook deliveries
+// so a redelivery of the same event is not handled twice.
+type deliveryCache struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen map[string]time.Time
+}
+
+func newDeliveryCache(ttl time.Duration) *deliveryCache {
+	return &deliveryCache{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// checkAndAdd records the delivery ID and reports whether it was already processed.
+// A nil cache never reports duplicates.
+func (c *deliveryCache) checkAndAdd(id string) bool {
+	if c == nil || id == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for seenID, at := range c.seen {
+		if now.Sub(at) > c.ttl {
+			delete(c.seen, seenID)
+		}
+	}
+
+	if _, ok := c.seen[id]; ok {
+		return true
+	}
+	c.seen[id] = now
+	return false
+}
+
+// remove forgets the delivery ID, so a redelivery of an event that failed is handled again.
+func (c *deliveryCache) remove(id string) {
+	if c == nil || id == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.seen, id)
+}
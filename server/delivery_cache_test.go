@@ -0,0 +1,55 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryCache(t *testing.T) {
+	c := newDeliveryCache(time.Hour)
+
+	assert.False(t, c.checkAndAdd("delivery-1"))
+	assert.True(t, c.checkAndAdd("delivery-1"))
+	assert.False(t, c.checkAndAdd("delivery-2"))
+	assert.False(t, c.checkAndAdd(""))
+	assert.False(t, c.checkAndAdd(""))
+
+	c.seen["delivery-1"] = time.Now().Add(-2 * time.Hour)
+	assert.False(t, c.checkAndAdd("delivery-1"))
+
+	c.remove("delivery-2")
+	assert.False(t, c.checkAndAdd("delivery-2"))
+	c.remove("")
+
+	var nilCache *deliveryCache
+	assert.False(t, nilCache.checkAndAdd("delivery-1"))
+	nilCache.remove("delivery-1")
+}
+
+func TestGithubEventDeliveries(t *testing.T) {
+	s := &Server{deliveries: newDeliveryCache(time.Hour)}
+	send := func(delivery, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/pr_event", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "ping")
+		req.Header.Set("X-GitHub-Delivery", delivery)
+		rec := httptest.NewRecorder()
+		s.githubEvent(rec, req)
+		return rec.Code
+	}
+
+	// A failed delivery is handled again when it is redelivered.
+	assert.Equal(t, http.StatusBadRequest, send("delivery-1", "not json"))
+	assert.Equal(t, http.StatusBadRequest, send("delivery-1", "not json"))
+
+	// A handled delivery is skipped, so the invalid body is not even read.
+	assert.Equal(t, http.StatusOK, send("delivery-2", `{"hook_id": 1}`))
+	assert.Equal(t, http.StatusOK, send("delivery-2", "not json"))
+}
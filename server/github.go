@@ -36,6 +36,7 @@ func (s *Server) GetPullRequestFromGithub(ctx context.Context, pullRequest *gith
 		CreatedAt:           pullRequest.GetCreatedAt(),
 		Merged:              NewBool(pullRequest.GetMerged()),
 		MergeCommitSHA:      pullRequest.GetMergeCommitSHA(),
+		Draft:               pullRequest.GetDraft(),
 		MaintainerCanModify: NewBool(pullRequest.GetMaintainerCanModify()),
 		MilestoneNumber:     NewInt64(int64(pullRequest.GetMilestone().GetNumber())),
 		MilestoneTitle:      NewString(pullRequest.GetMilestone().GetTitle()),
@@ -65,10 +66,10 @@ func (s *Server) GetPullRequestFromGithub(ctx context.Context, pullRequest *gith
 		}
 
 		for _, status := range checks.CheckRuns {
-			if status.GetName() == repo.BuildStatusContext {
+			if status.GetName() == repo.BuildStatusContext && !isStaleCheckRun(status, pr.Sha) {
 				pr.BuildStatus = status.GetStatus()
 				pr.BuildConclusion = status.GetConclusion()
-				pr.BuildLink = status.GetHTMLURL()
+				pr.BuildLink = getCheckRunLink(status)
 				break
 			}
 		}
@@ -127,11 +128,33 @@ func labelsToStringArray(labels []*github.Label) []string {
 }
 
 func (s *Server) sendGitHubComment(ctx context.Context, repoOwner, repoName string, number int, comment string) error {
-	mlog.Debug("Sending GitHub comment", mlog.Int("issue", number), mlog.String("comment", comment))
-	_, _, err := s.GithubClient.Issues.CreateComment(ctx, repoOwner, repoName, number, &github.IssueComment{Body: &comment})
+	_, err := s.createGitHubComment(ctx, repoOwner, repoName, number, comment)
 	return err
 }
 
+// createGitHubComment comments on the issue or PR and returns the created comment.
+func (s *Server) createGitHubComment(ctx context.Context, repoOwner, repoName string, number int, comment string) (*github.IssueComment, error) {
+	mlog.Debug("Sending GitHub comment", mlog.Int("issue", number), mlog.String("comment", comment))
+	created, _, err := s.getBotGithubClient(repoOwner, repoName).Issues.CreateComment(ctx, repoOwner, repoName, number, &github.IssueComment{Body: &comment})
+	return created, err
+}
+
+// getBotUsername returns the GitHub login mattermod comments as on the repository.
+func (s *Server) getBotUsername(repoOwner, repoName string) string {
+	if repo, ok := GetRepository(s.Config.Repositories, repoOwner, repoName); ok && repo.BotUsername != "" {
+		return repo.BotUsername
+	}
+	return s.Config.Username
+}
+
+// getBotGithubClient returns the client commenting as the bot of the repository.
+func (s *Server) getBotGithubClient(repoOwner, repoName string) *GithubClient {
+	if client, ok := s.RepoGithubClients[repoOwner+"/"+repoName]; ok {
+		return client
+	}
+	return s.GithubClient
+}
+
 func (s *Server) removeLabel(ctx context.Context, repoOwner, repoName string, number int, label string) {
 	mlog.Info("Removing label on issue", mlog.Int("issue", number), mlog.String("label", label))
 
@@ -237,6 +260,21 @@ func (s *Server) getMembers(ctx context.Context) (orgMembers []string, err error
 	return members, nil
 }
 
+// CheckGithubConnectivity verifies that the configured GitHub access token
+// is valid by fetching the authenticated user.
+func (s *Server) CheckGithubConnectivity() error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout*time.Second)
+	defer cancel()
+
+	user, _, err := s.GithubClient.Users.Get(ctx, "")
+	if err != nil {
+		return errors.Wrap(err, "unable to authenticate with GitHub")
+	}
+
+	mlog.Info("Authenticated with GitHub", mlog.String("user", user.GetLogin()))
+	return nil
+}
+
 func (s *Server) IsOrgMember(user string) bool {
 	for _, member := range s.OrgMembers {
 		if user == member {
@@ -16,6 +16,8 @@ import (
 )
 
 type ChecksService interface {
+	CreateCheckRun(ctx context.Context, owner, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error)
+	UpdateCheckRun(ctx context.Context, owner, repo string, checkRunID int64, opts github.UpdateCheckRunOptions) (*github.CheckRun, *github.Response, error)
 	ListCheckRunsForRef(ctx context.Context, owner, repo, ref string, opts *github.ListCheckRunsOptions) (*github.ListCheckRunsResults, *github.Response, error)
 }
 
@@ -32,6 +34,10 @@ type IssuesService interface {
 	RemoveLabelForIssue(ctx context.Context, owner string, repo string, number int, label string) (*github.Response, error)
 }
 
+type GistsService interface {
+	Create(ctx context.Context, gist *github.Gist) (*github.Gist, *github.Response, error)
+}
+
 type GitService interface {
 	CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error)
 	DeleteRef(ctx context.Context, owner string, repo string, ref string) (*github.Response, error)
@@ -57,10 +63,14 @@ type PullRequestsService interface {
 }
 
 type RepositoriesService interface {
+	CreateDeployment(ctx context.Context, owner, repo string, request *github.DeploymentRequest) (*github.Deployment, *github.Response, error)
+	CreateDeploymentStatus(ctx context.Context, owner, repo string, deployment int64, request *github.DeploymentStatusRequest) (*github.DeploymentStatus, *github.Response, error)
 	CreateStatus(ctx context.Context, owner, repo, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error)
 	Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
 	GetBranch(ctx context.Context, owner, repo, branch string) (*github.Branch, *github.Response, error)
 	GetCombinedStatus(ctx context.Context, owner, repo, ref string, opts *github.ListOptions) (*github.CombinedStatus, *github.Response, error)
+	IsCollaborator(ctx context.Context, owner, repo, user string) (bool, *github.Response, error)
+	ListDeployments(ctx context.Context, owner, repo string, opts *github.DeploymentsListOptions) ([]*github.Deployment, *github.Response, error)
 	ListTeams(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.Team, *github.Response, error)
 	ListStatuses(ctx context.Context, owner, repo, ref string, opts *github.ListOptions) ([]*github.RepoStatus, *github.Response, error)
 }
@@ -69,17 +79,23 @@ type TeamsService interface {
 	ListTeamMembersBySlug(ctx context.Context, org, slug string, opts *github.TeamListTeamMembersOptions) ([]*github.User, *github.Response, error)
 }
 
+type UsersService interface {
+	Get(ctx context.Context, user string) (*github.User, *github.Response, error)
+}
+
 // GithubClient wraps the github.Client with relevant interfaces.
 type GithubClient struct {
 	client *github.Client
 
 	Checks        ChecksService
+	Gists         GistsService
 	Git           GitService
 	Issues        IssuesService
 	Organizations OrganizationsService
 	PullRequests  PullRequestsService
 	Repositories  RepositoriesService
 	Teams         TeamsService
+	Users         UsersService
 }
 
 // NewGithubClientWithLimiter returns a new Github client with the provided limit and burst tokens
@@ -102,12 +118,14 @@ func NewGithubClientWithLimiter(accessToken string, limit rate.Limit, burstToken
 	return &GithubClient{
 		client:        client,
 		Checks:        client.Checks,
+		Gists:         client.Gists,
 		Git:           client.Git,
 		Issues:        client.Issues,
 		Organizations: client.Organizations,
 		PullRequests:  client.PullRequests,
 		Repositories:  client.Repositories,
 		Teams:         client.Teams,
+		Users:         client.Users,
 	}
 }
 
@@ -3,6 +3,7 @@ package server_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"testing"
@@ -74,6 +75,28 @@ func TestIsOrgMember(t *testing.T) {
 	assert.Equal(t, true, s.IsOrgMember("test1"))
 }
 
+func TestCheckGithubConnectivity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usersMock := mocks.NewMockUsersService(ctrl)
+	s := &server.Server{
+		GithubClient: &server.GithubClient{
+			Users: usersMock,
+		},
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		usersMock.EXPECT().Get(gomock.Any(), "").Return(&github.User{Login: github.String("mattermod")}, nil, nil)
+		require.NoError(t, s.CheckGithubConnectivity())
+	})
+
+	t.Run("bad credentials", func(t *testing.T) {
+		usersMock.EXPECT().Get(gomock.Any(), "").Return(nil, nil, errors.New("401 Bad credentials"))
+		require.Error(t, s.CheckGithubConnectivity())
+	})
+}
+
 func TestCannotGetAllOrgMembersDueToRateLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
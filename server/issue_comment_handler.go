@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
 	"github.com/mattermost/mattermost-server/v5/mlog"
 	"github.com/pkg/errors"
 )
@@ -45,6 +46,16 @@ func (s *Server) issueCommentEventHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Every comment syncs the PR from GitHub, so /pr sync compares with the PR stored beforehand.
+	var storedPR *model.PullRequest
+	var errStoredPR error
+	if ev.HasPRSync() {
+		storedPR, errStoredPR = s.Store.PullRequest().Get(ev.Repository.GetOwner().GetLogin(), ev.Repository.GetName(), ev.Issue.GetNumber())
+		if errStoredPR != nil {
+			mlog.Warn("Unable to get the stored PR", mlog.Int("pr", ev.Issue.GetNumber()), mlog.Err(errStoredPR))
+		}
+	}
+
 	pr, err := s.getPRFromIssueCommentEvent(ctx, ev)
 	if err != nil {
 		mlog.Error("Error getting PR from Comment", mlog.Err(err))
@@ -55,15 +66,15 @@ func (s *Server) issueCommentEventHandler(w http.ResponseWriter, r *http.Request
 
 	errs := make([]error, 0)
 
-	if ev.HasCheckCLA() {
+	if ev.HasCheckCLA() && s.isCommandAuthorized(ctx, "check-cla", "check-cla", commenter, pr) {
 		s.Metrics.IncreaseWebhookRequest("check_cla")
-		if _, err := s.handleCheckCLA(ctx, pr); err != nil {
+		if err := s.handleCheckCLACommand(ctx, pr); err != nil {
 			s.Metrics.IncreaseWebhookErrors("check_cla")
 			errs = append(errs, fmt.Errorf("error checking CLA: %w", err))
 		}
 	}
 
-	if ev.HasCherryPick() {
+	if ev.HasCherryPick() && s.isCommandAuthorized(ctx, "cherry-pick", "cherry-pick", commenter, pr) {
 		s.Metrics.IncreaseWebhookRequest("cherry_pick")
 		if err := s.handleCherryPick(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
 			s.Metrics.IncreaseWebhookErrors("cherry_pick")
@@ -71,7 +82,7 @@ func (s *Server) issueCommentEventHandler(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	if ev.HasAutoAssign() {
+	if ev.HasAutoAssign() && s.isCommandAuthorized(ctx, "autoassign", "autoassign", commenter, pr) {
 		s.Metrics.IncreaseWebhookRequest("auto_assign")
 		if err := s.handleAutoAssign(ctx, ev.Comment.GetHTMLURL(), pr); err != nil {
 			s.Metrics.IncreaseWebhookErrors("auto_assign")
@@ -79,7 +90,7 @@ func (s *Server) issueCommentEventHandler(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	if ev.HasUpdateBranch() {
+	if ev.HasUpdateBranch() && s.isCommandAuthorized(ctx, "update-branch", "update-branch", commenter, pr) {
 		s.Metrics.IncreaseWebhookRequest("update_branch")
 		if err := s.handleUpdateBranch(ctx, commenter, pr); err != nil {
 			s.Metrics.IncreaseWebhookErrors("update_branch")
@@ -87,6 +98,150 @@ func (s *Server) issueCommentEventHandler(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	if ev.HasPRSync() && s.isCommandAuthorized(ctx, "pr-sync", "pr sync", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("pr_sync")
+		if err := s.handlePRSync(ctx, storedPR, errStoredPR, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("pr_sync")
+			errs = append(errs, fmt.Errorf("error syncing the PR: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintCreate() && s.isCommandAuthorized(ctx, "spinmint-create", "spinmint create", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_create")
+		if err := s.handleSpinmintCreate(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_create")
+			errs = append(errs, fmt.Errorf("error creating test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintPing() && s.isCommandAuthorized(ctx, "spinmint", "spinmint ping", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_ping")
+		if err := s.handleSpinmintPing(ctx, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_ping")
+			errs = append(errs, fmt.Errorf("error pinging test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintTransfer() && s.isCommandAuthorized(ctx, "spinmint-transfer", "spinmint transfer", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_transfer")
+		if err := s.handleSpinmintTransfer(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_transfer")
+			errs = append(errs, fmt.Errorf("error transferring test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintSnapshot() && s.isCommandAuthorized(ctx, "spinmint", "spinmint snapshot", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_snapshot")
+		if err := s.handleSpinmintSnapshot(ctx, commenter, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_snapshot")
+			errs = append(errs, fmt.Errorf("error snapshotting test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintGetConfig() && s.isCommandAuthorized(ctx, "spinmint", "spinmint get-config", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_get_config")
+		if err := s.handleSpinmintGetConfig(ctx, commenter, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_get_config")
+			errs = append(errs, fmt.Errorf("error getting test server config: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintUsage() && s.isCommandAuthorized(ctx, "spinmint", "spinmint usage", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_usage")
+		if err := s.handleSpinmintUsage(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_usage")
+			errs = append(errs, fmt.Errorf("error getting test server usage: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintForceUpgrade() && s.isCommandAuthorized(ctx, "spinmint-force-upgrade", "spinmint force-upgrade", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_force_upgrade")
+		if err := s.handleSpinmintForceUpgrade(ctx, commenter, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_force_upgrade")
+			errs = append(errs, fmt.Errorf("error force upgrading test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintReinit() && s.isCommandAuthorized(ctx, "spinmint-reinit", "spinmint reinit", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_reinit")
+		if err := s.handleSpinmintReinit(ctx, commenter, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_reinit")
+			errs = append(errs, fmt.Errorf("error reinitializing test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintSet() && s.isCommandAuthorized(ctx, "spinmint-set", "spinmint set", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_set")
+		if err := s.handleSpinmintSet(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_set")
+			errs = append(errs, fmt.Errorf("error changing test server setting: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintApprove() && s.isCommandAuthorized(ctx, "spinmint", "spinmint approve", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_approve")
+		if err := s.handleSpinmintApprove(ctx, commenter, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_approve")
+			errs = append(errs, fmt.Errorf("error approving test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintHistory() && s.isCommandAuthorized(ctx, "spinmint", "spinmint history", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_history")
+		if err := s.handleSpinmintHistory(ctx, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_history")
+			errs = append(errs, fmt.Errorf("error getting test server history: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintReaper() && s.isCommandAuthorized(ctx, "spinmint", "spinmint reaper", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_reaper")
+		if err := s.handleSpinmintReaper(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_reaper")
+			errs = append(errs, fmt.Errorf("error changing the test server reaper state: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintRename() && s.isCommandAuthorized(ctx, "spinmint", "spinmint rename", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_rename")
+		if err := s.handleSpinmintRename(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_rename")
+			errs = append(errs, fmt.Errorf("error renaming the test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintVersions() && s.isCommandAuthorized(ctx, "spinmint", "spinmint versions", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_versions")
+		if err := s.handleSpinmintVersions(ctx, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_versions")
+			errs = append(errs, fmt.Errorf("error getting the test server versions: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintRollback() && s.isCommandAuthorized(ctx, "spinmint", "spinmint rollback", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_rollback")
+		if err := s.handleSpinmintRollback(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_rollback")
+			errs = append(errs, fmt.Errorf("error rolling back the test server: %w", err))
+		}
+	}
+
+	if ev.HasSpinmintTail() && s.isCommandAuthorized(ctx, "spinmint", "spinmint tail", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("spinmint_tail")
+		if err := s.handleSpinmintTail(ctx, commenter, ev.Comment.GetBody(), pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("spinmint_tail")
+			errs = append(errs, fmt.Errorf("error getting the test server logs: %w", err))
+		}
+	}
+
+	if ev.HasAbortBuild() && s.isCommandAuthorized(ctx, "build", "build abort", commenter, pr) {
+		s.Metrics.IncreaseWebhookRequest("abort_build")
+		if err := s.handleAbortBuild(ctx, commenter, pr); err != nil {
+			s.Metrics.IncreaseWebhookErrors("abort_build")
+			errs = append(errs, fmt.Errorf("error aborting build: %w", err))
+		}
+	}
+
 	for _, err := range errs {
 		mlog.Error("Error handling PR comment", mlog.Err(err))
 	}
@@ -135,3 +290,93 @@ func (e *issueCommentEvent) HasAutoAssign() bool {
 func (e *issueCommentEvent) HasUpdateBranch() bool {
 	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/update-branch")
 }
+
+// HasPRSync is true if body contains "/pr sync"
+func (e *issueCommentEvent) HasPRSync() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/pr sync")
+}
+
+// HasSpinmintCreate is true if body contains "/spinmint create"
+func (e *issueCommentEvent) HasSpinmintCreate() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint create")
+}
+
+// HasSpinmintPing is true if body contains "/spinmint ping"
+func (e *issueCommentEvent) HasSpinmintPing() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint ping")
+}
+
+// HasSpinmintTransfer is true if body contains "/spinmint transfer"
+func (e *issueCommentEvent) HasSpinmintTransfer() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint transfer")
+}
+
+// HasSpinmintSnapshot is true if body contains "/spinmint snapshot"
+func (e *issueCommentEvent) HasSpinmintSnapshot() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint snapshot")
+}
+
+// HasSpinmintGetConfig is true if body contains "/spinmint get-config"
+func (e *issueCommentEvent) HasSpinmintGetConfig() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint get-config")
+}
+
+// HasSpinmintUsage is true if body contains "/spinmint usage"
+func (e *issueCommentEvent) HasSpinmintUsage() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint usage")
+}
+
+// HasSpinmintForceUpgrade is true if body contains "/spinmint force-upgrade"
+func (e *issueCommentEvent) HasSpinmintForceUpgrade() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint force-upgrade")
+}
+
+// HasSpinmintReinit is true if body contains "/spinmint reinit"
+func (e *issueCommentEvent) HasSpinmintReinit() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint reinit")
+}
+
+// HasSpinmintSet is true if body contains "/spinmint set"
+func (e *issueCommentEvent) HasSpinmintSet() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint set")
+}
+
+// HasSpinmintApprove is true if body contains "/spinmint approve"
+func (e *issueCommentEvent) HasSpinmintApprove() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint approve")
+}
+
+// HasSpinmintHistory is true if body contains "/spinmint history"
+func (e *issueCommentEvent) HasSpinmintHistory() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint history")
+}
+
+// HasSpinmintReaper is true if body contains "/spinmint reaper"
+func (e *issueCommentEvent) HasSpinmintReaper() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint reaper")
+}
+
+// HasSpinmintRename is true if body contains "/spinmint rename"
+func (e *issueCommentEvent) HasSpinmintRename() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint rename")
+}
+
+// HasSpinmintTail is true if body contains "/spinmint tail"
+func (e *issueCommentEvent) HasSpinmintTail() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint tail")
+}
+
+// HasSpinmintVersions is true if body contains "/spinmint versions"
+func (e *issueCommentEvent) HasSpinmintVersions() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint versions")
+}
+
+// HasSpinmintRollback is true if body contains "/spinmint rollback"
+func (e *issueCommentEvent) HasSpinmintRollback() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/spinmint rollback")
+}
+
+// HasAbortBuild is true if body contains "/build abort"
+func (e *issueCommentEvent) HasAbortBuild() bool {
+	return strings.Contains(strings.TrimSpace(e.Comment.GetBody()), "/build abort")
+}
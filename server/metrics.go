@@ -43,6 +43,20 @@ type MetricsProvider interface {
 	ObserveCronTaskDuration(name string, elapsed float64)
 	// IncreaseCronTaskErrors stores the number of errors for a cron task
 	IncreaseCronTaskErrors(name string)
+
+	// IncreaseSpinmintEvents stores the number of spinmint lifecycle events, like
+	// created or failed, by repository and instance size
+	IncreaseSpinmintEvents(event, repo, size string)
+	// ObserveSpinmintWaitDuration stores the elapsed time waiting for a spinmint,
+	// like for its instance to run, by repository and instance size
+	ObserveSpinmintWaitDuration(wait, repo, size string, elapsed float64)
+
+	// ObserveBuildWaitDuration stores the elapsed time waiting for a PR build or its
+	// docker image, by repository and CI provider
+	ObserveBuildWaitDuration(wait, repo, ciProvider string, elapsed float64)
+	// IncreaseBuildWaitOutcomes stores the number of waits for a PR build or its docker
+	// image ending in outcome, which is success, failure, error, timeout or canceled
+	IncreaseBuildWaitOutcomes(wait, repo, ciProvider, outcome string)
 }
 
 // Transport is an HTTP transport that would check
@@ -34,6 +34,22 @@ func (m *MockChecksService) EXPECT() *MockChecksServiceMockRecorder {
 	return m.recorder
 }
 
+// CreateCheckRun mocks base method
+func (m *MockChecksService) CreateCheckRun(arg0 context.Context, arg1, arg2 string, arg3 github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCheckRun", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*github.CheckRun)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateCheckRun indicates an expected call of CreateCheckRun
+func (mr *MockChecksServiceMockRecorder) CreateCheckRun(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCheckRun", reflect.TypeOf((*MockChecksService)(nil).CreateCheckRun), arg0, arg1, arg2, arg3)
+}
+
 // ListCheckRunsForRef mocks base method
 func (m *MockChecksService) ListCheckRunsForRef(arg0 context.Context, arg1, arg2, arg3 string, arg4 *github.ListCheckRunsOptions) (*github.ListCheckRunsResults, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -49,3 +65,19 @@ func (mr *MockChecksServiceMockRecorder) ListCheckRunsForRef(arg0, arg1, arg2, a
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCheckRunsForRef", reflect.TypeOf((*MockChecksService)(nil).ListCheckRunsForRef), arg0, arg1, arg2, arg3, arg4)
 }
+
+// UpdateCheckRun mocks base method
+func (m *MockChecksService) UpdateCheckRun(arg0 context.Context, arg1, arg2 string, arg3 int64, arg4 github.UpdateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCheckRun", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*github.CheckRun)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateCheckRun indicates an expected call of UpdateCheckRun
+func (mr *MockChecksServiceMockRecorder) UpdateCheckRun(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCheckRun", reflect.TypeOf((*MockChecksService)(nil).UpdateCheckRun), arg0, arg1, arg2, arg3, arg4)
+}
@@ -48,6 +48,21 @@ func (mr *MockCircleCIServiceMockRecorder) BuildByProjectWithContext(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildByProjectWithContext", reflect.TypeOf((*MockCircleCIService)(nil).BuildByProjectWithContext), arg0, arg1, arg2, arg3, arg4)
 }
 
+// CancelBuildWithContext mocks base method
+func (m *MockCircleCIService) CancelBuildWithContext(arg0 context.Context, arg1 circleci.VcsType, arg2, arg3 string, arg4 int) (*circleci.Build, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBuildWithContext", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*circleci.Build)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelBuildWithContext indicates an expected call of CancelBuildWithContext
+func (mr *MockCircleCIServiceMockRecorder) CancelBuildWithContext(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBuildWithContext", reflect.TypeOf((*MockCircleCIService)(nil).CancelBuildWithContext), arg0, arg1, arg2, arg3, arg4)
+}
+
 // GetPipelineWorkflowWithContext mocks base method
 func (m *MockCircleCIService) GetPipelineWorkflowWithContext(arg0 context.Context, arg1, arg2 string) (*circleci.WorkflowList, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-mattermod/server (interfaces: GistsService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	github "github.com/google/go-github/v33/github"
+	reflect "reflect"
+)
+
+// MockGistsService is a mock of GistsService interface
+type MockGistsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockGistsServiceMockRecorder
+}
+
+// MockGistsServiceMockRecorder is the mock recorder for MockGistsService
+type MockGistsServiceMockRecorder struct {
+	mock *MockGistsService
+}
+
+// NewMockGistsService creates a new mock instance
+func NewMockGistsService(ctrl *gomock.Controller) *MockGistsService {
+	mock := &MockGistsService{ctrl: ctrl}
+	mock.recorder = &MockGistsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGistsService) EXPECT() *MockGistsServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockGistsService) Create(arg0 context.Context, arg1 *github.Gist) (*github.Gist, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(*github.Gist)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create
+func (mr *MockGistsServiceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGistsService)(nil).Create), arg0, arg1)
+}
@@ -32,6 +32,18 @@ func (m *MockMetricsProvider) EXPECT() *MockMetricsProviderMockRecorder {
 	return m.recorder
 }
 
+// IncreaseBuildWaitOutcomes mocks base method
+func (m *MockMetricsProvider) IncreaseBuildWaitOutcomes(arg0, arg1, arg2, arg3 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncreaseBuildWaitOutcomes", arg0, arg1, arg2, arg3)
+}
+
+// IncreaseBuildWaitOutcomes indicates an expected call of IncreaseBuildWaitOutcomes
+func (mr *MockMetricsProviderMockRecorder) IncreaseBuildWaitOutcomes(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseBuildWaitOutcomes", reflect.TypeOf((*MockMetricsProvider)(nil).IncreaseBuildWaitOutcomes), arg0, arg1, arg2, arg3)
+}
+
 // IncreaseCronTaskErrors mocks base method
 func (m *MockMetricsProvider) IncreaseCronTaskErrors(arg0 string) {
 	m.ctrl.T.Helper()
@@ -80,6 +92,18 @@ func (mr *MockMetricsProviderMockRecorder) IncreaseRateLimiterErrors() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseRateLimiterErrors", reflect.TypeOf((*MockMetricsProvider)(nil).IncreaseRateLimiterErrors))
 }
 
+// IncreaseSpinmintEvents mocks base method
+func (m *MockMetricsProvider) IncreaseSpinmintEvents(arg0, arg1, arg2 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncreaseSpinmintEvents", arg0, arg1, arg2)
+}
+
+// IncreaseSpinmintEvents indicates an expected call of IncreaseSpinmintEvents
+func (mr *MockMetricsProviderMockRecorder) IncreaseSpinmintEvents(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseSpinmintEvents", reflect.TypeOf((*MockMetricsProvider)(nil).IncreaseSpinmintEvents), arg0, arg1, arg2)
+}
+
 // IncreaseWebhookErrors mocks base method
 func (m *MockMetricsProvider) IncreaseWebhookErrors(arg0 string) {
 	m.ctrl.T.Helper()
@@ -104,6 +128,18 @@ func (mr *MockMetricsProviderMockRecorder) IncreaseWebhookRequest(arg0 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseWebhookRequest", reflect.TypeOf((*MockMetricsProvider)(nil).IncreaseWebhookRequest), arg0)
 }
 
+// ObserveBuildWaitDuration mocks base method
+func (m *MockMetricsProvider) ObserveBuildWaitDuration(arg0, arg1, arg2 string, arg3 float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveBuildWaitDuration", arg0, arg1, arg2, arg3)
+}
+
+// ObserveBuildWaitDuration indicates an expected call of ObserveBuildWaitDuration
+func (mr *MockMetricsProviderMockRecorder) ObserveBuildWaitDuration(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveBuildWaitDuration", reflect.TypeOf((*MockMetricsProvider)(nil).ObserveBuildWaitDuration), arg0, arg1, arg2, arg3)
+}
+
 // ObserveCronTaskDuration mocks base method
 func (m *MockMetricsProvider) ObserveCronTaskDuration(arg0 string, arg1 float64) {
 	m.ctrl.T.Helper()
@@ -139,3 +175,15 @@ func (mr *MockMetricsProviderMockRecorder) ObserveHTTPRequestDuration(arg0, arg1
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveHTTPRequestDuration", reflect.TypeOf((*MockMetricsProvider)(nil).ObserveHTTPRequestDuration), arg0, arg1, arg2, arg3)
 }
+
+// ObserveSpinmintWaitDuration mocks base method
+func (m *MockMetricsProvider) ObserveSpinmintWaitDuration(arg0, arg1, arg2 string, arg3 float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveSpinmintWaitDuration", arg0, arg1, arg2, arg3)
+}
+
+// ObserveSpinmintWaitDuration indicates an expected call of ObserveSpinmintWaitDuration
+func (mr *MockMetricsProviderMockRecorder) ObserveSpinmintWaitDuration(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveSpinmintWaitDuration", reflect.TypeOf((*MockMetricsProvider)(nil).ObserveSpinmintWaitDuration), arg0, arg1, arg2, arg3)
+}
@@ -34,6 +34,38 @@ func (m *MockRepositoriesService) EXPECT() *MockRepositoriesServiceMockRecorder
 	return m.recorder
 }
 
+// CreateDeployment mocks base method
+func (m *MockRepositoriesService) CreateDeployment(arg0 context.Context, arg1, arg2 string, arg3 *github.DeploymentRequest) (*github.Deployment, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeployment", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*github.Deployment)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateDeployment indicates an expected call of CreateDeployment
+func (mr *MockRepositoriesServiceMockRecorder) CreateDeployment(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeployment", reflect.TypeOf((*MockRepositoriesService)(nil).CreateDeployment), arg0, arg1, arg2, arg3)
+}
+
+// CreateDeploymentStatus mocks base method
+func (m *MockRepositoriesService) CreateDeploymentStatus(arg0 context.Context, arg1, arg2 string, arg3 int64, arg4 *github.DeploymentStatusRequest) (*github.DeploymentStatus, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeploymentStatus", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*github.DeploymentStatus)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateDeploymentStatus indicates an expected call of CreateDeploymentStatus
+func (mr *MockRepositoriesServiceMockRecorder) CreateDeploymentStatus(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeploymentStatus", reflect.TypeOf((*MockRepositoriesService)(nil).CreateDeploymentStatus), arg0, arg1, arg2, arg3, arg4)
+}
+
 // CreateStatus mocks base method
 func (m *MockRepositoriesService) CreateStatus(arg0 context.Context, arg1, arg2, arg3 string, arg4 *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -98,6 +130,38 @@ func (mr *MockRepositoriesServiceMockRecorder) GetCombinedStatus(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCombinedStatus", reflect.TypeOf((*MockRepositoriesService)(nil).GetCombinedStatus), arg0, arg1, arg2, arg3, arg4)
 }
 
+// IsCollaborator mocks base method
+func (m *MockRepositoriesService) IsCollaborator(arg0 context.Context, arg1, arg2, arg3 string) (bool, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCollaborator", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// IsCollaborator indicates an expected call of IsCollaborator
+func (mr *MockRepositoriesServiceMockRecorder) IsCollaborator(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCollaborator", reflect.TypeOf((*MockRepositoriesService)(nil).IsCollaborator), arg0, arg1, arg2, arg3)
+}
+
+// ListDeployments mocks base method
+func (m *MockRepositoriesService) ListDeployments(arg0 context.Context, arg1, arg2 string, arg3 *github.DeploymentsListOptions) ([]*github.Deployment, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeployments", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*github.Deployment)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDeployments indicates an expected call of ListDeployments
+func (mr *MockRepositoriesServiceMockRecorder) ListDeployments(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeployments", reflect.TypeOf((*MockRepositoriesService)(nil).ListDeployments), arg0, arg1, arg2, arg3)
+}
+
 // ListStatuses mocks base method
 func (m *MockRepositoriesService) ListStatuses(arg0 context.Context, arg1, arg2, arg3 string, arg4 *github.ListOptions) ([]*github.RepoStatus, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,103 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+)
+
+const (
+	msgPRSyncUpToDate = "The PR stored by Mattermod was already up to date with GitHub."
+	msgPRSyncNew      = "The PR was not stored by Mattermod yet. It is now synced from GitHub."
+	msgPRSyncNoStored = "The PR is now synced from GitHub, but the PR stored by Mattermod beforehand could not be read to tell what changed."
+)
+
+// prSyncChange is a field of the stored PR that differs from GitHub.
+type prSyncChange struct {
+	Field  string
+	Stored string
+	GitHub string
+}
+
+// getPRSyncChanges returns the fields a sync from GitHub changed in the stored PR.
+func getPRSyncChanges(stored, synced *model.PullRequest) []*prSyncChange {
+	fields := []struct {
+		name           string
+		stored, synced string
+	}{
+		{"Head", shortSha(stored.Sha), shortSha(synced.Sha)},
+		{"Branch", stored.Ref, synced.Ref},
+		{"State", stored.State, synced.State},
+		{"Merged", formatOptionalBool(stored.Merged), formatOptionalBool(synced.Merged)},
+		{"Labels", strings.Join(stored.Labels, ", "), strings.Join(synced.Labels, ", ")},
+		{"Milestone", formatOptionalString(stored.MilestoneTitle), formatOptionalString(synced.MilestoneTitle)},
+		{"Build status", stored.BuildStatus, synced.BuildStatus},
+		{"Build conclusion", stored.BuildConclusion, synced.BuildConclusion},
+		{"Build link", stored.BuildLink, synced.BuildLink},
+	}
+
+	var changes []*prSyncChange
+	for _, f := range fields {
+		if f.stored != f.synced {
+			changes = append(changes, &prSyncChange{Field: f.name, Stored: f.stored, GitHub: f.synced})
+		}
+	}
+	return changes
+}
+
+func formatPRSyncChanges(changes []*prSyncChange) string {
+	if len(changes) == 0 {
+		return msgPRSyncUpToDate
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Synced the PR from GitHub:\n\n")
+	sb.WriteString("| Field | Stored | GitHub |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+	for _, c := range changes {
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", escapeTableCell(c.Field), escapeTableCell(c.Stored), escapeTableCell(c.GitHub))
+	}
+	return sb.String()
+}
+
+// escapeTableCell keeps a value, like a label with a pipe, from breaking the markdown table it is in.
+func escapeTableCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// handlePRSync comments what syncing the PR from GitHub changed. The sync itself happens for
+// every comment, so stored is the PR as it was in the store before this one, and storedErr
+// the error reading it.
+func (s *Server) handlePRSync(ctx context.Context, stored *model.PullRequest, storedErr error, pr *model.PullRequest) error {
+	var msg string
+	switch {
+	case storedErr != nil:
+		msg = msgPRSyncNoStored
+	case stored == nil:
+		msg = msgPRSyncNew
+	default:
+		msg = formatPRSyncChanges(getPRSyncChanges(stored, pr))
+	}
+	return s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg)
+}
+
+func formatOptionalBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+func formatOptionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPRSyncChanges(t *testing.T) {
+	stored := &model.PullRequest{
+		Sha:         "aaaaaaa111",
+		Ref:         "feature",
+		State:       "open",
+		Labels:      []string{"Setup Test Server"},
+		BuildStatus: "pending",
+		Merged:      NewBool(false),
+	}
+	synced := *stored
+
+	assert.Empty(t, getPRSyncChanges(stored, &synced))
+	assert.Equal(t, msgPRSyncUpToDate, formatPRSyncChanges(nil))
+
+	synced.Sha = "bbbbbbb222"
+	synced.Labels = []string{"Setup Test Server", "2: Dev Review"}
+	synced.BuildStatus = "success"
+	synced.MilestoneTitle = NewString("v6.0.0")
+	changes := getPRSyncChanges(stored, &synced)
+	assert.Equal(t, []*prSyncChange{
+		{Field: "Head", Stored: "aaaaaaa", GitHub: "bbbbbbb"},
+		{Field: "Labels", Stored: "Setup Test Server", GitHub: "Setup Test Server, 2: Dev Review"},
+		{Field: "Milestone", Stored: "", GitHub: "v6.0.0"},
+		{Field: "Build status", Stored: "pending", GitHub: "success"},
+	}, changes)
+
+	expected := "Synced the PR from GitHub:\n\n" +
+		"| Field | Stored | GitHub |\n" +
+		"| --- | --- | --- |\n" +
+		"| Head | aaaaaaa | bbbbbbb |\n" +
+		"| Labels | Setup Test Server | Setup Test Server, 2: Dev Review |\n" +
+		"| Milestone |  | v6.0.0 |\n" +
+		"| Build status | pending | success |\n"
+	assert.Equal(t, expected, formatPRSyncChanges(changes))
+
+	escaped := formatPRSyncChanges([]*prSyncChange{{Field: "Labels", Stored: "", GitHub: "Area/Build | CI, Do Not\nMerge"}})
+	assert.Contains(t, escaped, "| Labels |  | Area/Build \\| CI, Do Not Merge |\n")
+}
+
+func TestHandlePRSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	s := &Server{Config: &Config{}, GithubClient: &GithubClient{Issues: is}}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123, Sha: "abcdef123456"}
+
+	t.Run("not stored yet", func(t *testing.T) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgPRSyncNew)}).Return(nil, nil, nil)
+		require.NoError(t, s.handlePRSync(ctx, nil, nil, pr))
+	})
+
+	t.Run("stored PR not readable", func(t *testing.T) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgPRSyncNoStored)}).Return(nil, nil, nil)
+		require.NoError(t, s.handlePRSync(ctx, nil, errors.New("connection refused"), pr))
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		stored := *pr
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgPRSyncUpToDate)}).Return(nil, nil, nil)
+		require.NoError(t, s.handlePRSync(ctx, &stored, nil, pr))
+	})
+}
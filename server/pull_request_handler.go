@@ -135,12 +135,18 @@ func (s *Server) pullRequestEventHandler(w http.ResponseWriter, r *http.Request)
 		}
 
 		// TODO: remove the old test server code
-		if event.Label.GetName() == s.Config.SetupSpinmintTag {
+		if event.Label.GetName() == s.Config.SetupSpinmintTag && !s.isSpinmintApprovalPending(ctx, pr) {
 			mlog.Info("Label to spin a old test server")
-			if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintMessage); err != nil {
+			msg := s.getSetupSpinmintMessage()
+			if s.isDraftSkipped(pr) {
+				msg = msgSpinmintDeferredForDraft
+			}
+			if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
 				mlog.Warn("Error while commenting", mlog.Err(err))
 			}
-			go s.waitForBuildAndSetupSpinmint(pr, false)
+			if !s.isDraftSkipped(pr) {
+				s.runSpinmintFlow("spinmint_setup", pr, func() error { return s.waitForBuildAndSetupSpinmint(pr, false) })
+			}
 		}
 		if s.isBlockPRMerge(*event.Label.Name) {
 			if err = s.unblockPRMerge(ctx, pr); err != nil {
@@ -183,14 +189,12 @@ func (s *Server) pullRequestEventHandler(w http.ResponseWriter, r *http.Request)
 			if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.DestroyedSpinmintMessage); err != nil {
 				mlog.Warn("Error while commenting", mlog.Err(err))
 			}
-			go s.destroySpinmint(pr, spinmint.InstanceID)
+			go s.destroySpinmint(pr, spinmint)
 		}
 	case "synchronize":
 		mlog.Debug("PR has a new commit", mlog.String("repo", pr.RepoName), mlog.Int("pr", pr.Number))
 
-		if _, err = s.handleCheckCLA(ctx, pr); err != nil {
-			mlog.Error("Unable to check CLA", mlog.Err(err))
-		}
+		s.checkCLAOnPush(ctx, pr)
 
 		if err = s.triggerCircleCIIfNeeded(ctx, pr); err != nil {
 			mlog.Error("Unable to trigger CircleCI", mlog.String("repo", pr.RepoName), mlog.Int("pr", pr.Number), mlog.String("fullname", pr.FullName), mlog.Err(err))
@@ -204,30 +208,64 @@ func (s *Server) pullRequestEventHandler(w http.ResponseWriter, r *http.Request)
 		}
 
 		s.setBlockStatusForPR(ctx, pr)
+
+		if s.isSpinmintRecreateOnPush(pr) {
+			spinmints, err2 := s.Store.Spinmint().GetAll(pr.Number, pr.RepoName)
+			if err2 != nil {
+				mlog.Error("Unable to get the spinmint information.", mlog.String("pr_error", err2.Error()))
+				break
+			}
+			// Drafts get their spinmints recreated once they are ready for review.
+			if len(spinmints) > 0 && !s.isDraftSkipped(pr) && !s.isSpinmintApprovalPending(ctx, pr) {
+				if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msgSpinmintRecreating); err != nil {
+					mlog.Warn("Error while commenting", mlog.Err(err))
+				}
+				s.runSpinmintFlow("spinmint_recreate", pr, func() error { return s.recreateSpinmints(pr, spinmints) })
+			}
+		}
+	case "ready_for_review":
+		mlog.Info("PR is ready for review", mlog.String("repo", pr.RepoName), mlog.Int("pr", pr.Number))
+		if !s.Config.SkipDraftPRBuilds {
+			break
+		}
+
+		if err = s.triggerCircleCIIfNeeded(ctx, pr); err != nil {
+			mlog.Error("Unable to trigger CircleCI", mlog.String("repo", pr.RepoName), mlog.Int("pr", pr.Number), mlog.String("fullname", pr.FullName), mlog.Err(err))
+		}
+
+		if !s.isSpinmintApprovalPending(ctx, pr) {
+			s.resumeDraftSpinmint(ctx, pr)
+		}
 	case "closed":
 		mlog.Info("PR was closed", mlog.String("repo", *event.Repo.Name), mlog.Int("pr", event.PRNumber))
 		go s.checkIfNeedCherryPick(pr)
 		go s.CleanUpLabels(pr)
+		if s.prDebouncer != nil {
+			s.prDebouncer.cancelPR(pr)
+		}
 
-		spinmint, err2 := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+		spinmints, err2 := s.Store.Spinmint().GetAll(pr.Number, pr.RepoName)
 		if err2 != nil {
 			mlog.Error("Unable to get the spinmint information.", mlog.String("pr_error", err2.Error()))
 			break
 		}
 
-		if spinmint == nil {
+		if len(spinmints) == 0 {
 			mlog.Info("Nothing to do. There is no Spinmint for this PR", mlog.Int("pr", pr.Number))
 			break
 		}
 
-		mlog.Info("Spinmint instance", mlog.String("spinmint", spinmint.InstanceID))
-		mlog.Info("Will destroy the spinmint for a merged/closed PR.")
+		mlog.Info("Will destroy the spinmints for a merged/closed PR.", mlog.Int("count", len(spinmints)))
 
-		if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.DestroyedSpinmintMessage); err != nil {
+		msg := s.getSpinmintReapedMessage("the PR was closed", s.Config.DestroyedSpinmintMessage, false)
+		if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
 			mlog.Warn("Error while commenting", mlog.Err(err))
 		}
-		if strings.Contains(spinmint.InstanceID, "i-") {
-			go s.destroySpinmint(pr, spinmint.InstanceID)
+		for _, spinmint := range spinmints {
+			mlog.Info("Spinmint instance", mlog.String("spinmint", spinmint.InstanceID))
+			if strings.Contains(spinmint.InstanceID, "i-") {
+				go s.destroySpinmint(pr, spinmint)
+			}
 		}
 	}
 
@@ -354,31 +392,38 @@ func (s *Server) handlePRLabeled(ctx context.Context, pr *model.PullRequest, add
 		return fmt.Errorf("unable to list comments for PR: %w", err)
 	}
 
+	botUsername := s.getBotUsername(pr.RepoOwner, pr.RepoName)
+
 	// Old comment created by Mattermod user for test server deletion will be deleted here
 	for _, comment := range comments {
-		if *comment.User.Login == s.Config.Username &&
-			strings.Contains(*comment.Body, s.Config.DestroyedSpinmintMessage) || strings.Contains(*comment.Body, s.Config.DestroyedExpirationSpinmintMessage) {
+		if *comment.User.Login == botUsername && s.isSpinmintDestroyedComment(*comment.Body) {
 			mlog.Info("Removing old server deletion comment with ID", mlog.Int64("ID", *comment.ID))
-			_, err = s.GithubClient.Issues.DeleteComment(ctx, pr.RepoOwner, pr.RepoName, *comment.ID)
+			_, err = s.getBotGithubClient(pr.RepoOwner, pr.RepoName).Issues.DeleteComment(ctx, pr.RepoOwner, pr.RepoName, *comment.ID)
 			if err != nil {
 				mlog.Error("Unable to remove old server deletion comment", mlog.Err(err))
 			}
 		}
 	}
 
-	if addedLabel == s.Config.SetupSpinmintUpgradeTag && !messageByUserContains(comments, s.Config.Username, s.Config.SetupSpinmintUpgradeMessage) {
+	if addedLabel == s.Config.SetupSpinmintUpgradeTag && !messageByUserContains(comments, botUsername, s.Config.SetupSpinmintUpgradeMessage) {
 		mlog.Info("Label to spin a test server for upgrade")
+		if s.isSpinmintApprovalPending(ctx, pr) {
+			return nil
+		}
+		if s.isDraftSkipped(pr) {
+			return s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msgSpinmintDeferredForDraft)
+		}
 		if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintUpgradeMessage); err != nil {
 			mlog.Warn("Error while commenting", mlog.Err(err))
 		}
-		go s.waitForBuildAndSetupSpinmint(pr, true)
+		s.runSpinmintFlow("spinmint_upgrade", pr, func() error { return s.waitForBuildAndSetupSpinmint(pr, true) })
 	} else {
 		mlog.Info("looking for other labels")
 
 		for _, label := range s.Config.PrLabels {
 			mlog.Info("looking for label", mlog.String("label", label.Label))
 			finalMessage := strings.Replace(label.Message, "USERNAME", pr.Username, -1)
-			if label.Label == addedLabel && !messageByUserContains(comments, s.Config.Username, finalMessage) {
+			if label.Label == addedLabel && !messageByUserContains(comments, botUsername, finalMessage) {
 				mlog.Info("Posted message for label on PR: ", mlog.String("label", label.Label), mlog.Int("pr", pr.Number))
 				if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, finalMessage); err != nil {
 					mlog.Warn("Error while commenting", mlog.Err(err))
@@ -399,10 +444,11 @@ func (s *Server) handlePRUnlabeled(ctx context.Context, pr *model.PullRequest, r
 		return fmt.Errorf("failed fetching comments: %w", err)
 	}
 
+	botUsername := s.getBotUsername(pr.RepoOwner, pr.RepoName)
 	if s.isSpinMintLabel(removedLabel) &&
-		(messageByUserContains(comments, s.Config.Username, s.Config.SetupSpinmintMessage) ||
-			messageByUserContains(comments, s.Config.Username, s.Config.SetupSpinmintUpgradeMessage)) &&
-		!messageByUserContains(comments, s.Config.Username, s.Config.DestroyedSpinmintMessage) {
+		(messageByUserContains(comments, botUsername, s.Config.SetupSpinmintMessage) ||
+			messageByUserContains(comments, botUsername, s.Config.SetupSpinmintUpgradeMessage)) &&
+		!messageByUserContains(comments, botUsername, s.Config.DestroyedSpinmintMessage) {
 		// Old comments created by Mattermod user will be deleted here.
 		s.removeOldComments(ctx, comments, pr)
 
@@ -422,7 +468,7 @@ func (s *Server) handlePRUnlabeled(ctx context.Context, pr *model.PullRequest, r
 		if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.DestroyedSpinmintMessage); err != nil {
 			mlog.Warn("Error while commenting", mlog.Err(err))
 		}
-		go s.destroySpinmint(pr, spinmint.InstanceID)
+		go s.destroySpinmint(pr, spinmint)
 	}
 
 	return nil
@@ -439,12 +485,13 @@ func (s *Server) removeOldComments(ctx context.Context, comments []*github.Issue
 	}
 
 	mlog.Info("Removing old Mattermod comments")
+	botUsername := s.getBotUsername(pr.RepoOwner, pr.RepoName)
 	for _, comment := range comments {
-		if *comment.User.Login == s.Config.Username {
+		if *comment.User.Login == botUsername {
 			for _, message := range serverMessages {
 				if strings.Contains(*comment.Body, message) {
 					mlog.Info("Removing old comment with ID", mlog.Int64("ID", *comment.ID))
-					_, err := s.GithubClient.Issues.DeleteComment(ctx, pr.RepoOwner, pr.RepoName, *comment.ID)
+					_, err := s.getBotGithubClient(pr.RepoOwner, pr.RepoName).Issues.DeleteComment(ctx, pr.RepoOwner, pr.RepoName, *comment.ID)
 					if err != nil {
 						mlog.Error("Unable to remove old Mattermod comment", mlog.Err(err))
 					}
@@ -622,6 +669,11 @@ func (s *Server) CleanUpLabels(pr *model.PullRequest) {
 	wg.Wait()
 }
 
+// isDraftSkipped is true if builds and spinmints of the PR are deferred because it is a draft.
+func (s *Server) isDraftSkipped(pr *model.PullRequest) bool {
+	return s.Config.SkipDraftPRBuilds && pr.Draft
+}
+
 func (s *Server) isBlockPRMerge(label string) bool {
 	for _, blocklabel := range s.Config.BlockPRMergeLabels {
 		if label == blocklabel {
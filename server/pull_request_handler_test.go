@@ -694,3 +694,53 @@ func TestCheckPRActivity(t *testing.T) {
 		s.CheckPRActivity()
 	})
 }
+
+func TestIsDraftSkipped(t *testing.T) {
+	s := &Server{Config: &Config{SetupSpinmintTag: "Setup Test Server"}}
+	draft := &model.PullRequest{Draft: true, Labels: []string{"Setup Test Server"}}
+
+	require.False(t, s.isDraftSkipped(draft))
+
+	s.Config.SkipDraftPRBuilds = true
+	require.True(t, s.isDraftSkipped(draft))
+	require.False(t, s.isDraftSkipped(&model.PullRequest{}))
+	require.True(t, s.hasSetupSpinmintLabel(draft.Labels))
+	require.False(t, s.hasSetupSpinmintLabel([]string{"AutoMerge"}))
+}
+
+func TestRemoveOldCommentsWithRepoBot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	globalIssues := mocks.NewMockIssuesService(ctrl)
+	repoIssues := mocks.NewMockIssuesService(ctrl)
+	s := &Server{
+		Config: &Config{
+			Username:             "mattermod",
+			SetupSpinmintMessage: "Creating a test server.",
+			Repositories: []*Repository{
+				{Owner: "mattermost", Name: "mattermost-server", BotUsername: "server-bot", GithubAccessToken: "token"},
+			},
+		},
+		GithubClient:      &GithubClient{Issues: globalIssues},
+		RepoGithubClients: map[string]*GithubClient{"mattermost/mattermost-server": {Issues: repoIssues}},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server", Number: 123}
+
+	require.Equal(t, "server-bot", s.getBotUsername(pr.RepoOwner, pr.RepoName))
+	require.Equal(t, "mattermod", s.getBotUsername(pr.RepoOwner, "mattermost-webapp"))
+
+	comments := []*github.IssueComment{
+		{ID: github.Int64(1), User: &github.User{Login: github.String("mattermod")}, Body: github.String("Creating a test server.")},
+		{ID: github.Int64(2), User: &github.User{Login: github.String("server-bot")}, Body: github.String("Creating a test server.")},
+	}
+	repoIssues.EXPECT().DeleteComment(ctx, pr.RepoOwner, pr.RepoName, int64(2)).Return(nil, nil)
+	s.removeOldComments(ctx, comments, pr)
+
+	repoIssues.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String("hello")}).Return(nil, nil, nil)
+	require.NoError(t, s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, "hello"))
+
+	globalIssues.EXPECT().CreateComment(ctx, pr.RepoOwner, "mattermost-webapp", pr.Number, &github.IssueComment{Body: github.String("hello")}).Return(nil, nil, nil)
+	require.NoError(t, s.sendGitHubComment(ctx, pr.RepoOwner, "mattermost-webapp", pr.Number, "hello"))
+}
@@ -21,6 +21,10 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
 	"github.com/google/go-github/v33/github"
 	"github.com/gorilla/mux"
 	"github.com/mattermost/go-circleci"
@@ -35,17 +39,30 @@ type Server struct {
 	Config                *Config
 	Store                 store.Store
 	GithubClient          *GithubClient
+	RepoGithubClients     map[string]*GithubClient // RepoGithubClients comment as the bot of the repos with their own token, keyed by owner/name.
 	CircleCiClient        CircleCIService
 	CircleCiClientV2      CircleCIService
 	OrgMembers            []string
 	Builds                buildsInterface
 	commentLock           sync.Mutex
 	StartTime             time.Time
-	awsSession            *session.Session
+	EC2Client             ec2iface.EC2API
+	RegionEC2Clients      map[string]ec2iface.EC2API
+	Route53Client         route53iface.Route53API
 	Metrics               MetricsProvider
 	cherryPickRequests    chan *cherryPickRequest
 	cherryPickStopChan    chan struct{}
 	cherryPickStoppedChan chan struct{}
+	deliveries            *deliveryCache
+	prDebouncer           *debouncer
+	claSigners            *claCache
+	claFailures           *claFetchFailures
+
+	spinmintSlots     map[string]struct{} // spinmintSlots are the PRs holding a SpinmintMaxCount slot for a new spinmint.
+	spinmintSlotsLock sync.Mutex
+
+	spinmintEventsTransport     *http.Transport // spinmintEventsTransport is shared by the events sent with a client certificate.
+	spinmintEventsTransportOnce sync.Once
 
 	server *http.Server
 }
@@ -66,6 +83,8 @@ const (
 	templateSpinmintLink = "SPINMINT_LINK"
 	templateInstanceID   = "INSTANCE_ID"
 	templateInternalIP   = "INTERNAL_IP"
+	templateReapReason   = "REAP_REASON"
+	templateRecreate     = "RECREATE_INSTRUCTIONS"
 
 	serverRepoName = "mattermost-server"
 )
@@ -79,6 +98,10 @@ func New(config *Config, metrics MetricsProvider) (*Server, error) {
 		cherryPickRequests:    make(chan *cherryPickRequest, 20),
 		cherryPickStopChan:    make(chan struct{}),
 		cherryPickStoppedChan: make(chan struct{}),
+		deliveries:            newDeliveryCache(deliveryCacheTTL),
+		prDebouncer:           newDebouncer(),
+		claSigners:            newCLACache(),
+		claFailures:           newCLAFetchFailures(),
 	}
 
 	ghClient, err := NewGithubClient(s.Config.GithubAccessToken, s.Config.GitHubTokenReserve, s.Metrics)
@@ -86,6 +109,10 @@ func New(config *Config, metrics MetricsProvider) (*Server, error) {
 		return nil, err
 	}
 	s.GithubClient = ghClient
+	s.RepoGithubClients, err = newRepoGithubClients(s.Config.Repositories, s.Config.GitHubTokenReserve, s.Metrics)
+	if err != nil {
+		return nil, err
+	}
 	s.CircleCiClient, err = circleci.NewClient(s.Config.CircleCIToken, circleci.APIVersion11)
 	if err != nil {
 		return nil, err
@@ -98,7 +125,13 @@ func New(config *Config, metrics MetricsProvider) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
-	s.awsSession = awsSession
+	s.EC2Client = ec2.New(awsSession, s.GetAwsConfig())
+	s.RegionEC2Clients = make(map[string]ec2iface.EC2API)
+	for name := range s.Config.SpinmintRegions {
+		region := s.getSpinmintRegion(name)
+		s.RegionEC2Clients[name] = ec2.New(awsSession, s.GetAwsConfig().WithRegion(region.AWSRegion))
+	}
+	s.Route53Client = route53.New(awsSession, s.GetAwsConfig())
 
 	s.Builds = &Builds{}
 	if os.Getenv(buildOverride) != "" {
@@ -113,6 +146,7 @@ func New(config *Config, metrics MetricsProvider) (*Server, error) {
 
 	r.HandleFunc("/healthz", s.ping).Methods(http.MethodGet)
 	r.HandleFunc("/pr_event", s.githubEvent).Methods(http.MethodPost)
+	r.HandleFunc("/api/prs/{repo}/{number:[0-9]+}/build", s.getPRBuildStatus).Methods(http.MethodGet)
 	r.Use(s.withRecovery)
 	r.Use(s.withRequestDuration)
 	r.Use(s.withValidation)
@@ -147,6 +181,9 @@ func (s *Server) Start() {
 // Stop stops a server
 func (s *Server) Stop() error {
 	s.finishCherryPickRequests()
+	if s.prDebouncer != nil {
+		s.prDebouncer.stop()
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -318,6 +355,21 @@ func (s *Server) ping(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) githubEvent(w http.ResponseWriter, r *http.Request) {
+	// The delivery is recorded before it is handled so a redelivery arriving meanwhile is skipped,
+	// and forgotten again if handling it fails so that GitHub can redeliver it.
+	delivery := r.Header.Get("X-GitHub-Delivery")
+	if s.deliveries.checkAndAdd(delivery) {
+		mlog.Info("Skipping already processed webhook delivery", mlog.String("delivery", delivery), mlog.String("event", r.Header.Get("X-GitHub-Event")))
+		return
+	}
+	ww := newWrappedWriter(w)
+	defer func() {
+		if ww.StatusCode() >= http.StatusBadRequest {
+			s.deliveries.remove(delivery)
+		}
+	}()
+	w = ww
+
 	switch event := r.Header.Get("X-GitHub-Event"); event {
 	case "ping":
 		pingEvent := PingEventFromJSON(r.Body)
@@ -405,6 +457,27 @@ func SetupLogging(config *Config) error {
 	return nil
 }
 
+// httpClient is shared by the requests made to other services, like the signed CLA list
+// or Jenkins, so that one which never answers can't hold a handler forever.
+var httpClient = &http.Client{Timeout: defaultRequestTimeout * time.Second}
+
+// newRepoGithubClients returns the clients of the repositories with their own token, keyed by owner/name.
+// Repositories left null in the config file are skipped.
+func newRepoGithubClients(repositories []*Repository, limitTokens int, metrics MetricsProvider) (map[string]*GithubClient, error) {
+	clients := make(map[string]*GithubClient)
+	for _, repo := range repositories {
+		if repo == nil || repo.GithubAccessToken == "" {
+			continue
+		}
+		client, err := NewGithubClient(repo.GithubAccessToken, limitTokens, metrics)
+		if err != nil {
+			return nil, err
+		}
+		clients[repo.Owner+"/"+repo.Name] = client
+	}
+	return clients, nil
+}
+
 func closeBody(r *http.Response) {
 	if r.Body != nil {
 		_, _ = io.Copy(ioutil.Discard, r.Body)
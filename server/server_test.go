@@ -102,3 +102,17 @@ func TestWithDurationMiddleware(t *testing.T) {
 		defer resp.Body.Close()
 	})
 }
+
+func TestNewRepoGithubClients(t *testing.T) {
+	clients, err := newRepoGithubClients([]*Repository{
+		{Owner: "mattermost", Name: "mattermost-server", GithubAccessToken: "token"},
+		nil,
+		{Owner: "mattermost", Name: "mattermost-webapp"},
+	}, 10, nil)
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	require.NotNil(t, clients["mattermost/mattermost-server"])
+
+	_, err = newRepoGithubClients([]*Repository{{Owner: "mattermost", Name: "mattermost-server", GithubAccessToken: "token"}}, 0, nil)
+	require.Error(t, err)
+}
@@ -4,118 +4,516 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/route53"
+	jenkins "github.com/cpanato/golang-jenkins"
 	"github.com/mattermost/mattermost-mattermod/model"
 	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 )
 
-func (s *Server) waitForBuildAndSetupSpinmint(pr *model.PullRequest, upgradeServer bool) {
-	// This needs its own context because is executing a heavy job
-	ctx, cancel := context.WithTimeout(context.Background(), defaultBuildMobileTimeout*time.Second)
-	defer cancel()
+const defaultSpinmintReaperConcurrency = 5
+
+// spinmintTagDelay gives a new instance time to be known to EC2 before tagging it.
+var spinmintTagDelay = 10 * time.Second
+
+// spinmintPRGoneAttempts is how many times in a row GitHub must not find the PR of a
+// spinmint before the reaper takes the PR as deleted.
+const spinmintPRGoneAttempts = 3
+
+// spinmintPRGoneRetryDelay is the wait between two lookups of a PR that was not found.
+var spinmintPRGoneRetryDelay = 5 * time.Second
+
+// spinmintWaitForServerScript runs before the InstancePostSetupScript, since the setup script
+// returns as soon as Mattermost is started, before its API is up. The setup fails if it never is.
+const spinmintWaitForServerScript = `MM_PORT=$(sed -n 's|.*"ListenAddress": *"[^:"]*:\([0-9]*\)".*|\1|p' config/config.json)
+for i in $(seq 60); do
+    curl -sf "http://localhost:${MM_PORT:-8065}/api/v4/system/ping" > /dev/null && break
+    [ "$i" -eq 60 ] && { echo "Mattermost did not start"; exit 1; }
+    sleep 5
+done
+`
+
+const (
+	msgSpinmintDeferredForDraft = "This PR is a draft. The test server will be created once it is marked as ready for review."
+	msgSpinmintImportDone       = "The test server with imported data is available at %s"
+	msgSpinmintRecreating       = "New commit detected. The test server will be destroyed and recreated from the new build."
+	msgSpinmintCapacity         = "%d of the %d test server slots are in use."
+	msgSpinmintCapacityFull     = "All %d test server slots are in use (%d running). Please destroy the test servers you no longer need."
+	msgSpinmintCapacityWarning  = "Test server capacity is nearly full (%d of %d slots in use). Please destroy the test servers you no longer need."
+	msgSpinmintMaxCountReached  = "Global test server capacity reached: %d test servers are running and at most %d are allowed. Please destroy the test servers you no longer need and try again."
+	msgSpinmintRecreated        = "The test server was recreated and is available at %s\n\nLog in with the credentials posted in %s"
+)
+
+func (s *Server) waitForBuildAndSetupSpinmint(pr *model.PullRequest, upgradeServer bool) error {
+	return s.waitForBuildAndSetupSpinmintWithCredentials(pr, upgradeServer, "")
+}
+
+// waitForBuildAndSetupSpinmintWithCredentials is waitForBuildAndSetupSpinmint for a PR whose
+// credentials were already posted in the comment at credentialsURL, if set.
+func (s *Server) waitForBuildAndSetupSpinmintWithCredentials(pr *model.PullRequest, upgradeServer bool, credentialsURL string) error {
 	repo, client, err := s.Builds.buildJenkinsClient(s, pr)
 	if err != nil {
 		mlog.Error("Error building Jenkins client", mlog.Err(err))
-		if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); err != nil {
-			mlog.Warn("Error while commenting", mlog.Err(err))
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout*time.Second)
+		defer cancel()
+		if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); errComment != nil {
+			mlog.Warn("Error while commenting", mlog.Err(errComment))
 		}
-		return
+		return errors.Wrap(err, "unable to build the Jenkins client")
 	}
 
-	mlog.Info("Waiting for Jenkins to build to set up spinmint for PR", mlog.Int("pr", pr.Number), mlog.String("repo_owner", pr.RepoOwner), mlog.String("repo_name", pr.RepoName))
+	// The wait for the build and the setup need their own contexts because they are heavy jobs.
+	buildCtx, cancelBuild := context.WithTimeout(context.Background(), getSpinmintBuildWaitTimeout(repo))
+	defer cancelBuild()
+	s.setSpinmintStatusLabel(buildCtx, pr, s.Config.SpinmintProvisioningLabel)
+	s.createSpinmintDeployment(buildCtx, pr)
+	s.startSpinmintCheckRun(buildCtx, pr)
+	if err = s.reserveNewSpinmintSlot(buildCtx, pr); err != nil {
+		return err
+	}
+	defer s.releaseSpinmintSlot(pr)
+	mlog.Info("Waiting for the build to set up spinmint for PR", mlog.Int("pr", pr.Number), mlog.String("repo_owner", pr.RepoOwner), mlog.String("repo_name", pr.RepoName))
 
-	pr, err = s.Builds.waitForBuild(ctx, s, client, pr)
+	timings := newSpinmintTimings()
+	previous := pr
+	built, err := s.waitForSpinmintArtifacts(buildCtx, client, pr, timings)
+	if built != nil {
+		pr = built
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultBuildMobileTimeout*time.Second)
+	defer cancel()
+	if pr.Sha != previous.Sha {
+		// The check run started above is for the commit the PR had before the push.
+		s.supersedeSpinmintCheckRun(ctx, previous, pr)
+	}
 	if err != nil {
 		mlog.Error("Error waiting for PR build to finish", mlog.Err(err))
-		if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); err != nil {
-			mlog.Warn("Error while commenting", mlog.Err(err))
+		if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); errComment != nil {
+			mlog.Warn("Error while commenting", mlog.Err(errComment))
 		}
-		return
+		s.emitSpinmintEvent(spinmintEventFailed, pr, "", pr.Labels)
+		s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+		s.failSpinmintCheckRun(ctx, pr)
+		return errors.Wrap(err, "unable to wait for the build")
+	}
+
+	return s.setupSpinmintForPR(ctx, pr, repo, upgradeServer, credentialsURL, timings)
+}
+
+// reserveNewSpinmintSlot reserves the SpinmintMaxCount slot of the PR before waiting for its build,
+// so that the PR doesn't wait for a build it can't get a spinmint for. PRs which already have a
+// spinmint don't need one. If none is left, the failure is commented on the PR and returned.
+func (s *Server) reserveNewSpinmintSlot(ctx context.Context, pr *model.PullRequest) error {
+	if s.Config.SpinmintMaxCount <= 0 {
+		return nil
+	}
+	if spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName); err != nil || spinmint != nil {
+		// The setup looks the spinmint up again and reserves the slot then if it needs one.
+		return nil
+	}
+
+	running, reserved := s.reserveSpinmintSlot(pr)
+	if reserved {
+		return nil
+	}
+	if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, fmt.Sprintf(msgSpinmintMaxCountReached, running, s.Config.SpinmintMaxCount)); err != nil {
+		mlog.Warn("Error while commenting", mlog.Err(err))
+	}
+	s.emitSpinmintEvent(spinmintEventFailed, pr, "", pr.Labels)
+	s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+	s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+	s.failSpinmintCheckRun(ctx, pr)
+	return errors.Errorf("%d spinmints are running, the maximum is %d", running, s.Config.SpinmintMaxCount)
+}
+
+// waitForSpinmintArtifacts waits for the build of the PR and, if configured, for its docker image.
+// The image is waited for after the build unless SpinmintParallelImageWait is set.
+func (s *Server) waitForSpinmintArtifacts(ctx context.Context, client *jenkins.Jenkins, pr *model.PullRequest, timings *spinmintTimings) (*model.PullRequest, error) {
+	start := time.Now()
+	if !s.Config.SpinmintWaitForImage {
+		built, errBuild := s.Builds.waitForBuild(ctx, s, client, pr)
+		timings.track(spinmintPhaseBuild, start)
+		return built, errBuild
+	}
+
+	repo, _ := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
+	image, err := getDockerImage(repo)
+	if err != nil {
+		return pr, err
+	}
+	reg, err := newDockerRegistry(s.getDockerRegistryURL(image), s.Config.DockerUsername, s.Config.DockerPassword)
+	if err != nil {
+		return pr, errors.Wrap(err, "unable to connect to the docker registry")
+	}
+
+	if !s.Config.SpinmintParallelImageWait {
+		built, errBuild := s.Builds.waitForBuild(ctx, s, client, pr)
+		timings.track(spinmintPhaseBuild, start)
+		if errBuild != nil {
+			return built, errBuild
+		}
+		imageStart := time.Now()
+		if _, err = s.Builds.waitForImage(ctx, s, reg, image, built); err != nil {
+			return built, err
+		}
+		timings.track(spinmintPhaseImage, imageStart)
+		return built, nil
+	}
+
+	built := pr
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		updated, errBuild := s.Builds.waitForBuild(gctx, s, client, pr)
+		if updated != nil {
+			built = updated
+		}
+		timings.track(spinmintPhaseBuild, start)
+		return errBuild
+	})
+	g.Go(func() error {
+		_, errImage := s.Builds.waitForImage(gctx, s, reg, image, pr)
+		timings.track(spinmintPhaseImage, start)
+		return errImage
+	})
+	if err = g.Wait(); err != nil {
+		return built, err
+	}
+	return built, nil
+}
+
+// forceUpgradeSpinmint replaces the primary spinmint of the PR with one upgraded to the
+// latest build of its commit, without waiting for the build status.
+func (s *Server) forceUpgradeSpinmint(pr *model.PullRequest, spinmint *model.Spinmint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultBuildMobileTimeout*time.Second)
+	defer cancel()
+	repo, _, err := s.Builds.buildJenkinsClient(s, pr)
+	if err != nil {
+		mlog.Error("Error building Jenkins client", mlog.Err(err))
+		if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); errComment != nil {
+			mlog.Warn("Error while commenting", mlog.Err(errComment))
+		}
+		return errors.Wrap(err, "unable to build the Jenkins client")
+	}
+
+	if spinmint != nil && strings.Contains(spinmint.InstanceID, "i-") {
+		s.destroySpinmint(pr, spinmint)
+	}
+
+	s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintProvisioningLabel)
+	s.createSpinmintDeployment(ctx, pr)
+	s.startSpinmintCheckRun(ctx, pr)
+	return s.setupSpinmintForPR(ctx, pr, repo, true, "", newSpinmintTimings())
+}
+
+// reinitSpinmint runs the initialization mattermod does on the primary spinmint of the PR again,
+// on its running instance: the readiness check, the server configuration and the smoke test.
+// The spinmint keeps its record, so its build, subdomain and owner, and the credentials are not posted again.
+func (s *Server) reinitSpinmint(pr *model.PullRequest, spinmint *model.Spinmint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultBuildMobileTimeout*time.Second)
+	defer cancel()
+	repo, _, err := s.Builds.buildJenkinsClient(s, pr)
+	if err != nil {
+		mlog.Error("Error building Jenkins client", mlog.Err(err))
+		if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); errComment != nil {
+			mlog.Warn("Error while commenting", mlog.Err(errComment))
+		}
+		return errors.Wrap(err, "unable to build the Jenkins client")
+	}
+
+	s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintProvisioningLabel)
+	s.createSpinmintDeployment(ctx, pr)
+	s.startSpinmintCheckRun(ctx, pr)
+	// The stored spinmint is adopted as it is, instead of a new one being launched.
+	return s.setupSpinmintForPR(ctx, pr, repo, false, spinmint.CredentialsCommentURL, newSpinmintTimings())
+}
+
+// setupSpinmintForPR sets up the primary spinmint of the PR, or adopts the existing one,
+// and posts its URL once it is reachable. Failures are commented on the PR and returned.
+// If credentialsURL is set, the credentials are not posted again and the comment links to it.
+// The durations of the setup phases are added to timings.
+func (s *Server) setupSpinmintForPR(ctx context.Context, pr *model.PullRequest, repo *Repository, upgradeServer bool, credentialsURL string, timings *spinmintTimings) error {
+	defer s.releaseSpinmintSlot(pr)
+
+	// The build wait follows new commits, so the commit deployed is not always the one approved.
+	if s.isSpinmintApprovalPending(ctx, pr) {
+		s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+		s.failSpinmintCheckRun(ctx, pr)
+		return errors.Errorf("commit %s of the fork PR is not approved", pr.Sha)
 	}
 
 	var instance *ec2.Instance
 	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
 	if err != nil {
 		mlog.Error("Unable to get the spinmint information. Will not build the spinmint", mlog.String("pr_error", err.Error()))
-		return
+		return errors.Wrap(err, "unable to get the spinmint information")
 	}
 
 	if spinmint == nil {
-		mlog.Error("No spinmint for this PR in the Database. will start a fresh one.")
 		var errInstance error
-		instance, errInstance = s.setupSpinmint(ctx, pr, repo, upgradeServer)
+		instance, errInstance = s.findExistingSpinmintInstance(ctx, pr)
+		if errInstance != nil {
+			mlog.Warn("Unable to look up existing spinmint instances", mlog.Int("pr", pr.Number), mlog.Err(errInstance))
+		}
+		launched := false
+		failedMsg := s.Config.SetupSpinmintFailedMessage
+		if instance != nil {
+			mlog.Info("Found a running instance for this PR, adopting it", mlog.String("instance", *instance.InstanceId), mlog.Int("pr", pr.Number))
+		} else if _, errType := s.findSpinmintInstanceType(pr.Labels); errType != nil {
+			errInstance = errType
+			failedMsg = fmt.Sprintf(msgSpinmintInstanceTypeNotAllowed, errType.requested, strings.Join(errType.valid, "`, `"))
+		} else if running, reserved := s.reserveSpinmintSlot(pr); !reserved {
+			errInstance = errors.Errorf("%d spinmints are running, the maximum is %d", running, s.Config.SpinmintMaxCount)
+			failedMsg = fmt.Sprintf(msgSpinmintMaxCountReached, running, s.Config.SpinmintMaxCount)
+		} else {
+			launched = true
+			mlog.Error("No spinmint for this PR in the Database. will start a fresh one.")
+			launchStart := time.Now()
+			instance, errInstance = s.setupSpinmint(ctx, pr, repo, upgradeServer)
+			timings.track(spinmintPhaseLaunch, launchStart)
+		}
 		if errInstance != nil {
 			s.logToMattermost(ctx, "Unable to set up spinmint for PR %v in %v/%v: %v", pr.Number, pr.RepoOwner, pr.RepoName, errInstance.Error())
-			if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); err != nil {
+			if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, failedMsg); err != nil {
 				mlog.Warn("Error while commenting", mlog.Err(err))
 			}
-			return
+			s.emitSpinmintEvent(spinmintEventFailed, pr, "", pr.Labels)
+			s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+			s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+			s.failSpinmintCheckRun(ctx, pr)
+			return errors.Wrap(errInstance, "unable to set up the spinmint instance")
 		}
+		now := model.NowUTC()
 		spinmint = &model.Spinmint{
 			InstanceID: *instance.InstanceId,
 			RepoOwner:  pr.RepoOwner,
 			RepoName:   pr.RepoName,
 			Number:     pr.Number,
-			CreatedAt:  time.Now().UTC().Unix(),
+			CreatedAt:  now,
+			CreatedBy:  pr.Username,
+			Labels:     s.getSpinmintTriggerLabels(pr),
+			Region:     s.getSpinmintRegionName(pr.Labels),
+			State:      model.SpinmintStateCreating,
+
+			StateChangedAt:        &now,
+			CredentialsCommentURL: credentialsURL,
+		}
+		stored, errCreate := s.Store.Spinmint().Create(spinmint)
+		if errCreate != nil {
+			// Without its record the spinmint would never be upgraded nor reaped.
+			s.logToMattermost(ctx, "Unable to store spinmint %v for PR %v in %v/%v: %v", spinmint.InstanceID, pr.Number, pr.RepoOwner, pr.RepoName, errCreate.Error())
+			if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+			s.terminateSpinmintInstance(ctx, spinmint.Region, spinmint.InstanceID)
+			s.emitSpinmintEvent(spinmintEventFailed, pr, spinmint.InstanceID, spinmint.Labels)
+			s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+			s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+			s.failSpinmintCheckRun(ctx, pr)
+			return errors.Wrap(errCreate, "unable to store the spinmint")
+		}
+		if stored.InstanceID != spinmint.InstanceID {
+			// Another flow stored a spinmint for this PR while we were setting ours up
+			// and is finishing it, so leave the PR to that one.
+			mlog.Info("Spinmint already stored for this PR, adopting it", mlog.String("instance", stored.InstanceID), mlog.Int("pr", pr.Number))
+			if launched {
+				s.terminateSpinmintInstance(ctx, spinmint.Region, spinmint.InstanceID)
+			}
+			return nil
 		}
-		s.storeSpinmintInfo(spinmint)
 	} else {
 		instance = &ec2.Instance{
 			InstanceId: aws.String(spinmint.InstanceID),
 		}
+		s.setSpinmintState(spinmint.InstanceID, model.SpinmintStateCreating)
 	}
 
 	mlog.Info("Waiting for instance to come up.")
-	time.Sleep(time.Minute * 2)
-	publicDNS, internalIP := s.getIPsForInstance(ctx, *instance.InstanceId)
+	phaseStart := time.Now()
+	if err = s.waitForSpinmintInstance(ctx, spinmint.Region, *instance.InstanceId, s.getSpinmintCreationTimeout(repo)); err != nil {
+		s.logToMattermost(ctx, "Spinmint instance %v for PR %v in %v/%v did not come up: %v", *instance.InstanceId, pr.Number, pr.RepoOwner, pr.RepoName, err.Error())
+		msg := fmt.Sprintf("Timed out waiting for the test server instance `%s` to come up. It might still be starting, please check its status in AWS.", *instance.InstanceId)
+		if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); errComment != nil {
+			mlog.Warn("Error while commenting", mlog.Err(errComment))
+		}
+		s.emitSpinmintEvent(spinmintEventFailed, pr, *instance.InstanceId, spinmint.Labels)
+		s.setSpinmintState(*instance.InstanceId, model.SpinmintStateFailed)
+		s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+		s.failSpinmintCheckRun(ctx, pr)
+		return errors.Wrapf(err, "instance %s did not come up", *instance.InstanceId)
+	}
+	timings.track(spinmintPhaseInstance, phaseStart)
+	s.observeSpinmintWait(spinmintWaitInstance, pr, spinmint.Labels, phaseStart)
+	publicDNS, internalIP := s.getIPsForInstance(ctx, spinmint.Region, *instance.InstanceId)
 
-	if err = s.updateRoute53Subdomain(ctx, *instance.InstanceId, publicDNS, "CREATE"); err != nil {
+	// UPSERT since adopted and reinitialized spinmints already have their record,
+	// under the subdomain they may have been renamed to.
+	subdomain := spinmint.GetSubdomain()
+	phaseStart = time.Now()
+	if err = s.updateRoute53Subdomain(ctx, spinmint.Region, subdomain, publicDNS, "UPSERT"); err != nil {
 		s.logToMattermost(ctx, "Unable to set up S3 subdomain for PR %v in %v/%v with instance %v: %v", pr.Number, pr.RepoOwner, pr.RepoName, *instance.InstanceId, err.Error())
-		if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); err != nil {
-			mlog.Warn("Error while commenting", mlog.Err(err))
+		if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); errComment != nil {
+			mlog.Warn("Error while commenting", mlog.Err(errComment))
 		}
-		return
+		s.emitSpinmintEvent(spinmintEventFailed, pr, *instance.InstanceId, spinmint.Labels)
+		s.setSpinmintState(*instance.InstanceId, model.SpinmintStateFailed)
+		s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+		s.failSpinmintCheckRun(ctx, pr)
+		return errors.Wrapf(err, "unable to set up the subdomain of instance %s", *instance.InstanceId)
 	}
 
-	smLink := fmt.Sprintf("%v.%v", *instance.InstanceId, s.Config.AWSDnsSuffix)
-	if s.Config.SpinmintsUseHTTPS {
-		smLink = "https://" + smLink
-	} else {
-		smLink = "http://" + smLink
+	timings.track(spinmintPhaseDNS, phaseStart)
+
+	smLink := s.getSpinmintURL(spinmint.Region, subdomain)
+	if check := s.getSpinmintReadinessCheck(pr); check != "" {
+		phaseStart = time.Now()
+		var dnsSuffix string
+		if dnsSuffix, err = s.waitForSpinmintReady(ctx, check, spinmint.Region, subdomain, s.getSpinmintCreationTimeout(repo)); err != nil {
+			s.logToMattermost(ctx, "Spinmint for PR %v in %v/%v with instance %v is not reachable: %v", pr.Number, pr.RepoOwner, pr.RepoName, *instance.InstanceId, err.Error())
+			if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); errComment != nil {
+				mlog.Warn("Error while commenting", mlog.Err(errComment))
+			}
+			s.emitSpinmintEvent(spinmintEventFailed, pr, *instance.InstanceId, spinmint.Labels)
+			s.setSpinmintState(*instance.InstanceId, model.SpinmintStateFailed)
+			s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+			s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+			s.failSpinmintCheckRun(ctx, pr)
+			return errors.Wrapf(err, "instance %s is not reachable", *instance.InstanceId)
+		}
+		smLink = s.getSpinmintURLForSuffix(subdomain, dnsSuffix)
+		timings.track(spinmintPhaseReadiness, phaseStart)
+		s.observeSpinmintWait(spinmintWaitReadiness, pr, spinmint.Labels, phaseStart)
 	}
 
+	// Imported data comes with its own users, so mattermod can't log in to configure it.
+	if len(s.Config.SpinmintServerConfig) > 0 && !s.isSpinmintImport(pr) {
+		if err = s.applySpinmintServerConfig(smLink); err != nil {
+			s.logToMattermost(ctx, "Unable to configure the spinmint for PR %v in %v/%v with instance %v: %v", pr.Number, pr.RepoOwner, pr.RepoName, *instance.InstanceId, err.Error())
+			if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.Config.SetupSpinmintFailedMessage); errComment != nil {
+				mlog.Warn("Error while commenting", mlog.Err(errComment))
+			}
+			s.emitSpinmintEvent(spinmintEventFailed, pr, *instance.InstanceId, spinmint.Labels)
+			s.setSpinmintState(*instance.InstanceId, model.SpinmintStateFailed)
+			s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+			s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+			s.failSpinmintCheckRun(ctx, pr)
+			return errors.Wrapf(err, "unable to configure instance %s", *instance.InstanceId)
+		}
+	}
+
+	// Imported data comes with its own users, so the smoke test can't log in.
+	if len(s.Config.SpinmintSmokeTest) > 0 && !s.isSpinmintImport(pr) {
+		phaseStart = time.Now()
+		if err = s.runSpinmintSmokeTest(ctx, pr, smLink); err != nil {
+			s.logToMattermost(ctx, "Spinmint for PR %v in %v/%v with instance %v failed the smoke test: %v", pr.Number, pr.RepoOwner, pr.RepoName, *instance.InstanceId, err.Error())
+			if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.getSpinmintSmokeTestFailedMessage(err, smLink)); errComment != nil {
+				mlog.Warn("Error while commenting", mlog.Err(errComment))
+			}
+			s.emitSpinmintEvent(spinmintEventFailed, pr, *instance.InstanceId, spinmint.Labels)
+			if s.Config.SpinmintKeepFailedSmokeTest {
+				s.setSpinmintState(*instance.InstanceId, model.SpinmintStateFailed)
+			} else {
+				s.destroySpinmint(pr, spinmint)
+			}
+			s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintFailedLabel)
+			s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateFailure, "")
+			s.failSpinmintCheckRun(ctx, pr)
+			return errors.Wrapf(err, "instance %s failed the smoke test", *instance.InstanceId)
+		}
+		timings.track(spinmintPhaseSmokeTest, phaseStart)
+	}
+
+	s.setSpinmintState(*instance.InstanceId, model.SpinmintStateStable)
+
 	var message string
 	if upgradeServer {
 		message = s.Config.SetupSpinmintUpgradeDoneMessage
+		s.emitSpinmintEvent(spinmintEventUpgraded, pr, *instance.InstanceId, spinmint.Labels)
+		s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintReadyLabel)
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateSuccess, smLink)
+		s.completeSpinmintCheckRun(ctx, pr, checkRunConclusionSuccess, smLink, s.getSpinmintCheckRunOutput(pr, smLink))
 	} else {
 		message = s.Config.SetupSpinmintDoneMessage
+		s.emitSpinmintEvent(spinmintEventCreated, pr, *instance.InstanceId, spinmint.Labels)
+		s.setSpinmintStatusLabel(ctx, pr, s.Config.SpinmintReadyLabel)
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateSuccess, smLink)
+		s.completeSpinmintCheckRun(ctx, pr, checkRunConclusionSuccess, smLink, s.getSpinmintCheckRunOutput(pr, smLink))
+	}
+
+	if s.isSpinmintImport(pr) && !upgradeServer {
+		// Imported data comes with its own users, so only the URL is shared.
+		message = fmt.Sprintf(msgSpinmintImportDone, smLink)
+	}
+	postsCredentials := !upgradeServer && !s.isSpinmintImport(pr)
+	if postsCredentials && credentialsURL != "" {
+		// The credentials come from the configuration and did not change.
+		message = fmt.Sprintf(msgSpinmintRecreated, smLink, credentialsURL)
+		postsCredentials = false
 	}
 
 	message = strings.Replace(message, templateSpinmintLink, smLink, 1)
 	message = strings.Replace(message, templateInstanceID, instanceIDMessage+*instance.InstanceId, 1)
 	message = strings.Replace(message, templateInternalIP, internalIP, 1)
+	if postsCredentials {
+		message += "\n\n" + s.getSpinmintCredentialsTable(pr)
+	}
+	if s.Config.SpinmintSeedUserCount > 0 && postsCredentials && !s.isSpinmintMinimal(pr) {
+		message += fmt.Sprintf("\n\nThe test server was seeded with %d users. They log in as `user-N` with the password `SampleUs@r-N`, where N goes from 1 to %d.",
+			s.Config.SpinmintSeedUserCount, s.Config.SpinmintSeedUserCount)
+	}
 
-	if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, message); err != nil {
+	if !upgradeServer {
+		if warning := s.getSpinmintCapacityWarning(); warning != "" {
+			message += "\n\n" + warning
+		}
+	}
+	if s.Config.SpinmintReportTimings {
+		if report := timings.format(); report != "" {
+			message += "\n\n" + report
+		}
+	}
+
+	comment, err := s.createGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, message)
+	if err != nil {
 		mlog.Warn("Error while commenting", mlog.Err(err))
+	} else if postsCredentials && comment.GetHTMLURL() != "" {
+		if err = s.Store.Spinmint().UpdateCredentialsCommentURL(*instance.InstanceId, comment.GetHTMLURL()); err != nil {
+			mlog.Warn("Unable to store the credentials comment of the spinmint", mlog.String("instance", *instance.InstanceId), mlog.Err(err))
+		}
 	}
+	return nil
 }
 
 // Returns instance ID of instance created
 func (s *Server) setupSpinmint(ctx context.Context, pr *model.PullRequest, repo *Repository, upgrade bool) (*ec2.Instance, error) {
 	mlog.Info("Setting up spinmint for PR", mlog.Int("pr", pr.Number))
 
-	svc := ec2.New(s.awsSession, s.GetAwsConfig())
+	regionName := s.getSpinmintRegionName(pr.Labels)
+	region := s.getSpinmintRegion(regionName)
+	svc := s.getSpinmintEC2Client(regionName)
 
 	var setupScript string
 	if upgrade {
@@ -129,6 +527,13 @@ func (s *Server) setupSpinmint(ctx context.Context, pr *model.PullRequest, repo
 		return nil, err
 	}
 	sdata := string(data)
+	if repo.InstancePostSetupScript != "" {
+		postData, err2 := ioutil.ReadFile(path.Join("config", repo.InstancePostSetupScript))
+		if err2 != nil {
+			return nil, err2
+		}
+		sdata = strings.TrimRight(sdata, "\n") + "\n\n" + spinmintWaitForServerScript + "\n" + string(postData)
+	}
 	// with circleci if the PR is opened in upstream we don't have the PR number and we have the branch name instead.
 	// so we will use the commit hash that we upload too
 	partialURL := fmt.Sprintf("commit/%s", pr.Sha)
@@ -136,28 +541,66 @@ func (s *Server) setupSpinmint(ctx context.Context, pr *model.PullRequest, repo
 	// for server
 	sdata = strings.Replace(sdata, "BUILD_NUMBER", strconv.Itoa(pr.Number), -1)
 	sdata = strings.Replace(sdata, "BRANCH_NAME", pr.Ref, -1)
-	mlog.Debug("Script to bootstrap the server", mlog.String("Script", sdata))
+	sdata = strings.Replace(sdata, "FILESTORE_BUCKET_PREFIX", s.getSpinmintFilestorePrefix(pr), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_URL_SCHEME", s.getSpinmintURLScheme(), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_DNS_SUFFIX", region.AWSDnsSuffix, -1)
+	sdata = strings.Replace(sdata, "SPINMINT_BANNER_TEXT", s.getSpinmintBannerText(pr), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_SEED_USER_COUNT", s.getSpinmintSeedUserCount(), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_SEED_WORKERS", s.getSpinmintSeedWorkers(), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_SKIP_SAMPLEDATA", s.getSpinmintSkipSampleData(pr), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_FEEDBACK_NAME", sanitizeSetupScriptValue(s.Config.SpinmintFeedbackName), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_FEEDBACK_EMAIL", sanitizeSetupScriptValue(s.Config.SpinmintFeedbackEmail), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_REPLY_TO_ADDRESS", sanitizeSetupScriptValue(s.Config.SpinmintReplyToAddress), -1)
+	sdata = strings.Replace(sdata, "SPINMINT_TEAM_NAME", s.getSpinmintTeamName(pr), -1)
+	credentials := s.getSpinmintAccounts(pr)
+	sdata = strings.Replace(sdata, "SPINMINT_ADMIN_USERNAME", credentials.AdminUsername, -1)
+	sdata = strings.Replace(sdata, "SPINMINT_ADMIN_PASSWORD", credentials.AdminPassword, -1)
+	sdata = strings.Replace(sdata, "SPINMINT_ADMIN_EMAIL", credentials.AdminEmail, -1)
+	sdata = strings.Replace(sdata, "SPINMINT_USER_USERNAME", credentials.UserUsername, -1)
+	sdata = strings.Replace(sdata, "SPINMINT_USER_PASSWORD", credentials.UserPassword, -1)
+	sdata = strings.Replace(sdata, "SPINMINT_USER_EMAIL", credentials.UserEmail, -1)
+
+	license, err := s.getSpinmintLicense()
+	if err != nil {
+		return nil, err
+	}
+	sdata = strings.Replace(sdata, "MATTERMOST_LICENSE", license, -1)
 	bsdata := []byte(sdata)
 	sdata = base64.StdEncoding.EncodeToString(bsdata)
 
 	var one int64 = 1
 	params := &ec2.RunInstancesInput{
-		ImageId:          &s.Config.AWSImageID,
+		ImageId:          aws.String(region.AWSImageID),
 		MaxCount:         &one,
 		MinCount:         &one,
-		InstanceType:     &s.Config.AWSInstanceType,
+		InstanceType:     aws.String(s.getSpinmintInstanceType(pr.Labels)),
 		UserData:         &sdata,
-		SecurityGroupIds: []*string{&s.Config.AWSSecurityGroup},
-		SubnetId:         &s.Config.AWSSubNetID,
+		SecurityGroupIds: []*string{aws.String(region.AWSSecurityGroup)},
+		SubnetId:         aws.String(region.AWSSubNetID),
+
+		InstanceMarketOptions: s.getSpinmintMarketOptions(pr),
 	}
+	params.ClientToken = aws.String(getSpinmintClientToken(pr, params))
 
 	resp, err := svc.RunInstancesWithContext(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
+	// A token that was already used for an instance which is gone by now returns
+	// that instance again, so fall back to a plain request in that case.
+	if state := resp.Instances[0].State; state != nil && state.Name != nil &&
+		*state.Name != ec2.InstanceStateNamePending && *state.Name != ec2.InstanceStateNameRunning {
+		mlog.Info("Client token matched a stopped instance, creating a new one", mlog.String("instance", *resp.Instances[0].InstanceId), mlog.Int("pr", pr.Number))
+		params.ClientToken = nil
+		resp, err = svc.RunInstancesWithContext(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Add tags to the created instance
-	time.Sleep(time.Second * 10)
+	time.Sleep(spinmintTagDelay)
 	_, errtag := svc.CreateTagsWithContext(ctx, &ec2.CreateTagsInput{
 		Resources: []*string{resp.Instances[0].InstanceId},
 		Tags: []*ec2.Tag{
@@ -177,6 +620,10 @@ func (s *Server) setupSpinmint(ctx context.Context, pr *model.PullRequest, repo
 				Key:   aws.String("RepoName"),
 				Value: aws.String(pr.RepoName),
 			},
+			{
+				Key:   aws.String("Labels"),
+				Value: aws.String(strings.Join(s.getSpinmintTriggerLabels(pr), ",")),
+			},
 		},
 	})
 	if errtag != nil {
@@ -186,12 +633,225 @@ func (s *Server) setupSpinmint(ctx context.Context, pr *model.PullRequest, repo
 	return resp.Instances[0], nil
 }
 
-func (s *Server) destroySpinmint(pr *model.PullRequest, instanceID string) {
+// getSpinmintCreationTimeout returns how long the spinmints of repo can take to come up.
+func (s *Server) getSpinmintCreationTimeout(repo *Repository) time.Duration {
+	if repo != nil && repo.InstallationWaitSeconds > 0 {
+		return time.Duration(repo.InstallationWaitSeconds) * time.Second
+	}
+	timeout := s.Config.SpinmintCreationTimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultSpinmintCreationTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// getSpinmintBuildWaitTimeout returns how long to wait for the build of a PR of repo.
+func getSpinmintBuildWaitTimeout(repo *Repository) time.Duration {
+	if repo != nil && repo.BuildWaitSeconds > 0 {
+		return time.Duration(repo.BuildWaitSeconds) * time.Second
+	}
+	return defaultBuildMobileTimeout * time.Second
+}
+
+// getSpinmintDNSZones returns the hosted zone of the region followed by the configured fallback
+// zones, which only apply to the default region.
+func (s *Server) getSpinmintDNSZones(region string) []*SpinmintDNSZone {
+	primary := s.getSpinmintRegion(region)
+	zones := []*SpinmintDNSZone{{AWSHostedZoneID: primary.AWSHostedZoneID, AWSDnsSuffix: primary.AWSDnsSuffix}}
+	if region != "" {
+		return zones
+	}
+	for _, zone := range s.Config.AWSDnsZoneFallbacks {
+		if zone != nil && zone.AWSDnsSuffix != primary.AWSDnsSuffix {
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
+// getSpinmintDNSSuffixes returns the DNS suffixes of getSpinmintDNSZones, in the same order.
+func (s *Server) getSpinmintDNSSuffixes(region string) []string {
+	zones := s.getSpinmintDNSZones(region)
+	suffixes := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		suffixes = append(suffixes, zone.AWSDnsSuffix)
+	}
+	return suffixes
+}
+
+// waitForSpinmintInstance waits until the instance is running or timeout is reached.
+func (s *Server) waitForSpinmintInstance(ctx context.Context, region, instanceID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	svc := s.getSpinmintEC2Client(region)
+	return svc.WaitUntilInstanceRunningWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{
+			&instanceID,
+		},
+	}, s.spinmintFailureStatesOption(),
+		request.WithWaiterDelay(request.ConstantWaiterDelay(spinmintInstanceWaitDelay)),
+		request.WithWaiterMaxAttempts(getSpinmintInstanceWaitAttempts(timeout)))
+}
+
+// spinmintInstanceWaitDelay is the time between two checks of a new spinmint instance, as for the AWS waiter.
+const spinmintInstanceWaitDelay = 15 * time.Second
+
+// getSpinmintInstanceWaitAttempts returns how many times a new spinmint instance is checked, so that
+// the timeout bounds the wait. The AWS waiter would otherwise give up after its 40 attempts, or 10 minutes.
+func getSpinmintInstanceWaitAttempts(timeout time.Duration) int {
+	return int(timeout/spinmintInstanceWaitDelay) + 1
+}
+
+// errSpinmintSnapshotNotSupported is returned when the spinmint instance has no EBS root volume to snapshot.
+var errSpinmintSnapshotNotSupported = errors.New("spinmint instance has no EBS root volume")
+
+// createSpinmintSnapshot takes an EBS snapshot of the root volume of the spinmint instance,
+// which holds the Mattermost database, and returns the snapshot ID.
+func (s *Server) createSpinmintSnapshot(ctx context.Context, pr *model.PullRequest, region, instanceID string) (string, error) {
+	svc := s.getSpinmintEC2Client(region)
+	resp, err := svc.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{
+			aws.String(instanceID),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Reservations) == 0 || len(resp.Reservations[0].Instances) == 0 {
+		return "", errors.Errorf("instance %s not found", instanceID)
+	}
+
+	instance := resp.Reservations[0].Instances[0]
+	var volumeID string
+	for _, mapping := range instance.BlockDeviceMappings {
+		if mapping.Ebs != nil && aws.StringValue(mapping.DeviceName) == aws.StringValue(instance.RootDeviceName) {
+			volumeID = aws.StringValue(mapping.Ebs.VolumeId)
+			break
+		}
+	}
+	if volumeID == "" {
+		return "", errSpinmintSnapshotNotSupported
+	}
+
+	snapshot, err := svc.CreateSnapshotWithContext(ctx, &ec2.CreateSnapshotInput{
+		VolumeId:    aws.String(volumeID),
+		Description: aws.String(fmt.Sprintf("Spinmint snapshot of %s/%s PR %d", pr.RepoOwner, pr.RepoName, pr.Number)),
+		TagSpecifications: []*ec2.TagSpecification{
+			{
+				ResourceType: aws.String(ec2.ResourceTypeSnapshot),
+				Tags: []*ec2.Tag{
+					{
+						Key:   aws.String("PRNumber"),
+						Value: aws.String("PR-" + strconv.Itoa(pr.Number)),
+					},
+					{
+						Key:   aws.String("RepoName"),
+						Value: aws.String(pr.RepoName),
+					},
+					{
+						Key:   aws.String("InstanceId"),
+						Value: aws.String(instanceID),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(snapshot.SnapshotId), nil
+}
+
+// deleteSpinmintSnapshot deletes a snapshot taken of a spinmint, which is only kept as long as the spinmint.
+func (s *Server) deleteSpinmintSnapshot(ctx context.Context, region, snapshotID string) {
+	_, err := s.getSpinmintEC2Client(region).DeleteSnapshotWithContext(ctx, &ec2.DeleteSnapshotInput{
+		SnapshotId: aws.String(snapshotID),
+	})
+	if err != nil {
+		mlog.Error("Error deleting the spinmint snapshot", mlog.String("snapshot", snapshotID), mlog.Err(err))
+	}
+}
+
+// spinmintFailureStatesOption makes the instance waiter stop on the configured
+// failure states too, on top of the ones the AWS waiter already knows about.
+func (s *Server) spinmintFailureStatesOption() request.WaiterOption {
+	return func(w *request.Waiter) {
+		for _, state := range s.Config.SpinmintFailureStates {
+			w.Acceptors = append(w.Acceptors, request.WaiterAcceptor{
+				State:    request.FailureWaiterState,
+				Matcher:  request.PathAnyWaiterMatch,
+				Argument: "Reservations[].Instances[].State.Name",
+				Expected: state,
+			})
+		}
+	}
+}
+
+// findExistingSpinmintInstance returns a pending or running instance tagged for the PR, if any.
+// This keeps a PR from getting a second instance when its spinmint record was lost.
+func (s *Server) findExistingSpinmintInstance(ctx context.Context, pr *model.PullRequest) (*ec2.Instance, error) {
+	svc := s.getSpinmintEC2Client(s.getSpinmintRegionName(pr.Labels))
+	params := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:PRNumber"),
+				Values: []*string{aws.String("PR-" + strconv.Itoa(pr.Number))},
+			},
+			{
+				Name:   aws.String("tag:RepoName"),
+				Values: []*string{aws.String(pr.RepoName)},
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []*string{aws.String(ec2.InstanceStateNamePending), aws.String(ec2.InstanceStateNameRunning)},
+			},
+		},
+	}
+
+	resp, err := svc.DescribeInstancesWithContext(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reservation := range resp.Reservations {
+		if len(reservation.Instances) > 0 {
+			return reservation.Instances[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// getSpinmintClientToken returns the idempotency token used when launching the spinmint instance of a PR
+// with params, so that resending the same request does not start a second instance. EC2 rejects a token
+// reused with other parameters, so it is derived from them: the setup script carries the commit, whether
+// the spinmint is upgraded and the labels it is set up from, and the other fields its image and size.
+func getSpinmintClientToken(pr *model.PullRequest, params *ec2.RunInstancesInput) string {
+	launch := *params
+	launch.ClientToken = nil
+	key := fmt.Sprintf("%s/%s/%d/%s", pr.RepoOwner, pr.RepoName, pr.Number, launch.String())
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Server) destroySpinmint(pr *model.PullRequest, spinmint *model.Spinmint) {
+	if err := s.destroySpinmintWithEvent(pr, spinmint, spinmintEventDestroyed); err != nil {
+		mlog.Error("Error terminating instances", mlog.Err(err))
+	}
+}
+
+// destroySpinmintWithEvent destroys the spinmint of a PR and emits event once it is terminated.
+// The record of the spinmint is removed once its instance is terminated, even if its DNS record
+// can't be. An error is returned if the instance could not be terminated, and the record is kept.
+func (s *Server) destroySpinmintWithEvent(pr *model.PullRequest, spinmint *model.Spinmint, event string) error {
+	region, instanceID := spinmint.Region, spinmint.InstanceID
 	ctx, cancel := context.WithTimeout(context.Background(), defaultBuildSpinmintTimeout*time.Second)
 	defer cancel()
 	mlog.Info("Destroying spinmint for PR", mlog.String("instance", instanceID), mlog.Int("pr", pr.Number), mlog.String("repo_owner", pr.RepoOwner), mlog.String("repo_name", pr.RepoName))
+	s.setSpinmintState(instanceID, model.SpinmintStateDeleting)
 
-	svc := ec2.New(s.awsSession, s.GetAwsConfig())
+	svc := s.getSpinmintEC2Client(region)
 
 	params := &ec2.TerminateInstancesInput{
 		InstanceIds: []*string{
@@ -199,24 +859,34 @@ func (s *Server) destroySpinmint(pr *model.PullRequest, instanceID string) {
 		},
 	}
 
+	// A terminated instance has no IP anymore, and the DNS record can only be deleted with it.
+	publicIP, _ := s.getIPsForInstance(ctx, region, instanceID)
 	_, err := svc.TerminateInstancesWithContext(ctx, params)
 	if err != nil {
-		mlog.Error("Error terminating instances", mlog.Err(err))
-		return
+		return errors.Wrapf(err, "unable to terminate instance %s", instanceID)
+	}
+	s.emitSpinmintEvent(event, pr, instanceID, spinmint.Labels)
+	if spinmint.SnapshotID != "" {
+		s.deleteSpinmintSnapshot(ctx, region, spinmint.SnapshotID)
+	}
+	s.setSpinmintStatusLabel(ctx, pr, "")
+	s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateInactive, "")
+
+	if err = s.Store.Spinmint().Archive(instanceID, s.getSpinmintInstanceType(spinmint.Labels), model.NowUTC()); err != nil {
+		mlog.Error("Error archiving the spinmint", mlog.Err(err))
 	}
 
 	// Remove route53 entry
-	err = s.updateRoute53Subdomain(ctx, instanceID, "", "DELETE")
-	if err != nil {
+	if err = s.updateRoute53Subdomain(ctx, region, spinmint.GetSubdomain(), publicIP, "DELETE"); err != nil {
 		mlog.Error("Error removing the Route53 entry", mlog.Err(err))
-		return
 	}
 
 	s.removeTestServerFromDB(instanceID)
+	return nil
 }
 
-func (s *Server) getIPsForInstance(ctx context.Context, instance string) (publicIP string, privateIP string) {
-	svc := ec2.New(s.awsSession, s.GetAwsConfig())
+func (s *Server) getIPsForInstance(ctx context.Context, region, instance string) (publicIP string, privateIP string) {
+	svc := s.getSpinmintEC2Client(region)
 	params := &ec2.DescribeInstancesInput{
 		InstanceIds: []*string{
 			&instance,
@@ -228,18 +898,35 @@ func (s *Server) getIPsForInstance(ctx context.Context, instance string) (public
 		return "", ""
 	}
 
-	return *resp.Reservations[0].Instances[0].PublicIpAddress, *resp.Reservations[0].Instances[0].PrivateIpAddress
+	// Instances that are stopped or terminated have no IP addresses.
+	if len(resp.Reservations) == 0 || len(resp.Reservations[0].Instances) == 0 {
+		return "", ""
+	}
+	instanceInfo := resp.Reservations[0].Instances[0]
+	return aws.StringValue(instanceInfo.PublicIpAddress), aws.StringValue(instanceInfo.PrivateIpAddress)
 }
 
-func (s *Server) updateRoute53Subdomain(ctx context.Context, name, target, action string) error {
-	svc := route53.New(s.awsSession, s.GetAwsConfig())
-	domainName := fmt.Sprintf("%v.%v", name, s.Config.AWSDnsSuffix)
-
+// updateRoute53Subdomain applies action to the record of the subdomain name in every DNS zone of
+// the region. Only a failure in the primary zone is returned, the fallback zones are best effort.
+func (s *Server) updateRoute53Subdomain(ctx context.Context, region, name, target, action string) error {
 	targetServer := target
 	if target == "" && action == "DELETE" {
-		targetServer, _ = s.getIPsForInstance(ctx, name)
+		targetServer, _ = s.getIPsForInstance(ctx, region, name)
 	}
 
+	zones := s.getSpinmintDNSZones(region)
+	for _, zone := range zones[1:] {
+		if err := s.changeRoute53Record(ctx, zone, name, targetServer, action); err != nil {
+			mlog.Warn("Unable to update the spinmint record in the fallback DNS zone", mlog.String("subdomain", name), mlog.String("dns_suffix", zone.AWSDnsSuffix), mlog.String("action", action), mlog.Err(err))
+		}
+	}
+	return s.changeRoute53Record(ctx, zones[0], name, targetServer, action)
+}
+
+func (s *Server) changeRoute53Record(ctx context.Context, zone *SpinmintDNSZone, name, targetServer, action string) error {
+	svc := s.Route53Client
+	domainName := fmt.Sprintf("%v.%v", name, zone.AWSDnsSuffix)
+
 	params := &route53.ChangeResourceRecordSetsInput{
 		ChangeBatch: &route53.ChangeBatch{
 			Changes: []*route53.Change{
@@ -258,7 +945,7 @@ func (s *Server) updateRoute53Subdomain(ctx context.Context, name, target, actio
 				},
 			},
 		},
-		HostedZoneId: &s.Config.AWSHostedZoneID,
+		HostedZoneId: aws.String(zone.AWSHostedZoneID),
 	}
 
 	if _, err := svc.ChangeResourceRecordSetsWithContext(ctx, params); err != nil {
@@ -278,6 +965,18 @@ func (s *Server) CheckTestServerLifeTime() {
 		elapsed := float64(time.Since(start)) / float64(time.Second)
 		s.Metrics.ObserveCronTaskDuration("check_test_server_lifetime", elapsed)
 	}()
+
+	paused, err := s.isSpinmintReaperPaused()
+	if err != nil {
+		mlog.Error("Unable to check if the test server reaper is paused", mlog.Err(err))
+		s.Metrics.IncreaseCronTaskErrors("check_test_server_lifetime")
+		return
+	}
+	if paused {
+		mlog.Warn("The test server reaper is PAUSED, no expired test server will be destroyed. Resume it with /spinmint reaper resume")
+		return
+	}
+
 	testServers, err := s.Store.Spinmint().List()
 	if err != nil {
 		mlog.Error("Unable to get updated PR while waiting for test server", mlog.String("testServer_error", err.Error()))
@@ -285,40 +984,607 @@ func (s *Server) CheckTestServerLifeTime() {
 		return
 	}
 
+	var wg sync.WaitGroup
+	var reaped int32
+	sem := make(chan struct{}, s.getSpinmintReaperConcurrency())
 	for _, testServer := range testServers {
 		mlog.Info("Check if need destroy Test Server for PR", mlog.String("instance", testServer.InstanceID), mlog.Int("TestServer", testServer.Number), mlog.String("repo_owner", testServer.RepoOwner), mlog.String("repo_name", testServer.RepoName))
-		testServerCreated := time.Unix(testServer.CreatedAt, 0)
-		duration := time.Since(testServerCreated)
-		if int(duration.Hours()) > s.Config.SpinmintExpirationHour {
-			mlog.Info("Will destroy spinmint for PR", mlog.String("instance", testServer.InstanceID), mlog.Int("TestServer", testServer.Number), mlog.String("repo_owner", testServer.RepoOwner), mlog.String("repo_name", testServer.RepoName))
-			pr := &model.PullRequest{
-				RepoOwner: testServer.RepoOwner,
-				RepoName:  testServer.RepoName,
-				Number:    testServer.Number,
+		duration := time.Since(testServer.CreatedAt)
+		expired := int(duration.Hours()) > s.Config.SpinmintExpirationHour
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(testServer *model.Spinmint) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var errReap error
+			switch {
+			case expired:
+				errReap = s.reapSpinmint(ctx, testServer)
+			case s.isSpinmintPRGone(ctx, testServer):
+				errReap = s.reapOrphanedSpinmint(testServer)
+			default:
+				return
 			}
-			go s.destroySpinmint(pr, testServer.InstanceID)
-			s.removeTestServerFromDB(testServer.InstanceID)
-			if err = s.sendGitHubComment(ctx, testServer.RepoOwner, testServer.RepoName, testServer.Number, s.Config.DestroyedExpirationSpinmintMessage); err != nil {
-				mlog.Warn("Error while commenting", mlog.Err(err))
+			if errReap != nil {
+				// The record is kept, so it is reaped again on the next check.
+				mlog.Error("Unable to reap the spinmint", mlog.String("instance", testServer.InstanceID), mlog.Err(errReap))
+				return
 			}
+			atomic.AddInt32(&reaped, 1)
+		}(testServer)
+	}
+	wg.Wait()
+
+	mlog.Info("Done checking Test Server lifetime.", mlog.Int("checked", len(testServers)), mlog.Int("reaped", int(reaped)))
+}
+
+// reapSpinmint destroys an expired spinmint and lets the PR know about it once it is gone.
+func (s *Server) reapSpinmint(ctx context.Context, testServer *model.Spinmint) error {
+	if err := s.destroyReapedSpinmint(testServer); err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("it was running for more than %d hours", s.Config.SpinmintExpirationHour)
+	msg := s.getSpinmintReapedMessage(reason, s.Config.DestroyedExpirationSpinmintMessage, true)
+	if err := s.sendGitHubComment(ctx, testServer.RepoOwner, testServer.RepoName, testServer.Number, msg); err != nil {
+		mlog.Warn("Error while commenting", mlog.Err(err))
+	}
+	return nil
+}
+
+// reapOrphanedSpinmint destroys a spinmint whose PR was deleted.
+// The close webhook never came for it, and there is no PR to comment on anymore.
+func (s *Server) reapOrphanedSpinmint(testServer *model.Spinmint) error {
+	mlog.Warn("The PR of the spinmint no longer exists", mlog.String("instance", testServer.InstanceID), mlog.Int("pr", testServer.Number), mlog.String("repo_owner", testServer.RepoOwner), mlog.String("repo_name", testServer.RepoName))
+	return s.destroyReapedSpinmint(testServer)
+}
+
+func (s *Server) destroyReapedSpinmint(testServer *model.Spinmint) error {
+	mlog.Info("Will destroy spinmint for PR", mlog.String("instance", testServer.InstanceID), mlog.Int("TestServer", testServer.Number), mlog.String("repo_owner", testServer.RepoOwner), mlog.String("repo_name", testServer.RepoName))
+	pr := &model.PullRequest{
+		RepoOwner: testServer.RepoOwner,
+		RepoName:  testServer.RepoName,
+		Number:    testServer.Number,
+	}
+	return s.destroySpinmintWithEvent(pr, testServer, spinmintEventReaped)
+}
+
+// isSpinmintPRGone is true if GitHub keeps answering that the PR of the spinmint does not exist.
+// Any other error is taken as transient, so the spinmint is kept until the next check.
+func (s *Server) isSpinmintPRGone(ctx context.Context, testServer *model.Spinmint) bool {
+	for attempt := 1; ; attempt++ {
+		_, r, err := s.GithubClient.PullRequests.Get(ctx, testServer.RepoOwner, testServer.RepoName, testServer.Number)
+		if err == nil {
+			return false
+		}
+		if r == nil || r.StatusCode != http.StatusNotFound {
+			mlog.Warn("Unable to check if the PR of the spinmint still exists", mlog.Int("pr", testServer.Number), mlog.String("repo_name", testServer.RepoName), mlog.Err(err))
+			return false
+		}
+		if attempt >= spinmintPRGoneAttempts {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(spinmintPRGoneRetryDelay):
 		}
 	}
+}
 
-	mlog.Info("Done checking Test Server lifetime.")
+// getSpinmintReaperConcurrency returns how many expired spinmints are destroyed at once.
+func (s *Server) getSpinmintReaperConcurrency() int {
+	if s.Config.SpinmintReaperConcurrency <= 0 {
+		return defaultSpinmintReaperConcurrency
+	}
+	return s.Config.SpinmintReaperConcurrency
 }
 
-func (s *Server) storeSpinmintInfo(spinmint *model.Spinmint) {
-	if _, err := s.Store.Spinmint().Save(spinmint); err != nil {
-		mlog.Error(err.Error())
+// terminateSpinmintInstance terminates an instance that was never stored nor given a subdomain.
+func (s *Server) terminateSpinmintInstance(ctx context.Context, region, instanceID string) {
+	params := &ec2.TerminateInstancesInput{
+		InstanceIds: []*string{
+			&instanceID,
+		},
+	}
+	if _, err := s.getSpinmintEC2Client(region).TerminateInstancesWithContext(ctx, params); err != nil {
+		mlog.Error("Error terminating unstored spinmint instance", mlog.String("instance", instanceID), mlog.Err(err))
 	}
 }
 
 func (s *Server) removeTestServerFromDB(instanceID string) {
+	if s.Config.SpinmintSoftDelete {
+		if err := s.Store.Spinmint().SoftDelete(instanceID, model.NowUTC()); err != nil {
+			mlog.Error(err.Error())
+		}
+		return
+	}
 	if err := s.Store.Spinmint().Delete(instanceID); err != nil {
 		mlog.Error(err.Error())
 	}
 }
 
+// getSpinmintURL returns the public URL of the spinmint with the given subdomain,
+// which is its instance ID unless it was renamed.
+func (s *Server) getSpinmintURL(region, subdomain string) string {
+	return s.getSpinmintURLForSuffix(subdomain, s.getSpinmintRegion(region).AWSDnsSuffix)
+}
+
+func (s *Server) getSpinmintURLForSuffix(subdomain, dnsSuffix string) string {
+	return fmt.Sprintf("%v://%v.%v", s.getSpinmintURLScheme(), subdomain, dnsSuffix)
+}
+
+func (s *Server) getSpinmintURLScheme() string {
+	if s.Config.SpinmintsUseHTTPS {
+		return "https"
+	}
+	return "http"
+}
+
+// getSpinmintSeedUserCount returns the number of users the sample data should create,
+// or an empty string to keep the sample data default.
+func (s *Server) getSpinmintSeedUserCount() string {
+	if s.Config.SpinmintSeedUserCount <= 0 {
+		return ""
+	}
+	return strconv.Itoa(s.Config.SpinmintSeedUserCount)
+}
+
+// getSetupSpinmintMessage returns the message announcing a new spinmint, with a note on
+// how many spinmints are running already.
+func (s *Server) getSetupSpinmintMessage() string {
+	note := s.getSpinmintUtilizationNote()
+	if note == "" {
+		return s.Config.SetupSpinmintMessage
+	}
+	return s.Config.SetupSpinmintMessage + "\n\n" + note
+}
+
+// getSpinmintUtilizationNote tells how many of the spinmint slots are in use.
+// It is empty when no capacity is configured.
+func (s *Server) getSpinmintUtilizationNote() string {
+	capacity := s.getSpinmintCapacity()
+	if capacity <= 0 {
+		return ""
+	}
+	spinmints, err := s.Store.Spinmint().List()
+	if err != nil {
+		mlog.Warn("Unable to count the running spinmints", mlog.Err(err))
+		return ""
+	}
+	if len(spinmints) >= capacity {
+		return fmt.Sprintf(msgSpinmintCapacityFull, capacity, len(spinmints))
+	}
+	return fmt.Sprintf(msgSpinmintCapacity, len(spinmints), capacity)
+}
+
+// getSpinmintCapacity returns the number of spinmint slots the notes and warnings refer to:
+// the SpinmintMaxCount hard limit when set, so they agree with the rejections, else SpinmintCapacity.
+func (s *Server) getSpinmintCapacity() int {
+	if s.Config.SpinmintMaxCount > 0 {
+		return s.Config.SpinmintMaxCount
+	}
+	return s.Config.SpinmintCapacity
+}
+
+// getSpinmintSlotKey returns the key of the SpinmintMaxCount slot of a PR.
+func getSpinmintSlotKey(repoOwner, repoName string, number int) string {
+	return fmt.Sprintf("%s/%s#%d", repoOwner, repoName, number)
+}
+
+// reserveSpinmintSlot reserves one of the SpinmintMaxCount slots for a new spinmint of the PR
+// until releaseSpinmintSlot. The count and the reservation happen under the same lock, so setups
+// running at once can't all take the last slot. It returns false and how many spinmints are running
+// or reserved if no slot is left. A PR keeps the slot it already has, and spinmints are not blocked
+// if they can't be counted.
+func (s *Server) reserveSpinmintSlot(pr *model.PullRequest) (int, bool) {
+	if s.Config.SpinmintMaxCount <= 0 {
+		return 0, true
+	}
+
+	key := getSpinmintSlotKey(pr.RepoOwner, pr.RepoName, pr.Number)
+	s.spinmintSlotsLock.Lock()
+	defer s.spinmintSlotsLock.Unlock()
+	if _, ok := s.spinmintSlots[key]; ok {
+		return 0, true
+	}
+
+	spinmints, err := s.Store.Spinmint().List()
+	if err != nil {
+		mlog.Warn("Unable to count the running spinmints", mlog.Err(err))
+		return 0, true
+	}
+	used := len(spinmints)
+	stored := make(map[string]bool, len(spinmints))
+	for _, spinmint := range spinmints {
+		stored[getSpinmintSlotKey(spinmint.RepoOwner, spinmint.RepoName, spinmint.Number)] = true
+	}
+	// A reservation is only counted until its spinmint is stored.
+	for reserved := range s.spinmintSlots {
+		if !stored[reserved] {
+			used++
+		}
+	}
+	if used >= s.Config.SpinmintMaxCount {
+		return used, false
+	}
+
+	if s.spinmintSlots == nil {
+		s.spinmintSlots = make(map[string]struct{})
+	}
+	s.spinmintSlots[key] = struct{}{}
+	return used, true
+}
+
+// releaseSpinmintSlot gives back the slot reserved for the PR, if any.
+func (s *Server) releaseSpinmintSlot(pr *model.PullRequest) {
+	s.spinmintSlotsLock.Lock()
+	defer s.spinmintSlotsLock.Unlock()
+	delete(s.spinmintSlots, getSpinmintSlotKey(pr.RepoOwner, pr.RepoName, pr.Number))
+}
+
+// getSpinmintCapacityWarning warns that the running spinmints are above the configured
+// fraction of the capacity. It is empty otherwise, or when no threshold is configured.
+func (s *Server) getSpinmintCapacityWarning() string {
+	capacity := s.getSpinmintCapacity()
+	if capacity <= 0 || s.Config.SpinmintCapacityWarningThreshold <= 0 {
+		return ""
+	}
+	spinmints, err := s.Store.Spinmint().List()
+	if err != nil {
+		mlog.Warn("Unable to count the running spinmints", mlog.Err(err))
+		return ""
+	}
+	if float64(len(spinmints)) <= s.Config.SpinmintCapacityWarningThreshold*float64(capacity) {
+		return ""
+	}
+	return fmt.Sprintf(msgSpinmintCapacityWarning, len(spinmints), capacity)
+}
+
+// getSpinmintSeedWorkers returns how many workers create the sample data concurrently,
+// or an empty string to keep the sample data default.
+func (s *Server) getSpinmintSeedWorkers() string {
+	if s.Config.SpinmintSeedWorkers <= 0 {
+		return ""
+	}
+	return strconv.Itoa(s.Config.SpinmintSeedWorkers)
+}
+
+// getSpinmintTriggerLabels returns the spinmint labels of the PR, which decide how its spinmint is set up.
+func (s *Server) getSpinmintTriggerLabels(pr *model.PullRequest) []string {
+	var labels []string
+	for _, label := range []string{s.Config.SetupSpinmintTag, s.Config.SetupSpinmintUpgradeTag, s.Config.SetupSpinmintImportTag, s.Config.SetupSpinmintMinimalTag, s.Config.SetupSpinmintRecreateOnPushTag, s.Config.SetupSpinmintSpotTag} {
+		if label != "" && contains(pr.Labels, label) && !contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	for _, prefix := range []string{s.Config.SpinmintSizeLabelPrefix, s.Config.SpinmintRegionLabelPrefix} {
+		if prefix == "" {
+			continue
+		}
+		for _, label := range pr.Labels {
+			if strings.HasPrefix(label, prefix) && !contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels
+}
+
+// getSpinmintMarketOptions requests a spot instance for PRs labeled for it, which is
+// cheaper but can be interrupted. It is nil for regular on-demand instances.
+func (s *Server) getSpinmintMarketOptions(pr *model.PullRequest) *ec2.InstanceMarketOptionsRequest {
+	if s.Config.SetupSpinmintSpotTag == "" || !contains(pr.Labels, s.Config.SetupSpinmintSpotTag) {
+		return nil
+	}
+
+	spotOptions := &ec2.SpotMarketOptions{
+		SpotInstanceType:             aws.String(ec2.SpotInstanceTypeOneTime),
+		InstanceInterruptionBehavior: aws.String(ec2.InstanceInterruptionBehaviorTerminate),
+	}
+	if s.Config.SpinmintSpotMaxPrice != "" {
+		spotOptions.MaxPrice = aws.String(s.Config.SpinmintSpotMaxPrice)
+	}
+	return &ec2.InstanceMarketOptionsRequest{
+		MarketType:  aws.String(ec2.MarketTypeSpot),
+		SpotOptions: spotOptions,
+	}
+}
+
+// getSpinmintInstanceType returns the EC2 instance type for the size label among the given ones,
+// or the configured default when there is no valid size label. It is used to report on spinmints,
+// findSpinmintInstanceType decides the instance type of new ones.
+func (s *Server) getSpinmintInstanceType(labels []string) string {
+	instanceType, err := s.findSpinmintInstanceType(labels)
+	if err != nil {
+		return s.Config.AWSInstanceType
+	}
+	return instanceType
+}
+
+// spinmintInstanceTypeError tells that a spinmint was requested with a size label or
+// instance type it can't use, and which ones it can.
+type spinmintInstanceTypeError struct {
+	requested string
+	valid     []string
+}
+
+func (e *spinmintInstanceTypeError) Error() string {
+	return fmt.Sprintf("instance type %s is not allowed", e.requested)
+}
+
+// findSpinmintInstanceType returns the EC2 instance type for the size label among the given ones,
+// or the configured default when there is no size label. A size label that is unknown or whose
+// instance type is not allowed is rejected with the valid size labels, and a default instance type
+// that is not allowed with the allowed instance types.
+func (s *Server) findSpinmintInstanceType(labels []string) (string, *spinmintInstanceTypeError) {
+	if s.Config.SpinmintSizeLabelPrefix != "" {
+		for _, label := range labels {
+			if !strings.HasPrefix(label, s.Config.SpinmintSizeLabelPrefix) {
+				continue
+			}
+			instanceType, ok := s.resolveSpinmintSize(strings.TrimPrefix(label, s.Config.SpinmintSizeLabelPrefix))
+			if !ok {
+				valid := s.getSpinmintSizeAliases()
+				for i, alias := range valid {
+					valid[i] = s.Config.SpinmintSizeLabelPrefix + alias
+				}
+				return "", &spinmintInstanceTypeError{requested: label, valid: valid}
+			}
+			return instanceType, nil
+		}
+	}
+	if !s.isSpinmintInstanceTypeAllowed(s.Config.AWSInstanceType) {
+		return "", &spinmintInstanceTypeError{requested: s.Config.AWSInstanceType, valid: s.Config.SpinmintAllowedInstanceTypes}
+	}
+	return s.Config.AWSInstanceType, nil
+}
+
+// resolveSpinmintSize returns the instance type of a size alias. Instance types
+// that are the target of an alias are accepted as they are. Sizes whose instance
+// type is not allowed are unknown.
+func (s *Server) resolveSpinmintSize(size string) (string, bool) {
+	size = strings.TrimSpace(size)
+	for alias, instanceType := range s.Config.SpinmintSizeAliases {
+		if strings.EqualFold(alias, size) || instanceType == size {
+			return instanceType, s.isSpinmintInstanceTypeAllowed(instanceType)
+		}
+	}
+	return "", false
+}
+
+// getSpinmintSizeAliases returns the known size aliases of allowed instance types, sorted.
+func (s *Server) getSpinmintSizeAliases() []string {
+	aliases := make([]string, 0, len(s.Config.SpinmintSizeAliases))
+	for alias, instanceType := range s.Config.SpinmintSizeAliases {
+		if s.isSpinmintInstanceTypeAllowed(instanceType) {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// isSpinmintInstanceTypeAllowed is true if spinmints may run on instanceType.
+func (s *Server) isSpinmintInstanceTypeAllowed(instanceType string) bool {
+	return len(s.Config.SpinmintAllowedInstanceTypes) == 0 || contains(s.Config.SpinmintAllowedInstanceTypes, instanceType)
+}
+
+// isSpinmintRecreateOnPush reports whether new commits of the PR get a fresh spinmint.
+func (s *Server) isSpinmintRecreateOnPush(pr *model.PullRequest) bool {
+	return s.Config.SetupSpinmintRecreateOnPushTag != "" && contains(pr.Labels, s.Config.SetupSpinmintRecreateOnPushTag)
+}
+
+// resumeDraftSpinmint starts the spinmint flow a PR missed while it was a draft: the setup
+// or upgrade its labels ask for, or the recreation of its spinmints on the commits pushed since.
+func (s *Server) resumeDraftSpinmint(ctx context.Context, pr *model.PullRequest) {
+	spinmints, err := s.Store.Spinmint().GetAll(pr.Number, pr.RepoName)
+	if err != nil {
+		mlog.Error("Unable to get the spinmint information.", mlog.String("pr_error", err.Error()))
+		return
+	}
+
+	var msg string
+	switch {
+	case len(spinmints) > 0 && s.isSpinmintRecreateOnPush(pr):
+		msg = msgSpinmintRecreating
+		s.runSpinmintFlow("spinmint_recreate", pr, func() error { return s.recreateSpinmints(pr, spinmints) })
+	case len(spinmints) > 0:
+		return
+	case s.hasSetupSpinmintLabel(pr.Labels):
+		msg = s.getSetupSpinmintMessage()
+		s.runSpinmintFlow("spinmint_setup", pr, func() error { return s.waitForBuildAndSetupSpinmint(pr, false) })
+	case s.Config.SetupSpinmintUpgradeTag != "" && contains(pr.Labels, s.Config.SetupSpinmintUpgradeTag):
+		msg = s.Config.SetupSpinmintUpgradeMessage
+		s.runSpinmintFlow("spinmint_upgrade", pr, func() error { return s.waitForBuildAndSetupSpinmint(pr, true) })
+	default:
+		return
+	}
+	if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+		mlog.Warn("Error while commenting", mlog.Err(err))
+	}
+}
+
+// runSpinmintFlow runs a spinmint flow in the background. The flow comments its failures
+// on the PR itself, so they are only logged and counted here under name.
+func (s *Server) runSpinmintFlow(name string, pr *model.PullRequest, flow func() error) {
+	go func() {
+		if err := flow(); err != nil {
+			mlog.Error("Spinmint flow failed", mlog.String("flow", name), mlog.Int("pr", pr.Number), mlog.String("repo_name", pr.RepoName), mlog.Err(err))
+			s.Metrics.IncreaseWebhookErrors(name)
+		}
+	}()
+}
+
+// recreateSpinmints destroys the spinmints of the PR and sets up a new one once the
+// build of the latest commit is done.
+func (s *Server) recreateSpinmints(pr *model.PullRequest, spinmints []*model.Spinmint) error {
+	var credentialsURL string
+	for _, spinmint := range spinmints {
+		if spinmint.CredentialsCommentURL != "" {
+			credentialsURL = spinmint.CredentialsCommentURL
+		}
+		mlog.Info("Recreating spinmint", mlog.String("spinmint", spinmint.InstanceID), mlog.Int("pr", pr.Number))
+		if strings.Contains(spinmint.InstanceID, "i-") {
+			s.destroySpinmint(pr, spinmint)
+		}
+	}
+	return s.waitForBuildAndSetupSpinmintWithCredentials(pr, false, credentialsURL)
+}
+
+// isSpinmintImport reports whether the spinmint of the PR restores imported data
+// instead of starting from a clean install.
+func (s *Server) isSpinmintImport(pr *model.PullRequest) bool {
+	return s.Config.SetupSpinmintImportTag != "" && contains(pr.Labels, s.Config.SetupSpinmintImportTag)
+}
+
+// isSpinmintMinimal reports whether the spinmint of the PR starts clean, without the sample data.
+func (s *Server) isSpinmintMinimal(pr *model.PullRequest) bool {
+	return s.Config.SetupSpinmintMinimalTag != "" && contains(pr.Labels, s.Config.SetupSpinmintMinimalTag)
+}
+
+// getSpinmintSkipSampleData returns "true" when the sample data must not be created,
+// on top of imported data or on a minimal spinmint, or an empty string otherwise.
+func (s *Server) getSpinmintSkipSampleData(pr *model.PullRequest) string {
+	if s.isSpinmintImport(pr) || s.isSpinmintMinimal(pr) {
+		return "true"
+	}
+	return ""
+}
+
+// getSpinmintTeamName returns the team the setup script creates for the system admin of a
+// minimal spinmint, or an empty string when the sample data creates the teams.
+func (s *Server) getSpinmintTeamName(pr *model.PullRequest) string {
+	if s.isSpinmintMinimal(pr) {
+		return spinmintMinimalTeamName
+	}
+	return ""
+}
+
+// getSpinmintBannerText returns the system banner identifying the spinmint of a PR.
+func (s *Server) getSpinmintBannerText(pr *model.PullRequest) string {
+	banner := strings.Replace(s.Config.SpinmintBannerText, "REPO_NAME", pr.RepoName, -1)
+	banner = strings.Replace(banner, "PR_NUMBER", strconv.Itoa(pr.Number), -1)
+	return sanitizeSetupScriptValue(banner)
+}
+
+// sanitizeSetupScriptValue drops quotes and sed delimiters from a value put in the setup script.
+func sanitizeSetupScriptValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune("\"'|\\`$", r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// getSpinmintFilestorePrefix returns the S3 path prefix the spinmint of a PR should store its files under.
+// It is empty if no bucket prefix is configured.
+func (s *Server) getSpinmintFilestorePrefix(pr *model.PullRequest) string {
+	if s.Config.SpinmintFilestoreBucketPrefix == "" {
+		return ""
+	}
+	return path.Join(s.Config.SpinmintFilestoreBucketPrefix, pr.RepoName, strconv.Itoa(pr.Number))
+}
+
+// getSpinmintLicense returns the base64 encoded Mattermost license to upload to spinmints.
+// It is empty if no license file is configured.
+func (s *Server) getSpinmintLicense() (string, error) {
+	if s.Config.SpinmintLicenseFile == "" {
+		return "", nil
+	}
+
+	data, err := ioutil.ReadFile(s.Config.SpinmintLicenseFile)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read spinmint license file")
+	}
+	return base64.StdEncoding.EncodeToString(bytes.TrimSpace(data)), nil
+}
+
+// getSpinmintReapedMessage renders SpinmintReapedMessage with the reason the spinmint was destroyed
+// and, if it can be recreated, how to get a new one. It returns fallback if no reaped message is configured.
+func (s *Server) getSpinmintReapedMessage(reason, fallback string, recreatable bool) string {
+	if s.Config.SpinmintReapedMessage == "" {
+		return fallback
+	}
+
+	recreate := ""
+	if recreatable {
+		// handleSpinmintCreate lets the PR author and org members run it, unless "spinmint-create"
+		// is given other permissions.
+		recreate = fmt.Sprintf("To create a new test server, comment `/spinmint create`, or remove and add the `%s` label again.", s.Config.SetupSpinmintTag)
+	}
+	message := strings.Replace(s.Config.SpinmintReapedMessage, templateReapReason, reason, -1)
+	message = strings.Replace(message, templateRecreate, recreate, -1)
+	return strings.TrimSpace(message)
+}
+
+// isSpinmintDestroyedComment reports whether body is one of the comments announcing that a
+// spinmint was destroyed. The reaped message is matched on its longest part that is not filled in.
+func (s *Server) isSpinmintDestroyedComment(body string) bool {
+	reaped := ""
+	for _, part := range strings.Split(strings.Replace(s.Config.SpinmintReapedMessage, templateRecreate, templateReapReason, -1), templateReapReason) {
+		if part = strings.TrimSpace(part); len(part) > len(reaped) {
+			reaped = part
+		}
+	}
+	for _, msg := range []string{s.Config.DestroyedSpinmintMessage, s.Config.DestroyedExpirationSpinmintMessage, reaped} {
+		if msg != "" && strings.Contains(body, msg) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Server) isSpinMintLabel(label string) bool {
 	return label == s.Config.SetupSpinmintTag || label == s.Config.SetupSpinmintUpgradeTag
 }
+
+// setSpinmintStatusLabel replaces the spinmint status label of the PR with label.
+// An empty label just removes the current status label.
+func (s *Server) setSpinmintStatusLabel(ctx context.Context, pr *model.PullRequest, label string) {
+	var statusLabels []string
+	for _, l := range []string{s.Config.SpinmintProvisioningLabel, s.Config.SpinmintReadyLabel, s.Config.SpinmintFailedLabel} {
+		if l != "" {
+			statusLabels = append(statusLabels, l)
+		}
+	}
+	if len(statusLabels) == 0 || (label != "" && !contains(statusLabels, label)) {
+		return
+	}
+
+	current, _, err := s.GithubClient.Issues.ListLabelsByIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, nil)
+	if err != nil {
+		mlog.Warn("Unable to list the PR labels", mlog.Int("pr", pr.Number), mlog.Err(err))
+		return
+	}
+
+	hasLabel := false
+	for _, l := range current {
+		switch {
+		case l.GetName() == label:
+			hasLabel = true
+		case contains(statusLabels, l.GetName()):
+			s.removeLabel(ctx, pr.RepoOwner, pr.RepoName, pr.Number, l.GetName())
+		}
+	}
+
+	if label == "" || hasLabel {
+		return
+	}
+	if _, _, err = s.GithubClient.Issues.AddLabelsToIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, []string{label}); err != nil {
+		mlog.Warn("Unable to add the spinmint status label", mlog.Int("pr", pr.Number), mlog.String("label", label), mlog.Err(err))
+	}
+}
+
+func (s *Server) hasSetupSpinmintLabel(labels []string) bool {
+	for _, label := range labels {
+		if s.Config.SetupSpinmintTag != "" && label == s.Config.SetupSpinmintTag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+const (
+	msgSpinmintHistoryEmpty = "Mattermod has not done anything with test servers on this PR yet."
+	msgSpinmintHistoryError = "Error trying to get the test server history of this PR."
+)
+
+// logSpinmintAction adds a spinmint lifecycle event to the action log of the PR.
+func (s *Server) logSpinmintAction(action string, pr *model.PullRequest, instanceID string) {
+	if err := s.Store.Spinmint().LogAction(&model.SpinmintAction{
+		RepoOwner:  pr.RepoOwner,
+		RepoName:   pr.RepoName,
+		Number:     pr.Number,
+		Action:     action,
+		InstanceID: instanceID,
+		Sha:        pr.Sha,
+		CreatedAt:  model.NowUTC(),
+	}); err != nil {
+		mlog.Warn("Unable to log the spinmint action", mlog.String("action", action), mlog.Int("pr", pr.Number), mlog.Err(err))
+	}
+}
+
+// handleSpinmintHistory comments the actions mattermod took on the spinmints of the PR.
+func (s *Server) handleSpinmintHistory(ctx context.Context, pr *model.PullRequest) error {
+	msg := msgSpinmintHistoryError
+	actions, err := s.Store.Spinmint().ListActions(pr.RepoOwner, pr.RepoName, pr.Number)
+	if err == nil {
+		msg = formatSpinmintActions(actions)
+	}
+	if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); errComment != nil {
+		mlog.Warn("Error while commenting", mlog.Err(errComment))
+	}
+	return err
+}
+
+func formatSpinmintActions(actions []*model.SpinmintAction) string {
+	if len(actions) == 0 {
+		return msgSpinmintHistoryEmpty
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Test server history of this PR:\n\n")
+	sb.WriteString("| Time (UTC) | Action | Instance | Commit |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for _, a := range actions {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", a.CreatedAt.UTC().Format("2006-01-02 15:04"), a.Action, a.InstanceID, shortSha(a.Sha))
+	}
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSpinmintActions(t *testing.T) {
+	assert.Equal(t, msgSpinmintHistoryEmpty, formatSpinmintActions(nil))
+
+	createdAt := time.Date(2021, 3, 4, 10, 30, 0, 0, time.UTC)
+	actions := []*model.SpinmintAction{
+		{Action: spinmintEventCreated, InstanceID: "i-1", Sha: "abcdef123456", CreatedAt: createdAt},
+		{Action: spinmintEventDestroyed, InstanceID: "i-1", CreatedAt: createdAt.Add(time.Hour)},
+	}
+	expected := "Test server history of this PR:\n\n" +
+		"| Time (UTC) | Action | Instance | Commit |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| 2021-03-04 10:30 | created | i-1 | abcdef1 |\n" +
+		"| 2021-03-04 11:30 | destroyed | i-1 |  |\n"
+	assert.Equal(t, expected, formatSpinmintActions(actions))
+}
+
+func TestHandleSpinmintHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is},
+		Store:        ss,
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+
+	t.Run("no actions", func(t *testing.T) {
+		sms.EXPECT().ListActions(pr.RepoOwner, pr.RepoName, pr.Number).Return(nil, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintHistoryEmpty)}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintHistory(ctx, pr))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		sms.EXPECT().ListActions(pr.RepoOwner, pr.RepoName, pr.Number).Return(nil, errors.New("connection refused"))
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintHistoryError)}).Return(nil, nil, nil)
+		require.Error(t, s.handleSpinmintHistory(ctx, pr))
+	})
+}
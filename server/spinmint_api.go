@@ -0,0 +1,199 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	mmmodel "github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+// The sample data creates this system admin and user on every spinmint.
+const (
+	spinmintAdminUsername = "sysadmin"
+	spinmintAdminPassword = "Sys@dmin-sample1"
+	spinmintUserUsername  = "user-1"
+	spinmintUserPassword  = "SampleUs@r-1"
+	spinmintAdminEmail    = "sysadmin@sample.mattermost.com"
+)
+
+// spinmintMinimalTeamName is the team created for the system admin of minimal spinmints.
+const spinmintMinimalTeamName = "test"
+
+// SpinmintCredentials are the system admin and user accounts created on every spinmint by the
+// setup script. An account is only created when its email is set, otherwise the one of the
+// sample data is used.
+type SpinmintCredentials struct {
+	AdminUsername string
+	AdminPassword string
+	AdminEmail    string
+	UserUsername  string
+	UserPassword  string
+	UserEmail     string
+}
+
+// validate checks that each account is either fully set or left to the sample data, and that
+// its values survive the setup script, which would otherwise silently strip some characters.
+func (c *SpinmintCredentials) validate() error {
+	if err := validateSpinmintAccount("admin", c.AdminUsername, c.AdminPassword, c.AdminEmail); err != nil {
+		return err
+	}
+	return validateSpinmintAccount("user", c.UserUsername, c.UserPassword, c.UserEmail)
+}
+
+func validateSpinmintAccount(account, username, password, email string) error {
+	if email == "" {
+		if username != "" || password != "" {
+			return errors.Errorf("the %s account has a username or password but no email, so it would not be created", account)
+		}
+		return nil
+	}
+	if username == "" || password == "" {
+		return errors.Errorf("the %s account needs a username and a password", account)
+	}
+	for _, value := range []string{username, password, email} {
+		if sanitizeSetupScriptValue(value) != value {
+			return errors.Errorf("the %s account has a value with one of the characters %s the setup script cannot take", account, "\"'|\\`$")
+		}
+	}
+	return nil
+}
+
+// getSpinmintCredentials returns the accounts of the spinmints, as put in the setup script.
+// The setup script creates them, and mattermod logs in and posts them, from these values only.
+func (s *Server) getSpinmintCredentials() *SpinmintCredentials {
+	credentials := &SpinmintCredentials{
+		AdminUsername: spinmintAdminUsername,
+		AdminPassword: spinmintAdminPassword,
+		UserUsername:  spinmintUserUsername,
+		UserPassword:  spinmintUserPassword,
+	}
+	if configured := s.Config.SpinmintCredentials; configured != nil {
+		if configured.AdminEmail != "" {
+			credentials.AdminUsername = sanitizeSetupScriptValue(configured.AdminUsername)
+			credentials.AdminPassword = sanitizeSetupScriptValue(configured.AdminPassword)
+			credentials.AdminEmail = sanitizeSetupScriptValue(configured.AdminEmail)
+		}
+		if configured.UserEmail != "" {
+			credentials.UserUsername = sanitizeSetupScriptValue(configured.UserUsername)
+			credentials.UserPassword = sanitizeSetupScriptValue(configured.UserPassword)
+			credentials.UserEmail = sanitizeSetupScriptValue(configured.UserEmail)
+		}
+	}
+	return credentials
+}
+
+// getSpinmintAccounts returns the accounts of the spinmint of the PR. Minimal spinmints have no
+// sample data, so the setup script always creates their system admin, and they have no user.
+func (s *Server) getSpinmintAccounts(pr *model.PullRequest) *SpinmintCredentials {
+	credentials := s.getSpinmintCredentials()
+	if s.isSpinmintMinimal(pr) {
+		if credentials.AdminEmail == "" {
+			credentials.AdminEmail = spinmintAdminEmail
+		}
+		credentials.UserUsername, credentials.UserPassword, credentials.UserEmail = "", "", ""
+	}
+	return credentials
+}
+
+// getSpinmintCredentialsTable lists the accounts to log in to the spinmint of the PR with.
+func (s *Server) getSpinmintCredentialsTable(pr *model.PullRequest) string {
+	credentials := s.getSpinmintAccounts(pr)
+	var sb strings.Builder
+	sb.WriteString("| Account | Username | Password |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+	fmt.Fprintf(&sb, "| System admin | `%s` | `%s` |\n", credentials.AdminUsername, credentials.AdminPassword)
+	if credentials.UserUsername != "" {
+		fmt.Fprintf(&sb, "| User | `%s` | `%s` |\n", credentials.UserUsername, credentials.UserPassword)
+	}
+	return sb.String()
+}
+
+// spinmintRequestInitialBackoff is the wait before the first retry of a failed spinmint request.
+var spinmintRequestInitialBackoff = time.Second
+
+// spinmintTransport is shared by all the requests to spinmints, so that polling
+// the same spinmint over and over reuses its connections.
+var spinmintTransport = newSpinmintTransport()
+
+func newSpinmintTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 10
+	return t
+}
+
+// spinmintHTTPClient returns the client to talk to spinmints, bounded by SpinmintRequestTimeoutSeconds.
+func (s *Server) spinmintHTTPClient() *http.Client {
+	return &http.Client{Transport: spinmintTransport, Timeout: s.getSpinmintRequestTimeout()}
+}
+
+func (s *Server) getSpinmintRequestTimeout() time.Duration {
+	timeout := s.Config.SpinmintRequestTimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultSpinmintRequestTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// doSpinmintRequest sends req with client. Network errors and 502, 503 and 504
+// responses are retried up to SpinmintRequestMaxRetries times, doubling the wait between
+// attempts. A POST is only retried on network errors, since the server may have acted on
+// it before failing.
+func (s *Server) doSpinmintRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	backoff := spinmintRequestInitialBackoff
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		r, err := client.Do(req)
+		if err == nil && (req.Method == http.MethodPost || !isTransientHTTPStatus(r.StatusCode)) {
+			return r, nil
+		}
+		if attempt >= s.Config.SpinmintRequestMaxRetries {
+			return r, err
+		}
+		if err != nil {
+			mlog.Warn("Spinmint request failed, retrying", mlog.String("url", req.URL.String()), mlog.Int("attempt", attempt+1), mlog.Err(err))
+		} else {
+			mlog.Warn("Spinmint request failed, retrying", mlog.String("url", req.URL.String()), mlog.Int("attempt", attempt+1), mlog.String("status", r.Status))
+			closeBody(r)
+		}
+
+		jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1)) //nolint:gosec
+		select {
+		case <-req.Context().Done():
+			return nil, errors.Wrap(req.Context().Err(), "timed out retrying the spinmint request")
+		case <-time.After(backoff + jitter):
+		}
+		backoff *= 2
+	}
+}
+
+func isTransientHTTPStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// newSpinmintAdminClient returns a Mattermost API client logged in as the system admin of the spinmint at siteURL.
+func (s *Server) newSpinmintAdminClient(siteURL string) (*mmmodel.Client4, error) {
+	client := mmmodel.NewAPIv4Client(strings.TrimSuffix(siteURL, "/"))
+	client.HttpClient = s.spinmintHTTPClient()
+
+	credentials := s.getSpinmintCredentials()
+	if _, resp := client.Login(credentials.AdminUsername, credentials.AdminPassword); resp.Error != nil {
+		return nil, errors.Wrap(resp.Error, "unable to log in to the test server")
+	}
+	return client, nil
+}
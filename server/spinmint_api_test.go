@@ -0,0 +1,174 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoSpinmintRequest(t *testing.T) {
+	defer func(backoff time.Duration) { spinmintRequestInitialBackoff = backoff }(spinmintRequestInitialBackoff)
+	spinmintRequestInitialBackoff = time.Millisecond
+
+	s := &Server{Config: &Config{SpinmintRequestMaxRetries: 3}}
+
+	// newFlakyServer fails the first two requests with fail, then answers 200 with the request body.
+	newFlakyServer := func(fail func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) <= 2 {
+				fail(w)
+				return
+			}
+			b, _ := ioutil.ReadAll(r.Body)
+			_, _ = w.Write(b)
+		}))
+		return ts, &calls
+	}
+	dropConnection := func(w http.ResponseWriter) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}
+	unavailable := func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	t.Run("GET is retried on 503", func(t *testing.T) {
+		ts, calls := newFlakyServer(unavailable)
+		defer ts.Close()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, http.NoBody)
+		require.NoError(t, err)
+		r, err := s.doSpinmintRequest(s.spinmintHTTPClient(), req)
+		require.NoError(t, err)
+		defer closeBody(r)
+		assert.Equal(t, http.StatusOK, r.StatusCode)
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("GET gives up after the max retries", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			unavailable(w)
+		}))
+		defer ts.Close()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, http.NoBody)
+		require.NoError(t, err)
+		r, err := s.doSpinmintRequest(s.spinmintHTTPClient(), req)
+		require.NoError(t, err)
+		defer closeBody(r)
+		assert.Equal(t, http.StatusServiceUnavailable, r.StatusCode)
+	})
+
+	t.Run("POST is retried on dropped connections", func(t *testing.T) {
+		ts, calls := newFlakyServer(dropConnection)
+		defer ts.Close()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL, bytes.NewReader([]byte("payload")))
+		require.NoError(t, err)
+		r, err := s.doSpinmintRequest(s.spinmintHTTPClient(), req)
+		require.NoError(t, err)
+		defer closeBody(r)
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(b))
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("POST is not retried on 503", func(t *testing.T) {
+		ts, calls := newFlakyServer(unavailable)
+		defer ts.Close()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, ts.URL, bytes.NewReader([]byte("payload")))
+		require.NoError(t, err)
+		r, err := s.doSpinmintRequest(s.spinmintHTTPClient(), req)
+		require.NoError(t, err)
+		defer closeBody(r)
+		assert.Equal(t, http.StatusServiceUnavailable, r.StatusCode)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("retries disabled", func(t *testing.T) {
+		ts, calls := newFlakyServer(dropConnection)
+		defer ts.Close()
+
+		noRetries := &Server{Config: &Config{}}
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, http.NoBody)
+		require.NoError(t, err)
+		_, err = noRetries.doSpinmintRequest(s.spinmintHTTPClient(), req) //nolint:bodyclose
+		require.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+}
+
+func TestGetSpinmintCredentials(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	assert.Equal(t, &SpinmintCredentials{
+		AdminUsername: spinmintAdminUsername,
+		AdminPassword: spinmintAdminPassword,
+		UserUsername:  spinmintUserUsername,
+		UserPassword:  spinmintUserPassword,
+	}, s.getSpinmintCredentials())
+
+	// Only the accounts with an email are created, the user is still the one of the sample data.
+	s.Config.SpinmintCredentials = &SpinmintCredentials{
+		AdminUsername: "admin",
+		AdminPassword: "Admin-pa$$1",
+		AdminEmail:    "admin@example.com",
+		UserUsername:  "tester",
+		UserPassword:  "Tester-pass1",
+	}
+	assert.Equal(t, &SpinmintCredentials{
+		AdminUsername: "admin",
+		AdminPassword: "Admin-pa1",
+		AdminEmail:    "admin@example.com",
+		UserUsername:  spinmintUserUsername,
+		UserPassword:  spinmintUserPassword,
+	}, s.getSpinmintCredentials())
+
+	assert.Equal(t, "| Account | Username | Password |\n| --- | --- | --- |\n| System admin | `admin` | `Admin-pa1` |\n| User | `user-1` | `SampleUs@r-1` |\n", s.getSpinmintCredentialsTable(&model.PullRequest{}))
+}
+
+func TestGetSpinmintAccounts(t *testing.T) {
+	s := &Server{Config: &Config{SetupSpinmintMinimalTag: "Minimal Test Server"}}
+	full := &model.PullRequest{Labels: []string{"Setup Test Server"}}
+	minimal := &model.PullRequest{Labels: []string{"Setup Test Server", "Minimal Test Server"}}
+
+	assert.Equal(t, s.getSpinmintCredentials(), s.getSpinmintAccounts(full))
+	assert.Contains(t, s.getSpinmintCredentialsTable(full), "| User |")
+
+	// Without the sample data, the system admin is created by the setup script and there is no user.
+	assert.Equal(t, &SpinmintCredentials{
+		AdminUsername: spinmintAdminUsername,
+		AdminPassword: spinmintAdminPassword,
+		AdminEmail:    spinmintAdminEmail,
+	}, s.getSpinmintAccounts(minimal))
+	assert.NotContains(t, s.getSpinmintCredentialsTable(minimal), "| User |")
+
+	s.Config.SpinmintCredentials = &SpinmintCredentials{
+		AdminUsername: "admin",
+		AdminPassword: "Admin-pass1",
+		AdminEmail:    "admin@example.com",
+		UserUsername:  "tester",
+		UserPassword:  "Tester-pass1",
+		UserEmail:     "tester@example.com",
+	}
+	assert.Equal(t, &SpinmintCredentials{
+		AdminUsername: "admin",
+		AdminPassword: "Admin-pass1",
+		AdminEmail:    "admin@example.com",
+	}, s.getSpinmintAccounts(minimal))
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	jenkins "github.com/cpanato/golang-jenkins"
+	"github.com/heroku/docker-registry-client/registry"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// artifactBuilds records the order in which the build and the image were waited for.
+type artifactBuilds struct {
+	MockedBuilds
+	buildErr   error
+	imageErr   error
+	buildDelay time.Duration
+	events     chan string
+	image      *dockerImage
+}
+
+func (b *artifactBuilds) waitForBuild(ctx context.Context, s *Server, client *jenkins.Jenkins, pr *model.PullRequest) (*model.PullRequest, error) {
+	b.events <- "build started"
+	select {
+	case <-time.After(b.buildDelay):
+	case <-ctx.Done():
+		return pr, ctx.Err()
+	}
+	b.events <- "build done"
+	built := *pr
+	built.BuildLink = "https://ci/build/1"
+	return &built, b.buildErr
+}
+
+func (b *artifactBuilds) waitForImage(ctx context.Context, s *Server, reg *registry.Registry, image *dockerImage, pr *model.PullRequest) (*model.PullRequest, error) {
+	b.events <- "image started"
+	b.image = image
+	return pr, b.imageErr
+}
+
+func TestWaitForSpinmintArtifacts(t *testing.T) {
+	defer func(orig func(string, string, string) (*registry.Registry, error)) { newDockerRegistry = orig }(newDockerRegistry)
+	newDockerRegistry = func(url, user, pass string) (*registry.Registry, error) {
+		return &registry.Registry{}, nil
+	}
+
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server", Number: 123, Sha: "abcdef123456"}
+
+	var timings *spinmintTimings
+	run := func(cfg *Config, b *artifactBuilds) (*model.PullRequest, error, []string) {
+		b.events = make(chan string, 10)
+		s := &Server{Config: cfg, Builds: b}
+		timings = newSpinmintTimings()
+		got, err := s.waitForSpinmintArtifacts(context.Background(), nil, pr, timings)
+		close(b.events)
+		var events []string
+		for e := range b.events {
+			events = append(events, e)
+		}
+		return got, err, events
+	}
+
+	t.Run("image wait disabled", func(t *testing.T) {
+		got, err, events := run(&Config{}, &artifactBuilds{})
+		require.NoError(t, err)
+		assert.Equal(t, "https://ci/build/1", got.BuildLink)
+		assert.Equal(t, []string{"build started", "build done"}, events)
+	})
+
+	t.Run("sequential", func(t *testing.T) {
+		got, err, events := run(&Config{SpinmintWaitForImage: true}, &artifactBuilds{buildDelay: 10 * time.Millisecond})
+		require.NoError(t, err)
+		assert.Equal(t, "https://ci/build/1", got.BuildLink)
+		assert.Equal(t, []string{"build started", "build done", "image started"}, events)
+		require.Len(t, timings.phases, 2)
+		assert.Equal(t, spinmintPhaseBuild, timings.phases[0].Name)
+		assert.GreaterOrEqual(t, int64(timings.phases[0].Duration), int64(10*time.Millisecond))
+		assert.Equal(t, spinmintPhaseImage, timings.phases[1].Name)
+	})
+
+	t.Run("sequential build failure skips the image", func(t *testing.T) {
+		_, err, events := run(&Config{SpinmintWaitForImage: true}, &artifactBuilds{buildErr: errors.New("build failed")})
+		require.EqualError(t, err, "build failed")
+		assert.Equal(t, []string{"build started", "build done"}, events)
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		got, err, events := run(&Config{SpinmintWaitForImage: true, SpinmintParallelImageWait: true}, &artifactBuilds{buildDelay: 50 * time.Millisecond})
+		require.NoError(t, err)
+		assert.Equal(t, "https://ci/build/1", got.BuildLink)
+		require.Len(t, events, 3)
+		assert.Equal(t, "build done", events[2], "the image should be waited for while the build runs")
+	})
+
+	t.Run("parallel image failure cancels the build", func(t *testing.T) {
+		_, err, events := run(&Config{SpinmintWaitForImage: true, SpinmintParallelImageWait: true}, &artifactBuilds{buildDelay: time.Minute, imageErr: errors.New("no image")})
+		require.EqualError(t, err, "no image")
+		assert.NotContains(t, events, "build done")
+	})
+
+	t.Run("repository image", func(t *testing.T) {
+		var registryURL string
+		newDockerRegistry = func(url, user, pass string) (*registry.Registry, error) {
+			registryURL = url
+			return &registry.Registry{}, nil
+		}
+		cfg := &Config{
+			SpinmintWaitForImage: true,
+			DockerRegistryURL:    "https://registry-1.docker.io",
+			Repositories: []*Repository{
+				{Owner: pr.RepoOwner, Name: pr.RepoName, DockerImage: "registry.internal/mattermost/enterprise:nightly"},
+			},
+		}
+		b := &artifactBuilds{}
+		_, err, _ := run(cfg, b)
+		require.NoError(t, err)
+		assert.Equal(t, "https://registry.internal", registryURL)
+		assert.Equal(t, &dockerImage{Registry: "registry.internal", Repository: "mattermost/enterprise", Tag: "nightly"}, b.image)
+
+		cfg.Repositories[0].DockerImage = ""
+		_, err, _ = run(cfg, b)
+		require.NoError(t, err)
+		assert.Equal(t, "https://registry-1.docker.io", registryURL)
+		assert.Equal(t, &dockerImage{Repository: defaultDockerImage}, b.image)
+
+		cfg.Repositories[0].DockerImage = "registry.internal/mattermost/enterprise:"
+		_, err, events := run(cfg, b)
+		require.Error(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("registry unavailable", func(t *testing.T) {
+		newDockerRegistry = func(url, user, pass string) (*registry.Registry, error) {
+			return nil, errors.New("unauthorized")
+		}
+		_, err, events := run(&Config{SpinmintWaitForImage: true}, &artifactBuilds{})
+		require.Error(t, err)
+		assert.Empty(t, events)
+	})
+}
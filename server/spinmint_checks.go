@@ -0,0 +1,116 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+const (
+	checkRunStatusInProgress  = "in_progress"
+	checkRunStatusCompleted   = "completed"
+	checkRunConclusionSuccess = "success"
+	checkRunConclusionFailure = "failure"
+	checkRunConclusionNeutral = "neutral"
+)
+
+// startSpinmintCheckRun creates an in progress check run for the spinmint of the PR commit,
+// so it shows in the checks tab of the PR along with the CI.
+func (s *Server) startSpinmintCheckRun(ctx context.Context, pr *model.PullRequest) {
+	if s.Config.SpinmintCheckRunName == "" {
+		return
+	}
+
+	_, _, err := s.GithubClient.Checks.CreateCheckRun(ctx, pr.RepoOwner, pr.RepoName, github.CreateCheckRunOptions{
+		Name:    s.Config.SpinmintCheckRunName,
+		HeadSHA: pr.Sha,
+		Status:  github.String(checkRunStatusInProgress),
+		Output: &github.CheckRunOutput{
+			Title:   github.String("Setting up the test server"),
+			Summary: github.String(fmt.Sprintf("A test server is being set up for commit `%s`.", shortSha(pr.Sha))),
+		},
+	})
+	if err != nil {
+		mlog.Warn("Unable to create the spinmint check run", mlog.Int("pr", pr.Number), mlog.Err(err))
+	}
+}
+
+// completeSpinmintCheckRun completes the latest spinmint check run of the PR commit.
+// A check run is created if the commit has none yet.
+func (s *Server) completeSpinmintCheckRun(ctx context.Context, pr *model.PullRequest, conclusion, detailsURL string, output *github.CheckRunOutput) {
+	if s.Config.SpinmintCheckRunName == "" {
+		return
+	}
+
+	var details *string
+	if detailsURL != "" {
+		details = github.String(detailsURL)
+	}
+
+	checkRuns, _, err := s.GithubClient.Checks.ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, &github.ListCheckRunsOptions{
+		CheckName: github.String(s.Config.SpinmintCheckRunName),
+	})
+	if err != nil {
+		mlog.Warn("Unable to list the spinmint check runs", mlog.Int("pr", pr.Number), mlog.Err(err))
+		return
+	}
+
+	if len(checkRuns.CheckRuns) == 0 {
+		_, _, err = s.GithubClient.Checks.CreateCheckRun(ctx, pr.RepoOwner, pr.RepoName, github.CreateCheckRunOptions{
+			Name:       s.Config.SpinmintCheckRunName,
+			HeadSHA:    pr.Sha,
+			DetailsURL: details,
+			Status:     github.String(checkRunStatusCompleted),
+			Conclusion: github.String(conclusion),
+			Output:     output,
+		})
+	} else {
+		_, _, err = s.GithubClient.Checks.UpdateCheckRun(ctx, pr.RepoOwner, pr.RepoName, checkRuns.CheckRuns[0].GetID(), github.UpdateCheckRunOptions{
+			Name:       s.Config.SpinmintCheckRunName,
+			DetailsURL: details,
+			Status:     github.String(checkRunStatusCompleted),
+			Conclusion: github.String(conclusion),
+			Output:     output,
+		})
+	}
+	if err != nil {
+		mlog.Warn("Unable to complete the spinmint check run", mlog.Int("pr", pr.Number), mlog.String("conclusion", conclusion), mlog.Err(err))
+	}
+}
+
+// failSpinmintCheckRun completes the spinmint check run of the PR commit as failed.
+func (s *Server) failSpinmintCheckRun(ctx context.Context, pr *model.PullRequest) {
+	s.completeSpinmintCheckRun(ctx, pr, checkRunConclusionFailure, "", &github.CheckRunOutput{
+		Title:   github.String("The test server could not be set up"),
+		Summary: github.String("The test server could not be set up. The comments of the PR have the details."),
+	})
+}
+
+// supersedeSpinmintCheckRun completes the spinmint check run of the previous commit of the PR
+// as neutral and starts one for its current commit, which the spinmint is set up from instead.
+func (s *Server) supersedeSpinmintCheckRun(ctx context.Context, previous, pr *model.PullRequest) {
+	s.completeSpinmintCheckRun(ctx, previous, checkRunConclusionNeutral, "", &github.CheckRunOutput{
+		Title:   github.String("Superseded by a newer commit"),
+		Summary: github.String(fmt.Sprintf("The test server is set up for commit `%s` instead.", shortSha(pr.Sha))),
+	})
+	s.startSpinmintCheckRun(ctx, pr)
+}
+
+// getSpinmintCheckRunOutput describes the ready spinmint at siteURL and how to log in to it.
+func (s *Server) getSpinmintCheckRunOutput(pr *model.PullRequest, siteURL string) *github.CheckRunOutput {
+	summary := fmt.Sprintf("The test server is available at %s", siteURL)
+	if !s.isSpinmintImport(pr) {
+		// Imported data comes with its own users.
+		summary += "\n\n" + s.getSpinmintCredentialsTable(pr)
+	}
+	return &github.CheckRunOutput{
+		Title:   github.String("The test server is ready"),
+		Summary: github.String(summary),
+	}
+}
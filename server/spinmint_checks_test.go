@@ -0,0 +1,97 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpinmintCheckRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	cs := mocks.NewMockChecksService(ctrl)
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Checks: cs},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, Sha: "abcdef123456"}
+	listOptions := &github.ListCheckRunsOptions{CheckName: github.String("Test Server")}
+
+	t.Run("disabled", func(t *testing.T) {
+		s.startSpinmintCheckRun(ctx, pr)
+		s.failSpinmintCheckRun(ctx, pr)
+	})
+
+	s.Config.SpinmintCheckRunName = "Test Server"
+
+	t.Run("start", func(t *testing.T) {
+		cs.EXPECT().CreateCheckRun(ctx, pr.RepoOwner, pr.RepoName, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+				assert.Equal(t, "Test Server", opts.Name)
+				assert.Equal(t, pr.Sha, opts.HeadSHA)
+				assert.Equal(t, checkRunStatusInProgress, opts.GetStatus())
+				return &github.CheckRun{ID: github.Int64(1)}, nil, nil
+			})
+		s.startSpinmintCheckRun(ctx, pr)
+	})
+
+	t.Run("complete the started check run", func(t *testing.T) {
+		cs.EXPECT().ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, listOptions).Return(&github.ListCheckRunsResults{
+			CheckRuns: []*github.CheckRun{{ID: github.Int64(1)}},
+		}, nil, nil)
+		cs.EXPECT().UpdateCheckRun(ctx, pr.RepoOwner, pr.RepoName, int64(1), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int64, opts github.UpdateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+				assert.Equal(t, checkRunStatusCompleted, opts.GetStatus())
+				assert.Equal(t, checkRunConclusionSuccess, opts.GetConclusion())
+				assert.Equal(t, "https://i-1234.spinmint.test", opts.GetDetailsURL())
+				assert.Contains(t, opts.Output.GetSummary(), "https://i-1234.spinmint.test")
+				assert.Contains(t, opts.Output.GetSummary(), spinmintAdminUsername)
+				return nil, nil, nil
+			})
+		s.completeSpinmintCheckRun(ctx, pr, checkRunConclusionSuccess, "https://i-1234.spinmint.test", s.getSpinmintCheckRunOutput(pr, "https://i-1234.spinmint.test"))
+	})
+
+	t.Run("fail without a started check run", func(t *testing.T) {
+		cs.EXPECT().ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, listOptions).Return(&github.ListCheckRunsResults{}, nil, nil)
+		cs.EXPECT().CreateCheckRun(ctx, pr.RepoOwner, pr.RepoName, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+				assert.Equal(t, checkRunStatusCompleted, opts.GetStatus())
+				assert.Equal(t, checkRunConclusionFailure, opts.GetConclusion())
+				assert.Nil(t, opts.DetailsURL)
+				return nil, nil, nil
+			})
+		s.failSpinmintCheckRun(ctx, pr)
+	})
+
+	t.Run("supersede the check run of the previous commit", func(t *testing.T) {
+		pushed := *pr
+		pushed.Sha = "0123456789ab"
+		cs.EXPECT().ListCheckRunsForRef(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, listOptions).Return(&github.ListCheckRunsResults{
+			CheckRuns: []*github.CheckRun{{ID: github.Int64(1)}},
+		}, nil, nil)
+		cs.EXPECT().UpdateCheckRun(ctx, pr.RepoOwner, pr.RepoName, int64(1), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int64, opts github.UpdateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+				assert.Equal(t, checkRunStatusCompleted, opts.GetStatus())
+				assert.Equal(t, checkRunConclusionNeutral, opts.GetConclusion())
+				assert.Contains(t, opts.Output.GetSummary(), "0123456")
+				return nil, nil, nil
+			})
+		cs.EXPECT().CreateCheckRun(ctx, pr.RepoOwner, pr.RepoName, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+				assert.Equal(t, pushed.Sha, opts.HeadSHA)
+				assert.Equal(t, checkRunStatusInProgress, opts.GetStatus())
+				return &github.CheckRun{ID: github.Int64(2)}, nil, nil
+			})
+		s.supersedeSpinmintCheckRun(ctx, pr, &pushed)
+	})
+}
@@ -0,0 +1,636 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	mmmodel "github.com/mattermost/mattermost-server/v5/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	msgSpinmintNotFound       = "There is no test server running for this PR."
+	msgSpinmintTransferUsage  = "Please specify the new owner of the test server, e.g. `/spinmint transfer @username`."
+	msgSpinmintTransferDenied = "Only the owner of the test server or a maintainer can transfer it."
+	msgSpinmintTransferNoUser = "`%s` is not a GitHub user."
+	msgSpinmintTransferNoRepo = "@%s is not a collaborator of this repository, so they can't own its test server."
+
+	msgSpinmintSnapshotNotSupported = "Snapshots are not supported for this test server."
+	msgSpinmintSnapshotError        = "Error trying to snapshot the test server. Please do it manually."
+
+	msgSpinmintMaintainerOnly = "Looks like you don't have permissions to trigger this command.\n Only available for org members"
+	msgSpinmintGetConfigError = "Error trying to get the configuration of the test server."
+
+	msgSpinmintCreateDraft  = "This PR is a draft. Please mark it as ready for review before creating a test server."
+	msgSpinmintUpgradeDraft = "This PR is a draft. Please mark it as ready for review before upgrading its test server."
+	msgSpinmintUnknownSize  = "Unknown test server size `%s`. The available sizes are: `%s`."
+
+	msgSpinmintInstanceTypeNotAllowed = "Test servers can't use `%s`. The allowed ones are: `%s`."
+
+	msgSpinmintUsageError = "Error trying to get the test server usage."
+
+	msgSpinmintForceUpgrade = "Upgrading the test server to the latest build of `%s` without waiting for the build status."
+	msgSpinmintReinit       = "Running the initialization of the test server `%s` again."
+
+	msgSpinmintSetUsage      = "Please specify the setting and its new value, e.g. `/spinmint set FeatureFlags.MyFeature true`."
+	msgSpinmintSetNotAllowed = "The setting `%s` can't be changed with this command."
+	msgSpinmintSetError      = "Error trying to change the setting of the test server."
+	msgSpinmintSetDone       = "Changed `%s` on the test server from `%s` to `%s`."
+)
+
+type mmPingResponse struct {
+	Status string `json:"status"`
+}
+
+// checkMMPing calls the system ping endpoint of the Mattermost server at siteURL
+// and returns the reported status and the server version.
+func (s *Server) checkMMPing(ctx context.Context, siteURL string) (status, version string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(siteURL, "/")+"/api/v4/system/ping", http.NoBody)
+	if err != nil {
+		return "", "", err
+	}
+	r, err := s.doSpinmintRequest(s.spinmintHTTPClient(), req)
+	if err != nil {
+		return "", "", err
+	}
+	defer closeBody(r)
+
+	if r.StatusCode != http.StatusOK {
+		return "", "", errors.Errorf("ping returned http status %s", r.Status)
+	}
+
+	var ping mmPingResponse
+	if err = json.NewDecoder(r.Body).Decode(&ping); err != nil {
+		return "", "", errors.Wrap(err, "unable to decode ping response")
+	}
+
+	return ping.Status, r.Header.Get("X-Version-Id"), nil
+}
+
+// handleSpinmintPing checks whether the test server of a PR is reachable and
+// replies on the PR with the ping status and the server version.
+func (s *Server) handleSpinmintPing(ctx context.Context, pr *model.PullRequest) error {
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	smLink := s.getSpinmintURL(spinmint.Region, spinmint.GetSubdomain())
+	status, version, err := s.checkMMPing(ctx, smLink)
+	if err != nil {
+		msg = fmt.Sprintf("Test server %s is not reachable: `%s`", smLink, err.Error())
+		return nil
+	}
+
+	msg = fmt.Sprintf("Test server %s is reachable.\nPing status: `%s`\nServer version: `%s`", smLink, status, version)
+	return nil
+}
+
+// handleSpinmintTransfer hands the test server of a PR over to another user, who must be a
+// collaborator of the repository. Only the current owner or an org member can transfer it.
+func (s *Server) handleSpinmintTransfer(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	newOwner := getSpinmintTransferTarget(body)
+	if newOwner == "" {
+		msg = msgSpinmintTransferUsage
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	if commenter != spinmint.CreatedBy && !s.IsOrgMember(commenter) {
+		msg = msgSpinmintTransferDenied
+		return nil
+	}
+
+	user, r, err := s.GithubClient.Users.Get(ctx, newOwner)
+	if err != nil {
+		if r != nil && r.StatusCode == http.StatusNotFound {
+			msg = fmt.Sprintf(msgSpinmintTransferNoUser, newOwner)
+			return nil
+		}
+		return errors.Wrapf(err, "unable to get the GitHub user %s", newOwner)
+	}
+	// The login returned by GitHub has the case the user chose, unlike the one typed in the comment.
+	newOwner = user.GetLogin()
+	isCollaborator, _, err := s.GithubClient.Repositories.IsCollaborator(ctx, pr.RepoOwner, pr.RepoName, newOwner)
+	if err != nil {
+		return errors.Wrapf(err, "unable to check if %s is a collaborator", newOwner)
+	}
+	if !isCollaborator {
+		msg = fmt.Sprintf(msgSpinmintTransferNoRepo, newOwner)
+		return nil
+	}
+
+	if err = s.Store.Spinmint().UpdateCreatedBy(spinmint.InstanceID, newOwner); err != nil {
+		return err
+	}
+
+	mlog.Info("Transferred spinmint", mlog.String("instance", spinmint.InstanceID), mlog.String("from", spinmint.CreatedBy), mlog.String("to", newOwner))
+	msg = fmt.Sprintf("The test server of this PR is now owned by @%s.", newOwner)
+	return nil
+}
+
+// getSpinmintTransferTarget returns the user named after "/spinmint transfer", without the leading @.
+func getSpinmintTransferTarget(body string) string {
+	index := strings.Index(body, "/spinmint transfer")
+	if index < 0 {
+		return ""
+	}
+	args := strings.Fields(body[index+len("/spinmint transfer"):])
+	if len(args) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(args[0], "@")
+}
+
+// handleSpinmintSnapshot snapshots the test server of a PR and keeps the snapshot ID
+// on the spinmint record so the database can be restored while it runs. Only org members can take one.
+func (s *Server) handleSpinmintSnapshot(ctx context.Context, commenter string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	snapshotID, err := s.createSpinmintSnapshot(ctx, pr, spinmint.Region, spinmint.InstanceID)
+	if errors.Is(err, errSpinmintSnapshotNotSupported) {
+		msg = msgSpinmintSnapshotNotSupported
+		return nil
+	}
+	if err != nil {
+		msg = msgSpinmintSnapshotError
+		return err
+	}
+
+	// Only the latest snapshot is kept, and it is deleted with the spinmint.
+	previousID := spinmint.SnapshotID
+	spinmint.SnapshotID = snapshotID
+	if _, err = s.Store.Spinmint().Save(spinmint); err != nil {
+		mlog.Error("Unable to store the spinmint snapshot", mlog.String("snapshot", snapshotID), mlog.Err(err))
+	} else if previousID != "" {
+		s.deleteSpinmintSnapshot(ctx, spinmint.Region, previousID)
+	}
+
+	msg = fmt.Sprintf("Created snapshot `%s` of the test server.", snapshotID)
+	return nil
+}
+
+// handleSpinmintGetConfig posts the configuration of the test server of a PR,
+// with its secrets redacted, as a secret gist.
+func (s *Server) handleSpinmintGetConfig(ctx context.Context, commenter string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	content, err := s.getSpinmintConfig(s.getSpinmintURL(spinmint.Region, spinmint.GetSubdomain()))
+	if err != nil {
+		msg = msgSpinmintGetConfigError
+		return err
+	}
+
+	gist, _, err := s.GithubClient.Gists.Create(ctx, &github.Gist{
+		Description: github.String(fmt.Sprintf("Test server configuration for %s/%s#%d", pr.RepoOwner, pr.RepoName, pr.Number)),
+		Public:      github.Bool(false),
+		Files: map[github.GistFilename]github.GistFile{
+			"config.json": {Content: github.String(string(content))},
+		},
+	})
+	if err != nil {
+		msg = msgSpinmintGetConfigError
+		return err
+	}
+
+	msg = fmt.Sprintf("The configuration of the test server, with secrets redacted, is available at %s", gist.GetHTMLURL())
+	return nil
+}
+
+// getSpinmintConfig returns the configuration of the spinmint at siteURL as indented JSON, with secrets redacted.
+func (s *Server) getSpinmintConfig(siteURL string) ([]byte, error) {
+	client, err := s.newSpinmintAdminClient(siteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, resp := client.GetConfig()
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	// Sanitize expects every settings section to be set.
+	cfg.SetDefaults()
+	cfg.Sanitize()
+
+	return json.MarshalIndent(cfg, "", "  ")
+}
+
+// handleSpinmintCreate sets up a test server without the setup label, for contributors who can't add labels.
+// Org members get the setup label added instead, so the usual label flow takes over.
+func (s *Server) handleSpinmintCreate(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	if _, ok := s.Config.CommandPermissions["spinmint-create"]; !ok && commenter != pr.Username && !s.IsOrgMember(commenter) {
+		return s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msgCommenterPermission)
+	}
+
+	if s.isDraftSkipped(pr) {
+		return s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msgSpinmintCreateDraft)
+	}
+
+	var sizeLabels []string
+	if size := getSpinmintCreateSize(body); size != "" {
+		if _, ok := s.resolveSpinmintSize(size); !ok || s.Config.SpinmintSizeLabelPrefix == "" {
+			msg := fmt.Sprintf(msgSpinmintUnknownSize, size, strings.Join(s.getSpinmintSizeAliases(), "`, `"))
+			return s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg)
+		}
+		sizeLabels = append(sizeLabels, s.Config.SpinmintSizeLabelPrefix+size)
+	}
+
+	if s.IsOrgMember(commenter) && s.Config.SetupSpinmintTag != "" {
+		_, _, err := s.GithubClient.Issues.AddLabelsToIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, append([]string{s.Config.SetupSpinmintTag}, sizeLabels...))
+		if err == nil {
+			return nil
+		}
+		mlog.Warn("Unable to add the spinmint label, creating the spinmint without it", mlog.Int("pr", pr.Number), mlog.Err(err))
+	} else if len(sizeLabels) > 0 {
+		if _, _, err := s.GithubClient.Issues.AddLabelsToIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, sizeLabels); err != nil {
+			mlog.Warn("Unable to add the spinmint size label", mlog.Int("pr", pr.Number), mlog.Err(err))
+		}
+	}
+	pr.Labels = append(pr.Labels, sizeLabels...)
+
+	if s.isSpinmintApprovalPending(ctx, pr) {
+		return nil
+	}
+
+	if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, s.getSetupSpinmintMessage()); err != nil {
+		mlog.Warn("Error while commenting", mlog.Err(err))
+	}
+	s.runSpinmintFlow("spinmint_setup", pr, func() error { return s.waitForBuildAndSetupSpinmint(pr, false) })
+	return nil
+}
+
+// handleSpinmintForceUpgrade replaces the primary spinmint of the PR with an upgraded one right away.
+// It is an escape hatch for when the build detection of the normal flow gets in the way.
+func (s *Server) handleSpinmintForceUpgrade(ctx context.Context, commenter string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+	if s.isDraftSkipped(pr) {
+		msg = msgSpinmintUpgradeDraft
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+
+	msg = fmt.Sprintf(msgSpinmintForceUpgrade, shortSha(pr.Sha))
+	s.runSpinmintFlow("spinmint_upgrade", pr, func() error { return s.forceUpgradeSpinmint(pr, spinmint) })
+	return nil
+}
+
+// handleSpinmintReinit runs the initialization of the primary spinmint again on its running
+// instance, for when only the first attempt failed. Only org members can run it.
+func (s *Server) handleSpinmintReinit(ctx context.Context, commenter string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	msg = fmt.Sprintf(msgSpinmintReinit, spinmint.InstanceID)
+	s.runSpinmintFlow("spinmint_reinit", pr, func() error { return s.reinitSpinmint(pr, spinmint) })
+	return nil
+}
+
+// handleSpinmintSet changes a single allowed setting of a running spinmint, e.g. to flip a feature flag.
+func (s *Server) handleSpinmintSet(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	path, value := getSpinmintSetArgs(body)
+	if path == "" || value == "" {
+		msg = msgSpinmintSetUsage
+		return nil
+	}
+	if !s.isSpinmintConfigPathSettable(path) {
+		msg = fmt.Sprintf(msgSpinmintSetNotAllowed, path)
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	before, after, err := s.setSpinmintConfigValue(s.getSpinmintURL(spinmint.Region, spinmint.GetSubdomain()), path, value)
+	if err != nil {
+		msg = msgSpinmintSetError
+		return err
+	}
+
+	msg = fmt.Sprintf(msgSpinmintSetDone, path, before, after)
+	return nil
+}
+
+// getSpinmintSetArgs returns the setting path and the value given after "/spinmint set".
+func getSpinmintSetArgs(body string) (path, value string) {
+	index := strings.Index(body, "/spinmint set")
+	if index < 0 {
+		return "", ""
+	}
+
+	args := strings.Fields(body[index+len("/spinmint set"):])
+	if len(args) < 2 {
+		return "", ""
+	}
+	return args[0], strings.Join(args[1:], " ")
+}
+
+// getSpinmintCreateSize returns the size=<size> argument of /spinmint create, if any.
+func getSpinmintCreateSize(body string) string {
+	index := strings.Index(body, "/spinmint create")
+	if index < 0 {
+		return ""
+	}
+	for _, field := range strings.Fields(body[index+len("/spinmint create"):]) {
+		if strings.HasPrefix(field, "size=") {
+			return strings.TrimPrefix(field, "size=")
+		}
+	}
+	return ""
+}
+
+// isSpinmintConfigPathSettable is true if the path is one of the allowed settings, or within an allowed section.
+func (s *Server) isSpinmintConfigPathSettable(path string) bool {
+	for _, allowed := range s.Config.SpinmintSettableConfigPaths {
+		if path == allowed || strings.HasPrefix(path, allowed+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// setSpinmintConfigValue sets a single setting of the spinmint at siteURL and returns its
+// previous and new value as JSON. The value is taken as JSON, or as a string if it is not valid JSON.
+func (s *Server) setSpinmintConfigValue(siteURL, path, value string) (before, after string, err error) {
+	client, err := s.newSpinmintAdminClient(siteURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	cfg, resp := client.GetConfig()
+	if resp.Error != nil {
+		return "", "", resp.Error
+	}
+
+	settings, err := configToMap(cfg)
+	if err != nil {
+		return "", "", err
+	}
+	previous, err := getConfigMapValue(settings, path)
+	if err != nil {
+		return "", "", err
+	}
+
+	var newValue interface{}
+	if err = json.Unmarshal([]byte(value), &newValue); err != nil {
+		newValue = value
+	}
+	if err = setConfigMapValue(settings, path, newValue); err != nil {
+		return "", "", err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", "", err
+	}
+	var newCfg mmmodel.Config
+	if err = json.Unmarshal(data, &newCfg); err != nil {
+		return "", "", errors.Wrapf(err, "invalid value for %s", path)
+	}
+
+	updated, resp := client.UpdateConfig(&newCfg)
+	if resp.Error != nil {
+		return "", "", resp.Error
+	}
+
+	updatedSettings, err := configToMap(updated)
+	if err != nil {
+		return "", "", err
+	}
+	current, err := getConfigMapValue(updatedSettings, path)
+	if err != nil {
+		return "", "", err
+	}
+
+	beforeJSON, _ := json.Marshal(previous)
+	afterJSON, _ := json.Marshal(current)
+	return string(beforeJSON), string(afterJSON), nil
+}
+
+// applySpinmintServerConfig merges SpinmintServerConfig over the settings of the spinmint at siteURL.
+func (s *Server) applySpinmintServerConfig(siteURL string) error {
+	client, err := s.newSpinmintAdminClient(siteURL)
+	if err != nil {
+		return err
+	}
+
+	cfg, resp := client.GetConfig()
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	settings, err := configToMap(cfg)
+	if err != nil {
+		return err
+	}
+	mergeConfigMaps(settings, s.Config.SpinmintServerConfig)
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	var newCfg mmmodel.Config
+	if err = json.Unmarshal(data, &newCfg); err != nil {
+		return errors.Wrap(err, "invalid SpinmintServerConfig")
+	}
+
+	if _, resp = client.UpdateConfig(&newCfg); resp.Error != nil {
+		return resp.Error
+	}
+	return nil
+}
+
+func configToMap(cfg *mmmodel.Config) (map[string]interface{}, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]interface{}
+	if err = json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func getConfigMapValue(settings map[string]interface{}, path string) (interface{}, error) {
+	parts := strings.Split(path, ".")
+	section := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := section[part].(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("unknown setting %s", path)
+		}
+		section = next
+	}
+
+	value, ok := section[parts[len(parts)-1]]
+	if !ok {
+		return nil, errors.Errorf("unknown setting %s", path)
+	}
+	return value, nil
+}
+
+func setConfigMapValue(settings map[string]interface{}, path string, value interface{}) error {
+	parts := strings.Split(path, ".")
+	section := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := section[part].(map[string]interface{})
+		if !ok {
+			return errors.Errorf("unknown setting %s", path)
+		}
+		section = next
+	}
+	section[parts[len(parts)-1]] = value
+	return nil
+}
+
+// mergeConfigMaps sets the values of overrides in settings. Sections present in both are
+// merged setting by setting, so the settings overrides leaves out are kept.
+func mergeConfigMaps(settings, overrides map[string]interface{}) {
+	for key, value := range overrides {
+		section, isSection := value.(map[string]interface{})
+		current, hasSection := settings[key].(map[string]interface{})
+		if isSection && hasSection {
+			mergeConfigMaps(current, section)
+			continue
+		}
+		settings[key] = value
+	}
+}
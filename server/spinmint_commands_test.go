@@ -0,0 +1,462 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMMPing(t *testing.T) {
+	s := &Server{Config: &Config{}}
+
+	t.Run("reachable server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/v4/system/ping", r.URL.Path)
+			w.Header().Set("X-Version-Id", "5.30.0")
+			_, _ = w.Write([]byte(`{"status":"OK"}`))
+		}))
+		defer ts.Close()
+
+		status, version, err := s.checkMMPing(context.Background(), ts.URL+"/")
+		require.NoError(t, err)
+		require.Equal(t, "OK", status)
+		require.Equal(t, "5.30.0", version)
+	})
+
+	t.Run("unhealthy server", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		_, _, err := s.checkMMPing(context.Background(), ts.URL)
+		require.Error(t, err)
+	})
+
+	t.Run("hung server", func(t *testing.T) {
+		done := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		}))
+		defer ts.Close()
+		defer close(done)
+
+		slow := &Server{Config: &Config{SpinmintRequestTimeoutSeconds: 1}}
+		start := time.Now()
+		_, _, err := slow.checkMMPing(context.Background(), ts.URL)
+		require.Error(t, err)
+		require.Less(t, time.Since(start).Seconds(), 5.0)
+	})
+}
+
+func TestGetSpinmintTransferTarget(t *testing.T) {
+	assert.Equal(t, "someone", getSpinmintTransferTarget("/spinmint transfer @someone"))
+	assert.Equal(t, "someone", getSpinmintTransferTarget("please /spinmint transfer someone thanks"))
+	assert.Equal(t, "", getSpinmintTransferTarget("/spinmint transfer"))
+}
+
+func TestHandleSpinmintTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	us := mocks.NewMockUsersService(ctrl)
+	rs := mocks.NewMockRepositoriesService(ctrl)
+
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is, Users: us, Repositories: rs},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+	spinmint := &model.Spinmint{InstanceID: "i-123", RepoName: pr.RepoName, Number: pr.Number, CreatedBy: "owner"}
+	expectCollaborator := func(user string, isCollaborator bool) {
+		us.EXPECT().Get(ctx, user).Return(&github.User{Login: github.String(user)}, nil, nil)
+		rs.EXPECT().IsCollaborator(ctx, pr.RepoOwner, pr.RepoName, user).Return(isCollaborator, nil, nil)
+	}
+
+	expectComment := func(msg string) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+	}
+
+	t.Run("missing user", func(t *testing.T) {
+		expectComment(msgSpinmintTransferUsage)
+		require.NoError(t, s.handleSpinmintTransfer(ctx, "owner", "/spinmint transfer", pr))
+	})
+
+	t.Run("no spinmint", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+		expectComment(msgSpinmintNotFound)
+		require.NoError(t, s.handleSpinmintTransfer(ctx, "owner", "/spinmint transfer @other", pr))
+	})
+
+	t.Run("not the owner", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		expectComment(msgSpinmintTransferDenied)
+		require.NoError(t, s.handleSpinmintTransfer(ctx, "someone", "/spinmint transfer @other", pr))
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		notFound := &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		us.EXPECT().Get(ctx, "nobody").Return(nil, notFound, errors.New("404 Not Found"))
+		expectComment(fmt.Sprintf(msgSpinmintTransferNoUser, "nobody"))
+		require.NoError(t, s.handleSpinmintTransfer(ctx, "owner", "/spinmint transfer @nobody", pr))
+	})
+
+	t.Run("not a collaborator", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		expectCollaborator("outsider", false)
+		expectComment(fmt.Sprintf(msgSpinmintTransferNoRepo, "outsider"))
+		require.NoError(t, s.handleSpinmintTransfer(ctx, "owner", "/spinmint transfer @outsider", pr))
+	})
+
+	t.Run("github error", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		us.EXPECT().Get(ctx, "other").Return(nil, nil, errors.New("502 Bad Gateway"))
+		require.Error(t, s.handleSpinmintTransfer(ctx, "owner", "/spinmint transfer @other", pr))
+	})
+
+	t.Run("owner transfers", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		expectCollaborator("other", true)
+		sms.EXPECT().UpdateCreatedBy("i-123", "other").Return(nil)
+		expectComment("The test server of this PR is now owned by @other.")
+		require.NoError(t, s.handleSpinmintTransfer(ctx, "owner", "/spinmint transfer @other", pr))
+	})
+
+	t.Run("maintainer transfers", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		expectCollaborator("other", true)
+		sms.EXPECT().UpdateCreatedBy("i-123", "other").Return(nil)
+		expectComment("The test server of this PR is now owned by @other.")
+		require.NoError(t, s.handleSpinmintTransfer(ctx, "maintainer", "/spinmint transfer @other", pr))
+	})
+}
+
+func TestHandleSpinmintSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintMaintainerOnly)}).Return(nil, nil, nil)
+	require.NoError(t, s.handleSpinmintSnapshot(ctx, "someone", pr))
+
+	sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintNotFound)}).Return(nil, nil, nil)
+	require.NoError(t, s.handleSpinmintSnapshot(ctx, "maintainer", pr))
+}
+
+func TestGetSpinmintConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/users/login":
+			w.Header().Set("Token", "token")
+			_, _ = w.Write([]byte(`{"id":"user-id"}`))
+		case "/api/v4/config":
+			require.Equal(t, "BEARER token", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"ServiceSettings":{"SiteURL":"http://test"},"SqlSettings":{"DataSource":"postgres://secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	s := &Server{Config: &Config{}}
+	content, err := s.getSpinmintConfig(ts.URL)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "http://test")
+	assert.NotContains(t, string(content), "postgres://secret")
+}
+
+func TestHandleSpinmintGetConfigPermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintMaintainerOnly)}).Return(nil, nil, nil)
+	require.NoError(t, s.handleSpinmintGetConfig(ctx, "someone", pr))
+
+	sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintNotFound)}).Return(nil, nil, nil)
+	require.NoError(t, s.handleSpinmintGetConfig(ctx, "maintainer", pr))
+}
+
+func TestHandleSpinmintCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+
+	s := &Server{
+		Config:       &Config{SetupSpinmintTag: "Setup Test Server", SkipDraftPRBuilds: true},
+		GithubClient: &GithubClient{Issues: is},
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123, Username: "contributor"}
+
+	t.Run("random user", func(t *testing.T) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgCommenterPermission)}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintCreate(ctx, "someone", "/spinmint create", pr))
+	})
+
+	t.Run("draft PR", func(t *testing.T) {
+		draft := *pr
+		draft.Draft = true
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintCreateDraft)}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintCreate(ctx, "contributor", "/spinmint create", &draft))
+	})
+
+	t.Run("org member adds the label", func(t *testing.T) {
+		is.EXPECT().AddLabelsToIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, []string{"Setup Test Server"}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintCreate(ctx, "maintainer", "/spinmint create", pr))
+	})
+
+	s.Config.SpinmintSizeAliases = map[string]string{"small": "t3.medium", "large": "t3.xlarge"}
+	s.Config.SpinmintSizeLabelPrefix = "Spinmint Size/"
+
+	t.Run("org member picks a size", func(t *testing.T) {
+		is.EXPECT().AddLabelsToIssue(ctx, pr.RepoOwner, pr.RepoName, pr.Number, []string{"Setup Test Server", "Spinmint Size/large"}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintCreate(ctx, "maintainer", "/spinmint create size=large", pr))
+	})
+
+	t.Run("unknown size", func(t *testing.T) {
+		msg := "Unknown test server size `huge`. The available sizes are: `large`, `small`."
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintCreate(ctx, "maintainer", "/spinmint create size=huge", pr))
+	})
+}
+
+func TestGetSpinmintCreateSize(t *testing.T) {
+	assert.Equal(t, "", getSpinmintCreateSize("/spinmint create"))
+	assert.Equal(t, "large", getSpinmintCreateSize("/spinmint create size=large"))
+	assert.Equal(t, "", getSpinmintCreateSize("size=large"))
+}
+
+func TestHandleSpinmintForceUpgradePermissions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintMaintainerOnly)}).Return(nil, nil, nil)
+	require.NoError(t, s.handleSpinmintForceUpgrade(ctx, "someone", pr))
+
+	sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, errors.New("store error"))
+	require.Error(t, s.handleSpinmintForceUpgrade(ctx, "maintainer", pr))
+
+	s.Config.SkipDraftPRBuilds = true
+	draft := *pr
+	draft.Draft = true
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintUpgradeDraft)}).Return(nil, nil, nil)
+	require.NoError(t, s.handleSpinmintForceUpgrade(ctx, "maintainer", &draft))
+}
+
+func TestHandleSpinmintReinit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+	expectComment := func(msg string) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+	}
+
+	expectComment(msgSpinmintMaintainerOnly)
+	require.NoError(t, s.handleSpinmintReinit(ctx, "someone", pr))
+
+	sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+	expectComment(msgSpinmintNotFound)
+	require.NoError(t, s.handleSpinmintReinit(ctx, "maintainer", pr))
+
+	sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, errors.New("store error"))
+	require.Error(t, s.handleSpinmintReinit(ctx, "maintainer", pr))
+}
+
+func TestGetSpinmintSetArgs(t *testing.T) {
+	path, value := getSpinmintSetArgs("/spinmint set FeatureFlags.MyFeature true")
+	assert.Equal(t, "FeatureFlags.MyFeature", path)
+	assert.Equal(t, "true", value)
+
+	path, value = getSpinmintSetArgs("/spinmint set TeamSettings.SiteName My test server")
+	assert.Equal(t, "TeamSettings.SiteName", path)
+	assert.Equal(t, "My test server", value)
+
+	path, value = getSpinmintSetArgs("/spinmint set FeatureFlags.MyFeature")
+	assert.Equal(t, "", path)
+	assert.Equal(t, "", value)
+}
+
+func TestIsSpinmintConfigPathSettable(t *testing.T) {
+	s := &Server{Config: &Config{SpinmintSettableConfigPaths: []string{"FeatureFlags", "ServiceSettings.EnableDeveloper"}}}
+
+	assert.True(t, s.isSpinmintConfigPathSettable("FeatureFlags.MyFeature"))
+	assert.True(t, s.isSpinmintConfigPathSettable("ServiceSettings.EnableDeveloper"))
+	assert.False(t, s.isSpinmintConfigPathSettable("ServiceSettings.SiteURL"))
+	assert.False(t, s.isSpinmintConfigPathSettable("FeatureFlagsExtra.MyFeature"))
+}
+
+func TestSetSpinmintConfigValue(t *testing.T) {
+	var updated map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v4/users/login":
+			w.Header().Set("Token", "token")
+			_, _ = w.Write([]byte(`{"id":"user-id"}`))
+		case r.URL.Path == "/api/v4/config" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"ServiceSettings":{"EnableDeveloper":false}}`))
+		case r.URL.Path == "/api/v4/config" && r.Method == http.MethodPut:
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &updated))
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	s := &Server{Config: &Config{}}
+	before, after, err := s.setSpinmintConfigValue(ts.URL, "ServiceSettings.EnableDeveloper", "true")
+	require.NoError(t, err)
+	assert.Equal(t, "false", before)
+	assert.Equal(t, "true", after)
+	assert.Equal(t, true, updated["ServiceSettings"].(map[string]interface{})["EnableDeveloper"])
+
+	_, _, err = s.setSpinmintConfigValue(ts.URL, "ServiceSettings.Unknown", "true")
+	require.Error(t, err)
+
+	_, _, err = s.setSpinmintConfigValue(ts.URL, "ServiceSettings.EnableDeveloper", "not-a-bool")
+	require.Error(t, err)
+}
+
+func TestMergeConfigMaps(t *testing.T) {
+	settings := map[string]interface{}{
+		"ServiceSettings": map[string]interface{}{"EnableDeveloper": true, "EnableTesting": false},
+		"EmailSettings":   map[string]interface{}{"SMTPPort": "465", "FeedbackEmail": "feedback@example.com"},
+		"FeatureFlags":    map[string]interface{}{"MyFeature": "off"},
+	}
+	mergeConfigMaps(settings, map[string]interface{}{
+		"ServiceSettings": map[string]interface{}{"EnableTesting": true},
+		"EmailSettings":   map[string]interface{}{"SMTPPort": "587"},
+		"LdapSettings":    map[string]interface{}{"Enable": true, "LdapServer": "ldap.example.com"},
+		"FeatureFlags":    nil,
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"ServiceSettings": map[string]interface{}{"EnableDeveloper": true, "EnableTesting": true},
+		"EmailSettings":   map[string]interface{}{"SMTPPort": "587", "FeedbackEmail": "feedback@example.com"},
+		"LdapSettings":    map[string]interface{}{"Enable": true, "LdapServer": "ldap.example.com"},
+		"FeatureFlags":    nil,
+	}, settings)
+}
+
+func TestApplySpinmintServerConfig(t *testing.T) {
+	var updated map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v4/users/login":
+			w.Header().Set("Token", "token")
+			_, _ = w.Write([]byte(`{"id":"user-id"}`))
+		case r.URL.Path == "/api/v4/config" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"ServiceSettings":{"EnableDeveloper":true,"EnableTesting":false},"EmailSettings":{"SMTPPort":"465"}}`))
+		case r.URL.Path == "/api/v4/config" && r.Method == http.MethodPut:
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &updated))
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	s := &Server{Config: &Config{SpinmintServerConfig: map[string]interface{}{
+		"ServiceSettings": map[string]interface{}{"EnableTesting": true},
+	}}}
+	require.NoError(t, s.applySpinmintServerConfig(ts.URL))
+	serviceSettings := updated["ServiceSettings"].(map[string]interface{})
+	assert.Equal(t, true, serviceSettings["EnableTesting"])
+	assert.Equal(t, true, serviceSettings["EnableDeveloper"])
+	assert.Equal(t, "465", updated["EmailSettings"].(map[string]interface{})["SMTPPort"])
+
+	s.Config.SpinmintServerConfig = map[string]interface{}{
+		"ServiceSettings": map[string]interface{}{"EnableTesting": "not-a-bool"},
+	}
+	require.Error(t, s.applySpinmintServerConfig(ts.URL))
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+const (
+	deploymentStatePending  = "pending"
+	deploymentStateSuccess  = "success"
+	deploymentStateFailure  = "failure"
+	deploymentStateInactive = "inactive"
+)
+
+// createSpinmintDeployment creates a GitHub deployment for the commit of the PR whose
+// spinmint is being set up, so it shows in the deployments UI of the repository.
+func (s *Server) createSpinmintDeployment(ctx context.Context, pr *model.PullRequest) {
+	if s.Config.SpinmintDeploymentEnvironment == "" {
+		return
+	}
+
+	deployment, err := s.newSpinmintDeployment(ctx, pr)
+	if err != nil {
+		mlog.Warn("Unable to create the spinmint deployment", mlog.Int("pr", pr.Number), mlog.Err(err))
+		return
+	}
+	s.createSpinmintDeploymentStatus(ctx, pr, deployment.GetID(), deploymentStatePending, "")
+}
+
+// setSpinmintDeploymentStatus updates the latest GitHub deployment of the PR environment.
+// The deployments are listed by environment only, so a spinmint destroyed after a push still
+// gets the deployment of its commit marked inactive. Any other state goes to a deployment of
+// the current commit, which is created if the environment has none for it yet.
+func (s *Server) setSpinmintDeploymentStatus(ctx context.Context, pr *model.PullRequest, state, environmentURL string) {
+	if s.Config.SpinmintDeploymentEnvironment == "" {
+		return
+	}
+
+	deployments, _, err := s.GithubClient.Repositories.ListDeployments(ctx, pr.RepoOwner, pr.RepoName, &github.DeploymentsListOptions{
+		Environment: s.getSpinmintDeploymentEnvironment(pr),
+	})
+	if err != nil {
+		mlog.Warn("Unable to list the spinmint deployments", mlog.Int("pr", pr.Number), mlog.Err(err))
+		return
+	}
+
+	var deployment *github.Deployment
+	switch {
+	case len(deployments) > 0 && (state == deploymentStateInactive || deployments[0].GetSHA() == pr.Sha):
+		deployment = deployments[0]
+	case state == deploymentStateInactive:
+		return
+	default:
+		if deployment, err = s.newSpinmintDeployment(ctx, pr); err != nil {
+			mlog.Warn("Unable to create the spinmint deployment", mlog.Int("pr", pr.Number), mlog.Err(err))
+			return
+		}
+	}
+	s.createSpinmintDeploymentStatus(ctx, pr, deployment.GetID(), state, environmentURL)
+}
+
+// getSpinmintDeploymentEnvironment returns the deployment environment of the PR.
+// Every PR gets its own, so their spinmints don't mark each other inactive.
+func (s *Server) getSpinmintDeploymentEnvironment(pr *model.PullRequest) string {
+	return fmt.Sprintf("%s-pr-%d", s.Config.SpinmintDeploymentEnvironment, pr.Number)
+}
+
+func (s *Server) newSpinmintDeployment(ctx context.Context, pr *model.PullRequest) (*github.Deployment, error) {
+	deployment, _, err := s.GithubClient.Repositories.CreateDeployment(ctx, pr.RepoOwner, pr.RepoName, &github.DeploymentRequest{
+		Ref:         github.String(pr.Sha),
+		Environment: github.String(s.getSpinmintDeploymentEnvironment(pr)),
+		Description: github.String(fmt.Sprintf("Test server for PR #%d", pr.Number)),
+		AutoMerge:   github.Bool(false),
+		// The spinmint is set up from the build, so the other checks of the commit don't matter.
+		RequiredContexts:     &[]string{},
+		TransientEnvironment: github.Bool(true),
+	})
+	return deployment, err
+}
+
+func (s *Server) createSpinmintDeploymentStatus(ctx context.Context, pr *model.PullRequest, deploymentID int64, state, environmentURL string) {
+	request := &github.DeploymentStatusRequest{State: github.String(state)}
+	if environmentURL != "" {
+		request.EnvironmentURL = github.String(environmentURL)
+	}
+	if _, _, err := s.GithubClient.Repositories.CreateDeploymentStatus(ctx, pr.RepoOwner, pr.RepoName, deploymentID, request); err != nil {
+		mlog.Warn("Unable to update the spinmint deployment", mlog.Int("pr", pr.Number), mlog.String("state", state), mlog.Err(err))
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+)
+
+func TestSpinmintDeployments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Repositories: rs},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123, Sha: "abcdef"}
+
+	t.Run("disabled", func(t *testing.T) {
+		s.createSpinmintDeployment(ctx, pr)
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateSuccess, "https://i-123.spinmint.com")
+	})
+
+	s.Config.SpinmintDeploymentEnvironment = "spinmint"
+	listOptions := &github.DeploymentsListOptions{Environment: "spinmint-pr-123"}
+
+	t.Run("provisioning creates a pending deployment", func(t *testing.T) {
+		rs.EXPECT().CreateDeployment(ctx, "mattertest", "mattermost-server", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, request *github.DeploymentRequest) (*github.Deployment, *github.Response, error) {
+				if request.GetRef() != "abcdef" || request.GetEnvironment() != "spinmint-pr-123" {
+					t.Errorf("unexpected deployment request %+v", request)
+				}
+				return &github.Deployment{ID: github.Int64(1)}, nil, nil
+			})
+		rs.EXPECT().CreateDeploymentStatus(ctx, "mattertest", "mattermost-server", int64(1), &github.DeploymentStatusRequest{State: github.String("pending")}).Return(nil, nil, nil)
+
+		s.createSpinmintDeployment(ctx, pr)
+	})
+
+	t.Run("ready updates the deployment", func(t *testing.T) {
+		rs.EXPECT().ListDeployments(ctx, "mattertest", "mattermost-server", listOptions).Return([]*github.Deployment{{ID: github.Int64(1), SHA: github.String("abcdef")}}, nil, nil)
+		rs.EXPECT().CreateDeploymentStatus(ctx, "mattertest", "mattermost-server", int64(1), &github.DeploymentStatusRequest{
+			State:          github.String("success"),
+			EnvironmentURL: github.String("https://i-123.spinmint.com"),
+		}).Return(nil, nil, nil)
+
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateSuccess, "https://i-123.spinmint.com")
+	})
+
+	t.Run("ready after a push creates a deployment of the new commit", func(t *testing.T) {
+		rs.EXPECT().ListDeployments(ctx, "mattertest", "mattermost-server", listOptions).Return([]*github.Deployment{{ID: github.Int64(1), SHA: github.String("012345")}}, nil, nil)
+		rs.EXPECT().CreateDeployment(ctx, "mattertest", "mattermost-server", gomock.Any()).Return(&github.Deployment{ID: github.Int64(2)}, nil, nil)
+		rs.EXPECT().CreateDeploymentStatus(ctx, "mattertest", "mattermost-server", int64(2), &github.DeploymentStatusRequest{
+			State:          github.String("success"),
+			EnvironmentURL: github.String("https://i-123.spinmint.com"),
+		}).Return(nil, nil, nil)
+
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateSuccess, "https://i-123.spinmint.com")
+	})
+
+	t.Run("destroy after a push marks the previous deployment inactive", func(t *testing.T) {
+		rs.EXPECT().ListDeployments(ctx, "mattertest", "mattermost-server", listOptions).Return([]*github.Deployment{{ID: github.Int64(1), SHA: github.String("012345")}}, nil, nil)
+		rs.EXPECT().CreateDeploymentStatus(ctx, "mattertest", "mattermost-server", int64(1), &github.DeploymentStatusRequest{State: github.String("inactive")}).Return(nil, nil, nil)
+
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateInactive, "")
+	})
+
+	t.Run("no deployment to mark inactive", func(t *testing.T) {
+		rs.EXPECT().ListDeployments(ctx, "mattertest", "mattermost-server", listOptions).Return(nil, nil, nil)
+
+		s.setSpinmintDeploymentStatus(ctx, pr, deploymentStateInactive, "")
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+)
+
+const (
+	spinmintEventCreated   = "created"
+	spinmintEventUpgraded  = "upgraded"
+	spinmintEventDestroyed = "destroyed"
+	spinmintEventFailed    = "failed"
+	spinmintEventReaped    = "reaped"
+)
+
+// SpinmintEvent is published to the configured events sink on spinmint lifecycle changes.
+type SpinmintEvent struct {
+	Type       string `json:"type"`
+	RepoOwner  string `json:"repo_owner"`
+	RepoName   string `json:"repo_name"`
+	PRNumber   int    `json:"pr_number"`
+	InstanceID string `json:"instance_id"`
+	Size       string `json:"size"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// emitSpinmintEvent adds a spinmint lifecycle event to the action log of the PR and
+// publishes it in the background. labels are the ones the spinmint was created with, which give its size.
+// Failures are only logged so they never block the spinmint flow.
+func (s *Server) emitSpinmintEvent(eventType string, pr *model.PullRequest, instanceID string, labels []string) {
+	s.logSpinmintAction(eventType, pr, instanceID)
+	if s.Metrics != nil {
+		s.Metrics.IncreaseSpinmintEvents(eventType, pr.RepoName, s.getSpinmintInstanceType(labels))
+	}
+
+	if s.Config.SpinmintEventsURL == "" {
+		return
+	}
+
+	event := &SpinmintEvent{
+		Type:       eventType,
+		RepoOwner:  pr.RepoOwner,
+		RepoName:   pr.RepoName,
+		PRNumber:   pr.Number,
+		InstanceID: instanceID,
+		Size:       s.getSpinmintInstanceType(labels),
+		Timestamp:  model.NowUTC().Unix(),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout*time.Second)
+		defer cancel()
+		if err := s.publishSpinmintEvent(ctx, event); err != nil {
+			mlog.Warn("Unable to publish spinmint event", mlog.String("type", event.Type), mlog.Int("pr", event.PRNumber), mlog.Err(err))
+		}
+	}()
+}
+
+func (s *Server) publishSpinmintEvent(ctx context.Context, event *SpinmintEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Config.SpinmintEventsURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Config.SpinmintEventsAuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Config.SpinmintEventsAuthToken)
+	}
+
+	r, err := s.doSpinmintRequest(s.spinmintEventsHTTPClient(), req)
+	if err != nil {
+		return err
+	}
+	defer closeBody(r)
+
+	if loc := r.Header.Get("Location"); loc != "" && r.StatusCode >= 300 && r.StatusCode < 400 {
+		return errors.Errorf("events sink redirected to %s with http status %s, SpinmintEventsURL should point to the final URL", loc, r.Status)
+	}
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		return errors.Errorf("events sink returned http status %s", r.Status)
+	}
+	return nil
+}
+
+// spinmintEventsHTTPClient returns the client for the events sink, presenting the configured
+// client certificate if any. Redirects are not followed: a redirected POST is replayed as a GET
+// without the event.
+func (s *Server) spinmintEventsHTTPClient() *http.Client {
+	client := s.spinmintHTTPClient()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	if s.Config.SpinmintEventsTLSCertFile == "" && s.Config.SpinmintEventsTLSKeyFile == "" {
+		return client
+	}
+
+	s.spinmintEventsTransportOnce.Do(func() {
+		s.spinmintEventsTransport = newSpinmintTransport()
+		s.spinmintEventsTransport.TLSClientConfig = &tls.Config{
+			GetClientCertificate: s.getSpinmintEventsClientCertificate,
+			MinVersion:           tls.VersionTLS12,
+		}
+	})
+	client.Transport = s.spinmintEventsTransport
+	return client
+}
+
+// getSpinmintEventsClientCertificate loads the client certificate for the events sink.
+// The pair is loaded on every handshake so renewed certificates are picked up.
+func (s *Server) getSpinmintEventsClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(s.Config.SpinmintEventsTLSCertFile, s.Config.SpinmintEventsTLSKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load the events sink client certificate")
+	}
+	return &cert, nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishSpinmintEvent(t *testing.T) {
+	var received SpinmintEvent
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer ts.Close()
+
+	s := &Server{Config: &Config{SpinmintEventsURL: ts.URL}}
+	event := &SpinmintEvent{
+		Type:       spinmintEventCreated,
+		RepoOwner:  "mattertest",
+		RepoName:   "mattermost-server",
+		PRNumber:   42,
+		InstanceID: "i-1234",
+		Size:       "t2.medium",
+		Timestamp:  1600000000,
+	}
+
+	require.NoError(t, s.publishSpinmintEvent(context.Background(), event))
+	require.Equal(t, *event, received)
+
+	s.Config.SpinmintEventsURL = ts.URL + "/missing"
+	ts.Config.Handler = http.NotFoundHandler()
+	require.Error(t, s.publishSpinmintEvent(context.Background(), event))
+}
+
+func TestPublishSpinmintEventAuthorization(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	s := &Server{Config: &Config{SpinmintEventsURL: ts.URL}}
+	event := &SpinmintEvent{Type: spinmintEventCreated, PRNumber: 42}
+
+	t.Run("no token", func(t *testing.T) {
+		require.NoError(t, s.publishSpinmintEvent(context.Background(), event))
+		require.Empty(t, authorization)
+	})
+
+	t.Run("bearer token", func(t *testing.T) {
+		s.Config.SpinmintEventsAuthToken = "secret"
+		require.NoError(t, s.publishSpinmintEvent(context.Background(), event))
+		require.Equal(t, "Bearer secret", authorization)
+	})
+}
+
+func TestPublishSpinmintEventRedirect(t *testing.T) {
+	var followed bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	ts := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusMovedPermanently))
+	defer ts.Close()
+
+	s := &Server{Config: &Config{SpinmintEventsURL: ts.URL}}
+	err := s.publishSpinmintEvent(context.Background(), &SpinmintEvent{Type: spinmintEventCreated, PRNumber: 42})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "redirected to "+target.URL)
+	require.False(t, followed)
+}
+
+func TestSpinmintEventsHTTPClient(t *testing.T) {
+	s := &Server{Config: &Config{}}
+
+	t.Run("no client certificate", func(t *testing.T) {
+		client := s.spinmintEventsHTTPClient()
+		require.Equal(t, spinmintTransport, client.Transport)
+	})
+
+	t.Run("missing client certificate", func(t *testing.T) {
+		s.Config.SpinmintEventsTLSCertFile = "missing.crt"
+		s.Config.SpinmintEventsTLSKeyFile = "missing.key"
+		_, err := s.getSpinmintEventsClientCertificate(nil)
+		require.Error(t, err)
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "spinmint-events")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "mattermod"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(t, err)
+		keyDer, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		s.Config.SpinmintEventsTLSCertFile = filepath.Join(dir, "client.crt")
+		s.Config.SpinmintEventsTLSKeyFile = filepath.Join(dir, "client.key")
+		require.NoError(t, ioutil.WriteFile(s.Config.SpinmintEventsTLSCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+		require.NoError(t, ioutil.WriteFile(s.Config.SpinmintEventsTLSKeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+		client := s.spinmintEventsHTTPClient()
+		transport, ok := client.Transport.(*http.Transport)
+		require.True(t, ok)
+		require.NotEqual(t, spinmintTransport, transport)
+		require.Equal(t, transport, s.spinmintEventsHTTPClient().Transport, "the transport is shared by the events")
+
+		cert, err := transport.TLSClientConfig.GetClientCertificate(nil)
+		require.NoError(t, err)
+		require.Len(t, cert.Certificate, 1)
+	})
+}
@@ -0,0 +1,970 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEC2 is an in-memory EC2 for the spinmint flows. New instances stay pending
+// for runningAfterPolls describe calls, then move to finalState.
+type fakeEC2 struct {
+	ec2iface.EC2API
+
+	mu                sync.Mutex
+	nextID            int
+	runningAfterPolls int
+	finalState        string
+	instances         map[string]*fakeInstance
+	deletedSnapshots  []string
+	terminateErr      error
+	waiterAttempts    int
+}
+
+type fakeInstance struct {
+	state    string
+	polls    int
+	tags     map[string]string
+	userData string
+}
+
+func newFakeEC2(runningAfterPolls int, finalState string) *fakeEC2 {
+	return &fakeEC2{
+		runningAfterPolls: runningAfterPolls,
+		finalState:        finalState,
+		instances:         make(map[string]*fakeInstance),
+	}
+}
+
+func (f *fakeEC2) RunInstancesWithContext(_ aws.Context, input *ec2.RunInstancesInput, _ ...request.Option) (*ec2.Reservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	userData, err := base64.StdEncoding.DecodeString(aws.StringValue(input.UserData))
+	if err != nil {
+		return nil, err
+	}
+	f.nextID++
+	id := fmt.Sprintf("i-fake%d", f.nextID)
+	f.instances[id] = &fakeInstance{state: ec2.InstanceStateNamePending, tags: make(map[string]string), userData: string(userData)}
+	return &ec2.Reservation{Instances: []*ec2.Instance{f.describe(id)}}, nil
+}
+
+func (f *fakeEC2) CreateTagsWithContext(_ aws.Context, input *ec2.CreateTagsInput, _ ...request.Option) (*ec2.CreateTagsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, id := range input.Resources {
+		instance, ok := f.instances[aws.StringValue(id)]
+		if !ok {
+			return nil, errors.Errorf("instance %s not found", aws.StringValue(id))
+		}
+		for _, tag := range input.Tags {
+			instance.tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (f *fakeEC2) DescribeInstancesWithContext(_ aws.Context, input *ec2.DescribeInstancesInput, _ ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Tag filter lookups never find anything, so every flow creates a fresh instance.
+	if len(input.InstanceIds) == 0 {
+		return &ec2.DescribeInstancesOutput{}, nil
+	}
+
+	reservation := &ec2.Reservation{}
+	for _, id := range input.InstanceIds {
+		instance, ok := f.instances[aws.StringValue(id)]
+		if !ok {
+			return nil, errors.Errorf("instance %s not found", aws.StringValue(id))
+		}
+		instance.polls++
+		if instance.state == ec2.InstanceStateNamePending && instance.polls >= f.runningAfterPolls {
+			instance.state = f.finalState
+		}
+		reservation.Instances = append(reservation.Instances, f.describe(aws.StringValue(id)))
+	}
+	return &ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{reservation}}, nil
+}
+
+func (f *fakeEC2) WaitUntilInstanceRunningWithContext(ctx aws.Context, input *ec2.DescribeInstancesInput, opts ...request.WaiterOption) error {
+	w := &request.Waiter{}
+	w.ApplyOptions(opts...)
+	f.mu.Lock()
+	f.waiterAttempts = w.MaxAttempts
+	f.mu.Unlock()
+
+	for {
+		resp, err := f.DescribeInstancesWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		switch state := aws.StringValue(resp.Reservations[0].Instances[0].State.Name); state {
+		case ec2.InstanceStateNameRunning:
+			return nil
+		case ec2.InstanceStateNamePending:
+		default:
+			return errors.Errorf("instance entered state %s", state)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (f *fakeEC2) TerminateInstancesWithContext(_ aws.Context, input *ec2.TerminateInstancesInput, _ ...request.Option) (*ec2.TerminateInstancesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.terminateErr != nil {
+		return nil, f.terminateErr
+	}
+	for _, id := range input.InstanceIds {
+		instance, ok := f.instances[aws.StringValue(id)]
+		if !ok {
+			return nil, errors.Errorf("instance %s not found", aws.StringValue(id))
+		}
+		instance.state = ec2.InstanceStateNameTerminated
+	}
+	return &ec2.TerminateInstancesOutput{}, nil
+}
+
+func (f *fakeEC2) DeleteSnapshotWithContext(_ aws.Context, input *ec2.DeleteSnapshotInput, _ ...request.Option) (*ec2.DeleteSnapshotOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.deletedSnapshots = append(f.deletedSnapshots, aws.StringValue(input.SnapshotId))
+	return &ec2.DeleteSnapshotOutput{}, nil
+}
+
+func (f *fakeEC2) state(id string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.instances[id].state
+}
+
+func (f *fakeEC2) describe(id string) *ec2.Instance {
+	instance := &ec2.Instance{
+		InstanceId: aws.String(id),
+		State:      &ec2.InstanceState{Name: aws.String(f.instances[id].state)},
+	}
+	// Like EC2, terminated instances have no IP addresses.
+	if f.instances[id].state != ec2.InstanceStateNameTerminated {
+		instance.PublicIpAddress = aws.String("203.0.113.10")
+		instance.PrivateIpAddress = aws.String("10.0.0.10")
+	}
+	return instance
+}
+
+// fakeRoute53 keeps the A records of the spinmint subdomains.
+type fakeRoute53 struct {
+	route53iface.Route53API
+
+	mu      sync.Mutex
+	records map[string]string
+}
+
+func (f *fakeRoute53) ChangeResourceRecordSetsWithContext(_ aws.Context, input *route53.ChangeResourceRecordSetsInput, _ ...request.Option) (*route53.ChangeResourceRecordSetsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, change := range input.ChangeBatch.Changes {
+		name := aws.StringValue(change.ResourceRecordSet.Name)
+		switch aws.StringValue(change.Action) {
+		case "CREATE", "UPSERT":
+			f.records[name] = aws.StringValue(change.ResourceRecordSet.ResourceRecords[0].Value)
+		case "DELETE":
+			if _, ok := f.records[name]; !ok {
+				return nil, errors.Errorf("record %s not found", name)
+			}
+			delete(f.records, name)
+		}
+	}
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func TestSpinmintFlow(t *testing.T) {
+	defer func(delay time.Duration) { spinmintTagDelay = delay }(spinmintTagDelay)
+	spinmintTagDelay = 0
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil).AnyTimes()
+	sms.EXPECT().UpdateState(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	newServer := func(fake *fakeEC2) (*Server, *fakeRoute53) {
+		r53 := &fakeRoute53{records: make(map[string]string)}
+		return &Server{
+			Config:        &Config{AWSDnsSuffix: "spinmint.test"},
+			Store:         ss,
+			EC2Client:     fake,
+			Route53Client: r53,
+		}, r53
+	}
+
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, Ref: "feature", Sha: "abcdef"}
+	// The setup script is looked up under config/, relative to the working directory.
+	repo := &Repository{InstanceSetupScript: "../../config/instance-setup.sh"}
+	ctx := context.Background()
+
+	t.Run("instance comes up and is destroyed", func(t *testing.T) {
+		fake := newFakeEC2(3, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		assert.Equal(t, "PR-123", fake.instances[id].tags["PRNumber"])
+
+		require.NoError(t, s.waitForSpinmintInstance(ctx, "", id, s.getSpinmintCreationTimeout(repo)))
+		assert.Equal(t, ec2.InstanceStateNameRunning, fake.state(id))
+		// The default creation timeout is longer than the 40 attempts of the AWS waiter.
+		assert.Equal(t, 61, fake.waiterAttempts)
+
+		publicIP, _ := s.getIPsForInstance(ctx, "", id)
+		require.NoError(t, s.updateRoute53Subdomain(ctx, "", id, publicIP, "CREATE"))
+		assert.Equal(t, "203.0.113.10", r53.records[id+".spinmint.test"])
+
+		sms.EXPECT().Archive(id, "", gomock.Any()).Return(nil)
+		sms.EXPECT().Delete(id).Return(nil)
+		s.destroySpinmint(pr, &model.Spinmint{InstanceID: id, SnapshotID: "snap-123"})
+		assert.Equal(t, ec2.InstanceStateNameTerminated, fake.state(id))
+		assert.Empty(t, r53.records)
+		assert.Equal(t, []string{"snap-123"}, fake.deletedSnapshots)
+	})
+
+	t.Run("instance fails to come up", func(t *testing.T) {
+		fake := newFakeEC2(2, ec2.InstanceStateNameTerminated)
+		s, _ := newServer(fake)
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+
+		err = s.waitForSpinmintInstance(ctx, "", aws.StringValue(instance.InstanceId), s.getSpinmintCreationTimeout(repo))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ec2.InstanceStateNameTerminated)
+	})
+
+	t.Run("stored spinmint keeps its running instance", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK"
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		r53.records[id+".spinmint.test"] = "203.0.113.10"
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(&model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number}, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).Return(nil, nil, nil)
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, "", nil))
+		assert.Len(t, fake.instances, 1)
+		assert.Equal(t, "203.0.113.10", r53.records[id+".spinmint.test"])
+	})
+
+	t.Run("stored spinmint keeps its renamed subdomain", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK"
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		r53.records["my-feature.spinmint.test"] = "203.0.113.10"
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(&model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number, Subdomain: "my-feature"}, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+				assert.Contains(t, comment.GetBody(), "Test server: http://my-feature.spinmint.test")
+				return nil, nil, nil
+			})
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, "", nil))
+		assert.Equal(t, map[string]string{"my-feature.spinmint.test": "203.0.113.10"}, r53.records)
+	})
+
+	t.Run("reinit keeps the running instance and its record", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Builds = &MockedBuilds{}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK"
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		r53.records["my-feature.spinmint.test"] = "203.0.113.10"
+		spinmint := &model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number, Subdomain: "my-feature", CreatedBy: "someone"}
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		is.EXPECT().CreateComment(gomock.Any(), pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+				assert.Contains(t, comment.GetBody(), "Test server: http://my-feature.spinmint.test")
+				return nil, nil, nil
+			})
+
+		require.NoError(t, s.reinitSpinmint(pr, spinmint))
+		assert.Len(t, fake.instances, 1)
+		assert.Equal(t, ec2.InstanceStateNameRunning, fake.state(id))
+		assert.Equal(t, map[string]string{"my-feature.spinmint.test": "203.0.113.10"}, r53.records)
+	})
+
+	t.Run("setup timings are reported", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK"
+		s.Config.SpinmintReportTimings = true
+		s.Config.AWSInstanceType = "t3.large"
+		metrics := mocks.NewMockMetricsProvider(ctrl)
+		s.Metrics = metrics
+		metrics.EXPECT().ObserveSpinmintWaitDuration(spinmintWaitInstance, pr.RepoName, "t3.large", gomock.Any())
+		metrics.EXPECT().IncreaseSpinmintEvents(spinmintEventCreated, pr.RepoName, "t3.large")
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		r53.records[id+".spinmint.test"] = "203.0.113.10"
+
+		timings := newSpinmintTimings()
+		timings.track(spinmintPhaseBuild, time.Now())
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(&model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number}, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+				assert.Contains(t, comment.GetBody(), "to set up:")
+				assert.Contains(t, comment.GetBody(), "| Build | 0s |\n| Instance running | 0s |\n| DNS | 0s |\n")
+				return nil, nil, nil
+			})
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, "", timings))
+	})
+
+	t.Run("credentials comment is recorded", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK, log in as sysadmin"
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		r53.records[id+".spinmint.test"] = "203.0.113.10"
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(&model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number}, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).Return(&github.IssueComment{HTMLURL: github.String("https://github.com/mattertest/mattermost-server/pull/123#issuecomment-1")}, nil, nil)
+		sms.EXPECT().UpdateCredentialsCommentURL(id, "https://github.com/mattertest/mattermost-server/pull/123#issuecomment-1").Return(nil)
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, "", nil))
+	})
+
+	t.Run("posted credentials are the created accounts", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK"
+		s.Config.SpinmintCredentials = &SpinmintCredentials{
+			AdminUsername: "admin",
+			AdminPassword: "Admin-pass1",
+			AdminEmail:    "admin@example.com",
+			UserUsername:  "tester",
+			UserPassword:  "Tester-pass1",
+			UserEmail:     "tester@example.com",
+		}
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		r53.records[id+".spinmint.test"] = "203.0.113.10"
+		script := fake.instances[id].userData
+		assert.Contains(t, script, `user create --email "$ADMIN_EMAIL" --username "admin" --password "Admin-pass1" --system_admin`)
+		assert.Contains(t, script, `ADMIN_EMAIL="admin@example.com"`)
+		assert.Contains(t, script, `user create --email "$USER_EMAIL" --username "tester" --password "Tester-pass1"`)
+		assert.Contains(t, script, `USER_EMAIL="tester@example.com"`)
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(&model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number}, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+				assert.Contains(t, comment.GetBody(), "| System admin | `admin` | `Admin-pass1` |\n| User | `tester` | `Tester-pass1` |\n")
+				return nil, nil, nil
+			})
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, "", nil))
+	})
+
+	t.Run("minimal spinmint only has the system admin and a team", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK"
+		s.Config.SetupSpinmintMinimalTag = "Minimal Test Server"
+		s.Config.SpinmintSeedUserCount = 50
+		minimalPR := *pr
+		minimalPR.Labels = []string{"Minimal Test Server"}
+
+		instance, err := s.setupSpinmint(ctx, &minimalPR, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		r53.records[id+".spinmint.test"] = "203.0.113.10"
+		script := fake.instances[id].userData
+		assert.Contains(t, script, `SKIP_SAMPLEDATA="true"`)
+		assert.Contains(t, script, `ADMIN_EMAIL="`+spinmintAdminEmail+`"`)
+		assert.Contains(t, script, `USER_EMAIL=""`)
+		assert.Contains(t, script, `TEAM_NAME="`+spinmintMinimalTeamName+`"`)
+		assert.Contains(t, script, `team add "$TEAM_NAME" "`+spinmintAdminUsername+`"`)
+
+		// Minimal spinmints are waited for until Mattermost answers, since the setup script
+		// doesn't start it when it can't create the admin and the team.
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"OK"}`))
+		}))
+		defer ts.Close()
+		s.Config.AWSDnsSuffix = strings.TrimPrefix(ts.URL, "http://127.")
+
+		sms.EXPECT().Get(minimalPR.Number, minimalPR.RepoName).Return(&model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number, Subdomain: "127"}, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+				assert.Contains(t, comment.GetBody(), "| System admin |")
+				assert.NotContains(t, comment.GetBody(), "| User |")
+				assert.NotContains(t, comment.GetBody(), "seeded")
+				return nil, nil, nil
+			})
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, &minimalPR, repo, false, "", nil))
+	})
+
+	t.Run("fallback DNS zone", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK"
+		s.Config.SpinmintReadinessCheck = spinmintReadinessHTTP
+
+		// The spinmint is only reachable through the fallback zone.
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"OK"}`))
+		}))
+		defer ts.Close()
+		fallback := strings.TrimPrefix(ts.URL, "http://127.")
+		s.Config.AWSDnsSuffix = "invalid.invalid"
+		s.Config.AWSDnsZoneFallbacks = []*SpinmintDNSZone{{AWSHostedZoneID: "Z2", AWSDnsSuffix: fallback}}
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		id := aws.StringValue(instance.InstanceId)
+		spinmint := &model.Spinmint{InstanceID: id, RepoName: pr.RepoName, Number: pr.Number, Subdomain: "127"}
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _, _ string, _ int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+				assert.Contains(t, comment.GetBody(), "Test server: http://127."+fallback)
+				return nil, nil, nil
+			})
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, "", nil))
+		assert.Equal(t, map[string]string{"127.invalid.invalid": "203.0.113.10", "127." + fallback: "203.0.113.10"}, r53.records)
+
+		sms.EXPECT().Archive(id, "", gomock.Any()).Return(nil)
+		sms.EXPECT().Delete(id).Return(nil)
+		s.destroySpinmint(pr, spinmint)
+		assert.Empty(t, r53.records)
+	})
+
+	t.Run("full spinmint has the sample data", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, _ := newServer(fake)
+		s.Config.SetupSpinmintMinimalTag = "Minimal Test Server"
+
+		instance, err := s.setupSpinmint(ctx, pr, repo, false)
+		require.NoError(t, err)
+		script := fake.instances[aws.StringValue(instance.InstanceId)].userData
+		assert.Contains(t, script, `SKIP_SAMPLEDATA=""`)
+		assert.Contains(t, script, `ADMIN_EMAIL=""`)
+		assert.Contains(t, script, `TEAM_NAME=""`)
+	})
+
+	t.Run("post-setup script runs after the setup script", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, _ := newServer(fake)
+
+		postSetup := filepath.Join(t.TempDir(), "post-setup.sh")
+		require.NoError(t, ioutil.WriteFile(postSetup, []byte("echo \"post setup of BUILD_NUMBER\"\n"), 0600))
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		// The script is looked up under config/ too.
+		postSetup, err = filepath.Rel(filepath.Join(wd, "config"), postSetup)
+		require.NoError(t, err)
+		withPostSetup := *repo
+		withPostSetup.InstancePostSetupScript = postSetup
+
+		instance, err := s.setupSpinmint(ctx, pr, &withPostSetup, false)
+		require.NoError(t, err)
+		script := fake.instances[aws.StringValue(instance.InstanceId)].userData
+		assert.True(t, strings.HasSuffix(script, "\n\n"+spinmintWaitForServerScript+"\necho \"post setup of 123\"\n"), "the post-setup script ends the user data, once Mattermost is up")
+		assert.Less(t, strings.Index(script, "start mattermost"), strings.Index(script, "/api/v4/system/ping"))
+		assert.Contains(t, script, `SKIP_SAMPLEDATA=""`)
+
+		withPostSetup.InstancePostSetupScript = "missing-post-setup.sh"
+		_, err = s.setupSpinmint(ctx, pr, &withPostSetup, false)
+		require.Error(t, err)
+	})
+
+	t.Run("recreated spinmint links to the posted credentials", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, _ := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+		s.Config.SetupSpinmintDoneMessage = "Test server: SPINMINT_LINK, log in as sysadmin"
+		credentialsURL := "https://github.com/mattertest/mattermost-server/pull/123#issuecomment-1"
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+		sms.EXPECT().Create(gomock.AssignableToTypeOf(&model.Spinmint{})).DoAndReturn(func(spinmint *model.Spinmint) (*model.Spinmint, error) {
+			assert.Equal(t, credentialsURL, spinmint.CredentialsCommentURL)
+			return spinmint, nil
+		})
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).DoAndReturn(func(_ context.Context, _, _ string, _ int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+			assert.Equal(t, fmt.Sprintf(msgSpinmintRecreated, "http://i-fake1.spinmint.test", credentialsURL), comment.GetBody())
+			return &github.IssueComment{HTMLURL: github.String("https://github.com/mattertest/mattermost-server/pull/123#issuecomment-2")}, nil, nil
+		})
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, credentialsURL, nil))
+	})
+
+	t.Run("losing the race to another flow terminates the new instance", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+		sms.EXPECT().Create(gomock.AssignableToTypeOf(&model.Spinmint{})).Return(&model.Spinmint{InstanceID: "i-other", RepoName: pr.RepoName, Number: pr.Number}, nil)
+
+		require.NoError(t, s.setupSpinmintForPR(ctx, pr, repo, false, "", nil))
+		require.Len(t, fake.instances, 1)
+		assert.Equal(t, ec2.InstanceStateNameTerminated, fake.state("i-fake1"))
+		assert.Empty(t, r53.records)
+	})
+
+	t.Run("failing to store the spinmint terminates the new instance", func(t *testing.T) {
+		fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+		s, r53 := newServer(fake)
+		is := mocks.NewMockIssuesService(ctrl)
+		s.GithubClient = &GithubClient{Issues: is}
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+		sms.EXPECT().Create(gomock.AssignableToTypeOf(&model.Spinmint{})).Return(nil, errors.New("connection refused"))
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).Return(nil, nil, nil)
+
+		err := s.setupSpinmintForPR(ctx, pr, repo, false, "", nil)
+		require.EqualError(t, err, "unable to store the spinmint: connection refused")
+		require.Len(t, fake.instances, 1)
+		assert.Equal(t, ec2.InstanceStateNameTerminated, fake.state("i-fake1"))
+		assert.Empty(t, r53.records)
+	})
+}
+
+// failingRoute53 rejects every change of record.
+type failingRoute53 struct {
+	route53iface.Route53API
+}
+
+func (f *failingRoute53) ChangeResourceRecordSetsWithContext(_ aws.Context, _ *route53.ChangeResourceRecordSetsInput, _ ...request.Option) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return nil, errors.New("throttled")
+}
+
+func TestSetupSpinmintForPRErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, Ref: "feature", Sha: "abcdef"}
+	repo := &Repository{InstanceSetupScript: "../../config/instance-setup.sh"}
+	existing := &model.Spinmint{InstanceID: "i-fake1", RepoName: pr.RepoName, Number: pr.Number}
+
+	testCases := []struct {
+		name          string
+		repo          *Repository
+		finalState    string
+		route53       route53iface.Route53API
+		stored        *model.Spinmint
+		storeErr      error
+		allowedTypes  []string
+		labels        []string
+		maxCount      int
+		expectComment bool
+		comment       string
+		expectedErr   string
+	}{
+		{
+			name:        "store failure",
+			storeErr:    errors.New("connection refused"),
+			expectedErr: "unable to get the spinmint information: connection refused",
+		},
+		{
+			name:          "instance setup failure",
+			repo:          &Repository{InstanceSetupScript: "missing.sh"},
+			expectComment: true,
+			expectedErr:   "unable to set up the spinmint instance",
+		},
+		{
+			name:          "instance type not allowed",
+			allowedTypes:  []string{"t3.xlarge"},
+			expectComment: true,
+			comment:       "Test servers can't use `t3.large`. The allowed ones are: `t3.xlarge`.",
+			expectedErr:   "instance type t3.large is not allowed",
+		},
+		{
+			name:          "unknown size label",
+			labels:        []string{"Spinmint Size/huge"},
+			expectComment: true,
+			comment:       "Test servers can't use `Spinmint Size/huge`. The allowed ones are: `Spinmint Size/large`, `Spinmint Size/small`.",
+			expectedErr:   "instance type Spinmint Size/huge is not allowed",
+		},
+		{
+			name:          "size label not allowed",
+			labels:        []string{"Spinmint Size/large"},
+			allowedTypes:  []string{"t3.large", "t3.medium"},
+			expectComment: true,
+			comment:       "Test servers can't use `Spinmint Size/large`. The allowed ones are: `Spinmint Size/small`.",
+			expectedErr:   "instance type Spinmint Size/large is not allowed",
+		},
+		{
+			name:          "maximum count reached",
+			maxCount:      1,
+			expectComment: true,
+			expectedErr:   "1 spinmints are running, the maximum is 1",
+		},
+		{
+			name:          "instance does not come up",
+			finalState:    ec2.InstanceStateNameTerminated,
+			stored:        existing,
+			expectComment: true,
+			expectedErr:   "instance i-fake1 did not come up",
+		},
+		{
+			name:          "subdomain failure",
+			route53:       &failingRoute53{},
+			stored:        existing,
+			expectComment: true,
+			expectedErr:   "unable to set up the subdomain of instance i-fake1: throttled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sms := stmock.NewMockSpinmintStore(ctrl)
+			sms.EXPECT().LogAction(gomock.Any()).Return(nil).AnyTimes()
+			sms.EXPECT().UpdateState(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			ss := stmock.NewMockStore(ctrl)
+			ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+			is := mocks.NewMockIssuesService(ctrl)
+			metrics := mocks.NewMockMetricsProvider(ctrl)
+
+			finalState := tc.finalState
+			if finalState == "" {
+				finalState = ec2.InstanceStateNameRunning
+			}
+			fake := newFakeEC2(0, finalState)
+			if tc.stored != nil {
+				fake.instances[tc.stored.InstanceID] = &fakeInstance{state: finalState, tags: make(map[string]string)}
+			}
+			r53 := tc.route53
+			if r53 == nil {
+				r53 = &fakeRoute53{records: make(map[string]string)}
+			}
+			s := &Server{
+				Config: &Config{
+					AWSDnsSuffix:                 "spinmint.test",
+					AWSInstanceType:              "t3.large",
+					SpinmintAllowedInstanceTypes: tc.allowedTypes,
+					SpinmintMaxCount:             tc.maxCount,
+					SpinmintSizeLabelPrefix:      "Spinmint Size/",
+					SpinmintSizeAliases:          map[string]string{"small": "t3.medium", "large": "t3.xlarge"},
+				},
+				Store:         ss,
+				EC2Client:     fake,
+				Route53Client: r53,
+				GithubClient:  &GithubClient{Issues: is},
+				Metrics:       metrics,
+			}
+
+			sms.EXPECT().Get(pr.Number, pr.RepoName).Return(tc.stored, tc.storeErr)
+			if tc.maxCount > 0 {
+				sms.EXPECT().List().Return([]*model.Spinmint{{InstanceID: "i-other"}}, nil)
+			}
+			if tc.expectComment {
+				comment := gomock.Any()
+				if tc.comment != "" {
+					comment = gomock.Eq(&github.IssueComment{Body: github.String(tc.comment)})
+				}
+				is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, comment).Return(nil, nil, nil)
+				metrics.EXPECT().IncreaseSpinmintEvents(spinmintEventFailed, pr.RepoName, "t3.large")
+			}
+			if tc.name == "subdomain failure" {
+				metrics.EXPECT().ObserveSpinmintWaitDuration(spinmintWaitInstance, pr.RepoName, "t3.large", gomock.Any())
+			}
+
+			r := repo
+			if tc.repo != nil {
+				r = tc.repo
+			}
+			labeled := *pr
+			labeled.Labels = tc.labels
+			err := s.setupSpinmintForPR(ctx, &labeled, r, false, "", nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestWaitForBuildAndSetupSpinmintMaxCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	b := &artifactBuilds{events: make(chan string, 10)}
+	s := &Server{
+		Config:       &Config{SpinmintMaxCount: 1},
+		Store:        ss,
+		Builds:       b,
+		GithubClient: &GithubClient{Issues: is},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, Sha: "abcdef123456"}
+
+	sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+	sms.EXPECT().List().Return([]*model.Spinmint{{InstanceID: "i-other", RepoOwner: "mattertest", RepoName: serverRepoName, Number: 1}}, nil)
+	sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil)
+	is.EXPECT().CreateComment(gomock.Any(), pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(fmt.Sprintf(msgSpinmintMaxCountReached, 1, 1))}).Return(nil, nil, nil)
+
+	err := s.waitForBuildAndSetupSpinmint(pr, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 spinmints are running, the maximum is 1")
+	// The PR is turned down before waiting for its build.
+	close(b.events)
+	assert.Empty(t, b.events)
+}
+
+func TestCheckTestServerLifeTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().UpdateState(gomock.Any(), model.SpinmintStateDeleting).Return(nil).AnyTimes()
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	sys := stmock.NewMockSystemStore(ctrl)
+	ss.EXPECT().System().Return(sys).AnyTimes()
+	sys.EXPECT().Get(systemSpinmintReaperPaused).Return("false", nil)
+	is := mocks.NewMockIssuesService(ctrl)
+	prService := mocks.NewMockPullRequestsService(ctrl)
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	metricsMock.EXPECT().ObserveCronTaskDuration(gomock.Any(), gomock.Any()).AnyTimes()
+
+	fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+	r53 := &fakeRoute53{records: make(map[string]string)}
+	s := &Server{
+		Config:        &Config{AWSDnsSuffix: "spinmint.test", SpinmintExpirationHour: 2, SpinmintReaperConcurrency: 2},
+		Store:         ss,
+		EC2Client:     fake,
+		Route53Client: r53,
+		GithubClient:  &GithubClient{Issues: is, PullRequests: prService},
+		Metrics:       metricsMock,
+	}
+
+	var testServers []*model.Spinmint
+	for i := 1; i <= 5; i++ {
+		id := fmt.Sprintf("i-fake%d", i)
+		fake.instances[id] = &fakeInstance{state: ec2.InstanceStateNameRunning, tags: make(map[string]string)}
+		r53.records[id+".spinmint.test"] = "203.0.113.10"
+		createdAt := time.Now().Add(-5 * time.Hour)
+		if i == 5 {
+			createdAt = time.Now()
+		}
+		testServers = append(testServers, &model.Spinmint{InstanceID: id, RepoOwner: "mattertest", RepoName: serverRepoName, Number: i, CreatedAt: createdAt})
+	}
+
+	sms.EXPECT().List().Return(testServers, nil)
+	sms.EXPECT().Archive(gomock.Any(), "", gomock.Any()).Return(nil).Times(4)
+	// Each expired spinmint is logged as reaped only, and removed from the store once.
+	sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil).Times(4)
+	metricsMock.EXPECT().IncreaseSpinmintEvents(spinmintEventReaped, serverRepoName, "").Times(4)
+	sms.EXPECT().Delete(gomock.Any()).Return(nil).Times(4)
+	is.EXPECT().CreateComment(gomock.Any(), "mattertest", serverRepoName, gomock.Any(), gomock.Any()).Return(nil, nil, nil).Times(4)
+	// The PR of the spinmint that did not expire is still there.
+	prService.EXPECT().Get(gomock.Any(), "mattertest", serverRepoName, 5).Return(&github.PullRequest{}, nil, nil)
+
+	s.CheckTestServerLifeTime()
+
+	for i := 1; i <= 4; i++ {
+		assert.Equal(t, ec2.InstanceStateNameTerminated, fake.state(fmt.Sprintf("i-fake%d", i)))
+	}
+	assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-fake5"))
+	assert.Len(t, r53.records, 1)
+}
+
+func TestCheckTestServerLifeTimeDestroyFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().UpdateState(gomock.Any(), model.SpinmintStateDeleting).Return(nil).AnyTimes()
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	sys := stmock.NewMockSystemStore(ctrl)
+	ss.EXPECT().System().Return(sys).AnyTimes()
+	sys.EXPECT().Get(systemSpinmintReaperPaused).Return("", nil).AnyTimes()
+	is := mocks.NewMockIssuesService(ctrl)
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	metricsMock.EXPECT().ObserveCronTaskDuration(gomock.Any(), gomock.Any()).AnyTimes()
+
+	fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+	fake.instances["i-fake1"] = &fakeInstance{state: ec2.InstanceStateNameRunning, tags: make(map[string]string)}
+	r53 := &fakeRoute53{records: make(map[string]string)}
+	s := &Server{
+		Config:        &Config{AWSDnsSuffix: "spinmint.test", SpinmintExpirationHour: 2},
+		Store:         ss,
+		EC2Client:     fake,
+		Route53Client: r53,
+		GithubClient:  &GithubClient{Issues: is},
+		Metrics:       metricsMock,
+	}
+	expired := []*model.Spinmint{{InstanceID: "i-fake1", RepoOwner: "mattertest", RepoName: serverRepoName, Number: 1, CreatedAt: time.Now().Add(-5 * time.Hour)}}
+
+	t.Run("instance not terminated", func(t *testing.T) {
+		fake.terminateErr = errors.New("RequestLimitExceeded")
+		defer func() { fake.terminateErr = nil }()
+
+		// The record is kept and the PR is not told about it.
+		sms.EXPECT().List().Return(expired, nil)
+		s.CheckTestServerLifeTime()
+		assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-fake1"))
+	})
+
+	t.Run("dns record not removed", func(t *testing.T) {
+		// There is no record to remove, which fails the DELETE.
+		sms.EXPECT().List().Return(expired, nil)
+		sms.EXPECT().Archive("i-fake1", "", gomock.Any()).Return(nil)
+		sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil)
+		metricsMock.EXPECT().IncreaseSpinmintEvents(spinmintEventReaped, serverRepoName, "")
+		sms.EXPECT().Delete("i-fake1").Return(nil)
+		is.EXPECT().CreateComment(gomock.Any(), "mattertest", serverRepoName, 1, gomock.Any()).Return(nil, nil, nil)
+
+		s.CheckTestServerLifeTime()
+		assert.Equal(t, ec2.InstanceStateNameTerminated, fake.state("i-fake1"))
+	})
+
+	t.Run("terminated instance has no IP", func(t *testing.T) {
+		publicIP, privateIP := s.getIPsForInstance(context.Background(), "", "i-fake1")
+		assert.Empty(t, publicIP)
+		assert.Empty(t, privateIP)
+	})
+}
+
+func TestCheckTestServerLifeTimeDeletedPR(t *testing.T) {
+	defer func(delay time.Duration) { spinmintPRGoneRetryDelay = delay }(spinmintPRGoneRetryDelay)
+	spinmintPRGoneRetryDelay = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().UpdateState(gomock.Any(), model.SpinmintStateDeleting).Return(nil).AnyTimes()
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	sys := stmock.NewMockSystemStore(ctrl)
+	ss.EXPECT().System().Return(sys).AnyTimes()
+	sys.EXPECT().Get(systemSpinmintReaperPaused).Return("", nil)
+	prService := mocks.NewMockPullRequestsService(ctrl)
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	metricsMock.EXPECT().ObserveCronTaskDuration(gomock.Any(), gomock.Any()).AnyTimes()
+
+	fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+	r53 := &fakeRoute53{records: make(map[string]string)}
+	s := &Server{
+		Config:        &Config{AWSDnsSuffix: "spinmint.test", SpinmintExpirationHour: 2},
+		Store:         ss,
+		EC2Client:     fake,
+		Route53Client: r53,
+		GithubClient:  &GithubClient{PullRequests: prService},
+		Metrics:       metricsMock,
+	}
+
+	var testServers []*model.Spinmint
+	for i := 1; i <= 3; i++ {
+		id := fmt.Sprintf("i-fake%d", i)
+		fake.instances[id] = &fakeInstance{state: ec2.InstanceStateNameRunning, tags: make(map[string]string)}
+		r53.records[id+".spinmint.test"] = "203.0.113.10"
+		testServers = append(testServers, &model.Spinmint{InstanceID: id, RepoOwner: "mattertest", RepoName: serverRepoName, Number: i, CreatedAt: time.Now()})
+	}
+	notFound := &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	badGateway := &github.Response{Response: &http.Response{StatusCode: http.StatusBadGateway}}
+
+	// The PR of the first spinmint was deleted.
+	prService.EXPECT().Get(gomock.Any(), "mattertest", serverRepoName, 1).Return(nil, notFound, errors.New("404 Not Found")).Times(spinmintPRGoneAttempts)
+	// GitHub did not find the second one once, then did.
+	gomock.InOrder(
+		prService.EXPECT().Get(gomock.Any(), "mattertest", serverRepoName, 2).Return(nil, notFound, errors.New("404 Not Found")),
+		prService.EXPECT().Get(gomock.Any(), "mattertest", serverRepoName, 2).Return(&github.PullRequest{}, nil, nil),
+	)
+	// GitHub failed to look up the third one.
+	prService.EXPECT().Get(gomock.Any(), "mattertest", serverRepoName, 3).Return(nil, badGateway, errors.New("502 Bad Gateway"))
+
+	sms.EXPECT().List().Return(testServers, nil)
+	sms.EXPECT().Archive("i-fake1", "", gomock.Any()).Return(nil)
+	sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil)
+	metricsMock.EXPECT().IncreaseSpinmintEvents(spinmintEventReaped, serverRepoName, "")
+	sms.EXPECT().Delete("i-fake1").Return(nil)
+
+	s.CheckTestServerLifeTime()
+
+	assert.Equal(t, ec2.InstanceStateNameTerminated, fake.state("i-fake1"))
+	assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-fake2"))
+	assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-fake3"))
+	assert.Len(t, r53.records, 2)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+// spinmintApprovalContext is the commit status recording that a maintainer approved
+// running the code of a fork PR on a spinmint. Being a status, it is tied to the commit.
+const spinmintApprovalContext = "spinmint/approval"
+
+const (
+	msgSpinmintForkApprovalRequired = "This PR comes from a fork, so a maintainer needs to approve commit `%s` with `/spinmint approve` before a test server runs it."
+	msgSpinmintApproved             = "Approved commit `%s` to run on a test server."
+)
+
+// isForkPR is true if the head branch of the PR lives in another repository. A PR whose
+// head repository is unknown, like a deleted fork, is taken as a fork.
+func isForkPR(pr *model.PullRequest) bool {
+	return pr.FullName == "" || !strings.EqualFold(pr.FullName, pr.RepoOwner+"/"+pr.RepoName)
+}
+
+// isSpinmintApprovalPending is true if the PR comes from a fork and its head commit
+// was not approved to run on a spinmint yet. The contributor is told so in that case.
+func (s *Server) isSpinmintApprovalPending(ctx context.Context, pr *model.PullRequest) bool {
+	if !s.Config.SpinmintForkApproval || !isForkPR(pr) {
+		return false
+	}
+
+	approved, err := s.isSpinmintApproved(ctx, pr)
+	if err != nil {
+		mlog.Warn("Unable to check the spinmint approval", mlog.Int("pr", pr.Number), mlog.Err(err))
+	}
+	if approved {
+		return false
+	}
+
+	mlog.Info("Spinmint of fork PR is waiting for approval", mlog.Int("pr", pr.Number), mlog.String("sha", pr.Sha))
+	if err = s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, fmt.Sprintf(msgSpinmintForkApprovalRequired, shortSha(pr.Sha))); err != nil {
+		mlog.Warn("Error while commenting", mlog.Err(err))
+	}
+	return true
+}
+
+func (s *Server) isSpinmintApproved(ctx context.Context, pr *model.PullRequest) (bool, error) {
+	statuses, _, err := s.GithubClient.Repositories.ListStatuses(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil)
+	if err != nil {
+		return false, err
+	}
+	// Statuses are listed newest first.
+	for _, status := range statuses {
+		if status.GetContext() == spinmintApprovalContext {
+			return status.GetState() == stateSuccess, nil
+		}
+	}
+	return false, nil
+}
+
+// handleSpinmintApprove approves the head commit of a fork PR to run on a spinmint
+// and starts the spinmints that were waiting for it.
+func (s *Server) handleSpinmintApprove(ctx context.Context, commenter string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	status := &github.RepoStatus{
+		Context:     github.String(spinmintApprovalContext),
+		State:       github.String(stateSuccess),
+		Description: github.String(fmt.Sprintf("Approved by @%s to run on a test server", commenter)),
+	}
+	if err := s.createRepoStatus(ctx, pr, status); err != nil {
+		return err
+	}
+
+	spinmints, err := s.Store.Spinmint().GetAll(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(spinmints) == 0 && s.hasSetupSpinmintLabel(pr.Labels) && !s.isDraftSkipped(pr):
+		msg = s.getSetupSpinmintMessage()
+		s.runSpinmintFlow("spinmint_setup", pr, func() error { return s.waitForBuildAndSetupSpinmint(pr, false) })
+	case len(spinmints) == 0 && s.Config.SetupSpinmintUpgradeTag != "" && contains(pr.Labels, s.Config.SetupSpinmintUpgradeTag):
+		msg = s.Config.SetupSpinmintUpgradeMessage
+		s.runSpinmintFlow("spinmint_upgrade", pr, func() error { return s.waitForBuildAndSetupSpinmint(pr, true) })
+	case len(spinmints) > 0 && s.isSpinmintRecreateOnPush(pr):
+		msg = msgSpinmintRecreating
+		s.runSpinmintFlow("spinmint_recreate", pr, func() error { return s.recreateSpinmints(pr, spinmints) })
+	default:
+		msg = fmt.Sprintf(msgSpinmintApproved, shortSha(pr.Sha))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsForkPR(t *testing.T) {
+	// The head repository of a deleted fork is unknown.
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server"}
+	assert.True(t, isForkPR(pr))
+
+	pr.FullName = "mattermost/mattermost-server"
+	assert.False(t, isForkPR(pr))
+
+	pr.FullName = "contributor/mattermost-server"
+	assert.True(t, isForkPR(pr))
+}
+
+func TestIsSpinmintApprovalPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is, Repositories: rs},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server", FullName: "contributor/mattermost-server", Number: 123, Sha: "abcdef123456"}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.False(t, s.isSpinmintApprovalPending(ctx, pr))
+	})
+
+	s.Config.SpinmintForkApproval = true
+
+	t.Run("same repository", func(t *testing.T) {
+		upstream := *pr
+		upstream.FullName = "mattermost/mattermost-server"
+		assert.False(t, s.isSpinmintApprovalPending(ctx, &upstream))
+	})
+
+	t.Run("approved commit", func(t *testing.T) {
+		rs.EXPECT().ListStatuses(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil).Return([]*github.RepoStatus{
+			{Context: github.String("ci"), State: github.String(statePending)},
+			{Context: github.String(spinmintApprovalContext), State: github.String(stateSuccess)},
+		}, nil, nil)
+		assert.False(t, s.isSpinmintApprovalPending(ctx, pr))
+	})
+
+	t.Run("unapproved commit", func(t *testing.T) {
+		rs.EXPECT().ListStatuses(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil).Return(nil, nil, nil)
+		msg := "This PR comes from a fork, so a maintainer needs to approve commit `abcdef1` with `/spinmint approve` before a test server runs it."
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+		assert.True(t, s.isSpinmintApprovalPending(ctx, pr))
+	})
+}
+
+func TestHandleSpinmintApprove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	s := &Server{
+		Config:       &Config{SpinmintForkApproval: true},
+		GithubClient: &GithubClient{Issues: is, Repositories: rs},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server", FullName: "contributor/mattermost-server", Number: 123, Sha: "abcdef123456"}
+
+	t.Run("random user", func(t *testing.T) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msgSpinmintMaintainerOnly)}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintApprove(ctx, "someone", pr))
+	})
+
+	t.Run("maintainer approves the commit", func(t *testing.T) {
+		rs.EXPECT().CreateStatus(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, &github.RepoStatus{
+			Context:     github.String(spinmintApprovalContext),
+			State:       github.String(stateSuccess),
+			Description: github.String("Approved by @maintainer to run on a test server"),
+		}).Return(nil, nil, nil)
+		sms.EXPECT().GetAll(pr.Number, pr.RepoName).Return(nil, nil)
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String("Approved commit `abcdef1` to run on a test server.")}).Return(nil, nil, nil)
+		require.NoError(t, s.handleSpinmintApprove(ctx, "maintainer", pr))
+	})
+}
+
+func TestSetupSpinmintForPRUnapprovedCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	rs := mocks.NewMockRepositoriesService(ctrl)
+	s := &Server{
+		Config:       &Config{SpinmintForkApproval: true},
+		GithubClient: &GithubClient{Issues: is, Repositories: rs},
+	}
+	// The build wait moved the PR to a commit pushed after the approval.
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server", FullName: "contributor/mattermost-server", Number: 123, Sha: "fedcba654321"}
+
+	rs.EXPECT().ListStatuses(ctx, pr.RepoOwner, pr.RepoName, pr.Sha, nil).Return(nil, nil, nil)
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).Return(nil, nil, nil)
+
+	err := s.setupSpinmintForPR(ctx, pr, &Repository{}, false, "", nil)
+	require.EqualError(t, err, "commit fedcba654321 of the fork PR is not approved")
+}
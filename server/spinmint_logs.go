@@ -0,0 +1,153 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+const (
+	defaultSpinmintTailLines = 50
+	maxSpinmintTailLines     = 500
+	// maxSpinmintTailLength keeps the comment under the GitHub limit of 65536 characters.
+	maxSpinmintTailLength = 60000
+)
+
+const (
+	msgSpinmintTailUsage = "Please specify how many log lines to get, e.g. `/spinmint tail 100`. At most %d lines can be fetched."
+	msgSpinmintTailError = "Error trying to get the logs of the test server."
+	msgSpinmintTailEmpty = "The test server has no logs yet."
+)
+
+// spinmintLogSecretRegexps match the secrets which may show up in the server logs.
+// The first group is kept and the rest is redacted.
+var spinmintLogSecretRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api_?key|access_?key|datasource)"?\s*[:=]\s*"?)[^"\s,}]+`),
+	regexp.MustCompile(`(?i)(bearer\s+)[a-z0-9._~+/=-]+`),
+	regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+(@)`),
+	regexp.MustCompile(`()AKIA[0-9A-Z]{16}`),
+}
+
+// redactSpinmintLogLine hides the obvious secrets of a log line.
+func redactSpinmintLogLine(line string) string {
+	for _, re := range spinmintLogSecretRegexps {
+		line = re.ReplaceAllStringFunc(line, func(match string) string {
+			groups := re.FindStringSubmatch(match)
+			redacted := groups[1] + "[REDACTED]"
+			if len(groups) > 2 {
+				redacted += groups[2]
+			}
+			return redacted
+		})
+	}
+	return line
+}
+
+// getSpinmintTailCount returns the number of log lines asked after "/spinmint tail",
+// capped to maxSpinmintTailLines. It is zero if the argument is not a positive number.
+func getSpinmintTailCount(body string) int {
+	index := strings.Index(body, "/spinmint tail")
+	if index < 0 {
+		return 0
+	}
+	for _, arg := range strings.Fields(body[index+len("/spinmint tail"):]) {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return 0
+		}
+		if n > maxSpinmintTailLines {
+			return maxSpinmintTailLines
+		}
+		return n
+	}
+	return defaultSpinmintTailLines
+}
+
+// handleSpinmintTail comments the last log lines of the test server of a PR, with their secrets redacted.
+func (s *Server) handleSpinmintTail(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	n := getSpinmintTailCount(body)
+	if n == 0 {
+		msg = fmt.Sprintf(msgSpinmintTailUsage, maxSpinmintTailLines)
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	lines, err := s.getSpinmintLogs(s.getSpinmintURL(spinmint.Region, spinmint.GetSubdomain()), n)
+	if err != nil {
+		msg = msgSpinmintTailError
+		return err
+	}
+	msg = formatSpinmintLogs(lines)
+	return nil
+}
+
+func (s *Server) getSpinmintLogs(siteURL string, n int) ([]string, error) {
+	client, err := s.newSpinmintAdminClient(siteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first page holds the most recent lines.
+	lines, resp := client.GetLogs(0, n)
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return lines, nil
+}
+
+// formatSpinmintLogs returns the redacted log lines in a collapsed code block. The oldest
+// lines are dropped if they don't fit in a comment.
+func formatSpinmintLogs(lines []string) string {
+	if len(lines) == 0 {
+		return msgSpinmintTailEmpty
+	}
+
+	redacted := make([]string, 0, len(lines))
+	length := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		// Keep the log lines from closing the code block.
+		line := strings.ReplaceAll(redactSpinmintLogLine(strings.TrimRight(lines[i], "\n")), "```", "'''")
+		length += len(line) + 1
+		if length > maxSpinmintTailLength {
+			break
+		}
+		redacted = append([]string{line}, redacted...)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<details><summary>Last %d log lines of the test server</summary>\n\n", len(redacted))
+	sb.WriteString("```\n")
+	sb.WriteString(strings.Join(redacted, "\n"))
+	sb.WriteString("\n```\n</details>")
+	return sb.String()
+}
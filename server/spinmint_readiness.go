@@ -0,0 +1,141 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+)
+
+// Checks confirming that a running spinmint is reachable before it is reported ready.
+const (
+	spinmintReadinessTCP     = "tcp"
+	spinmintReadinessResolve = "resolve"
+	spinmintReadinessHTTP    = "http"
+	// spinmintReadinessHTTPS pings the spinmint over HTTPS whatever its URL scheme.
+	// It is what SpinmintHTTPSReadinessProbe enables.
+	spinmintReadinessHTTPS = "https"
+)
+
+// validateSpinmintReadinessCheck rejects a readiness check mattermod doesn't know,
+// which would otherwise only fail once a spinmint is waited for.
+func validateSpinmintReadinessCheck(check string) error {
+	switch strings.ToLower(check) {
+	case "", spinmintReadinessTCP, spinmintReadinessResolve, spinmintReadinessHTTP, spinmintReadinessHTTPS:
+		return nil
+	}
+	return errors.Errorf("unknown check %q, expected %s, %s, %s or %s", check, spinmintReadinessTCP, spinmintReadinessResolve, spinmintReadinessHTTP, spinmintReadinessHTTPS)
+}
+
+// spinmintReadinessPollInterval is the wait between two readiness checks of a spinmint.
+var spinmintReadinessPollInterval = 10 * time.Second
+
+// getSpinmintReadinessCheck returns the configured readiness check for the spinmint of the PR,
+// or an empty string if only the instance state is waited for. Spinmints mattermod logs in to
+// once running are always waited for until Mattermost answers pings, and so are minimal ones,
+// whose setup script doesn't start Mattermost when it can't create their admin and team.
+func (s *Server) getSpinmintReadinessCheck(pr *model.PullRequest) string {
+	check := ""
+	if s.Config.SpinmintReadinessCheck != "" {
+		check = strings.ToLower(s.Config.SpinmintReadinessCheck)
+	} else if s.Config.SpinmintHTTPSReadinessProbe {
+		check = spinmintReadinessHTTPS
+	}
+	if (s.usesSpinmintAPIOnSetup() || s.isSpinmintMinimal(pr)) && check != spinmintReadinessHTTP && check != spinmintReadinessHTTPS {
+		return spinmintReadinessHTTP
+	}
+	return check
+}
+
+// usesSpinmintAPIOnSetup is true if mattermod calls the API of new spinmints before reporting them ready.
+func (s *Server) usesSpinmintAPIOnSetup() bool {
+	return len(s.Config.SpinmintServerConfig) > 0 || len(s.Config.SpinmintSmokeTest) > 0
+}
+
+// waitForSpinmintReady waits until the spinmint passes check through its hostname.
+// Unlike the instance state, this catches DNS, wildcard certificate and ingress routing issues.
+// Every configured DNS suffix is tried in order and the first reachable one is returned.
+func (s *Server) waitForSpinmintReady(ctx context.Context, check, region, subdomain string, timeout time.Duration) (string, error) {
+	return s.waitForSpinmintHosts(ctx, check, subdomain, s.getSpinmintDNSSuffixes(region), timeout)
+}
+
+// waitForSpinmintHosts waits until the spinmint passes check through the subdomain under
+// any of suffixes and returns the first suffix it was reachable through.
+func (s *Server) waitForSpinmintHosts(ctx context.Context, check, subdomain string, suffixes []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		for _, suffix := range suffixes {
+			host := fmt.Sprintf("%v.%v", subdomain, suffix)
+			err := s.probeSpinmint(ctx, check, host)
+			if err == nil {
+				return suffix, nil
+			}
+			mlog.Debug("Spinmint is not ready yet", mlog.String("host", host), mlog.String("check", check), mlog.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", errors.Errorf("timed out waiting for %s to pass the %s check through any of %v", subdomain, check, suffixes)
+		case <-time.After(spinmintReadinessPollInterval):
+		}
+	}
+}
+
+// probeSpinmint runs check once against the spinmint at host, which may include a port.
+func (s *Server) probeSpinmint(ctx context.Context, check, host string) error {
+	switch check {
+	case spinmintReadinessResolve:
+		hostname := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			hostname = h
+		}
+		addrs, err := net.DefaultResolver.LookupHost(ctx, hostname)
+		if err != nil {
+			return err
+		}
+		if len(addrs) == 0 {
+			return errors.Errorf("%s resolves to no address", hostname)
+		}
+		return nil
+	case spinmintReadinessTCP:
+		addr := host
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			port := "80"
+			if s.getSpinmintURLScheme() == "https" {
+				port = "443"
+			}
+			addr = net.JoinHostPort(host, port)
+		}
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case spinmintReadinessHTTP, spinmintReadinessHTTPS:
+		scheme := s.getSpinmintURLScheme()
+		if check == spinmintReadinessHTTPS {
+			scheme = "https"
+		}
+		status, _, err := s.checkMMPing(ctx, scheme+"://"+host)
+		if err != nil {
+			return err
+		}
+		if status != "OK" {
+			return errors.Errorf("ping returned status %q", status)
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown readiness check %q", check)
+	}
+}
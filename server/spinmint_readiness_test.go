@@ -0,0 +1,111 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpinmintReadinessCheck(t *testing.T) {
+	s := &Server{Config: &Config{SetupSpinmintMinimalTag: "Minimal Test Server"}}
+	pr := &model.PullRequest{Labels: []string{"Setup Test Server"}}
+	assert.Equal(t, "", s.getSpinmintReadinessCheck(pr))
+
+	s.Config.SpinmintHTTPSReadinessProbe = true
+	assert.Equal(t, spinmintReadinessHTTPS, s.getSpinmintReadinessCheck(pr))
+
+	s.Config.SpinmintReadinessCheck = "TCP"
+	assert.Equal(t, spinmintReadinessTCP, s.getSpinmintReadinessCheck(pr))
+
+	// Mattermost must be up to be configured through its API.
+	s.Config.SpinmintServerConfig = map[string]interface{}{"ServiceSettings": map[string]interface{}{"EnableTesting": true}}
+	assert.Equal(t, spinmintReadinessHTTP, s.getSpinmintReadinessCheck(pr))
+
+	s.Config.SpinmintReadinessCheck = ""
+	assert.Equal(t, spinmintReadinessHTTPS, s.getSpinmintReadinessCheck(pr))
+
+	s.Config.SpinmintHTTPSReadinessProbe = false
+	assert.Equal(t, spinmintReadinessHTTP, s.getSpinmintReadinessCheck(pr))
+
+	s.Config.SpinmintServerConfig = nil
+	assert.Equal(t, "", s.getSpinmintReadinessCheck(pr))
+
+	// Minimal spinmints are only usable once their setup script created the admin.
+	minimal := &model.PullRequest{Labels: []string{"Setup Test Server", "Minimal Test Server"}}
+	assert.Equal(t, spinmintReadinessHTTP, s.getSpinmintReadinessCheck(minimal))
+
+	s.Config.SpinmintSmokeTest = []*SpinmintSmokeTestStep{{Path: "/api/v4/users/me"}}
+	assert.Equal(t, spinmintReadinessHTTP, s.getSpinmintReadinessCheck(pr))
+}
+
+func TestProbeSpinmint(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{Config: &Config{}}
+
+	status := "OK"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/system/ping", r.URL.Path)
+		_, _ = w.Write([]byte(`{"status":"` + status + `"}`))
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	t.Run("http", func(t *testing.T) {
+		require.NoError(t, s.probeSpinmint(ctx, spinmintReadinessHTTP, host))
+
+		status = "UNHEALTHY"
+		defer func() { status = "OK" }()
+		require.Error(t, s.probeSpinmint(ctx, spinmintReadinessHTTP, host))
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		require.NoError(t, s.probeSpinmint(ctx, spinmintReadinessTCP, host))
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		closed := l.Addr().String()
+		require.NoError(t, l.Close())
+		require.Error(t, s.probeSpinmint(ctx, spinmintReadinessTCP, closed))
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		require.NoError(t, s.probeSpinmint(ctx, spinmintReadinessResolve, "localhost:8065"))
+		require.Error(t, s.probeSpinmint(ctx, spinmintReadinessResolve, "invalid.invalid"))
+	})
+
+	t.Run("unknown check", func(t *testing.T) {
+		require.Error(t, s.probeSpinmint(ctx, "icmp", host))
+	})
+}
+
+func TestWaitForSpinmintReady(t *testing.T) {
+	defer func(interval time.Duration) { spinmintReadinessPollInterval = interval }(spinmintReadinessPollInterval)
+	spinmintReadinessPollInterval = time.Millisecond
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	// The host is the instance ID followed by the suffix, so instance "127" and
+	// the fallback suffix below point at the listener.
+	reachable := "0.0.1:" + strings.TrimPrefix(l.Addr().String(), "127.0.0.1:")
+	s := &Server{Config: &Config{AWSDnsSuffix: "invalid.invalid", AWSDnsZoneFallbacks: []*SpinmintDNSZone{{AWSHostedZoneID: "Z2", AWSDnsSuffix: reachable}}}}
+
+	suffix, err := s.waitForSpinmintReady(context.Background(), spinmintReadinessTCP, "", "127", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, reachable, suffix)
+
+	_, err = s.waitForSpinmintReady(context.Background(), spinmintReadinessResolve, "", "i-unreachable", 50*time.Millisecond)
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+// systemSpinmintReaperPaused is the system value holding whether the reaper is paused.
+// It is kept in the database so the jobserver sees it and a restart doesn't resume reaping.
+const systemSpinmintReaperPaused = "SpinmintReaperPaused"
+
+const (
+	msgSpinmintReaperUsage   = "Use `/spinmint reaper pause` or `/spinmint reaper resume`."
+	msgSpinmintReaperPaused  = "Paused the test server reaper. No test server will be destroyed for being too old until it is resumed with `/spinmint reaper resume`."
+	msgSpinmintReaperResumed = "Resumed the test server reaper."
+	msgSpinmintReaperError   = "Error trying to change the state of the test server reaper."
+)
+
+func (s *Server) isSpinmintReaperPaused() (bool, error) {
+	value, err := s.Store.System().Get(systemSpinmintReaperPaused)
+	if err != nil || value == "" {
+		return false, err
+	}
+	return strconv.ParseBool(value)
+}
+
+func (s *Server) setSpinmintReaperPaused(paused bool) error {
+	return s.Store.System().Save(systemSpinmintReaperPaused, strconv.FormatBool(paused))
+}
+
+// handleSpinmintReaper pauses or resumes the reaper of expired spinmints for all PRs.
+func (s *Server) handleSpinmintReaper(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	var paused bool
+	switch getSpinmintReaperAction(body) {
+	case "pause":
+		paused = true
+		msg = msgSpinmintReaperPaused
+	case "resume":
+		msg = msgSpinmintReaperResumed
+	default:
+		msg = msgSpinmintReaperUsage
+		return nil
+	}
+
+	if err := s.setSpinmintReaperPaused(paused); err != nil {
+		msg = msgSpinmintReaperError
+		return err
+	}
+	mlog.Warn("Changed the state of the spinmint reaper", mlog.Bool("paused", paused), mlog.String("by", commenter))
+	return nil
+}
+
+// getSpinmintReaperAction returns the word following "/spinmint reaper" in body.
+func getSpinmintReaperAction(body string) string {
+	i := strings.Index(body, "/spinmint reaper")
+	if i < 0 {
+		return ""
+	}
+	fields := strings.Fields(body[i+len("/spinmint reaper"):])
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpinmintReaperAction(t *testing.T) {
+	assert.Equal(t, "pause", getSpinmintReaperAction("/spinmint reaper pause"))
+	assert.Equal(t, "resume", getSpinmintReaperAction("please /spinmint reaper Resume now"))
+	assert.Equal(t, "", getSpinmintReaperAction("/spinmint reaper"))
+	assert.Equal(t, "", getSpinmintReaperAction("/spinmint history"))
+}
+
+func TestHandleSpinmintReaper(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sys := stmock.NewMockSystemStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().System().Return(sys).AnyTimes()
+
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server", Number: 123}
+	expectComment := func(msg string) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+	}
+
+	t.Run("random user", func(t *testing.T) {
+		expectComment(msgSpinmintMaintainerOnly)
+		require.NoError(t, s.handleSpinmintReaper(ctx, "someone", "/spinmint reaper pause", pr))
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		expectComment(msgSpinmintReaperUsage)
+		require.NoError(t, s.handleSpinmintReaper(ctx, "maintainer", "/spinmint reaper stop", pr))
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		sys.EXPECT().Save(systemSpinmintReaperPaused, "true").Return(nil)
+		expectComment(msgSpinmintReaperPaused)
+		require.NoError(t, s.handleSpinmintReaper(ctx, "maintainer", "/spinmint reaper pause", pr))
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		sys.EXPECT().Save(systemSpinmintReaperPaused, "false").Return(nil)
+		expectComment(msgSpinmintReaperResumed)
+		require.NoError(t, s.handleSpinmintReaper(ctx, "maintainer", "/spinmint reaper resume", pr))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		sys.EXPECT().Save(systemSpinmintReaperPaused, "true").Return(errors.New("some error"))
+		expectComment(msgSpinmintReaperError)
+		require.Error(t, s.handleSpinmintReaper(ctx, "maintainer", "/spinmint reaper pause", pr))
+	})
+}
+
+func TestCheckTestServerLifeTimePaused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sys := stmock.NewMockSystemStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	ss.EXPECT().System().Return(sys).AnyTimes()
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	metricsMock.EXPECT().ObserveCronTaskDuration(gomock.Any(), gomock.Any()).AnyTimes()
+
+	s := &Server{
+		Config:  &Config{SpinmintExpirationHour: 2},
+		Store:   ss,
+		Metrics: metricsMock,
+	}
+
+	// No spinmint is even listed while the reaper is paused.
+	sys.EXPECT().Get(systemSpinmintReaperPaused).Return("true", nil)
+	s.CheckTestServerLifeTime()
+}
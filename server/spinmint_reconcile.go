@@ -0,0 +1,116 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+const msgSpinmintStuckDestroyed = "The test server `%s` was stuck in the `%s` state and has been destroyed. To create a new test server, remove and add the `%s` label again."
+
+// setSpinmintState records the state of a spinmint. Failures are only logged since
+// the state is informational for the setup flows.
+func (s *Server) setSpinmintState(instanceID, state string) {
+	if err := s.Store.Spinmint().UpdateState(instanceID, state); err != nil {
+		mlog.Warn("Unable to update the spinmint state", mlog.String("instance", instanceID), mlog.String("state", state), mlog.Err(err))
+	}
+}
+
+func (s *Server) getSpinmintStuckTTL() time.Duration {
+	if s.Config.SpinmintStuckTTLMinutes > 0 {
+		return time.Duration(s.Config.SpinmintStuckTTLMinutes) * time.Minute
+	}
+	return defaultSpinmintStuckTTL * time.Second
+}
+
+// ReconcileSpinmints cleans up the spinmints stuck in setup. Those still being set up are left
+// to their flow until SpinmintStuckTTLMinutes after they entered setup, then destroyed with their
+// records; their setup is not resumed. Spinmints left behind while being destroyed are destroyed
+// again. Failed spinmints, kept for debugging, are left to the reaper of expired spinmints.
+// Nothing is destroyed while the reaper is paused.
+func (s *Server) ReconcileSpinmints() {
+	mlog.Info("Reconciling spinmints...")
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCronTaskTimeout*time.Second)
+	defer cancel()
+	defer func() {
+		elapsed := float64(time.Since(start)) / float64(time.Second)
+		s.Metrics.ObserveCronTaskDuration("reconcile_spinmints", elapsed)
+	}()
+
+	paused, err := s.isSpinmintReaperPaused()
+	if err != nil {
+		mlog.Error("Unable to check if the test server reaper is paused", mlog.Err(err))
+		s.Metrics.IncreaseCronTaskErrors("reconcile_spinmints")
+		return
+	}
+	if paused {
+		mlog.Warn("The test server reaper is PAUSED, no stuck test server will be destroyed. Resume it with /spinmint reaper resume")
+		return
+	}
+
+	spinmints, err := s.Store.Spinmint().List()
+	if err != nil {
+		mlog.Error("Unable to list the spinmints to reconcile", mlog.Err(err))
+		s.Metrics.IncreaseCronTaskErrors("reconcile_spinmints")
+		return
+	}
+
+	ttl := s.getSpinmintStuckTTL()
+	for _, spinmint := range spinmints {
+		switch {
+		case spinmint.State == "" || spinmint.State == model.SpinmintStateStable || spinmint.State == model.SpinmintStateFailed:
+			continue
+		case spinmint.State != model.SpinmintStateDeleting && time.Since(spinmint.GetStateChangedAt()) < ttl:
+			mlog.Debug("Spinmint is still being set up", mlog.String("instance", spinmint.InstanceID), mlog.String("state", spinmint.State))
+			continue
+		}
+
+		mlog.Warn("Cleaning up stuck spinmint", mlog.String("instance", spinmint.InstanceID), mlog.String("state", spinmint.State), mlog.Int("pr", spinmint.Number), mlog.String("repo_name", spinmint.RepoName))
+		pr := &model.PullRequest{
+			RepoOwner: spinmint.RepoOwner,
+			RepoName:  spinmint.RepoName,
+			Number:    spinmint.Number,
+		}
+		if spinmint.State != model.SpinmintStateDeleting {
+			msg := fmt.Sprintf(msgSpinmintStuckDestroyed, spinmint.InstanceID, spinmint.State, s.Config.SetupSpinmintTag)
+			if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); errComment != nil {
+				mlog.Warn("Error while commenting", mlog.Err(errComment))
+			}
+		}
+		if s.isSpinmintInstanceGone(ctx, spinmint) {
+			// Nothing is left to terminate, only the record.
+			s.removeTestServerFromDB(spinmint.InstanceID)
+			continue
+		}
+		s.destroySpinmint(pr, spinmint)
+	}
+}
+
+// isSpinmintInstanceGone is true if the instance of the spinmint is terminated or no longer exists.
+func (s *Server) isSpinmintInstanceGone(ctx context.Context, spinmint *model.Spinmint) bool {
+	resp, err := s.getSpinmintEC2Client(spinmint.Region).DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []*string{aws.String(spinmint.InstanceID)},
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == "InvalidInstanceID.NotFound" {
+			return true
+		}
+		mlog.Warn("Unable to describe the spinmint instance", mlog.String("instance", spinmint.InstanceID), mlog.Err(err))
+		return false
+	}
+	if len(resp.Reservations) == 0 || len(resp.Reservations[0].Instances) == 0 {
+		return true
+	}
+	state := resp.Reservations[0].Instances[0].State
+	return state != nil && aws.StringValue(state.Name) == ec2.InstanceStateNameTerminated
+}
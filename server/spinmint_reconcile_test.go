@@ -0,0 +1,121 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSpinmintStuckTTL(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	assert.Equal(t, 2*time.Hour, s.getSpinmintStuckTTL())
+
+	s.Config.SpinmintStuckTTLMinutes = 30
+	assert.Equal(t, 30*time.Minute, s.getSpinmintStuckTTL())
+}
+
+func TestReconcileSpinmints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil).AnyTimes()
+	sys := stmock.NewMockSystemStore(ctrl)
+	sys.EXPECT().Get(systemSpinmintReaperPaused).Return("", nil)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	ss.EXPECT().System().Return(sys).AnyTimes()
+	is := mocks.NewMockIssuesService(ctrl)
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	metricsMock.EXPECT().ObserveCronTaskDuration("reconcile_spinmints", gomock.Any())
+
+	fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+	r53 := &fakeRoute53{records: make(map[string]string)}
+	s := &Server{
+		Config: &Config{
+			AWSDnsSuffix:            "spinmint.test",
+			SetupSpinmintTag:        "Setup Test Server",
+			SpinmintStuckTTLMinutes: 60,
+			SpinmintSizeLabelPrefix: "spinmint-size/",
+			SpinmintSizeAliases:     map[string]string{"large": "t3.large"},
+		},
+		Store:         ss,
+		EC2Client:     fake,
+		Route53Client: r53,
+		GithubClient:  &GithubClient{Issues: is},
+		Metrics:       metricsMock,
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	newSpinmint := func(id, state string, createdAt time.Time, instanceState string) *model.Spinmint {
+		fake.instances[id] = &fakeInstance{state: instanceState, tags: make(map[string]string)}
+		if instanceState == ec2.InstanceStateNameRunning {
+			r53.records[id+".spinmint.test"] = "203.0.113.10"
+		}
+		return &model.Spinmint{InstanceID: id, RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, CreatedAt: createdAt, State: state}
+	}
+	// An old spinmint being set up again entered setup recently.
+	reinit := newSpinmint("i-reinit", model.SpinmintStateCreating, old, ec2.InstanceStateNameRunning)
+	reinitAt := time.Now()
+	reinit.StateChangedAt = &reinitAt
+	// The stuck spinmint is sized from the labels it was created with, as its PR is not fetched.
+	stuck := newSpinmint("i-stuck", model.SpinmintStateCreating, old, ec2.InstanceStateNameRunning)
+	stuck.Labels = []string{"Setup Test Server", "spinmint-size/large"}
+	spinmints := []*model.Spinmint{
+		newSpinmint("i-legacy", "", old, ec2.InstanceStateNameRunning),
+		newSpinmint("i-stable", model.SpinmintStateStable, old, ec2.InstanceStateNameRunning),
+		newSpinmint("i-creating", model.SpinmintStateCreating, time.Now(), ec2.InstanceStateNameRunning),
+		reinit,
+		newSpinmint("i-failed", model.SpinmintStateFailed, old, ec2.InstanceStateNameRunning),
+		stuck,
+		newSpinmint("i-deleting", model.SpinmintStateDeleting, time.Now(), ec2.InstanceStateNameTerminated),
+	}
+	sms.EXPECT().List().Return(spinmints, nil)
+
+	// The stuck spinmint is reported on its PR and destroyed.
+	msg := fmt.Sprintf(msgSpinmintStuckDestroyed, "i-stuck", model.SpinmintStateCreating, "Setup Test Server")
+	is.EXPECT().CreateComment(gomock.Any(), "mattertest", serverRepoName, 123, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+	sms.EXPECT().UpdateState("i-stuck", model.SpinmintStateDeleting).Return(nil)
+	sms.EXPECT().Archive("i-stuck", "t3.large", gomock.Any()).Return(nil)
+	sms.EXPECT().Delete("i-stuck").Return(nil)
+	metricsMock.EXPECT().IncreaseSpinmintEvents(spinmintEventDestroyed, serverRepoName, "t3.large")
+	// The instance left while being destroyed is gone already, so only its record is removed.
+	sms.EXPECT().Delete("i-deleting").Return(nil)
+
+	s.ReconcileSpinmints()
+
+	assert.Equal(t, ec2.InstanceStateNameTerminated, fake.state("i-stuck"))
+	assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-creating"))
+	assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-legacy"))
+	assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-reinit"))
+	assert.Equal(t, ec2.InstanceStateNameRunning, fake.state("i-failed"))
+	assert.NotContains(t, r53.records, "i-stuck.spinmint.test")
+	assert.Len(t, r53.records, 5)
+}
+
+func TestReconcileSpinmintsReaperPaused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sys := stmock.NewMockSystemStore(ctrl)
+	sys.EXPECT().Get(systemSpinmintReaperPaused).Return("true", nil)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().System().Return(sys).AnyTimes()
+	metricsMock := mocks.NewMockMetricsProvider(ctrl)
+	metricsMock.EXPECT().ObserveCronTaskDuration("reconcile_spinmints", gomock.Any())
+
+	// No spinmint is listed, let alone destroyed.
+	s := &Server{Config: &Config{}, Store: ss, Metrics: metricsMock}
+	s.ReconcileSpinmints()
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+)
+
+// SpinmintRegion places spinmints in another AWS region. The image, security group and subnet
+// only exist in their region, so they must all be set. The DNS settings fall back to the default ones.
+type SpinmintRegion struct {
+	AWSRegion        string
+	AWSImageID       string
+	AWSSecurityGroup string
+	AWSSubNetID      string
+	AWSHostedZoneID  string
+	AWSDnsSuffix     string
+}
+
+// SpinmintDNSZone is a Route53 hosted zone spinmints get a record in.
+type SpinmintDNSZone struct {
+	AWSHostedZoneID string
+	AWSDnsSuffix    string
+}
+
+// validate rejects a zone records can't be created in.
+func (z *SpinmintDNSZone) validate() error {
+	switch {
+	case z == nil:
+		return errors.New("no settings")
+	case z.AWSHostedZoneID == "":
+		return errors.New("AWSHostedZoneID is not set")
+	case z.AWSDnsSuffix == "":
+		return errors.New("AWSDnsSuffix is not set")
+	}
+	return nil
+}
+
+// getSpinmintRegionName returns the configured region the labels ask for.
+// It is empty for the default region.
+func (s *Server) getSpinmintRegionName(labels []string) string {
+	if s.Config.SpinmintRegionLabelPrefix == "" {
+		return ""
+	}
+	for _, label := range labels {
+		if !strings.HasPrefix(label, s.Config.SpinmintRegionLabelPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(label, s.Config.SpinmintRegionLabelPrefix)
+		if _, ok := s.Config.SpinmintRegions[name]; ok {
+			return name
+		}
+		mlog.Warn("Unknown spinmint region, using the default one", mlog.String("region", name))
+	}
+	return ""
+}
+
+// validate rejects a region missing the settings that can't be shared with the default region.
+func (r *SpinmintRegion) validate() error {
+	if r == nil {
+		return errors.New("no settings")
+	}
+	switch {
+	case r.AWSRegion == "":
+		return errors.New("AWSRegion is not set")
+	case r.AWSImageID == "":
+		return errors.New("AWSImageID is not set")
+	case r.AWSSecurityGroup == "":
+		return errors.New("AWSSecurityGroup is not set")
+	case r.AWSSubNetID == "":
+		return errors.New("AWSSubNetID is not set")
+	}
+	return nil
+}
+
+// getSpinmintRegion returns the settings of the named region, completed with the defaults.
+func (s *Server) getSpinmintRegion(name string) *SpinmintRegion {
+	region := SpinmintRegion{
+		AWSRegion:        s.Config.AWSRegion,
+		AWSImageID:       s.Config.AWSImageID,
+		AWSSecurityGroup: s.Config.AWSSecurityGroup,
+		AWSSubNetID:      s.Config.AWSSubNetID,
+		AWSHostedZoneID:  s.Config.AWSHostedZoneID,
+		AWSDnsSuffix:     s.Config.AWSDnsSuffix,
+	}
+	override, ok := s.Config.SpinmintRegions[name]
+	if name == "" || !ok || override == nil {
+		return &region
+	}
+
+	if override.AWSRegion != "" {
+		region.AWSRegion = override.AWSRegion
+	}
+	if override.AWSImageID != "" {
+		region.AWSImageID = override.AWSImageID
+	}
+	if override.AWSSecurityGroup != "" {
+		region.AWSSecurityGroup = override.AWSSecurityGroup
+	}
+	if override.AWSSubNetID != "" {
+		region.AWSSubNetID = override.AWSSubNetID
+	}
+	if override.AWSHostedZoneID != "" {
+		region.AWSHostedZoneID = override.AWSHostedZoneID
+	}
+	if override.AWSDnsSuffix != "" {
+		region.AWSDnsSuffix = override.AWSDnsSuffix
+	}
+	return &region
+}
+
+// getSpinmintEC2Client returns the EC2 client for the named region.
+func (s *Server) getSpinmintEC2Client(name string) ec2iface.EC2API {
+	if client, ok := s.RegionEC2Clients[name]; ok {
+		return client
+	}
+	return s.EC2Client
+}
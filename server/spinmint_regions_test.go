@@ -0,0 +1,105 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpinmintRegion(t *testing.T) {
+	s := &Server{Config: &Config{
+		AWSRegion:       "us-east-1",
+		AWSImageID:      "ami-east",
+		AWSSubNetID:     "subnet-east",
+		AWSHostedZoneID: "zone-east",
+		AWSDnsSuffix:    "test.mattermost.com",
+		SpinmintRegions: map[string]*SpinmintRegion{
+			"us-west": {AWSRegion: "us-west-2", AWSImageID: "ami-west", AWSSecurityGroup: "sg-west", AWSSubNetID: "subnet-west", AWSDnsSuffix: "west.test.mattermost.com"},
+		},
+	}}
+	labels := []string{"Setup Test Server", "Spinmint/region:us-west"}
+
+	assert.Equal(t, "", s.getSpinmintRegionName(labels))
+
+	s.Config.SpinmintRegionLabelPrefix = "Spinmint/region:"
+	assert.Equal(t, "us-west", s.getSpinmintRegionName(labels))
+	assert.Equal(t, "", s.getSpinmintRegionName([]string{"Spinmint/region:mars"}))
+
+	region := s.getSpinmintRegion("us-west")
+	assert.Equal(t, "us-west-2", region.AWSRegion)
+	assert.Equal(t, "ami-west", region.AWSImageID)
+	assert.Equal(t, "sg-west", region.AWSSecurityGroup)
+	assert.Equal(t, "subnet-west", region.AWSSubNetID)
+	assert.Equal(t, "zone-east", region.AWSHostedZoneID)
+	assert.Equal(t, "west.test.mattermost.com", region.AWSDnsSuffix)
+
+	assert.Equal(t, "us-east-1", s.getSpinmintRegion("").AWSRegion)
+	assert.Equal(t, "http://i-123.west.test.mattermost.com", s.getSpinmintURL("us-west", "i-123"))
+	assert.Equal(t, []string{"west.test.mattermost.com"}, s.getSpinmintDNSSuffixes("us-west"))
+}
+
+func TestSpinmintRegionFlow(t *testing.T) {
+	defer func(delay time.Duration) { spinmintTagDelay = delay }(spinmintTagDelay)
+	spinmintTagDelay = 0
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil).AnyTimes()
+	sms.EXPECT().UpdateState(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	defaultEC2 := newFakeEC2(0, ec2.InstanceStateNameRunning)
+	westEC2 := newFakeEC2(0, ec2.InstanceStateNameRunning)
+	r53 := &fakeRoute53{records: make(map[string]string)}
+	is := mocks.NewMockIssuesService(ctrl)
+	s := &Server{
+		Config: &Config{
+			AWSDnsSuffix:              "spinmint.test",
+			SpinmintRegionLabelPrefix: "Spinmint/region:",
+			SpinmintRegions:           map[string]*SpinmintRegion{"us-west": {AWSDnsSuffix: "west.spinmint.test"}},
+		},
+		Store:            ss,
+		EC2Client:        defaultEC2,
+		RegionEC2Clients: map[string]ec2iface.EC2API{"us-west": westEC2},
+		Route53Client:    r53,
+		GithubClient:     &GithubClient{Issues: is},
+	}
+
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: serverRepoName, Number: 123, Sha: "abcdef", Labels: []string{"Spinmint/region:us-west"}}
+	repo := &Repository{InstanceSetupScript: "../../config/instance-setup.sh"}
+	ctx := context.Background()
+
+	sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+	sms.EXPECT().Create(gomock.AssignableToTypeOf(&model.Spinmint{})).DoAndReturn(func(spinmint *model.Spinmint) (*model.Spinmint, error) {
+		assert.Equal(t, "us-west", spinmint.Region)
+		return spinmint, nil
+	})
+
+	is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, gomock.Any()).Return(nil, nil, nil)
+
+	s.setupSpinmintForPR(ctx, pr, repo, false, "", nil)
+	require.Len(t, westEC2.instances, 1)
+	assert.Empty(t, defaultEC2.instances)
+	assert.Equal(t, "203.0.113.10", r53.records["i-fake1.west.spinmint.test"])
+
+	sms.EXPECT().Archive("i-fake1", "", gomock.Any()).Return(nil)
+	sms.EXPECT().Delete("i-fake1").Return(nil)
+	s.destroySpinmint(pr, &model.Spinmint{InstanceID: "i-fake1", Region: "us-west"})
+	assert.Equal(t, ec2.InstanceStateNameTerminated, westEC2.state("i-fake1"))
+	assert.Empty(t, r53.records)
+}
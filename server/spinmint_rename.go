@@ -0,0 +1,152 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/store"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+)
+
+const spinmintActionRenamed = "renamed"
+
+const (
+	msgSpinmintRenameUsage   = "Please specify the new subdomain of the test server, e.g. `/spinmint rename my-feature`."
+	msgSpinmintRenameInvalid = "`%s` is not a valid subdomain. Use up to 63 lowercase letters, digits and hyphens, not starting or ending with a hyphen nor starting with `i-`."
+	msgSpinmintRenameTaken   = "The subdomain `%s` is already used by another test server."
+	msgSpinmintRenameError   = "Error trying to rename the test server. It is still available at %s"
+	msgSpinmintRenamed       = "The test server is now available at %s"
+	msgSpinmintRenamedNoSite = "The test server is now available at %s, but its Site URL could not be updated, so the links it generates still use the old address."
+)
+
+// dnsLabelRegexp matches a lowercase DNS label as defined by RFC 1123.
+var dnsLabelRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// isValidSpinmintSubdomain is true if name can be used as the subdomain of a spinmint.
+// Names starting with "i-" are kept for the instance IDs spinmints are named after by default.
+func isValidSpinmintSubdomain(name string) bool {
+	return dnsLabelRegexp.MatchString(name) && !strings.HasPrefix(name, "i-")
+}
+
+// getSpinmintRenameTarget returns the subdomain named after "/spinmint rename", lowercased.
+func getSpinmintRenameTarget(body string) string {
+	index := strings.Index(body, "/spinmint rename")
+	if index < 0 {
+		return ""
+	}
+	args := strings.Fields(body[index+len("/spinmint rename"):])
+	if len(args) == 0 {
+		return ""
+	}
+	return strings.ToLower(args[0])
+}
+
+// handleSpinmintRename moves the test server of a PR to another subdomain. The new record is
+// created and checked before the old one is removed, so the server stays reachable if it fails.
+func (s *Server) handleSpinmintRename(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	subdomain := getSpinmintRenameTarget(body)
+	if subdomain == "" {
+		msg = msgSpinmintRenameUsage
+		return nil
+	}
+	if !isValidSpinmintSubdomain(subdomain) {
+		msg = fmt.Sprintf(msgSpinmintRenameInvalid, subdomain)
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+	oldSubdomain := spinmint.GetSubdomain()
+	if subdomain == oldSubdomain {
+		msg = fmt.Sprintf(msgSpinmintRenamed, s.getSpinmintURL(spinmint.Region, subdomain))
+		return nil
+	}
+
+	msg = fmt.Sprintf(msgSpinmintRenameError, s.getSpinmintURL(spinmint.Region, oldSubdomain))
+	publicIP, _ := s.getIPsForInstance(ctx, spinmint.Region, spinmint.InstanceID)
+	if publicIP == "" {
+		return errors.Errorf("unable to get the public IP of instance %s", spinmint.InstanceID)
+	}
+	// The store keeps subdomains unique in a region, so recording it first reserves it.
+	if err = s.Store.Spinmint().UpdateSubdomain(spinmint.InstanceID, subdomain); err != nil {
+		if errors.Is(err, store.ErrSpinmintSubdomainTaken) {
+			msg = fmt.Sprintf(msgSpinmintRenameTaken, subdomain)
+			return nil
+		}
+		return err
+	}
+	if err = s.updateRoute53Subdomain(ctx, spinmint.Region, subdomain, publicIP, "UPSERT"); err != nil {
+		s.restoreSpinmintSubdomain(spinmint)
+		return errors.Wrapf(err, "unable to create the subdomain %s", subdomain)
+	}
+	if err = s.waitForSpinmintSubdomain(ctx, pr, spinmint.Region, subdomain); err != nil {
+		if errDelete := s.updateRoute53Subdomain(ctx, spinmint.Region, subdomain, publicIP, "DELETE"); errDelete != nil {
+			mlog.Warn("Unable to remove the new subdomain", mlog.String("subdomain", subdomain), mlog.Err(errDelete))
+		}
+		s.restoreSpinmintSubdomain(spinmint)
+		return err
+	}
+	if err = s.updateRoute53Subdomain(ctx, spinmint.Region, oldSubdomain, publicIP, "DELETE"); err != nil {
+		mlog.Warn("Unable to remove the old subdomain", mlog.String("subdomain", oldSubdomain), mlog.Err(err))
+	}
+
+	mlog.Info("Renamed spinmint", mlog.String("instance", spinmint.InstanceID), mlog.String("from", oldSubdomain), mlog.String("to", subdomain))
+	s.logSpinmintAction(spinmintActionRenamed, pr, spinmint.InstanceID)
+
+	// The setup script wrote the old URL as the SiteURL, which Mattermost uses for its links and redirects.
+	newURL := s.getSpinmintURL(spinmint.Region, subdomain)
+	if _, _, err = s.setSpinmintConfigValue(newURL, "ServiceSettings.SiteURL", newURL); err != nil {
+		mlog.Warn("Unable to update the SiteURL of the renamed spinmint", mlog.String("instance", spinmint.InstanceID), mlog.Err(err))
+		msg = fmt.Sprintf(msgSpinmintRenamedNoSite, newURL)
+		return nil
+	}
+	msg = fmt.Sprintf(msgSpinmintRenamed, newURL)
+	return nil
+}
+
+// restoreSpinmintSubdomain gives the spinmint back the subdomain it had before a failed rename.
+func (s *Server) restoreSpinmintSubdomain(spinmint *model.Spinmint) {
+	if err := s.Store.Spinmint().UpdateSubdomain(spinmint.InstanceID, spinmint.Subdomain); err != nil {
+		mlog.Warn("Unable to restore the subdomain of the spinmint", mlog.String("instance", spinmint.InstanceID), mlog.Err(err))
+	}
+}
+
+// waitForSpinmintSubdomain waits until the new subdomain of a spinmint passes the readiness
+// check, or at least resolves if none is configured.
+func (s *Server) waitForSpinmintSubdomain(ctx context.Context, pr *model.PullRequest, region, subdomain string) error {
+	check := s.getSpinmintReadinessCheck(pr)
+	if check == "" {
+		check = spinmintReadinessResolve
+	}
+	// The record only exists under the domain of the region, not under the fallbacks.
+	suffixes := []string{s.getSpinmintRegion(region).AWSDnsSuffix}
+	_, err := s.waitForSpinmintHosts(ctx, check, subdomain, suffixes, s.getSpinmintCreationTimeout(nil))
+	return err
+}
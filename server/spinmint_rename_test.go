@@ -0,0 +1,158 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	"github.com/mattermost/mattermost-mattermod/store"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsValidSpinmintSubdomain(t *testing.T) {
+	for _, name := range []string{"a", "my-feature", "pr123", strings.Repeat("a", 63)} {
+		assert.True(t, isValidSpinmintSubdomain(name), name)
+	}
+	for _, name := range []string{"", "-feature", "feature-", "My-Feature", "my_feature", "my.feature", "i-0123456789", strings.Repeat("a", 64)} {
+		assert.False(t, isValidSpinmintSubdomain(name), name)
+	}
+}
+
+func TestGetSpinmintRenameTarget(t *testing.T) {
+	assert.Equal(t, "my-feature", getSpinmintRenameTarget("/spinmint rename My-Feature"))
+	assert.Equal(t, "", getSpinmintRenameTarget("/spinmint rename"))
+}
+
+func TestHandleSpinmintRename(t *testing.T) {
+	defer func(interval time.Duration) { spinmintReadinessPollInterval = interval }(spinmintReadinessPollInterval)
+	spinmintReadinessPollInterval = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The new host is the subdomain followed by the DNS suffix, so subdomain "127"
+	// with this suffix points at the test server.
+	var siteURL interface{}
+	failConfig := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v4/users/login":
+			w.Header().Set("Token", "token")
+			_, _ = w.Write([]byte(`{"id":"user-id"}`))
+		case r.URL.Path == "/api/v4/config" && failConfig:
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.URL.Path == "/api/v4/config" && r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"ServiceSettings":{"SiteURL":"http://i-fake1.invalid"}}`))
+		case r.URL.Path == "/api/v4/config" && r.Method == http.MethodPut:
+			var updated map[string]interface{}
+			body, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(body, &updated))
+			siteURL = updated["ServiceSettings"].(map[string]interface{})["SiteURL"]
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	suffix := strings.TrimPrefix(ts.URL, "http://127.")
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().LogAction(gomock.AssignableToTypeOf(&model.SpinmintAction{})).Return(nil).AnyTimes()
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	fake := newFakeEC2(0, ec2.InstanceStateNameRunning)
+	fake.instances["i-fake1"] = &fakeInstance{state: ec2.InstanceStateNameRunning}
+	r53 := &fakeRoute53{records: map[string]string{"i-fake1." + suffix: "203.0.113.10"}}
+	s := &Server{
+		Config:        &Config{AWSDnsSuffix: suffix, SpinmintReadinessCheck: spinmintReadinessTCP, SpinmintCreationTimeoutSeconds: 1},
+		GithubClient:  &GithubClient{Issues: is},
+		Store:         ss,
+		EC2Client:     fake,
+		Route53Client: r53,
+		OrgMembers:    []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattermost", RepoName: "mattermost-server", Number: 123}
+	spinmint := &model.Spinmint{InstanceID: "i-fake1", RepoOwner: pr.RepoOwner, RepoName: pr.RepoName, Number: pr.Number}
+	expectComment := func(msg string) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+	}
+
+	t.Run("random user", func(t *testing.T) {
+		expectComment(msgSpinmintMaintainerOnly)
+		require.NoError(t, s.handleSpinmintRename(ctx, "someone", "/spinmint rename 127", pr))
+	})
+
+	t.Run("invalid subdomain", func(t *testing.T) {
+		expectComment(fmt.Sprintf(msgSpinmintRenameInvalid, "my_feature"))
+		require.NoError(t, s.handleSpinmintRename(ctx, "maintainer", "/spinmint rename my_feature", pr))
+	})
+
+	t.Run("no spinmint", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+		expectComment(msgSpinmintNotFound)
+		require.NoError(t, s.handleSpinmintRename(ctx, "maintainer", "/spinmint rename 127", pr))
+	})
+
+	t.Run("subdomain taken", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		sms.EXPECT().UpdateSubdomain("i-fake1", "127").Return(store.ErrSpinmintSubdomainTaken)
+		expectComment(fmt.Sprintf(msgSpinmintRenameTaken, "127"))
+		require.NoError(t, s.handleSpinmintRename(ctx, "maintainer", "/spinmint rename 127", pr))
+	})
+
+	t.Run("renamed", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		sms.EXPECT().UpdateSubdomain("i-fake1", "127").Return(nil)
+		expectComment(fmt.Sprintf(msgSpinmintRenamed, "http://127."+suffix))
+		require.NoError(t, s.handleSpinmintRename(ctx, "maintainer", "/spinmint rename 127", pr))
+		assert.Equal(t, map[string]string{"127." + suffix: "203.0.113.10"}, r53.records)
+		assert.Equal(t, "http://127."+suffix, siteURL)
+	})
+
+	t.Run("site url not updated", func(t *testing.T) {
+		failConfig = true
+		defer func() { failConfig = false }()
+		r53.records = map[string]string{"i-fake1." + suffix: "203.0.113.10"}
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(spinmint, nil)
+		sms.EXPECT().UpdateSubdomain("i-fake1", "127").Return(nil)
+		expectComment(fmt.Sprintf(msgSpinmintRenamedNoSite, "http://127."+suffix))
+		require.NoError(t, s.handleSpinmintRename(ctx, "maintainer", "/spinmint rename 127", pr))
+		assert.Equal(t, map[string]string{"127." + suffix: "203.0.113.10"}, r53.records)
+	})
+
+	t.Run("new subdomain unreachable", func(t *testing.T) {
+		renamed := *spinmint
+		renamed.Subdomain = "127"
+		s.Config.AWSDnsSuffix = "invalid.invalid"
+		r53.records = map[string]string{"127.invalid.invalid": "203.0.113.10"}
+
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(&renamed, nil)
+		// The new subdomain is reserved, then given back.
+		sms.EXPECT().UpdateSubdomain("i-fake1", "other").Return(nil)
+		sms.EXPECT().UpdateSubdomain("i-fake1", "127").Return(nil)
+		expectComment(fmt.Sprintf(msgSpinmintRenameError, "http://127.invalid.invalid"))
+		require.Error(t, s.handleSpinmintRename(ctx, "maintainer", "/spinmint rename other", pr))
+		assert.Equal(t, map[string]string{"127.invalid.invalid": "203.0.113.10"}, r53.records)
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	msgSpinmintSmokeTestFailed    = "The test server came up but failed the smoke test at step `%s`: %s"
+	msgSpinmintSmokeTestKept      = "It is kept for debugging at %s. Please remove the `%s` label to destroy it once done."
+	msgSpinmintSmokeTestDestroyed = "It was destroyed. Please fix the PR and push a new commit, or try again with `/spinmint create`."
+)
+
+// SpinmintSmokeTestStep is an API call a new spinmint must answer successfully. REPO_NAME, PR_NUMBER
+// and {step.field}, a field of the JSON response of an earlier named step, are filled in the path and body.
+// They are escaped for a URL path in the path, and for a JSON string in the body.
+type SpinmintSmokeTestStep struct {
+	Name           string
+	Method         string // Method defaults to GET.
+	Path           string // Path is relative to the site URL, like /api/v4/users/me.
+	Body           string
+	ExpectedStatus int // ExpectedStatus is the http status the step must return. Any 2xx status succeeds if unset.
+}
+
+// spinmintSmokeTestRef matches the {step.field} references to the responses of earlier smoke test steps.
+var spinmintSmokeTestRef = regexp.MustCompile(`{([A-Za-z0-9_-]+)\.([A-Za-z0-9_]+)}`)
+
+// spinmintSmokeTestError is a smoke test step that did not succeed.
+type spinmintSmokeTestError struct {
+	Step string
+	Err  error
+}
+
+func (e *spinmintSmokeTestError) Error() string {
+	return fmt.Sprintf("smoke test step %s failed: %s", e.Step, e.Err)
+}
+
+// runSpinmintSmokeTest runs the SpinmintSmokeTest steps against the spinmint at siteURL,
+// logged in as its system admin, and stops at the first one that fails.
+func (s *Server) runSpinmintSmokeTest(ctx context.Context, pr *model.PullRequest, siteURL string) error {
+	client, err := s.newSpinmintAdminClient(siteURL)
+	if err != nil {
+		return &spinmintSmokeTestError{Step: "login", Err: err}
+	}
+
+	responses := make(map[string]map[string]interface{})
+	for i, step := range s.Config.SpinmintSmokeTest {
+		name := step.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		response, errStep := s.runSpinmintSmokeTestStep(ctx, pr, client.Url, client.AuthType+" "+client.AuthToken, step, responses)
+		if errStep != nil {
+			return &spinmintSmokeTestError{Step: name, Err: errStep}
+		}
+		if step.Name != "" {
+			responses[step.Name] = response
+		}
+	}
+	return nil
+}
+
+// runSpinmintSmokeTestStep sends the request of step and returns its JSON response, if it is an object.
+func (s *Server) runSpinmintSmokeTestStep(ctx context.Context, pr *model.PullRequest, siteURL, authorization string, step *SpinmintSmokeTestStep, responses map[string]map[string]interface{}) (map[string]interface{}, error) {
+	path, err := expandSpinmintSmokeTestValue(pr, step.Path, responses, url.PathEscape)
+	if err != nil {
+		return nil, err
+	}
+	body, err := expandSpinmintSmokeTestValue(pr, step.Body, responses, escapeJSONString)
+	if err != nil {
+		return nil, err
+	}
+
+	method := step.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), siteURL+"/"+strings.TrimPrefix(path, "/"), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	r, err := s.doSpinmintRequest(s.spinmintHTTPClient(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(r)
+
+	if step.ExpectedStatus != 0 && r.StatusCode != step.ExpectedStatus {
+		return nil, errors.Errorf("%s %s returned http status %s, expected %d", req.Method, path, r.Status, step.ExpectedStatus)
+	}
+	if step.ExpectedStatus == 0 && (r.StatusCode < 200 || r.StatusCode > 299) {
+		return nil, errors.Errorf("%s %s returned http status %s", req.Method, path, r.Status)
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the response")
+	}
+	var response map[string]interface{}
+	if json.Unmarshal(b, &response) != nil {
+		// Only objects can be referenced by the next steps.
+		return nil, nil
+	}
+	return response, nil
+}
+
+// expandSpinmintSmokeTestValue fills REPO_NAME, PR_NUMBER and the {step.field} references in value,
+// each passed through escape.
+func expandSpinmintSmokeTestValue(pr *model.PullRequest, value string, responses map[string]map[string]interface{}, escape func(string) string) (string, error) {
+	value = strings.ReplaceAll(value, "REPO_NAME", escape(pr.RepoName))
+	value = strings.ReplaceAll(value, "PR_NUMBER", escape(strconv.Itoa(pr.Number)))
+
+	var err error
+	value = spinmintSmokeTestRef.ReplaceAllStringFunc(value, func(ref string) string {
+		m := spinmintSmokeTestRef.FindStringSubmatch(ref)
+		field, ok := responses[m[1]][m[2]]
+		if !ok {
+			if err == nil {
+				err = errors.Errorf("%s is not in the response of an earlier step", ref)
+			}
+			return ref
+		}
+		return escape(fmt.Sprint(field))
+	})
+	return value, err
+}
+
+// escapeJSONString escapes value to be put within a JSON string.
+func escapeJSONString(value string) string {
+	b, _ := json.Marshal(value)
+	return string(b[1 : len(b)-1])
+}
+
+// getSpinmintSmokeTestFailedMessage is commented on the PR when its spinmint failed the smoke test.
+func (s *Server) getSpinmintSmokeTestFailedMessage(err error, smLink string) string {
+	step, reason := "login", err.Error()
+	var smokeErr *spinmintSmokeTestError
+	if errors.As(err, &smokeErr) {
+		step, reason = smokeErr.Step, smokeErr.Err.Error()
+	}
+
+	msg := fmt.Sprintf(msgSpinmintSmokeTestFailed, step, reason) + "\n\n"
+	if s.Config.SpinmintKeepFailedSmokeTest {
+		return msg + fmt.Sprintf(msgSpinmintSmokeTestKept, smLink, s.Config.SetupSpinmintTag)
+	}
+	return msg + msgSpinmintSmokeTestDestroyed
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSmokeTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/users/login" {
+			w.Header().Set("Token", "token")
+			_, _ = w.Write([]byte(`{"id":"user-id"}`))
+			return
+		}
+		require.Equal(t, "BEARER token", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v4/teams/name/ad-1":
+			_, _ = w.Write([]byte(`{"id":"team-id"}`))
+		case "POST /api/v4/channels":
+			b, _ := ioutil.ReadAll(r.Body)
+			require.JSONEq(t, `{"team_id":"team-id","name":"smoke-123"}`, string(b))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"channel-id"}`))
+		case "POST /api/v4/posts":
+			b, _ := ioutil.ReadAll(r.Body)
+			require.JSONEq(t, `{"channel_id":"channel-id","message":"mattermost-server"}`, string(b))
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRunSpinmintSmokeTest(t *testing.T) {
+	ts := newSmokeTestServer(t)
+	defer ts.Close()
+
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+	team := &SpinmintSmokeTestStep{Name: "team", Path: "/api/v4/teams/name/ad-1"}
+	channel := &SpinmintSmokeTestStep{
+		Name:           "channel",
+		Method:         "post",
+		Path:           "/api/v4/channels",
+		Body:           `{"team_id":"{team.id}","name":"smoke-PR_NUMBER"}`,
+		ExpectedStatus: http.StatusCreated,
+	}
+	post := &SpinmintSmokeTestStep{Name: "post", Method: http.MethodPost, Path: "/api/v4/posts", Body: `{"channel_id":"{channel.id}","message":"REPO_NAME"}`}
+
+	t.Run("success", func(t *testing.T) {
+		s := &Server{Config: &Config{SpinmintSmokeTest: []*SpinmintSmokeTestStep{team, channel}}}
+		require.NoError(t, s.runSpinmintSmokeTest(context.Background(), pr, ts.URL))
+	})
+
+	for name, tc := range map[string]struct {
+		steps  []*SpinmintSmokeTestStep
+		step   string
+		reason string
+	}{
+		"failing step": {
+			steps:  []*SpinmintSmokeTestStep{team, channel, post},
+			step:   "post",
+			reason: "POST /api/v4/posts returned http status 500 Internal Server Error",
+		},
+		"unexpected status": {
+			steps:  []*SpinmintSmokeTestStep{{Path: "/api/v4/teams/name/ad-1", ExpectedStatus: http.StatusNoContent}},
+			step:   "1",
+			reason: "GET /api/v4/teams/name/ad-1 returned http status 200 OK, expected 204",
+		},
+		"unknown reference": {
+			steps:  []*SpinmintSmokeTestStep{channel},
+			step:   "channel",
+			reason: "{team.id} is not in the response of an earlier step",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{Config: &Config{SpinmintSmokeTest: tc.steps}}
+			err := s.runSpinmintSmokeTest(context.Background(), pr, ts.URL)
+			var smokeErr *spinmintSmokeTestError
+			require.True(t, errors.As(err, &smokeErr))
+			assert.Equal(t, tc.step, smokeErr.Step)
+			assert.EqualError(t, smokeErr.Err, tc.reason)
+		})
+	}
+
+	t.Run("login failure", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer down.Close()
+
+		s := &Server{Config: &Config{SpinmintSmokeTest: []*SpinmintSmokeTestStep{team}}}
+		err := s.runSpinmintSmokeTest(context.Background(), pr, down.URL)
+		var smokeErr *spinmintSmokeTestError
+		require.True(t, errors.As(err, &smokeErr))
+		assert.Equal(t, "login", smokeErr.Step)
+	})
+}
+
+func TestGetSpinmintSmokeTestFailedMessage(t *testing.T) {
+	err := errors.Wrap(&spinmintSmokeTestError{Step: "post", Err: errors.New("boom")}, "instance i-1 failed the smoke test")
+
+	s := &Server{Config: &Config{}}
+	assert.Equal(t, "The test server came up but failed the smoke test at step `post`: boom\n\n"+msgSpinmintSmokeTestDestroyed,
+		s.getSpinmintSmokeTestFailedMessage(err, "https://i-1.test.mattermost.com"))
+
+	s.Config.SpinmintKeepFailedSmokeTest = true
+	s.Config.SetupSpinmintTag = "Setup Test Server"
+	assert.Equal(t, "The test server came up but failed the smoke test at step `post`: boom\n\n"+
+		"It is kept for debugging at https://i-1.test.mattermost.com. Please remove the `Setup Test Server` label to destroy it once done.",
+		s.getSpinmintSmokeTestFailedMessage(err, "https://i-1.test.mattermost.com"))
+}
+
+func TestExpandSpinmintSmokeTestValue(t *testing.T) {
+	pr := &model.PullRequest{RepoName: "mattermost-server", Number: 123}
+	responses := map[string]map[string]interface{}{
+		"user": {"id": "user-id", "nickname": `say "hi"\now`, "position": "a/b c"},
+	}
+
+	body, err := expandSpinmintSmokeTestValue(pr, `{"message":"{user.nickname} in REPO_NAME"}`, responses, escapeJSONString)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"say \"hi\"\\now in mattermost-server"}`, body)
+
+	path, err := expandSpinmintSmokeTestValue(pr, "/api/v4/users/{user.id}/position/{user.position}", responses, url.PathEscape)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v4/users/user-id/position/a%2Fb%20c", path)
+}
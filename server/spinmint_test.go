@@ -0,0 +1,543 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpinmintFilestorePrefix(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	pr := &model.PullRequest{RepoName: "mattermost-server", Number: 1234}
+
+	assert.Equal(t, "", s.getSpinmintFilestorePrefix(pr))
+
+	s.Config.SpinmintFilestoreBucketPrefix = "staging"
+	assert.Equal(t, "staging/mattermost-server/1234", s.getSpinmintFilestorePrefix(pr))
+}
+
+func TestGetSpinmintLicense(t *testing.T) {
+	s := &Server{Config: &Config{}}
+
+	license, err := s.getSpinmintLicense()
+	require.NoError(t, err)
+	assert.Equal(t, "", license)
+
+	f, err := ioutil.TempFile("", "license")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("license-data\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s.Config.SpinmintLicenseFile = f.Name()
+	license, err = s.getSpinmintLicense()
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("license-data")), license)
+
+	s.Config.SpinmintLicenseFile = f.Name() + "-missing"
+	_, err = s.getSpinmintLicense()
+	require.Error(t, err)
+}
+
+func TestGetSpinmintClientToken(t *testing.T) {
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 1234, Sha: "abcdef"}
+	params := func() *ec2.RunInstancesInput {
+		return &ec2.RunInstancesInput{
+			ImageId:      aws.String("ami-123"),
+			InstanceType: aws.String("t3.large"),
+			UserData:     aws.String("c2V0dXAgYWJjZGVm"),
+		}
+	}
+
+	token := getSpinmintClientToken(pr, params())
+	assert.Len(t, token, 64)
+	assert.Equal(t, token, getSpinmintClientToken(pr, params()))
+
+	withToken := params()
+	withToken.ClientToken = aws.String(token)
+	assert.Equal(t, token, getSpinmintClientToken(pr, withToken))
+
+	// Reusing the token with other parameters would make EC2 reject the launch.
+	resized := params()
+	resized.InstanceType = aws.String("t3.xlarge")
+	assert.NotEqual(t, token, getSpinmintClientToken(pr, resized))
+
+	rescripted := params()
+	rescripted.UserData = aws.String("c2V0dXAgMTIzNDU2")
+	assert.NotEqual(t, token, getSpinmintClientToken(pr, rescripted))
+
+	other := *pr
+	other.Number = 1235
+	assert.NotEqual(t, token, getSpinmintClientToken(&other, params()))
+}
+
+func TestGetSpinmintReapedMessage(t *testing.T) {
+	s := &Server{Config: &Config{SetupSpinmintTag: "Setup Test Server"}}
+
+	assert.Equal(t, "fallback", s.getSpinmintReapedMessage("the PR was closed", "fallback", false))
+
+	s.Config.SpinmintReapedMessage = "Test server destroyed because REAP_REASON. RECREATE_INSTRUCTIONS"
+	assert.Equal(t,
+		"Test server destroyed because it was running for more than 24 hours. To create a new test server, comment `/spinmint create`, or remove and add the `Setup Test Server` label again.",
+		s.getSpinmintReapedMessage("it was running for more than 24 hours", "fallback", true))
+	assert.Equal(t,
+		"Test server destroyed because the PR was closed.",
+		s.getSpinmintReapedMessage("the PR was closed", "fallback", false))
+}
+
+func TestIsSpinmintDestroyedComment(t *testing.T) {
+	s := &Server{Config: &Config{SetupSpinmintTag: "Setup Test Server"}}
+	assert.False(t, s.isSpinmintDestroyedComment("Creating a test server."))
+
+	s.Config.DestroyedSpinmintMessage = "Test server destroyed"
+	assert.True(t, s.isSpinmintDestroyedComment("Test server destroyed"))
+	assert.False(t, s.isSpinmintDestroyedComment("Creating a test server."))
+
+	s.Config.SpinmintReapedMessage = "REAP_REASON, so the test server was removed. RECREATE_INSTRUCTIONS"
+	assert.True(t, s.isSpinmintDestroyedComment(s.getSpinmintReapedMessage("The PR was idle", "", true)))
+	assert.False(t, s.isSpinmintDestroyedComment("Creating a test server."))
+}
+
+func TestGetSpinmintDNSSuffixes(t *testing.T) {
+	s := &Server{Config: &Config{AWSDnsSuffix: "spinmint.com"}}
+	assert.Equal(t, []string{"spinmint.com"}, s.getSpinmintDNSSuffixes(""))
+
+	s.Config.AWSDnsZoneFallbacks = []*SpinmintDNSZone{
+		{AWSHostedZoneID: "Z1", AWSDnsSuffix: "spinmint.com"},
+		nil,
+		{AWSHostedZoneID: "Z2", AWSDnsSuffix: "spinmint-backup.com"},
+	}
+	assert.Equal(t, []string{"spinmint.com", "spinmint-backup.com"}, s.getSpinmintDNSSuffixes(""))
+
+	s.Config.SpinmintsUseHTTPS = true
+	assert.Equal(t, "https://i-123.spinmint-backup.com", s.getSpinmintURLForSuffix("i-123", "spinmint-backup.com"))
+}
+
+func TestGetSpinmintBannerText(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	pr := &model.PullRequest{RepoName: "mattermost-server", Number: 1234}
+
+	assert.Equal(t, "", s.getSpinmintBannerText(pr))
+
+	s.Config.SpinmintBannerText = "Test server for \"REPO_NAME\" PR #PR_NUMBER | not production"
+	assert.Equal(t, "Test server for mattermost-server PR #1234  not production", s.getSpinmintBannerText(pr))
+}
+
+func TestSanitizeSetupScriptValue(t *testing.T) {
+	assert.Equal(t, "Spinmint Feedback", sanitizeSetupScriptValue("Spinmint Feedback"))
+	assert.Equal(t, "feedback@example.com", sanitizeSetupScriptValue("feedback@example.com"))
+	assert.Equal(t, "rm -rf ", sanitizeSetupScriptValue("\"|`rm -rf $'"))
+}
+
+func TestSpinmintFailureStatesOption(t *testing.T) {
+	s := &Server{Config: &Config{SpinmintFailureStates: []string{"stopped"}}}
+
+	w := &request.Waiter{}
+	w.ApplyOptions(s.spinmintFailureStatesOption())
+	require.Len(t, w.Acceptors, 1)
+	assert.Equal(t, request.FailureWaiterState, w.Acceptors[0].State)
+	assert.Equal(t, "stopped", w.Acceptors[0].Expected)
+}
+
+func TestGetSpinmintInstanceWaitAttempts(t *testing.T) {
+	assert.Equal(t, 61, getSpinmintInstanceWaitAttempts(defaultSpinmintCreationTimeout*time.Second))
+	assert.Equal(t, 121, getSpinmintInstanceWaitAttempts(30*time.Minute))
+	assert.Equal(t, 1, getSpinmintInstanceWaitAttempts(time.Second))
+}
+
+func TestGetSpinmintSeedUserCount(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	assert.Equal(t, "", s.getSpinmintSeedUserCount())
+
+	s.Config.SpinmintSeedUserCount = 50
+	assert.Equal(t, "50", s.getSpinmintSeedUserCount())
+}
+
+func TestGetSpinmintSeedWorkers(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	assert.Equal(t, "", s.getSpinmintSeedWorkers())
+
+	s.Config.SpinmintSeedWorkers = 4
+	assert.Equal(t, "4", s.getSpinmintSeedWorkers())
+}
+
+func TestSpinmintImport(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	pr := &model.PullRequest{Labels: []string{"Setup Test Server", "Import Data"}}
+
+	assert.False(t, s.isSpinmintImport(pr))
+	assert.Equal(t, "", s.getSpinmintSkipSampleData(pr))
+
+	s.Config.SetupSpinmintImportTag = "Import Data"
+	assert.True(t, s.isSpinmintImport(pr))
+	assert.Equal(t, "true", s.getSpinmintSkipSampleData(pr))
+
+	pr.Labels = []string{"Setup Test Server"}
+	assert.False(t, s.isSpinmintImport(pr))
+}
+
+func TestSpinmintMinimal(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	pr := &model.PullRequest{Labels: []string{"Setup Test Server", "Minimal Test Server"}}
+
+	assert.False(t, s.isSpinmintMinimal(pr))
+	assert.Equal(t, "", s.getSpinmintSkipSampleData(pr))
+	assert.Equal(t, "", s.getSpinmintTeamName(pr))
+
+	s.Config.SetupSpinmintMinimalTag = "Minimal Test Server"
+	assert.True(t, s.isSpinmintMinimal(pr))
+	assert.Equal(t, "true", s.getSpinmintSkipSampleData(pr))
+	assert.Equal(t, spinmintMinimalTeamName, s.getSpinmintTeamName(pr))
+}
+
+func TestIsSpinmintRecreateOnPush(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	pr := &model.PullRequest{Labels: []string{"Setup Test Server", "Recreate Test Server"}}
+
+	assert.False(t, s.isSpinmintRecreateOnPush(pr))
+
+	s.Config.SetupSpinmintRecreateOnPushTag = "Recreate Test Server"
+	assert.True(t, s.isSpinmintRecreateOnPush(pr))
+
+	pr.Labels = nil
+	assert.False(t, s.isSpinmintRecreateOnPush(pr))
+}
+
+func TestGetSpinmintTriggerLabels(t *testing.T) {
+	s := &Server{Config: &Config{SetupSpinmintTag: "Setup Test Server", SetupSpinmintImportTag: "Import Data"}}
+	pr := &model.PullRequest{Labels: []string{"AutoMerge", "Import Data", "Setup Test Server"}}
+
+	assert.Equal(t, []string{"Setup Test Server", "Import Data"}, s.getSpinmintTriggerLabels(pr))
+
+	pr.Labels = []string{"AutoMerge"}
+	assert.Empty(t, s.getSpinmintTriggerLabels(pr))
+}
+
+func TestGetSpinmintMarketOptions(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	pr := &model.PullRequest{Labels: []string{"Setup Test Server", "Spot Test Server"}}
+
+	assert.Nil(t, s.getSpinmintMarketOptions(pr))
+
+	s.Config.SetupSpinmintSpotTag = "Spot Test Server"
+	options := s.getSpinmintMarketOptions(pr)
+	require.NotNil(t, options)
+	assert.Equal(t, ec2.MarketTypeSpot, aws.StringValue(options.MarketType))
+	assert.Nil(t, options.SpotOptions.MaxPrice)
+
+	s.Config.SpinmintSpotMaxPrice = "0.05"
+	assert.Equal(t, "0.05", aws.StringValue(s.getSpinmintMarketOptions(pr).SpotOptions.MaxPrice))
+
+	pr.Labels = []string{"Setup Test Server"}
+	assert.Nil(t, s.getSpinmintMarketOptions(pr))
+}
+
+func TestGetSpinmintInstanceType(t *testing.T) {
+	s := &Server{Config: &Config{
+		AWSInstanceType:     "t3.large",
+		SpinmintSizeAliases: map[string]string{"small": "t3.medium", "large": "t3.xlarge"},
+	}}
+	labels := []string{"Setup Test Server", "Spinmint Size/Large"}
+
+	assert.Equal(t, "t3.large", s.getSpinmintInstanceType(labels))
+
+	s.Config.SpinmintSizeLabelPrefix = "Spinmint Size/"
+	assert.Equal(t, "t3.xlarge", s.getSpinmintInstanceType(labels))
+	assert.Equal(t, "t3.medium", s.getSpinmintInstanceType([]string{"Spinmint Size/t3.medium"}))
+	assert.Equal(t, "t3.large", s.getSpinmintInstanceType([]string{"Spinmint Size/huge"}))
+	assert.Equal(t, "t3.large", s.getSpinmintInstanceType(nil))
+
+	assert.Equal(t, []string{"large", "small"}, s.getSpinmintSizeAliases())
+}
+
+func TestIsSpinmintInstanceTypeAllowed(t *testing.T) {
+	s := &Server{Config: &Config{
+		AWSInstanceType:         "t3.large",
+		SpinmintSizeAliases:     map[string]string{"small": "t3.medium", "large": "t3.xlarge"},
+		SpinmintSizeLabelPrefix: "Spinmint Size/",
+	}}
+
+	t.Run("empty allowlist allows anything", func(t *testing.T) {
+		assert.True(t, s.isSpinmintInstanceTypeAllowed("t3.medium"))
+		assert.True(t, s.isSpinmintInstanceTypeAllowed("x1e.32xlarge"))
+		assert.Equal(t, []string{"large", "small"}, s.getSpinmintSizeAliases())
+	})
+
+	s.Config.SpinmintAllowedInstanceTypes = []string{"t3.large", "t3.medium"}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.True(t, s.isSpinmintInstanceTypeAllowed("t3.medium"))
+		instanceType, ok := s.resolveSpinmintSize("small")
+		assert.True(t, ok)
+		assert.Equal(t, "t3.medium", instanceType)
+		assert.Equal(t, "t3.medium", s.getSpinmintInstanceType([]string{"Spinmint Size/small"}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.False(t, s.isSpinmintInstanceTypeAllowed("t3.xlarge"))
+		_, ok := s.resolveSpinmintSize("large")
+		assert.False(t, ok)
+		assert.Equal(t, "t3.large", s.getSpinmintInstanceType([]string{"Spinmint Size/large"}))
+		assert.Equal(t, []string{"small"}, s.getSpinmintSizeAliases())
+	})
+}
+
+func TestFindSpinmintInstanceType(t *testing.T) {
+	s := &Server{Config: &Config{
+		AWSInstanceType:              "t3.large",
+		SpinmintSizeAliases:          map[string]string{"small": "t3.medium", "large": "t3.xlarge"},
+		SpinmintSizeLabelPrefix:      "Spinmint Size/",
+		SpinmintAllowedInstanceTypes: []string{"t3.large", "t3.medium"},
+	}}
+
+	instanceType, err := s.findSpinmintInstanceType([]string{"Setup Test Server", "Spinmint Size/small"})
+	require.Nil(t, err)
+	assert.Equal(t, "t3.medium", instanceType)
+
+	instanceType, err = s.findSpinmintInstanceType(nil)
+	require.Nil(t, err)
+	assert.Equal(t, "t3.large", instanceType)
+
+	_, err = s.findSpinmintInstanceType([]string{"Spinmint Size/huge"})
+	require.NotNil(t, err)
+	assert.Equal(t, "Spinmint Size/huge", err.requested)
+	assert.Equal(t, []string{"Spinmint Size/small"}, err.valid)
+
+	_, err = s.findSpinmintInstanceType([]string{"Spinmint Size/large"})
+	require.NotNil(t, err)
+	assert.Equal(t, "Spinmint Size/large", err.requested)
+
+	s.Config.AWSInstanceType = "t3.2xlarge"
+	_, err = s.findSpinmintInstanceType(nil)
+	require.NotNil(t, err)
+	assert.Equal(t, "t3.2xlarge", err.requested)
+	assert.Equal(t, []string{"t3.large", "t3.medium"}, err.valid)
+}
+
+func TestSetSpinmintStatusLabel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	s := &Server{
+		Config:       &Config{},
+		GithubClient: &GithubClient{Issues: is},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123}
+
+	t.Run("no labels configured", func(t *testing.T) {
+		s.setSpinmintStatusLabel(ctx, pr, "")
+	})
+
+	s.Config.SpinmintProvisioningLabel = "Spinmint: Provisioning"
+	s.Config.SpinmintReadyLabel = "Spinmint: Ready"
+	s.Config.SpinmintFailedLabel = "Spinmint: Failed"
+
+	t.Run("replaces the previous status label", func(t *testing.T) {
+		is.EXPECT().ListLabelsByIssue(ctx, "mattertest", "mattermost-server", 123, nil).
+			Return([]*github.Label{{Name: github.String("Setup Test Server")}, {Name: github.String("Spinmint: Provisioning")}}, nil, nil)
+		is.EXPECT().RemoveLabelForIssue(ctx, "mattertest", "mattermost-server", 123, "Spinmint: Provisioning").Return(nil, nil)
+		is.EXPECT().AddLabelsToIssue(ctx, "mattertest", "mattermost-server", 123, []string{"Spinmint: Ready"}).Return(nil, nil, nil)
+
+		s.setSpinmintStatusLabel(ctx, pr, "Spinmint: Ready")
+	})
+
+	t.Run("clears the status label", func(t *testing.T) {
+		is.EXPECT().ListLabelsByIssue(ctx, "mattertest", "mattermost-server", 123, nil).
+			Return([]*github.Label{{Name: github.String("Spinmint: Failed")}}, nil, nil)
+		is.EXPECT().RemoveLabelForIssue(ctx, "mattertest", "mattermost-server", 123, "Spinmint: Failed").Return(nil, nil)
+
+		s.setSpinmintStatusLabel(ctx, pr, "")
+	})
+}
+
+func TestRemoveTestServerFromDB(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	s := &Server{Config: &Config{}, Store: ss}
+
+	sms.EXPECT().Delete("i-123").Return(nil)
+	s.removeTestServerFromDB("i-123")
+
+	s.Config.SpinmintSoftDelete = true
+	sms.EXPECT().SoftDelete("i-123", gomock.Any()).Return(nil)
+	s.removeTestServerFromDB("i-123")
+}
+
+func TestGetSetupSpinmintMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	s := &Server{Config: &Config{SetupSpinmintMessage: "Creating a test server."}, Store: ss}
+
+	assert.Equal(t, "Creating a test server.", s.getSetupSpinmintMessage())
+
+	s.Config.SpinmintCapacity = 2
+	sms.EXPECT().List().Return([]*model.Spinmint{{InstanceID: "i-1"}}, nil)
+	assert.Equal(t, "Creating a test server.\n\n1 of the 2 test server slots are in use.", s.getSetupSpinmintMessage())
+
+	sms.EXPECT().List().Return([]*model.Spinmint{{InstanceID: "i-1"}, {InstanceID: "i-2"}}, nil)
+	assert.Contains(t, s.getSetupSpinmintMessage(), "All 2 test server slots are in use (2 running).")
+
+	sms.EXPECT().List().Return(nil, errors.New("connection refused"))
+	assert.Equal(t, "Creating a test server.", s.getSetupSpinmintMessage())
+
+	s.Config.SpinmintMaxCount = 3
+	sms.EXPECT().List().Return([]*model.Spinmint{{InstanceID: "i-1"}, {InstanceID: "i-2"}}, nil)
+	assert.Equal(t, "Creating a test server.\n\n2 of the 3 test server slots are in use.", s.getSetupSpinmintMessage())
+}
+
+func TestGetSpinmintCapacityWarning(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	s := &Server{Config: &Config{SpinmintCapacity: 10}, Store: ss}
+
+	assert.Empty(t, s.getSpinmintCapacityWarning())
+
+	s.Config.SpinmintCapacityWarningThreshold = 0.8
+	newSpinmints := func(n int) []*model.Spinmint {
+		spinmints := make([]*model.Spinmint, n)
+		for i := range spinmints {
+			spinmints[i] = &model.Spinmint{InstanceID: fmt.Sprintf("i-%d", i)}
+		}
+		return spinmints
+	}
+
+	sms.EXPECT().List().Return(newSpinmints(8), nil)
+	assert.Empty(t, s.getSpinmintCapacityWarning())
+
+	sms.EXPECT().List().Return(newSpinmints(9), nil)
+	assert.Equal(t, "Test server capacity is nearly full (9 of 10 slots in use). Please destroy the test servers you no longer need.", s.getSpinmintCapacityWarning())
+
+	sms.EXPECT().List().Return(nil, errors.New("connection refused"))
+	assert.Empty(t, s.getSpinmintCapacityWarning())
+
+	// The hard limit replaces the capacity.
+	s.Config.SpinmintMaxCount = 5
+	sms.EXPECT().List().Return(newSpinmints(5), nil)
+	assert.Equal(t, "Test server capacity is nearly full (5 of 5 slots in use). Please destroy the test servers you no longer need.", s.getSpinmintCapacityWarning())
+}
+
+func TestReserveSpinmintSlot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	s := &Server{Config: &Config{}, Store: ss}
+	pr := func(number int) *model.PullRequest {
+		return &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: number}
+	}
+	running := []*model.Spinmint{{InstanceID: "i-1", RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 1}}
+
+	_, reserved := s.reserveSpinmintSlot(pr(2))
+	assert.True(t, reserved)
+
+	s.Config.SpinmintMaxCount = 3
+	sms.EXPECT().List().Return(running, nil).Times(3)
+	used, reserved := s.reserveSpinmintSlot(pr(2))
+	assert.True(t, reserved)
+	assert.Equal(t, 1, used)
+
+	// The PR keeps its slot without counting again.
+	_, reserved = s.reserveSpinmintSlot(pr(2))
+	assert.True(t, reserved)
+
+	// The slot of PR 2 is taken even though its spinmint is not stored yet.
+	used, reserved = s.reserveSpinmintSlot(pr(3))
+	assert.True(t, reserved)
+	assert.Equal(t, 2, used)
+	used, reserved = s.reserveSpinmintSlot(pr(4))
+	assert.False(t, reserved)
+	assert.Equal(t, 3, used)
+
+	// Once stored, the spinmint of PR 3 is not counted twice.
+	s.releaseSpinmintSlot(pr(2))
+	stored := append(running, &model.Spinmint{InstanceID: "i-3", RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 3})
+	sms.EXPECT().List().Return(stored, nil)
+	used, reserved = s.reserveSpinmintSlot(pr(4))
+	assert.True(t, reserved)
+	assert.Equal(t, 2, used)
+
+	s.releaseSpinmintSlot(pr(3))
+	s.releaseSpinmintSlot(pr(4))
+	sms.EXPECT().List().Return(nil, errors.New("connection refused"))
+	_, reserved = s.reserveSpinmintSlot(pr(5))
+	assert.True(t, reserved)
+}
+
+func TestReserveSpinmintSlotConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	sms.EXPECT().List().Return(nil, nil).AnyTimes()
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+	s := &Server{Config: &Config{SpinmintMaxCount: 2}, Store: ss}
+
+	var wg sync.WaitGroup
+	var reservations int32
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(number int) {
+			defer wg.Done()
+			if _, reserved := s.reserveSpinmintSlot(&model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: number}); reserved {
+				atomic.AddInt32(&reservations, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), reservations)
+}
+
+func TestGetSpinmintTimeouts(t *testing.T) {
+	s := &Server{Config: &Config{}}
+	assert.Equal(t, defaultSpinmintCreationTimeout*time.Second, s.getSpinmintCreationTimeout(nil))
+	assert.Equal(t, defaultBuildMobileTimeout*time.Second, getSpinmintBuildWaitTimeout(nil))
+
+	s.Config.SpinmintCreationTimeoutSeconds = 600
+	repo := &Repository{}
+	assert.Equal(t, 600*time.Second, s.getSpinmintCreationTimeout(repo))
+	assert.Equal(t, defaultBuildMobileTimeout*time.Second, getSpinmintBuildWaitTimeout(repo))
+
+	repo.InstallationWaitSeconds = 1200
+	repo.BuildWaitSeconds = 3600
+	assert.Equal(t, 1200*time.Second, s.getSpinmintCreationTimeout(repo))
+	assert.Equal(t, time.Hour, getSpinmintBuildWaitTimeout(repo))
+}
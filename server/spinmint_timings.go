@@ -0,0 +1,89 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+)
+
+// Phases of a spinmint setup whose durations are reported.
+const (
+	spinmintPhaseBuild     = "Build"
+	spinmintPhaseImage     = "Docker image"
+	spinmintPhaseLaunch    = "Instance launch"
+	spinmintPhaseInstance  = "Instance running"
+	spinmintPhaseDNS       = "DNS"
+	spinmintPhaseReadiness = "Readiness"
+	spinmintPhaseSmokeTest = "Smoke test"
+)
+
+// Waits for a spinmint whose durations are kept in the metrics.
+const (
+	spinmintWaitInstance  = "instance"
+	spinmintWaitReadiness = "readiness"
+)
+
+// observeSpinmintWait keeps how long the spinmint of the PR took to pass wait in the metrics,
+// sized from the labels the spinmint was created with.
+func (s *Server) observeSpinmintWait(wait string, pr *model.PullRequest, labels []string, start time.Time) {
+	if s.Metrics != nil {
+		s.Metrics.ObserveSpinmintWaitDuration(wait, pr.RepoName, s.getSpinmintInstanceType(labels), time.Since(start).Seconds())
+	}
+}
+
+// spinmintTimings records how long the phases of a spinmint setup took. A nil
+// spinmintTimings records nothing.
+type spinmintTimings struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []*spinmintPhase
+}
+
+type spinmintPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+func newSpinmintTimings() *spinmintTimings {
+	return &spinmintTimings{start: time.Now()}
+}
+
+// track records the phase name as lasting from start until now. The build and the
+// image can be waited for at once, so it is safe to call concurrently.
+func (t *spinmintTimings) track(name string, start time.Time) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases = append(t.phases, &spinmintPhase{Name: name, Duration: time.Since(start)})
+}
+
+// format returns the phases as a table, with the total time since the setup started.
+func (t *spinmintTimings) format() string {
+	if t == nil {
+		return ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.phases) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The test server took %s to set up:\n\n", time.Since(t.start).Round(time.Second))
+	sb.WriteString("| Phase | Duration |\n")
+	sb.WriteString("| --- | --- |\n")
+	for _, p := range t.phases {
+		fmt.Fprintf(&sb, "| %s | %s |\n", p.Name, p.Duration.Round(time.Second))
+	}
+	return sb.String()
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpinmintTimings(t *testing.T) {
+	t.Run("nil records nothing", func(t *testing.T) {
+		var timings *spinmintTimings
+		timings.track(spinmintPhaseBuild, time.Now())
+		assert.Empty(t, timings.format())
+	})
+
+	t.Run("no phases", func(t *testing.T) {
+		assert.Empty(t, newSpinmintTimings().format())
+	})
+
+	t.Run("format", func(t *testing.T) {
+		timings := newSpinmintTimings()
+		timings.start = time.Now().Add(-12 * time.Minute)
+		timings.track(spinmintPhaseBuild, time.Now().Add(-10*time.Minute))
+		timings.track(spinmintPhaseInstance, time.Now().Add(-90*time.Second))
+		timings.track(spinmintPhaseReadiness, time.Now().Add(-1500*time.Millisecond))
+
+		assert.Equal(t, "The test server took 12m0s to set up:\n\n"+
+			"| Phase | Duration |\n"+
+			"| --- | --- |\n"+
+			"| Build | 10m0s |\n"+
+			"| Instance running | 1m30s |\n"+
+			"| Readiness | 2s |\n", timings.format())
+	})
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+)
+
+const defaultSpinmintUsageDays = 30
+
+// spinmintUsage is the number of spinmints and the hours they ran for a repository and instance type.
+type spinmintUsage struct {
+	RepoName     string
+	InstanceType string
+	Count        int
+	RunningHours float64
+}
+
+func (s *Server) handleSpinmintUsage(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	days := getSpinmintUsageDays(body)
+	now := model.NowUTC()
+	usage, err := s.getSpinmintUsage(now.AddDate(0, 0, -days), now)
+	if err != nil {
+		msg = msgSpinmintUsageError
+		return err
+	}
+
+	msg = formatSpinmintUsage(usage, days)
+	return nil
+}
+
+// getSpinmintUsage aggregates the destroyed and running spinmints between since and until.
+// Only the time spent within the window is counted.
+func (s *Server) getSpinmintUsage(since, until time.Time) ([]*spinmintUsage, error) {
+	history, err := s.Store.Spinmint().ListHistory(since)
+	if err != nil {
+		return nil, err
+	}
+	running, err := s.Store.Spinmint().List()
+	if err != nil {
+		return nil, err
+	}
+
+	usageByKey := map[string]*spinmintUsage{}
+	add := func(repoName, instanceType string, createdAt, destroyedAt time.Time) {
+		if createdAt.Before(since) {
+			createdAt = since
+		}
+		if destroyedAt.After(until) {
+			destroyedAt = until
+		}
+		if !destroyedAt.After(createdAt) {
+			return
+		}
+
+		key := repoName + "/" + instanceType
+		usage, ok := usageByKey[key]
+		if !ok {
+			usage = &spinmintUsage{RepoName: repoName, InstanceType: instanceType}
+			usageByKey[key] = usage
+		}
+		usage.Count++
+		usage.RunningHours += destroyedAt.Sub(createdAt).Hours()
+	}
+
+	for _, h := range history {
+		add(h.RepoName, h.InstanceType, h.CreatedAt, h.DestroyedAt)
+	}
+	for _, spinmint := range running {
+		add(spinmint.RepoName, s.getSpinmintInstanceType(spinmint.Labels), spinmint.CreatedAt, until)
+	}
+
+	usage := make([]*spinmintUsage, 0, len(usageByKey))
+	for _, u := range usageByKey {
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].RepoName != usage[j].RepoName {
+			return usage[i].RepoName < usage[j].RepoName
+		}
+		return usage[i].InstanceType < usage[j].InstanceType
+	})
+	return usage, nil
+}
+
+// getSpinmintUsageDays returns the number of days given by a "days=<n>" argument of a
+// spinmint command, or the default window.
+func getSpinmintUsageDays(body string) int {
+	for _, field := range strings.Fields(body) {
+		if strings.HasPrefix(field, "days=") {
+			days, err := strconv.Atoi(strings.TrimPrefix(field, "days="))
+			if err == nil && days > 0 {
+				return days
+			}
+		}
+	}
+	return defaultSpinmintUsageDays
+}
+
+func formatSpinmintUsage(usage []*spinmintUsage, days int) string {
+	if len(usage) == 0 {
+		return fmt.Sprintf("No test servers ran in the last %d days.", days)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Test server usage in the last %d days:\n\n", days)
+	sb.WriteString("| Repository | Instance type | Test servers | Running hours |\n")
+	sb.WriteString("| --- | --- | --- | --- |\n")
+	for _, u := range usage {
+		fmt.Fprintf(&sb, "| %s | %s | %d | %.1f |\n", u.RepoName, u.InstanceType, u.Count, u.RunningHours)
+	}
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-mattermod/model"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpinmintUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	s := &Server{
+		Config: &Config{AWSInstanceType: "m1.medium"},
+		Store:  ss,
+	}
+
+	until := time.Date(2020, 10, 31, 0, 0, 0, 0, time.UTC)
+	since := until.AddDate(0, 0, -30)
+
+	sms.EXPECT().ListHistory(since).Return([]*model.SpinmintHistory{
+		{RepoName: "mattermost-server", InstanceType: "m1.medium", CreatedAt: until.Add(-10 * time.Hour), DestroyedAt: until.Add(-5 * time.Hour)},
+		// Started before the window, only the last 2 hours are counted.
+		{RepoName: "mattermost-server", InstanceType: "m1.medium", CreatedAt: since.Add(-time.Hour), DestroyedAt: since.Add(2 * time.Hour)},
+		{RepoName: "mattermost-server", InstanceType: "t3.large", CreatedAt: until.Add(-4 * time.Hour), DestroyedAt: until.Add(-3 * time.Hour)},
+	}, nil)
+	sms.EXPECT().List().Return([]*model.Spinmint{
+		{RepoName: "mattermost-webapp", CreatedAt: until.Add(-3 * time.Hour)},
+	}, nil)
+
+	usage, err := s.getSpinmintUsage(since, until)
+	require.NoError(t, err)
+	assert.Equal(t, []*spinmintUsage{
+		{RepoName: "mattermost-server", InstanceType: "m1.medium", Count: 2, RunningHours: 7},
+		{RepoName: "mattermost-server", InstanceType: "t3.large", Count: 1, RunningHours: 1},
+		{RepoName: "mattermost-webapp", InstanceType: "m1.medium", Count: 1, RunningHours: 3},
+	}, usage)
+
+	msg := formatSpinmintUsage(usage, 30)
+	assert.Contains(t, msg, "| mattermost-server | m1.medium | 2 | 7.0 |")
+	assert.Equal(t, "No test servers ran in the last 7 days.", formatSpinmintUsage(nil, 7))
+}
+
+func TestGetSpinmintUsageDays(t *testing.T) {
+	assert.Equal(t, defaultSpinmintUsageDays, getSpinmintUsageDays("/spinmint usage"))
+	assert.Equal(t, 7, getSpinmintUsageDays("/spinmint usage days=7"))
+	assert.Equal(t, defaultSpinmintUsageDays, getSpinmintUsageDays("/spinmint usage days=-1"))
+	assert.Equal(t, defaultSpinmintUsageDays, getSpinmintUsageDays("/spinmint usage days=week"))
+}
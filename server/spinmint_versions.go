@@ -0,0 +1,179 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-server/v5/mlog"
+	"github.com/pkg/errors"
+)
+
+const (
+	msgSpinmintVersionsEmpty       = "No commit of this PR ran on a test server yet."
+	msgSpinmintRollbackUsage       = "Please specify the commit to roll back to, e.g. `/spinmint rollback abcdef1`. Use `/spinmint versions` to list the tested ones."
+	msgSpinmintRollbackUnknown     = "Commit `%s` never ran on a test server of this PR. Use `/spinmint versions` to list the tested ones."
+	msgSpinmintRollback            = "Rolling the test server back to the build of `%s`."
+	msgSpinmintRollbackUnsupported = "Rolling back is not supported for this repository. Its test servers install the latest build of the PR, whatever the commit."
+)
+
+// spinmintVersion is a commit of the PR that ran on a spinmint.
+type spinmintVersion struct {
+	Sha       string
+	Action    string
+	CreatedAt string
+}
+
+// getSpinmintVersions returns the commits that ran on the spinmints of the PR, newest first,
+// from the created and upgraded actions. The history is kept per PR since upgrades replace the instance.
+func getSpinmintVersions(actions []*model.SpinmintAction) []*spinmintVersion {
+	var versions []*spinmintVersion
+	seen := map[string]bool{}
+	for i := len(actions) - 1; i >= 0; i-- {
+		a := actions[i]
+		if (a.Action != spinmintEventCreated && a.Action != spinmintEventUpgraded) || a.Sha == "" || seen[a.Sha] {
+			continue
+		}
+		seen[a.Sha] = true
+		versions = append(versions, &spinmintVersion{
+			Sha:       a.Sha,
+			Action:    a.Action,
+			CreatedAt: a.CreatedAt.UTC().Format("2006-01-02 15:04"),
+		})
+	}
+	return versions
+}
+
+func formatSpinmintVersions(versions []*spinmintVersion) string {
+	if len(versions) == 0 {
+		return msgSpinmintVersionsEmpty
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Commits tested on the test servers of this PR, newest first:\n\n")
+	sb.WriteString("| Commit | Last run (UTC) | Action |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+	for i, v := range versions {
+		sha := "`" + shortSha(v.Sha) + "`"
+		if i == 0 {
+			sha += " (current)"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", sha, v.CreatedAt, v.Action)
+	}
+	return sb.String()
+}
+
+// handleSpinmintVersions comments the commits that ran on the test servers of the PR.
+func (s *Server) handleSpinmintVersions(ctx context.Context, pr *model.PullRequest) error {
+	msg := msgSpinmintHistoryError
+	actions, err := s.Store.Spinmint().ListActions(pr.RepoOwner, pr.RepoName, pr.Number)
+	if err == nil {
+		msg = formatSpinmintVersions(getSpinmintVersions(actions))
+	}
+	if errComment := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); errComment != nil {
+		mlog.Warn("Error while commenting", mlog.Err(errComment))
+	}
+	return err
+}
+
+// getSpinmintRollbackTarget returns the commit named after "/spinmint rollback", lowercased.
+func getSpinmintRollbackTarget(body string) string {
+	index := strings.Index(body, "/spinmint rollback")
+	if index < 0 {
+		return ""
+	}
+	args := strings.Fields(body[index+len("/spinmint rollback"):])
+	if len(args) == 0 {
+		return ""
+	}
+	return strings.ToLower(args[0])
+}
+
+// isSpinmintBuildKeyedByCommit is true if the upgrade script of the repository of the PR installs
+// the build of the commit being set up. Only PR-BUILD_NUMBER is replaced by the commit, while
+// BUILD_NUMBER is the PR number, whose build is overwritten by every new commit.
+func (s *Server) isSpinmintBuildKeyedByCommit(pr *model.PullRequest) (bool, error) {
+	repo, ok := GetRepository(s.Config.Repositories, pr.RepoOwner, pr.RepoName)
+	if !ok || repo.InstanceSetupUpgradeScript == "" {
+		return false, nil
+	}
+	data, err := ioutil.ReadFile(path.Join("config", repo.InstanceSetupUpgradeScript))
+	if err != nil {
+		return false, errors.Wrap(err, "unable to read the upgrade script")
+	}
+	script := string(data)
+	return strings.Contains(script, "PR-BUILD_NUMBER") && !strings.Contains(strings.Replace(script, "PR-BUILD_NUMBER", "", -1), "BUILD_NUMBER"), nil
+}
+
+// handleSpinmintRollback replaces the primary spinmint of the PR with one upgraded to the build
+// of a commit that already ran on it. Only tested commits, named by at least their short sha,
+// are accepted since their build is known to exist. It is refused for repositories whose builds
+// are not kept per commit.
+func (s *Server) handleSpinmintRollback(ctx context.Context, commenter, body string, pr *model.PullRequest) error {
+	var msg string
+	defer func() {
+		if msg != "" {
+			if err := s.sendGitHubComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, msg); err != nil {
+				mlog.Warn("Error while commenting", mlog.Err(err))
+			}
+		}
+	}()
+
+	if !s.IsOrgMember(commenter) {
+		msg = msgSpinmintMaintainerOnly
+		return nil
+	}
+
+	target := getSpinmintRollbackTarget(body)
+	if len(target) < 7 {
+		msg = msgSpinmintRollbackUsage
+		return nil
+	}
+
+	keyed, err := s.isSpinmintBuildKeyedByCommit(pr)
+	if err != nil {
+		return err
+	}
+	if !keyed {
+		msg = msgSpinmintRollbackUnsupported
+		return nil
+	}
+
+	spinmint, err := s.Store.Spinmint().Get(pr.Number, pr.RepoName)
+	if err != nil {
+		return err
+	}
+	if spinmint == nil {
+		msg = msgSpinmintNotFound
+		return nil
+	}
+
+	actions, err := s.Store.Spinmint().ListActions(pr.RepoOwner, pr.RepoName, pr.Number)
+	if err != nil {
+		return err
+	}
+	var sha string
+	for _, v := range getSpinmintVersions(actions) {
+		if strings.HasPrefix(v.Sha, target) {
+			sha = v.Sha
+			break
+		}
+	}
+	if sha == "" {
+		msg = fmt.Sprintf(msgSpinmintRollbackUnknown, target)
+		return nil
+	}
+
+	// The setup installs the build of pr.Sha, the PR itself is not saved.
+	rollbackPR := *pr
+	rollbackPR.Sha = sha
+	msg = fmt.Sprintf(msgSpinmintRollback, shortSha(sha))
+	s.runSpinmintFlow("spinmint_rollback", pr, func() error { return s.forceUpgradeSpinmint(&rollbackPR, spinmint) })
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v33/github"
+	"github.com/mattermost/mattermost-mattermod/model"
+	"github.com/mattermost/mattermost-mattermod/server/mocks"
+	stmock "github.com/mattermost/mattermost-mattermod/store/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSpinmintVersions(t *testing.T) {
+	createdAt := time.Date(2021, 3, 4, 10, 30, 0, 0, time.UTC)
+	actions := []*model.SpinmintAction{
+		{Action: spinmintEventCreated, InstanceID: "i-1", Sha: "aaaaaaa111", CreatedAt: createdAt},
+		{Action: spinmintEventUpgraded, InstanceID: "i-2", Sha: "bbbbbbb222", CreatedAt: createdAt.Add(time.Hour)},
+		{Action: spinmintEventDestroyed, InstanceID: "i-2", Sha: "ccccccc333", CreatedAt: createdAt.Add(2 * time.Hour)},
+		{Action: spinmintEventUpgraded, InstanceID: "i-3", Sha: "aaaaaaa111", CreatedAt: createdAt.Add(3 * time.Hour)},
+	}
+
+	versions := getSpinmintVersions(actions)
+	require.Len(t, versions, 2)
+	assert.Equal(t, &spinmintVersion{Sha: "aaaaaaa111", Action: spinmintEventUpgraded, CreatedAt: "2021-03-04 13:30"}, versions[0])
+	assert.Equal(t, &spinmintVersion{Sha: "bbbbbbb222", Action: spinmintEventUpgraded, CreatedAt: "2021-03-04 11:30"}, versions[1])
+
+	assert.Equal(t, msgSpinmintVersionsEmpty, formatSpinmintVersions(nil))
+	expected := "Commits tested on the test servers of this PR, newest first:\n\n" +
+		"| Commit | Last run (UTC) | Action |\n" +
+		"| --- | --- | --- |\n" +
+		"| `aaaaaaa` (current) | 2021-03-04 13:30 | upgraded |\n" +
+		"| `bbbbbbb` | 2021-03-04 11:30 | upgraded |\n"
+	assert.Equal(t, expected, formatSpinmintVersions(versions))
+}
+
+func TestGetSpinmintRollbackTarget(t *testing.T) {
+	assert.Equal(t, "", getSpinmintRollbackTarget("/spinmint versions"))
+	assert.Equal(t, "", getSpinmintRollbackTarget("/spinmint rollback"))
+	assert.Equal(t, "abcdef1", getSpinmintRollbackTarget("/spinmint rollback ABCDEF1 please"))
+}
+
+func TestHandleSpinmintRollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	is := mocks.NewMockIssuesService(ctrl)
+	sms := stmock.NewMockSpinmintStore(ctrl)
+	ss := stmock.NewMockStore(ctrl)
+	ss.EXPECT().Spinmint().Return(sms).AnyTimes()
+
+	// The scripts are looked up under config/, relative to the working directory.
+	dir, err := ioutil.TempDir("", "mattermod-rollback")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	configDir, err := filepath.Abs("config")
+	require.NoError(t, err)
+	writeScript := func(name, content string) string {
+		script := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(script, []byte(content), 0600))
+		rel, errRel := filepath.Rel(configDir, script)
+		require.NoError(t, errRel)
+		return rel
+	}
+	repo := &Repository{
+		Owner:                      "mattertest",
+		Name:                       "mattermost-server",
+		InstanceSetupUpgradeScript: writeScript("commit.sh", "wget https://builds.example.com/PR-BUILD_NUMBER/mattermost.tar.gz\n"),
+	}
+
+	s := &Server{
+		Config:       &Config{Repositories: []*Repository{repo}},
+		GithubClient: &GithubClient{Issues: is},
+		Store:        ss,
+		OrgMembers:   []string{"maintainer"},
+	}
+	pr := &model.PullRequest{RepoOwner: "mattertest", RepoName: "mattermost-server", Number: 123, Sha: "ccccccc333"}
+	expectComment := func(msg string) {
+		is.EXPECT().CreateComment(ctx, pr.RepoOwner, pr.RepoName, pr.Number, &github.IssueComment{Body: github.String(msg)}).Return(nil, nil, nil)
+	}
+
+	t.Run("random user", func(t *testing.T) {
+		expectComment(msgSpinmintMaintainerOnly)
+		require.NoError(t, s.handleSpinmintRollback(ctx, "someone", "/spinmint rollback aaaaaaa", pr))
+	})
+
+	t.Run("missing commit", func(t *testing.T) {
+		expectComment(msgSpinmintRollbackUsage)
+		require.NoError(t, s.handleSpinmintRollback(ctx, "maintainer", "/spinmint rollback aaa", pr))
+	})
+
+	t.Run("build of the PR number", func(t *testing.T) {
+		defer func(script string) { repo.InstanceSetupUpgradeScript = script }(repo.InstanceSetupUpgradeScript)
+		repo.InstanceSetupUpgradeScript = writeScript("pr.sh", "wget https://builds.example.com/BUILD_NUMBER/mattermost.tar.gz\n")
+		expectComment(msgSpinmintRollbackUnsupported)
+		require.NoError(t, s.handleSpinmintRollback(ctx, "maintainer", "/spinmint rollback aaaaaaa", pr))
+	})
+
+	t.Run("no test server", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(nil, nil)
+		expectComment(msgSpinmintNotFound)
+		require.NoError(t, s.handleSpinmintRollback(ctx, "maintainer", "/spinmint rollback aaaaaaa", pr))
+	})
+
+	t.Run("untested commit", func(t *testing.T) {
+		sms.EXPECT().Get(pr.Number, pr.RepoName).Return(&model.Spinmint{InstanceID: "i-1"}, nil)
+		sms.EXPECT().ListActions(pr.RepoOwner, pr.RepoName, pr.Number).Return([]*model.SpinmintAction{
+			{Action: spinmintEventCreated, Sha: "aaaaaaa111"},
+		}, nil)
+		expectComment("Commit `ddddddd` never ran on a test server of this PR. Use `/spinmint versions` to list the tested ones.")
+		require.NoError(t, s.handleSpinmintRollback(ctx, "maintainer", "/spinmint rollback ddddddd", pr))
+	})
+}
@@ -38,3 +38,11 @@ func contains(list []string, item string) bool {
 	}
 	return false
 }
+
+// shortSha returns the abbreviated form of a commit SHA.
+func shortSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
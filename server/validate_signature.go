@@ -9,14 +9,33 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"github.com/mattermost/mattermost-server/v5/mlog"
 )
 
+// unsignedAPIPaths are the read-only API endpoints. They are not called by GitHub, so their
+// requests are not signed.
+var unsignedAPIPaths = []*regexp.Regexp{
+	regexp.MustCompile(`^/api/prs/[^/]+/[0-9]+/build$`),
+}
+
+func isUnsignedAPIRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	for _, path := range unsignedAPIPaths {
+		if path.MatchString(r.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Server) withValidation(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.RequestURI == "/healthz" {
+		if r.RequestURI == "/healthz" || isUnsignedAPIRequest(r) {
 			next.ServeHTTP(w, r)
 			return
 		}
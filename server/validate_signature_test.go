@@ -78,6 +78,35 @@ func TestWithValidationMiddleware(t *testing.T) {
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 		defer resp.Body.Close()
 	})
+
+	t.Run("Should skip validation of the read-only API", func(t *testing.T) {
+		req, err := http.NewRequest("GET", ts.URL+"/api/prs/mattermost-server/1234/build", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		defer resp.Body.Close()
+	})
+
+	t.Run("Should validate other API requests", func(t *testing.T) {
+		for _, path := range []string{"/api/prs/mattermost-server/1234/build/extra", "/api/spinmints"} {
+			req, err := http.NewRequest("GET", ts.URL+path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+			resp.Body.Close()
+		}
+
+		req, err := http.NewRequest("POST", ts.URL+"/api/prs/mattermost-server/1234/build", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		defer resp.Body.Close()
+	})
 }
 
 func TestValidateSignatureWithIncorrectSignature(t *testing.T) {
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE Spinmint ADD COLUMN CreatedAtUnix bigint(20) DEFAULT NULL;
+UPDATE Spinmint SET CreatedAtUnix = UNIX_TIMESTAMP(CreatedAt) WHERE CreatedAt IS NOT NULL;
+ALTER TABLE Spinmint DROP COLUMN CreatedAt;
+ALTER TABLE Spinmint CHANGE CreatedAtUnix CreatedAt bigint(20) DEFAULT NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE Spinmint ADD COLUMN CreatedAtTime timestamp NULL DEFAULT NULL;
+UPDATE Spinmint SET CreatedAtTime = FROM_UNIXTIME(CreatedAt) WHERE CreatedAt IS NOT NULL;
+ALTER TABLE Spinmint DROP COLUMN CreatedAt;
+ALTER TABLE Spinmint CHANGE CreatedAtTime CreatedAt timestamp NULL DEFAULT NULL;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS `SpinmintHistory` (
+  `InstanceId` varchar(128) NOT NULL,
+  `RepoOwner` varchar(255) DEFAULT NULL,
+  `RepoName` varchar(255) DEFAULT NULL,
+  `Number` int(11) DEFAULT NULL,
+  `Variant` varchar(64) NOT NULL DEFAULT '',
+  `InstanceType` varchar(64) NOT NULL DEFAULT '',
+  `CreatedAt` timestamp NULL DEFAULT NULL,
+  `DestroyedAt` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`InstanceId`),
+  KEY `idx_spinmint_history_destroyed_at` (`DestroyedAt`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+COMMIT;
@@ -0,0 +1,52 @@
+BEGIN;
+
+-- Keep only the most recent active spinmint of each PR variant so the unique key can be added.
+-- Spinmints created in the same second are told apart by their instance ID.
+UPDATE Spinmint older
+  JOIN Spinmint newer
+    ON older.RepoOwner = newer.RepoOwner
+   AND older.RepoName = newer.RepoName
+   AND older.Number = newer.Number
+   AND older.Variant = newer.Variant
+   AND (older.CreatedAt < newer.CreatedAt
+     OR (older.CreatedAt = newer.CreatedAt AND older.InstanceId < newer.InstanceId))
+   AND newer.DeletedAt IS NULL
+SET older.DeletedAt = NOW()
+WHERE older.DeletedAt IS NULL;
+
+SET @dbName = DATABASE();
+SET @tableName = "Spinmint";
+SET @columnName = "Active";
+SET @columnType = "tinyint(1) GENERATED ALWAYS AS (IF(DeletedAt IS NULL, 1, NULL)) STORED";
+SET @preparedStatement = (SELECT IF(
+  (
+    SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+    WHERE
+      (table_name = @tableName)
+      AND (table_schema = @dbName)
+      AND (column_name = @columnName)
+  ) > 0,
+  "SELECT 1",
+  CONCAT("ALTER TABLE ", @tableName, " ADD ", @columnName, " ", @columnType, ";")
+));
+PREPARE alterIfNotExists FROM @preparedStatement;
+EXECUTE alterIfNotExists;
+DEALLOCATE PREPARE alterIfNotExists;
+
+SET @indexName = "idx_spinmint_active_pr";
+SET @preparedStatement = (SELECT IF(
+  (
+    SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+    WHERE
+      (table_name = @tableName)
+      AND (table_schema = @dbName)
+      AND (index_name = @indexName)
+  ) > 0,
+  "SELECT 1",
+  CONCAT("ALTER TABLE ", @tableName, " ADD UNIQUE INDEX ", @indexName, " (RepoOwner, RepoName, Number, Variant, Active);")
+));
+PREPARE alterIfNotExists FROM @preparedStatement;
+EXECUTE alterIfNotExists;
+DEALLOCATE PREPARE alterIfNotExists;
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS `SpinmintActions` (
+  `Id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `RepoOwner` varchar(255) NOT NULL,
+  `RepoName` varchar(255) NOT NULL,
+  `Number` int(11) NOT NULL,
+  `Action` varchar(32) NOT NULL,
+  `InstanceId` varchar(128) NOT NULL DEFAULT '',
+  `Sha` varchar(64) NOT NULL DEFAULT '',
+  `CreatedAt` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`Id`),
+  KEY `idx_spinmint_actions_pr` (`RepoOwner`, `RepoName`, `Number`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+COMMIT;
@@ -0,0 +1,48 @@
+BEGIN;
+
+-- Renamed spinmints sharing a subdomain go back to their instance ID so the unique key can be added.
+UPDATE Spinmint later
+  JOIN Spinmint earlier
+    ON later.Region = earlier.Region
+   AND later.Subdomain = earlier.Subdomain
+   AND later.InstanceId > earlier.InstanceId
+   AND earlier.DeletedAt IS NULL
+SET later.Subdomain = ''
+WHERE later.Subdomain != '' AND later.DeletedAt IS NULL;
+
+SET @dbName = DATABASE();
+SET @tableName = "Spinmint";
+SET @columnName = "ActiveSubdomain";
+SET @columnType = "varchar(128) GENERATED ALWAYS AS (IF(DeletedAt IS NULL, IF(Subdomain = '', InstanceId, Subdomain), NULL)) STORED";
+SET @preparedStatement = (SELECT IF(
+  (
+    SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS
+    WHERE
+      (table_name = @tableName)
+      AND (table_schema = @dbName)
+      AND (column_name = @columnName)
+  ) > 0,
+  "SELECT 1",
+  CONCAT("ALTER TABLE ", @tableName, " ADD ", @columnName, " ", @columnType, ";")
+));
+PREPARE alterIfNotExists FROM @preparedStatement;
+EXECUTE alterIfNotExists;
+DEALLOCATE PREPARE alterIfNotExists;
+
+SET @indexName = "idx_spinmint_active_subdomain";
+SET @preparedStatement = (SELECT IF(
+  (
+    SELECT COUNT(*) FROM INFORMATION_SCHEMA.STATISTICS
+    WHERE
+      (table_name = @tableName)
+      AND (table_schema = @dbName)
+      AND (index_name = @indexName)
+  ) > 0,
+  "SELECT 1",
+  CONCAT("ALTER TABLE ", @tableName, " ADD UNIQUE INDEX ", @indexName, " (Region, ActiveSubdomain);")
+));
+PREPARE alterIfNotExists FROM @preparedStatement;
+EXECUTE alterIfNotExists;
+DEALLOCATE PREPARE alterIfNotExists;
+
+COMMIT;
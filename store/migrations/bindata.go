@@ -4,6 +4,40 @@
 // migrations/000001_base.up.sql (3.007kB)
 // migrations/000002_add_milestone.down.sql (958B)
 // migrations/000002_add_milestone.up.sql (1.069kB)
+// migrations/000003_spinmint_created_at_timestamp.down.sql (300B)
+// migrations/000003_spinmint_created_at_timestamp.up.sql (307B)
+// migrations/000004_spinmint_created_by.down.sql (507B)
+// migrations/000004_spinmint_created_by.up.sql (587B)
+// migrations/000005_spinmint_snapshot_id.down.sql (508B)
+// migrations/000005_spinmint_snapshot_id.up.sql (588B)
+// migrations/000006_spinmint_variant.down.sql (505B)
+// migrations/000006_spinmint_variant.up.sql (584B)
+// migrations/000007_spinmint_history.down.sql (57B)
+// migrations/000007_spinmint_history.up.sql (528B)
+// migrations/000008_spinmint_deleted_at.down.sql (507B)
+// migrations/000008_spinmint_deleted_at.up.sql (582B)
+// migrations/000009_spinmint_labels.down.sql (504B)
+// migrations/000009_spinmint_labels.up.sql (556B)
+// migrations/000010_spinmint_unique_pr.down.sql (958B)
+// migrations/000010_spinmint_unique_pr.up.sql (1.725kB)
+// migrations/000011_spinmint_actions.down.sql (57B)
+// migrations/000011_spinmint_actions.up.sql (506B)
+// migrations/000012_spinmint_region.down.sql (504B)
+// migrations/000012_spinmint_region.up.sql (583B)
+// migrations/000013_systems.down.sql (49B)
+// migrations/000013_systems.up.sql (167B)
+// migrations/000014_spinmint_subdomain.down.sql (507B)
+// migrations/000014_spinmint_subdomain.up.sql (586B)
+// migrations/000015_spinmint_state.down.sql (503B)
+// migrations/000015_spinmint_state.up.sql (582B)
+// migrations/000016_spinmint_credentials_comment.down.sql (519B)
+// migrations/000016_spinmint_credentials_comment.up.sql (599B)
+// migrations/000017_spinmint_state_changed_at.down.sql (512B)
+// migrations/000017_spinmint_state_changed_at.up.sql (587B)
+// migrations/000018_spinmint_unique_subdomain.down.sql (974B)
+// migrations/000018_spinmint_unique_subdomain.up.sql (1.564kB)
+// migrations/000019_spinmint_drop_variant.down.sql (2.161kB)
+// migrations/000019_spinmint_drop_variant.up.sql (2.26kB)
 
 package migrations
 
@@ -152,6 +186,686 @@ func _000002_add_milestoneUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __000003_spinmint_created_at_timestampDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x8f\x4d\x8b\xc2\x30\x10\x40\xef\xf9\x15\x73\x6c\x6f\xcb\x5e\xcb\x1e\xa6\xc9\x6c\x1b\xc8\x47\x69\x26\xe8\x4d\x14\x8b\xe4\x60\x10\xc9\xc1\x9f\x2f\x08\xd6\xa2\xf6\x38\xcc\x9b\xc7\x9b\x96\x3a\xed\x1a\x21\xd0\x30\x8d\xc0\xd8\x1a\x82\x70\x49\xf9\x9c\x72\x01\x54\x0a\xa4\x37\xd1\x3a\x90\xd7\x69\x5f\xa6\x23\x96\x98\xd3\x0d\x0e\xe9\x94\x72\xa9\x7e\x7f\x6a\x50\xf4\x8f\xd1\x30\xb8\x68\x4c\x23\xe2\xa0\x90\x17\x86\x40\xfc\x76\xfa\x07\xd1\xe9\xed\x8e\xb5\xa5\xc0\x68\x87\x6a\x5e\xd7\xb0\xe9\x69\xa4\x17\x0f\x3a\x80\xf3\x4f\xf5\xd7\x42\x35\xfa\xe1\x23\x71\x85\x95\x3d\xba\x6e\xa1\x7f\xe4\xcc\xd3\xfa\x4f\x42\x7a\x6b\x35\x37\xe2\x3e\x00\xef\xe4\x24\x0d\x2c\x01\x00\x00")
+
+func _000003_spinmint_created_at_timestampDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000003_spinmint_created_at_timestampDownSql,
+		"000003_spinmint_created_at_timestamp.down.sql",
+	)
+}
+
+func _000003_spinmint_created_at_timestampDownSql() (*asset, error) {
+	bytes, err := _000003_spinmint_created_at_timestampDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000003_spinmint_created_at_timestamp.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe4, 0x78, 0xca, 0x2c, 0x6f, 0x26, 0xf4, 0x77, 0x53, 0x4b, 0x5, 0x5a, 0x60, 0xbd, 0x54, 0x2, 0x8, 0xc, 0xb8, 0x90, 0xea, 0x3f, 0x83, 0xd1, 0x7e, 0x3a, 0x42, 0xd0, 0xb1, 0x3d, 0x2c, 0x81}}
+	return a, nil
+}
+
+var __000003_spinmint_created_at_timestampUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x8f\xbb\x8a\xc3\x30\x10\x45\x7b\x7d\xc5\x94\xbb\xdf\x60\xb6\x18\x4b\x63\x5b\xa0\x87\x91\x47\xec\x76\x8b\x21\x2a\x54\xc8\x98\x44\xff\x4f\xc0\x10\xc7\xe4\x55\x5e\xe6\xce\xe1\xdc\x96\x7a\xed\x1a\x21\xd0\x30\x05\x60\x6c\x0d\xc1\xb4\xe6\xa5\xe4\xa5\x02\x2a\x05\xd2\x9b\x68\x1d\xc8\x73\x9a\x6b\x3a\x61\xe5\x5c\x12\xd4\x5c\xd2\xa5\xce\x65\x05\x17\x8d\x01\x45\x1d\x46\xc3\x5b\x68\x44\x1c\x15\xf2\x81\x32\x11\x3f\xbc\xff\x40\x17\xbc\xfd\x8f\x4e\xff\xb1\xb6\xf4\xb5\x5f\xbf\xe1\x77\xa0\x40\xf7\x3a\xe8\x09\x9c\xbf\x91\x5f\x4a\xaa\xe0\xc7\x27\xcb\x37\x5d\x39\xa0\xeb\x0f\xf8\xcd\x66\x4f\x9f\x67\x09\xe9\xad\xd5\xdc\x88\xeb\x00\xc9\x0e\x12\xfe\x33\x01\x00\x00")
+
+func _000003_spinmint_created_at_timestampUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000003_spinmint_created_at_timestampUpSql,
+		"000003_spinmint_created_at_timestamp.up.sql",
+	)
+}
+
+func _000003_spinmint_created_at_timestampUpSql() (*asset, error) {
+	bytes, err := _000003_spinmint_created_at_timestampUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000003_spinmint_created_at_timestamp.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x93, 0x7d, 0x3c, 0xc2, 0x64, 0x4e, 0xf5, 0xc7, 0xb2, 0xf3, 0xb3, 0xec, 0xe7, 0xca, 0x8a, 0xa, 0x27, 0xd3, 0xa8, 0xa9, 0xcf, 0x75, 0x5e, 0xcf, 0x5f, 0xa3, 0x10, 0xc6, 0x7f, 0xf0, 0x98, 0xc9}}
+	return a, nil
+}
+
+var __000004_spinmint_created_byDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x5f\x6b\xc3\x20\x14\xc5\xdf\xfd\x14\x17\x9f\xe2\x08\x63\x7b\x96\x8e\x19\x73\xbb\x06\xa2\x16\xb5\x6c\x6f\xc5\xb6\x8e\x15\x9a\xac\xa4\x0e\xb6\x6f\x3f\x9a\x3f\xeb\xfe\x3d\x08\x72\x7f\xc7\xe3\x39\xb7\xc0\x87\x4a\x73\x42\x1c\x7a\xb8\xdf\x6d\x74\x68\x22\xcc\xa0\x14\x5e\x14\xc2\x61\xc6\xf8\x40\x52\xd8\x1c\xe2\x08\xa9\x3b\xee\xdb\x66\xdf\x26\x3a\xc2\xed\xeb\xe1\xad\x69\x27\x2a\xbb\x18\x52\xdc\x15\x1f\x13\x3e\x76\xf1\x18\xba\xb8\x73\x29\xa4\xd8\xc4\x36\xc1\x0c\x32\x87\x35\x4a\x0f\xd5\x3c\x23\x00\xe7\x03\x30\x8e\xa4\x59\x69\x9f\x5d\x31\x98\x5b\xa3\xa0\xd2\x73\x63\x95\xf0\x95\xd1\x6b\x27\x17\xa8\xc4\xb5\x34\xf5\x4a\x69\xd7\xbf\x79\x5c\xa0\xc5\xfe\x06\x90\xf5\x21\xd7\xed\x90\xe3\x12\x99\x8d\x5c\xe8\x72\xd2\x9c\xb6\x2f\xb1\x09\x30\x9b\x2a\xff\x90\x0c\x75\xbe\x7c\x2e\xed\xce\x2a\x06\x77\x70\x93\x13\x00\x69\xb4\x14\x3e\xa3\xa2\xf6\x68\xc1\x8b\xa2\x46\xa0\xf9\xb7\x6f\x73\xa0\x50\x5a\xb3\xec\xa7\x17\x93\x1c\x28\xa7\xec\xec\x40\xc7\xc2\xb7\x94\x30\xc6\xc9\xd2\xe2\x52\x58\x84\x70\x48\xb1\xab\x9e\xf1\x7d\x7f\x4a\xa7\x61\x09\x7f\x57\xc8\x09\x3e\xa1\x5c\xf9\x5f\x72\x4e\x48\x89\xa2\xae\x8d\x14\x1e\xe1\x5f\x47\x4e\xa4\x51\xaa\xf2\x9c\x7c\x0e\x00\x1f\x5f\x90\x8c\xfb\x01\x00\x00")
+
+func _000004_spinmint_created_byDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000004_spinmint_created_byDownSql,
+		"000004_spinmint_created_by.down.sql",
+	)
+}
+
+func _000004_spinmint_created_byDownSql() (*asset, error) {
+	bytes, err := _000004_spinmint_created_byDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000004_spinmint_created_by.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x96, 0x1a, 0x42, 0xbc, 0x24, 0xe3, 0x49, 0x50, 0xc8, 0x3a, 0xd1, 0x90, 0x53, 0x34, 0xfb, 0x93, 0x9, 0x4f, 0xa9, 0xf3, 0x7e, 0x42, 0xa7, 0x7b, 0xcb, 0xc6, 0x5d, 0x47, 0x3d, 0x98, 0xa5, 0xd3}}
+	return a, nil
+}
+
+var __000004_spinmint_created_byUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x4f\x6b\xdc\x30\x10\xc5\xef\xfa\x14\x83\x2e\xb1\x8a\x29\x6d\x21\x27\xb1\xa5\x63\x79\xdc\x35\xc8\x52\x90\xe5\xb6\xb7\xe0\x64\x55\xba\xb0\x76\x8c\x57\x85\xe6\xdb\x17\xff\xab\x1b\x42\x0e\x06\xeb\xfd\x66\x1e\xf3\x5e\x46\x5f\x4b\x23\x19\xab\xc9\xc3\x97\xd3\x83\x69\xbb\x00\x07\xc8\xd1\x63\x86\x35\x25\x42\x2e\x24\xb6\x0f\x97\xb0\x42\x5e\x0f\xe7\xbe\x3b\xf7\x91\xaf\xf0\xf1\xe9\xf2\xbb\xeb\x37\xaa\xc6\xd0\xc6\x70\xca\x9e\x5f\x62\xff\x3c\x4c\xce\xfc\x1b\x3a\x75\x44\x97\x7c\xba\xbd\x15\x60\xac\x07\xd3\x68\x0d\x39\x15\xd8\x68\x0f\x37\x37\xdb\xd6\x30\x86\xa1\x1d\xc3\xa9\x8e\x6d\x0c\x5d\xe8\x23\x1c\x20\xa9\x49\x93\xf2\x50\x16\x09\x03\x98\x3e\x80\x55\x52\xb6\x31\x3e\x79\x27\xa0\x70\xb6\x82\xd2\x14\xd6\x55\xe8\x4b\x6b\xee\x6b\x75\xa4\x0a\xdf\x2b\xab\x9b\xca\xd4\xf3\xce\xf7\x23\x39\x9a\xff\x00\x92\x39\xda\x7d\xbf\x5c\xbf\x07\x15\x2b\x47\x93\x6f\x33\xd7\xc7\x5f\xa1\x6b\xe1\xb0\x15\xf5\x62\x64\x49\xf9\xcf\x67\xef\x64\x9a\x12\xf0\x19\x3e\xa4\x0c\x80\xaf\xe7\x7e\xe4\xd3\x4b\x59\xa3\xd0\x27\x1c\xb5\x27\x07\x1e\x33\x4d\xc0\xd3\xff\x8e\x48\x81\x03\xe6\xf9\x2c\xee\x8e\x93\xba\x2b\x53\xb1\x29\x70\xc9\x05\x13\x42\xb2\x3b\x47\x77\xe8\x08\xda\x4b\x0c\x63\xf9\xd3\x3c\x45\xfa\x73\xbe\xc6\xeb\x52\xcc\xeb\x5a\x25\xa3\x1f\xa4\x1a\xff\x7a\x43\x32\x96\x13\x6a\x6d\x15\x7a\x82\xb7\x7c\x25\x53\xb6\xaa\x4a\x2f\xd9\xdf\x01\x00\x95\xc6\x80\x22\x4b\x02\x00\x00")
+
+func _000004_spinmint_created_byUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000004_spinmint_created_byUpSql,
+		"000004_spinmint_created_by.up.sql",
+	)
+}
+
+func _000004_spinmint_created_byUpSql() (*asset, error) {
+	bytes, err := _000004_spinmint_created_byUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000004_spinmint_created_by.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2c, 0x73, 0x66, 0x64, 0x73, 0xa3, 0x3f, 0x3e, 0x43, 0x57, 0xc7, 0x6, 0xcb, 0xc5, 0xb7, 0x1e, 0xd7, 0x1b, 0x30, 0x50, 0xab, 0xc4, 0x51, 0x3e, 0x41, 0x9, 0xcb, 0x49, 0x21, 0xb6, 0xd9, 0x50}}
+	return a, nil
+}
+
+var __000005_spinmint_snapshot_idDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x5f\x6b\xc2\x30\x14\xc5\xdf\xf3\x29\x2e\x79\x6a\x46\x19\xdb\x73\x70\x2c\xa6\xd7\x19\x68\x13\x49\x22\xdb\x9b\x44\xcd\x50\xb0\xb5\xd8\x0c\xf6\xf1\x87\x6d\xd5\xfd\x7b\x08\x84\xfb\x3b\x39\x39\xe7\x4e\xf1\x45\x69\x4e\x88\x43\x0f\xcf\xdb\xb5\x0e\x75\x84\x09\x14\xc2\x8b\xa9\x70\x98\x31\x3e\x90\x14\xd6\x87\x38\x42\xea\xda\x7d\x53\xef\x9b\x44\x47\xb8\x39\x1e\x3e\xea\xe6\x4a\x9b\xd0\x76\xbb\x63\x52\xdb\x0b\x6f\x4f\xb1\x0d\xa7\xb8\x75\x29\xa4\x58\xc7\x26\xc1\x04\x32\x87\x25\x4a\x0f\x6a\x96\x11\x80\xf3\x01\x18\x47\xd2\x2c\xb5\xcf\xee\x18\xcc\xac\xa9\x40\xe9\x99\xb1\x95\xf0\xca\xe8\x95\x93\x73\xac\xc4\xbd\x34\xe5\xb2\xd2\xae\x7f\xf3\x3a\x47\x8b\xfd\x0d\x20\xeb\x53\xae\x9a\x21\xc8\x2d\x33\x1b\xb9\xd0\xc5\x45\xd3\x6d\x76\xb1\x0e\x30\xb9\x74\xfe\x21\x19\xfa\x5c\x7d\x6e\xf5\xce\x2a\x06\x4f\xf0\x90\x13\x00\x69\xb4\x14\x3e\xa3\xa2\xf4\x68\xc1\x8b\x69\x89\x40\xf3\x6f\xdf\xe6\x40\xa1\xb0\x66\xd1\x4f\x6f\x26\x39\x50\x4e\xd9\xd9\x81\x8e\x85\x1f\x29\x61\x8c\x93\x85\xc5\x85\xb0\x08\xe1\x90\xe2\x49\xbd\xe3\xe7\xbe\x4b\xdd\xb0\x84\xbf\x2b\xe4\x04\xdf\x50\x2e\xfd\x2f\x39\x27\xa4\x40\x51\x96\x46\x0a\x8f\xf0\xaf\x23\x27\xd2\x54\x95\xf2\x9c\x7c\x0d\x00\x38\xe6\x3d\x74\xfc\x01\x00\x00")
+
+func _000005_spinmint_snapshot_idDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000005_spinmint_snapshot_idDownSql,
+		"000005_spinmint_snapshot_id.down.sql",
+	)
+}
+
+func _000005_spinmint_snapshot_idDownSql() (*asset, error) {
+	bytes, err := _000005_spinmint_snapshot_idDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000005_spinmint_snapshot_id.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x65, 0x74, 0xd2, 0x16, 0x7c, 0xd5, 0x36, 0x99, 0x1f, 0xf8, 0xef, 0x7e, 0xeb, 0x70, 0x7d, 0xee, 0x7f, 0x4c, 0x6d, 0xd6, 0xdd, 0xb7, 0x1e, 0x1d, 0x5, 0x36, 0xcb, 0xf7, 0xda, 0xa8, 0x51, 0xc1}}
+	return a, nil
+}
+
+var __000005_spinmint_snapshot_idUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x4f\x8b\xdb\x30\x10\xc5\xef\xfa\x14\x83\x2e\x6b\x15\x53\xda\xc2\x9e\x44\x4a\x27\xf2\xb8\x31\xc8\xd2\x22\xcb\x6d\x6f\x8b\x77\xa3\xb2\x81\xd8\x31\xb1\x0a\xed\xb7\x2f\xfe\x93\xb8\x21\xf4\x60\xb0\xde\x6f\xe6\x31\xef\x6d\xe9\x6b\x61\x24\x63\x15\x79\xf8\xb2\x7f\x31\x4d\x1b\x60\x03\x19\x7a\xdc\x62\x45\x89\x90\x33\x89\xcd\xcb\x31\x2c\x90\x57\xfd\xa1\x6b\x0f\x5d\xe4\x0b\x7c\x3d\x1d\x7f\xb5\xdd\x95\x76\x4d\x3f\xbc\x9d\x62\xb1\xbf\xe5\xfe\x4f\x3f\x5a\xf3\x6f\xe8\xd4\x0e\x5d\xf2\xe9\xf1\x51\x80\xb1\x1e\x4c\xad\x35\x64\x94\x63\xad\x3d\x3c\x3c\x5c\xb6\xfa\x73\xe8\x9b\x73\xd8\x57\xb1\x89\xa1\x0d\x5d\x84\x0d\x24\x15\x69\x52\x1e\x8a\x3c\x61\x00\xe3\x07\xb0\x48\xca\xd6\xc6\x27\xef\x04\xe4\xce\x96\x50\x98\xdc\xba\x12\x7d\x61\xcd\x73\xa5\x76\x54\xe2\x7b\x65\x75\x5d\x9a\x6a\xda\xf9\xbe\x23\x47\xd3\x1f\x40\x32\x65\x7b\xee\xe6\xf3\xd7\xa4\x62\xe1\x68\xb2\xcb\xcc\xf0\xfa\x16\xda\x06\x36\x97\xa6\x6e\x46\xe6\x94\x57\x9f\xb5\x94\x71\x4a\xc0\x67\xf8\x90\x32\x00\xbe\x9c\xfb\x91\x8f\x2f\x65\x8d\x42\x9f\x70\xd4\x9e\x1c\x78\xdc\x6a\x02\x9e\xfe\x73\x44\x0a\x1c\x30\xcb\x26\x71\x75\x1c\xd5\x55\x19\x8b\x4d\x81\x4b\x2e\x98\x10\x92\x3d\x39\x7a\x42\x47\xd0\x1c\x63\x38\x17\x3f\xcd\x29\xd2\xef\xc3\x10\x87\xb9\x98\xfb\x5a\x25\xa3\x1f\xa4\x6a\x7f\xbf\x21\x19\xcb\x08\xb5\xb6\x0a\x3d\xc1\xff\x7c\x25\x53\xb6\x2c\x0b\x2f\xd9\xdf\x01\x00\x36\x4c\xff\x22\x4c\x02\x00\x00")
+
+func _000005_spinmint_snapshot_idUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000005_spinmint_snapshot_idUpSql,
+		"000005_spinmint_snapshot_id.up.sql",
+	)
+}
+
+func _000005_spinmint_snapshot_idUpSql() (*asset, error) {
+	bytes, err := _000005_spinmint_snapshot_idUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000005_spinmint_snapshot_id.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x26, 0xed, 0xb9, 0xe5, 0xd5, 0xa4, 0x75, 0x81, 0xb9, 0xf9, 0x5b, 0x27, 0xb2, 0xc9, 0xf4, 0x71, 0xe1, 0xd2, 0x7, 0x4b, 0x62, 0x5f, 0xd7, 0xa7, 0x99, 0xbd, 0xfc, 0x99, 0x14, 0x28, 0xd, 0x65}}
+	return a, nil
+}
+
+var __000006_spinmint_variantDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x5f\x6b\xc3\x20\x14\xc5\xdf\xfd\x14\x17\x9f\xe2\x08\x63\x7b\x96\x8e\x59\x73\xbb\x06\xa2\x16\xb5\xdb\xde\x8a\x6d\x1d\x0b\x34\x59\x49\x1d\xec\xe3\x8f\x26\xe9\xb2\x7f\x0f\x82\xdc\xdf\xf1\x78\xce\x9d\xe3\x43\xa9\x39\x21\x0e\x3d\xdc\xef\xb7\x3a\x34\x11\x66\x50\x08\x2f\xe6\xc2\x61\xc6\xf8\x40\x52\xd8\x1e\xe2\x08\xa9\x3b\xd6\x6d\x53\xb7\x89\x8e\x70\xf7\x76\x78\x6f\xda\x0b\x7d\x0c\x5d\x1d\x26\x78\xec\xe2\x31\x74\x71\xef\x52\x48\xb1\x89\x6d\x82\x19\x64\x0e\x2b\x94\x1e\xca\x45\x46\x00\xce\x07\x60\x1c\x49\xb3\xd6\x3e\xbb\x62\xb0\xb0\x46\x41\xa9\x17\xc6\x2a\xe1\x4b\xa3\x37\x4e\x2e\x51\x89\x6b\x69\xaa\xb5\xd2\xae\x7f\xf3\xb4\x44\x8b\xfd\x0d\x20\xeb\x23\x6e\xda\x21\xc5\x14\x98\x8d\x5c\xe8\xe2\xa2\x39\xed\x5e\x63\x13\x60\x76\x29\xfc\x43\x32\x94\xf9\xf2\x99\xba\x9d\x55\x0c\xee\xe0\x26\x27\x00\xd2\x68\x29\x7c\x46\x45\xe5\xd1\x82\x17\xf3\x0a\x81\xe6\xdf\xbe\xcd\x81\x42\x61\xcd\xaa\x9f\x4e\x26\x39\x50\x4e\xd9\xd9\x81\x8e\x85\x6f\x29\x61\x8c\x93\x95\xc5\x95\xb0\x08\xe1\x90\x62\x57\xbe\xe0\x47\x7d\x4a\xa7\x61\x09\x7f\x57\xc8\x09\x3e\xa3\x5c\xfb\x5f\x72\x4e\x48\x81\xa2\xaa\x8c\x14\x1e\xe1\x5f\x47\x4e\xa4\x51\xaa\xf4\x9c\x7c\x0e\x00\xf6\x76\x51\x14\xf9\x01\x00\x00")
+
+func _000006_spinmint_variantDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000006_spinmint_variantDownSql,
+		"000006_spinmint_variant.down.sql",
+	)
+}
+
+func _000006_spinmint_variantDownSql() (*asset, error) {
+	bytes, err := _000006_spinmint_variantDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000006_spinmint_variant.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x93, 0x3c, 0xe1, 0xf9, 0xb9, 0x6d, 0xfb, 0x1d, 0x10, 0x28, 0x94, 0x70, 0xa2, 0x78, 0x8, 0x9f, 0x20, 0x97, 0xf7, 0x44, 0xb9, 0x43, 0x19, 0x1a, 0xf0, 0xea, 0x72, 0xac, 0x72, 0x4b, 0xea, 0x1a}}
+	return a, nil
+}
+
+var __000006_spinmint_variantUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x5f\x8b\xd4\x30\x14\xc5\xdf\xf3\x29\x2e\x79\xd9\x46\x06\x51\x10\x5f\xc2\x88\x77\xd2\x5b\xa7\x90\x26\x4b\x9a\xaa\x6f\x4b\x76\x37\x62\x61\xda\x2d\x9d\x08\xfa\xed\xa5\xff\xac\xba\xec\x43\xa1\x39\xbf\x7b\x2f\xe7\x9c\x13\x7d\x2a\x8d\x64\xac\x26\x0f\x1f\x1f\xef\x4d\xe8\x22\x1c\x21\x47\x8f\x27\xac\x29\x13\x72\x21\x29\xdc\x5f\xe2\x0a\x79\x3d\xb4\x7d\xd7\xf6\x89\xaf\xf0\xe1\xe9\xf2\xa3\xeb\x37\xfa\x39\x8c\x6d\xf8\x1f\xfa\x5f\xc3\x02\xd1\xa9\x33\xba\xec\xfd\x3b\x01\xc6\x7a\x30\x8d\xd6\x90\x53\x81\x8d\xf6\x70\x73\xb3\x2d\x0d\x63\x1c\xc2\x18\x1f\xeb\x14\x52\xec\x62\x9f\xe0\x08\x59\x4d\x9a\x94\x87\xb2\xc8\x18\xc0\xf4\x01\xac\x92\xb2\x8d\xf1\xd9\x2b\x01\x85\xb3\x15\x94\xa6\xb0\xae\x42\x5f\x5a\x73\x57\xab\x33\x55\xf8\x5a\x59\xdd\x54\xa6\x9e\x77\xbe\x9c\xc9\xd1\xfc\x07\x90\xcd\xb9\xee\xfa\xc5\xfa\x9e\x52\xac\x1c\x4d\xbe\xcd\x5c\x1f\xbe\xc7\x2e\xc0\x71\x6b\xe9\x9f\x91\x25\xe4\x9f\x3b\x7b\x21\xd3\x94\x80\x0f\xf0\xe6\xc0\x00\xf8\x6a\xf7\x2d\x9f\x5e\xca\x1a\x85\x3e\xe3\xa8\x3d\x39\xf0\x78\xd2\x04\xfc\xf0\x97\x89\x03\x70\xc0\x3c\x9f\xc5\xfd\xe2\xa4\xee\xca\xd4\xeb\x01\xb8\xe4\x82\x09\x21\xd9\xad\xa3\x5b\x74\x04\xe1\x92\xe2\x58\x7e\x33\x4f\x89\x7e\xb6\xd7\x74\x5d\x8a\x79\x5e\xab\x64\xf4\x95\x54\xe3\x9f\x6f\x48\xc6\x72\x42\xad\xad\x42\x4f\xf0\xd2\x5d\xc9\x94\xad\xaa\xd2\x4b\xf6\x7b\x00\xa6\xbb\xdb\xf4\x48\x02\x00\x00")
+
+func _000006_spinmint_variantUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000006_spinmint_variantUpSql,
+		"000006_spinmint_variant.up.sql",
+	)
+}
+
+func _000006_spinmint_variantUpSql() (*asset, error) {
+	bytes, err := _000006_spinmint_variantUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000006_spinmint_variant.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x70, 0xe5, 0x6b, 0xb5, 0x4e, 0x88, 0x45, 0xa7, 0x51, 0xe3, 0x53, 0xe6, 0x2f, 0xe8, 0x4e, 0x33, 0xe2, 0xea, 0x7d, 0xc6, 0x60, 0x54, 0xa3, 0xc9, 0x10, 0xfe, 0x3f, 0xd8, 0x59, 0x31, 0x1b, 0x31}}
+	return a, nil
+}
+
+var __000007_spinmint_historyDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x39\x00\xc6\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x60\x53\x70\x69\x6e\x6d\x69\x6e\x74\x48\x69\x73\x74\x6f\x72\x79\x60\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xb9\xab\x41\x81\x39\x00\x00\x00")
+
+func _000007_spinmint_historyDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000007_spinmint_historyDownSql,
+		"000007_spinmint_history.down.sql",
+	)
+}
+
+func _000007_spinmint_historyDownSql() (*asset, error) {
+	bytes, err := _000007_spinmint_historyDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000007_spinmint_history.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdb, 0xcb, 0x6d, 0x3b, 0xaa, 0xc7, 0xf8, 0x14, 0x17, 0x5c, 0x5e, 0x6a, 0xf1, 0x82, 0x86, 0x4e, 0x9, 0xbc, 0x19, 0x16, 0x1, 0x42, 0x40, 0xf5, 0xd6, 0x84, 0x17, 0xe5, 0x53, 0x1b, 0xe3, 0x9b}}
+	return a, nil
+}
+
+var __000007_spinmint_historyUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x91\x3b\x6f\xf2\x30\x14\x86\x77\xff\x8a\xb3\x91\x48\x2c\x20\xf8\x84\x84\x18\x4c\x30\x60\x7d\xc1\x54\x89\xa9\xca\x14\x1b\xe2\x0a\x0f\x76\x22\xe7\xd0\x96\x7f\x5f\xa5\x17\x2e\x55\xa5\xb2\x5a\xcf\x7b\xf1\x79\xa7\x6c\xc1\xc5\x98\x90\x24\x63\x54\x32\x90\x74\x9a\x32\xe0\x73\x10\x6b\x09\xec\x89\xe7\x32\x07\x95\xd7\xd6\x3b\xeb\x71\x69\x1b\xac\xc2\x49\x41\x44\x00\x14\xf7\x0d\x6a\xbf\x37\xbc\x54\xf0\xa2\xc3\xfe\xa0\x43\xd4\xeb\x8f\xe2\x0f\xa9\xd8\xa4\x69\xb7\xa5\x32\x53\x57\xeb\x57\x6f\xc2\x05\xea\x0f\x87\x31\xcc\xd8\x9c\x6e\xd2\x1f\xa0\xd0\xce\xfc\xc5\x89\xa3\xdb\xb5\x6e\xd6\x63\xd4\xeb\xfd\x02\x3c\xea\x60\xb5\xc7\x8b\xcf\xbf\xc1\xa5\xd3\x19\xef\x74\xba\xd7\x9f\x90\xa7\xda\xdc\xa7\x48\x82\xd1\x68\x4a\x8a\x0a\xd0\x3a\xd3\xa0\x76\xf5\x2d\x78\x6e\x32\x33\x0d\x86\xea\x74\x17\xfc\x90\xf1\x15\xcd\xb6\xf0\x9f\x6d\x21\xba\xbe\x6d\xdc\x5a\xb5\xaf\xca\x96\x6f\x45\xf3\x35\x45\x71\xf8\xdc\xa2\x28\xbf\x33\x0a\x8d\x0a\xa2\x9b\xcc\x98\xc4\xc0\xc4\x82\x0b\x36\xe1\xde\x57\xb3\xe9\x39\x35\x59\xd2\x2c\x67\x72\x72\xc4\xe7\x91\xdb\x0d\xc6\x84\x24\xeb\xd5\x8a\xcb\x31\x79\x1f\x00\x36\x9a\x44\xfc\x10\x02\x00\x00")
+
+func _000007_spinmint_historyUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000007_spinmint_historyUpSql,
+		"000007_spinmint_history.up.sql",
+	)
+}
+
+func _000007_spinmint_historyUpSql() (*asset, error) {
+	bytes, err := _000007_spinmint_historyUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000007_spinmint_history.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb7, 0xe4, 0x48, 0x4b, 0x2e, 0x78, 0x9c, 0x2b, 0xd3, 0x7e, 0xbd, 0x78, 0x35, 0x3f, 0xf5, 0x5f, 0x73, 0xe1, 0x89, 0x9, 0xdf, 0x33, 0x99, 0x4c, 0x74, 0x7e, 0x33, 0x72, 0xf3, 0x31, 0x0, 0xdd}}
+	return a, nil
+}
+
+var __000008_spinmint_deleted_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x5f\x6b\xc2\x30\x14\xc5\xdf\xf3\x29\x0e\x79\x6a\x47\x19\xdb\x73\x70\x2c\xa6\xd7\x59\x68\x13\x49\x22\xdb\x9b\x54\xcd\x98\xd0\x76\xa2\x19\xec\xe3\x0f\xdb\xaa\xfb\xf7\x10\x08\xf7\x77\x72\x72\xce\x9d\xd2\x53\xa1\x05\x63\x8e\x3c\x1e\xb7\x6b\x5d\xb7\x01\x13\xe4\xd2\xcb\xa9\x74\x94\xa4\x62\x20\xb1\x5e\x37\x61\x84\xdc\xed\x77\x5d\xbb\xeb\x22\x1f\xe1\xe6\xbd\xf9\x68\xbb\x33\xcd\x43\x13\x62\xd8\xca\x0b\xde\x1f\xc2\xbe\x3e\x84\xad\x8b\x75\x0c\x6d\xe8\x22\x26\x48\x1c\x95\xa4\x3c\x8a\x59\xc2\x80\xd3\x01\xc6\x91\x32\x4b\xed\x93\x9b\x14\x33\x6b\x2a\x14\x7a\x66\x6c\x25\x7d\x61\xf4\xca\xa9\x39\x55\xf2\x56\x99\x72\x59\x69\xd7\xbf\x79\x9e\x93\xa5\xfe\x06\x24\x7d\xc8\x55\x37\xe4\xb8\x46\x4e\x47\x2e\x75\x7e\xd6\x1c\x37\x6f\xa1\xad\x31\x39\x57\xfe\x21\x19\xea\x5c\x7c\xae\xed\x4e\xaa\x14\x0f\xb8\xcb\x18\xa0\x8c\x56\xd2\x27\x5c\x96\x9e\x2c\xbc\x9c\x96\x04\x9e\x7d\xfb\x36\x03\x47\x6e\xcd\xa2\x9f\x5e\x4d\x32\x70\xc1\xd3\x93\x03\x1f\x0b\xdf\x73\x96\xa6\x82\x2d\x2c\x2d\xa4\x25\xd4\x4d\x0c\x87\xe2\x95\x3e\x77\xc7\x78\x1c\x96\xf0\x77\x85\x82\xd1\x0b\xa9\xa5\xff\x25\x17\x8c\xe5\x24\xcb\xd2\x28\xe9\x09\xff\x3a\x0a\xa6\x4c\x55\x15\x5e\xb0\xaf\x01\x00\xd0\xc0\x2c\xaf\xfb\x01\x00\x00")
+
+func _000008_spinmint_deleted_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000008_spinmint_deleted_atDownSql,
+		"000008_spinmint_deleted_at.down.sql",
+	)
+}
+
+func _000008_spinmint_deleted_atDownSql() (*asset, error) {
+	bytes, err := _000008_spinmint_deleted_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000008_spinmint_deleted_at.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x28, 0x96, 0x7b, 0x76, 0xf7, 0x83, 0xa5, 0xdf, 0xf4, 0x1, 0x8a, 0x45, 0xf0, 0x3c, 0xe9, 0x8a, 0xb2, 0x97, 0xf2, 0x2d, 0xc1, 0xcd, 0xc4, 0xd2, 0xd9, 0xf5, 0xab, 0x35, 0xb9, 0x39, 0x70, 0xe4}}
+	return a, nil
+}
+
+var __000008_spinmint_deleted_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x4f\x8b\xdb\x30\x10\xc5\xef\xfa\x14\x83\x4e\x76\x31\xa5\x3d\x8b\x94\x4e\xe4\x71\x63\x90\xa5\x60\xcb\xb4\xb7\xe0\x24\x2a\x35\xd8\x8e\x89\x55\xd8\xfd\xf6\x8b\xff\xad\x77\x37\xec\x41\xa0\x79\xbf\x99\xc7\xcc\xdb\xd3\xaf\x54\x0b\xc6\x0a\xb2\xf0\xf3\x7a\xd6\x55\xeb\x60\x07\x31\x5a\xdc\x63\x41\x41\x28\x66\xe2\xab\x73\xe3\x16\xc8\x8b\xbe\xee\xda\xba\xf3\x7c\x81\x97\x5b\xf3\xbf\xed\x56\x1a\xbb\xc6\x79\x77\xc5\x0f\xd8\x3e\xf7\xa3\x33\xf7\x75\xeb\x06\x5f\xb5\x3d\xe8\x52\x29\x88\x29\xc1\x52\xd9\xa9\x58\x07\xfa\xbb\xeb\xab\xbb\xbb\x16\xbe\xf2\xae\x75\x9d\x87\x1d\x04\x05\x29\x92\x16\xd2\x24\x60\x00\xe3\x03\x58\x24\x69\x4a\x6d\x83\x2f\x21\x24\xb9\xc9\x20\xd5\x89\xc9\x33\xb4\xa9\xd1\xa7\x42\x1e\x28\xc3\xaf\xd2\xa8\x32\xd3\xc5\x34\xf3\xfb\x40\x39\x4d\x3f\x80\x60\xba\xea\xd4\xcd\x8b\x6f\x37\x86\x0b\x47\x1d\xaf\x3d\xc3\xe5\x9f\x6b\x2b\xd8\xad\x19\xbd\x6b\x99\xef\x7f\xf5\xd9\xe2\x18\xbb\x42\xf8\x01\xdf\x22\x06\xc0\x97\x75\xbf\xf3\xb1\x92\x46\x4b\xb4\x01\x47\x65\x29\x07\x8b\x7b\x45\xc0\xa3\x37\x4b\x44\xc0\x01\xe3\x78\x12\x37\xc7\x51\xdd\x94\x31\xd3\x08\xb8\xe0\x21\x0b\x43\xc1\x8e\x39\x1d\x31\x27\xa8\x1a\xef\xee\xe9\x5f\x7d\xf3\xf4\x54\x0f\x7e\x98\x83\x79\x8c\x55\x30\xfa\x43\xb2\xb4\x8f\x13\x82\xb1\x98\x50\x29\x23\xd1\x12\x7c\xe6\x2b\x98\x34\x59\x96\x5a\xc1\x5e\x06\x00\x0e\xca\xda\xab\x46\x02\x00\x00")
+
+func _000008_spinmint_deleted_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000008_spinmint_deleted_atUpSql,
+		"000008_spinmint_deleted_at.up.sql",
+	)
+}
+
+func _000008_spinmint_deleted_atUpSql() (*asset, error) {
+	bytes, err := _000008_spinmint_deleted_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000008_spinmint_deleted_at.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfa, 0xa5, 0xc7, 0x64, 0x7d, 0xd6, 0x75, 0x5, 0xa8, 0x77, 0x18, 0xef, 0xb3, 0x3, 0xb7, 0xe7, 0x41, 0x50, 0x0, 0xf8, 0x5, 0x44, 0x59, 0x58, 0xf2, 0x19, 0x9e, 0xe6, 0x28, 0x4, 0x87, 0xe6}}
+	return a, nil
+}
+
+var __000009_spinmint_labelsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x4d\x6b\x33\x21\x14\x85\xf7\xfe\x8a\x8b\xab\xf1\x65\x78\x69\xd7\x92\x52\xe3\xdc\x34\x03\x8e\x06\x35\xb4\xbb\x60\x12\x4b\x03\x33\xd3\x90\xb1\xd0\x9f\x5f\x32\x1f\x9d\x7e\x2d\x04\xb9\xcf\xf1\x78\xce\x5d\xe2\x43\xa9\x39\x21\x0e\x3d\xdc\x1f\xf7\x3a\x34\x11\x16\x50\x08\x2f\x96\xc2\x61\xc6\xf8\x40\x52\xd8\xd7\x71\x84\xd4\x9d\x4f\x6d\x73\x6a\x13\x1d\xe1\xe1\xb5\x7e\x6b\xda\x89\xaa\xb0\x8f\x75\x37\xb1\xf3\x25\x9e\xc3\x25\x1e\x5d\x0a\x29\x36\xb1\x4d\xb0\x80\xcc\xa1\x42\xe9\xa1\x5c\x65\x04\xe0\x7a\x00\xc6\x91\x34\x5b\xed\xb3\x7f\x0c\x56\xd6\x54\x50\xea\x95\xb1\x95\xf0\xa5\xd1\x3b\x27\xd7\x58\x89\xff\xd2\xa8\x6d\xa5\x5d\xff\xe6\x71\x8d\x16\xfb\x1b\x40\xd6\x27\xdc\xb5\x43\x88\x39\x2f\x1b\xb9\xd0\xc5\xa4\xe9\x0e\x2f\xb1\x09\xb0\x98\xfa\x7e\x93\x0c\x5d\x3e\x7d\xe6\x6a\x57\x15\x83\x3b\xb8\xc9\x09\x80\x34\x5a\x0a\x9f\x51\xa1\x3c\x5a\xf0\x62\xa9\x10\x68\xfe\xe5\xdb\x1c\x28\x14\xd6\x6c\xfa\xe9\x6c\x92\x03\xe5\x94\x5d\x1d\xe8\x58\xf8\x96\x12\xc6\x38\xd9\x58\xdc\x08\x8b\x10\xea\x14\x2f\xe5\x33\xbe\x9f\xba\xd4\x0d\x4b\xf8\xbd\x42\x4e\xf0\x09\xe5\xd6\xff\x90\x73\x42\x0a\x14\x4a\x19\x29\x3c\xc2\x9f\x8e\x9c\x48\x53\x55\xa5\xe7\xe4\x63\x00\x74\x53\x27\x96\xf8\x01\x00\x00")
+
+func _000009_spinmint_labelsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000009_spinmint_labelsDownSql,
+		"000009_spinmint_labels.down.sql",
+	)
+}
+
+func _000009_spinmint_labelsDownSql() (*asset, error) {
+	bytes, err := _000009_spinmint_labelsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000009_spinmint_labels.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x67, 0xfd, 0xcd, 0xdc, 0x77, 0xea, 0x21, 0xd1, 0x60, 0x64, 0xcd, 0x0, 0x23, 0xb6, 0x22, 0xc0, 0x77, 0x57, 0x62, 0x5b, 0xd4, 0xaf, 0xcb, 0x45, 0x90, 0xfb, 0x82, 0x54, 0xdf, 0x2, 0x49, 0x31}}
+	return a, nil
+}
+
+var __000009_spinmint_labelsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x5f\x6b\xc2\x30\x14\xc5\xdf\xf3\x29\x2e\x79\x6a\x47\x19\xdb\x73\x70\x2c\xa6\xd7\x59\x48\x13\x69\x22\xdb\x9b\x44\xcd\x98\xd0\xd6\x62\x33\x70\xdf\x7e\xf4\x8f\x76\x22\x7b\x28\x34\xe7\x77\xef\xe1\xdc\x33\xc7\xb7\x4c\x31\x42\x0c\x5a\x78\xdd\x6f\x95\xab\x3c\xcc\x20\xe5\x96\xcf\xb9\xc1\x28\x66\x03\x09\x6e\x5b\xfa\x11\x52\xd3\x1c\xea\xea\x50\x07\x3a\xc2\xdd\xb1\xfc\xae\xea\x0b\x95\x6e\xeb\xcb\xf6\x96\xd9\x9f\xa6\xb3\xa5\xc1\x9f\xaf\x5b\xcd\xc9\x37\xee\xe4\xf7\x26\xb8\xe0\x2b\x5f\x07\x98\x41\x64\x50\xa2\xb0\x90\x2d\x22\x02\xd0\x7d\x00\xa3\x24\xf4\x5a\xd9\xe8\x21\x86\x45\xa1\x73\xc8\xd4\x42\x17\x39\xb7\x99\x56\x1b\x23\x96\x98\xf3\x47\xa1\xe5\x3a\x57\xa6\xdf\x79\x5f\x62\x81\xfd\x1f\x40\xd4\x67\xdf\xd4\x43\xbc\xe9\x92\x78\xe4\x5c\xa5\x97\x99\x76\xf7\xe5\x2b\x07\xb3\x4b\x13\x37\x23\xc3\x25\x57\x9f\xe9\xe8\x6e\x2a\x86\x17\x78\x4a\x08\x00\x1d\xe3\x3e\xd3\xee\x25\xb4\x12\xdc\x46\x94\x4b\x8b\x05\x58\x3e\x97\x08\x34\xf9\x13\x22\x01\x0a\x3c\x4d\x7b\x71\x72\xec\xd4\x49\xe9\xca\x4b\x80\x32\x1a\x93\x38\x66\x64\x55\xe0\x8a\x17\x08\xae\x0c\xfe\x94\x7d\xaa\x63\xc0\xf3\xa1\x0d\xed\x50\xcc\x7d\xad\x8c\xe0\x07\x8a\xb5\xbd\xdf\x60\x84\xa4\xc8\xa5\xd4\x82\x5b\x84\xff\x7c\x19\x11\x3a\xcf\x33\xcb\xc8\xef\x00\x41\x73\xb0\x67\x2c\x02\x00\x00")
+
+func _000009_spinmint_labelsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000009_spinmint_labelsUpSql,
+		"000009_spinmint_labels.up.sql",
+	)
+}
+
+func _000009_spinmint_labelsUpSql() (*asset, error) {
+	bytes, err := _000009_spinmint_labelsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000009_spinmint_labels.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x68, 0xac, 0x0, 0x5c, 0x57, 0xd9, 0x7c, 0x7f, 0x65, 0x16, 0xcb, 0x10, 0x22, 0xb3, 0x7, 0xa5, 0x87, 0x65, 0xd9, 0xff, 0x17, 0x56, 0xe2, 0xa8, 0x84, 0x21, 0xae, 0x5e, 0xd3, 0x14, 0x6a, 0xb0}}
+	return a, nil
+}
+
+var __000010_spinmint_unique_prDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x91\xcd\x6a\xe3\x30\x14\x85\xf7\x7a\x8a\x8b\x56\xf6\x60\x86\x99\xb5\x49\xa9\x22\xdf\x34\x02\x5b\x0a\x96\x42\xb3\x33\x4e\xac\x52\x43\xec\x1a\x5b\x2d\x79\xfc\x12\xff\x25\xfd\x59\x15\x42\x17\x06\xa3\x73\x74\x74\xcf\xfd\x96\xf8\x20\x64\x48\x88\x46\x03\xf7\xc5\x5e\xe6\x95\x85\x05\x44\xcc\xb0\x25\xd3\xe8\xf9\xe1\xa0\xb8\x7c\x7f\xb4\xa3\x48\x75\x53\xd6\x55\x59\x3b\x3a\x8a\x65\x5d\xd8\xd3\x24\x96\xc5\x29\xeb\x46\x43\x96\x1f\x5c\xf9\x66\xb3\xa6\x9d\xac\x4d\x6b\x9b\xbc\xb5\x85\x76\xb9\xb3\x95\xad\x1d\x2c\xc0\xd3\x18\x23\x37\x20\x56\x1e\x01\x38\x7f\x00\xe3\x11\x57\x5b\x69\xbc\x3f\x3e\xac\x52\x95\x80\x90\x2b\x95\x26\xcc\x08\x25\x33\xcd\xd7\x98\xb0\xbf\xda\x30\x23\xb4\x11\x5c\xf7\xd7\x1e\xd7\x98\x62\xff\x07\xe0\xf5\x33\x67\xf5\x30\xd7\xa5\x81\x3f\xea\x4c\x46\x93\xa7\x3b\x3c\xdb\x2a\x87\xc5\xb4\x81\x0f\x96\xbe\xdd\x1c\x33\x77\x3d\x7b\x7c\xb8\x83\x7f\x01\x01\xe0\x4a\x72\x66\x3c\xca\x62\x83\x29\x18\xb6\x8c\x11\x68\x70\xf5\x68\x00\x14\xa2\x54\x6d\x40\xc8\x08\x77\xbd\x36\x27\x05\x40\x43\xea\x9f\x63\xe8\x58\xfb\x3f\x25\xbe\x1f\x92\x4d\x8a\x1b\x96\x22\xe4\x47\x67\x5b\xf1\x84\xa7\xb2\x73\xdd\xb0\x8a\xaf\x8b\x0c\x09\xee\x90\x6f\xcd\x27\x7b\x48\x22\x64\x71\xac\x38\x33\x08\xdf\x06\x4e\xf0\x0f\x2f\xc7\xd7\xaa\x9e\x30\xb2\x9e\xdc\xad\xb0\x71\x15\x6f\x13\x79\x3b\x66\x43\x97\x39\xe7\x52\xed\x87\xd4\x68\x70\x1d\xf2\xfb\xc0\xb8\x4a\x12\x61\x42\xf2\x3e\x00\xcf\x99\x59\x0b\xbe\x03\x00\x00")
+
+func _000010_spinmint_unique_prDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000010_spinmint_unique_prDownSql,
+		"000010_spinmint_unique_pr.down.sql",
+	)
+}
+
+func _000010_spinmint_unique_prDownSql() (*asset, error) {
+	bytes, err := _000010_spinmint_unique_prDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000010_spinmint_unique_pr.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x28, 0x3a, 0x54, 0xf2, 0x2f, 0x18, 0x97, 0x74, 0x81, 0x96, 0x4c, 0x73, 0x24, 0xe, 0x85, 0x7c, 0xf, 0x90, 0x30, 0x9a, 0xb8, 0xa, 0x36, 0x35, 0x8a, 0xd0, 0x24, 0x2f, 0x72, 0x4f, 0x12, 0x8}}
+	return a, nil
+}
+
+var __000010_spinmint_unique_prUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x54\xcf\x6f\xb3\x46\x10\xbd\xf3\x57\x3c\x71\x82\x8a\x58\xcd\x99\xba\xea\x06\xd6\xc9\xb6\x78\x71\x61\xdd\xa4\x27\x6b\x0d\x1b\x05\xd5\x5e\x28\xac\x93\xf8\xbf\xaf\xf8\x6d\xd7\xfa\x6e\x5f\xf4\x1d\x90\xd8\x37\x6f\x1e\xb3\x6f\x86\x79\xa0\x8f\x8c\xfb\x96\x75\x77\x87\x3f\x94\xaa\x50\xea\xc3\x19\xe6\x4d\xe1\x58\x36\x06\xb5\xca\x94\x36\x90\x99\x29\xde\x15\x9a\xaa\xd0\xc7\x42\x1b\x94\xaf\x50\x32\x7b\xc3\x26\xc1\xbb\xac\x0b\xa9\x0d\x9a\xb2\xcb\x3a\xe9\xe2\xdf\x93\xc2\x3f\xea\x8c\x4c\x6a\xec\x15\x64\x9e\xab\x7c\xd1\xea\xa7\x43\x7a\x83\xac\x56\xd2\xa8\x1c\x85\xee\x92\x1a\x79\x54\x68\x54\x56\xea\x1c\xb2\x56\x30\xe5\x21\x87\xac\x64\x6d\xb0\xef\x8a\x29\x6a\x14\xba\x31\x52\x67\x0a\x2c\x5c\x58\xdb\x4d\x48\x04\x9d\x04\x51\x1e\x72\x55\x5b\xc0\xef\x31\xe3\x33\xaa\xd5\x47\x87\x02\x31\xef\x29\x8b\x44\x55\x65\xfc\xa1\x55\x8d\x65\x1f\x9e\x91\x96\x48\x78\x78\x41\xe4\x6d\x59\x97\xbc\x16\xb8\xa6\xf1\xd3\x71\x7f\x21\xd6\x1f\xaf\x29\x7f\x0d\x06\x8d\x9c\xe1\x3c\x92\x9c\x9e\x15\xf4\x8e\x10\x83\x5f\x06\xde\x84\xb4\x4c\x20\x4e\x6e\xa9\xcb\xff\x53\x2f\x3e\xcb\x06\xbf\x58\x3e\x29\xce\x90\xeb\x8e\x9f\xef\x05\x42\x75\x50\xbd\x00\x4b\xc1\xb7\x51\x64\xa5\x54\x0c\x42\x73\x6c\x09\x1e\x3f\x3b\xae\xf5\xfc\x44\x13\x7a\x13\x1d\x32\x7d\xab\xcb\xfd\x2d\xdf\x0f\xf6\x85\x44\x90\x07\x92\x52\xc7\xf5\xfb\x88\x91\xfb\x83\x1a\x82\xf6\xd8\x2c\x7b\x08\x66\xe5\xe1\x74\xd4\x63\x94\x74\x73\x77\x1d\x13\xe7\xaa\xed\x8a\x6d\x0a\x7d\x2e\xb4\x71\xee\x5d\x3c\x52\x4e\x13\x22\x68\x08\x12\x3d\x93\xbf\x53\x90\x14\x0e\x5b\x39\x37\xc5\x79\xb8\xf7\xba\x17\xd7\x45\x2a\xe2\x84\x86\xa3\x76\x55\xab\x4a\xd6\x2a\x4f\x8d\x34\xea\xd8\xce\xfc\x12\x4e\x4a\x23\x1a\x08\xb0\x95\x63\x01\xed\x03\x0c\x50\x10\x6f\xb9\x70\x7e\x72\xb1\x4a\xe2\x35\x18\x5f\xc5\xc9\x9a\x08\x16\xf3\x5d\x1a\x3c\xd1\x35\x59\x04\x71\xb4\x5d\xf3\xb4\xcb\xe9\xfc\xea\xde\x00\xa7\xbb\xfd\x4e\xf7\x17\x9c\xbd\x70\x87\x78\xdb\x93\x81\xd3\x64\x6f\xea\x28\xb1\x1c\xbd\xbc\xa2\xf4\x5e\x4c\x3a\xb3\x6d\x2d\xcb\xc5\xaf\xf8\xd9\xb3\x00\x7b\x28\xf7\xde\x6e\x4f\x41\xcc\x03\x22\x1c\x9b\x44\x82\x26\x10\xe4\x21\xa2\xb0\xbd\x8b\x22\x3c\xd8\x20\x61\xd8\x81\xb3\x62\x8b\xce\x48\x6b\xbf\x07\xdb\xb7\x5d\xcb\x75\x7d\x6b\x93\xd0\x0d\x49\x28\xe4\xc1\xa8\x9a\xbd\xf2\xd2\xd0\xcf\xa2\x31\x4d\x6f\xcc\xad\xad\xbe\x45\x5f\x68\xb0\x15\xb7\x19\xbe\x15\x52\x12\x45\x71\xd0\xfe\xd9\xdf\x92\x1d\xa7\xab\xd0\xb9\xfa\x1c\xa7\xa4\xc8\x3f\x77\xe3\x6a\xda\xf5\xab\x6a\x57\xd5\x5f\xd5\xd9\x54\x10\xc1\x52\xc1\x82\xaf\x6b\x6e\x77\xbb\x49\x66\xba\xeb\xf7\x68\xed\x96\xb3\x3f\xb7\x14\x8c\x87\xf4\xa5\x63\x4c\xea\x2d\xc3\x99\xb6\xa1\x87\x71\xe1\x79\xe8\xb7\x9a\x87\x61\x73\x79\xe8\x7f\x4b\xf7\xc7\xcd\x40\x10\xaf\xd7\x4c\xf8\xd6\x7f\x03\x00\x2d\xa7\x53\x68\xbd\x06\x00\x00")
+
+func _000010_spinmint_unique_prUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000010_spinmint_unique_prUpSql,
+		"000010_spinmint_unique_pr.up.sql",
+	)
+}
+
+func _000010_spinmint_unique_prUpSql() (*asset, error) {
+	bytes, err := _000010_spinmint_unique_prUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000010_spinmint_unique_pr.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x43, 0x13, 0xe8, 0xd1, 0x19, 0x1d, 0xb0, 0x2, 0x84, 0x91, 0xe7, 0x88, 0xe2, 0x66, 0xc0, 0x61, 0x14, 0xdb, 0x26, 0x65, 0x83, 0xa, 0xc, 0x55, 0x9b, 0xd3, 0x2d, 0xf8, 0xc9, 0x37, 0x71, 0xd5}}
+	return a, nil
+}
+
+var __000011_spinmint_actionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x39\x00\xc6\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x60\x53\x70\x69\x6e\x6d\x69\x6e\x74\x41\x63\x74\x69\x6f\x6e\x73\x60\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x00\x38\x60\xf4\x39\x00\x00\x00")
+
+func _000011_spinmint_actionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000011_spinmint_actionsDownSql,
+		"000011_spinmint_actions.down.sql",
+	)
+}
+
+func _000011_spinmint_actionsDownSql() (*asset, error) {
+	bytes, err := _000011_spinmint_actionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000011_spinmint_actions.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd6, 0x45, 0x9a, 0x94, 0x41, 0x79, 0xe9, 0xc4, 0x16, 0xec, 0xe0, 0xe9, 0x85, 0x0, 0xb8, 0xa9, 0x51, 0xec, 0x21, 0xd6, 0x54, 0xa5, 0x8f, 0xc, 0x3c, 0xc5, 0x15, 0x43, 0xf9, 0x5f, 0x8c, 0x47}}
+	return a, nil
+}
+
+var __000011_spinmint_actionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\xc1\x6e\xea\x30\x10\x45\xf7\xfe\x8a\xd9\x91\x48\x2c\x1e\x79\x50\x21\x21\x16\x26\x0c\xd4\x6a\xe2\x54\x89\x91\xca\x2a\x36\xc4\x2d\x5e\xc4\x44\x89\x69\xfb\xf9\x95\x69\x4b\x28\x52\xbb\xb4\x7d\xae\xe7\xce\x59\xe0\x9a\xf1\x19\x21\x71\x8e\x54\x20\x08\xba\x48\x10\xd8\x0a\x78\x26\x00\x9f\x58\x21\x0a\x90\x45\x63\x6c\x6d\xac\xa3\x7b\x67\x8e\xb6\x93\x10\x10\x00\xc9\x2a\x09\x3b\xf3\x62\xac\x0b\xa2\x7f\xe1\x39\xc0\x37\x49\x02\x74\x23\xb2\x92\xf1\x38\xc7\x14\xb9\x18\x7a\x34\xd7\xcd\x31\x7b\xb3\xba\x95\xf0\xaa\xda\xfd\x41\xb5\x41\x34\x99\xf4\x99\x0b\xc4\x55\xad\xff\x62\xf8\xa9\xde\xf9\x5f\xfc\xd0\xd1\xe8\xe6\xf1\xb3\x5e\x1f\xff\x1f\xdd\x00\xcc\x76\x4e\xd9\xbd\x66\x55\x0f\x8d\xa2\x69\x4f\xc1\x12\x57\x74\x93\x08\x18\x0c\xce\x81\xe2\xa0\x7a\xf2\x6e\xfc\x3b\x18\xb7\x5a\x39\x5d\x51\x27\xc1\x99\x5a\x77\x4e\xd5\xcd\x4f\xf0\xbb\xc4\x63\xce\x52\x9a\x6f\xe1\x01\xb7\x10\x78\x87\xa1\xbf\xf5\x27\x69\xaa\xf7\xb2\xfb\x52\x5d\xaa\xf3\x32\x5d\xd9\xb4\x12\x82\x2b\x81\xc3\x2b\x51\xc3\x8b\x90\x90\x84\x80\x7c\xcd\x38\xce\x99\xb5\xc7\xe5\xe2\x32\x37\xbe\xa7\x79\x81\x62\x7e\x72\xcf\xd3\x7a\x37\x9e\x11\x12\x67\x69\xca\xc4\x8c\x7c\x0c\x00\xc7\x70\x99\xe4\xfa\x01\x00\x00")
+
+func _000011_spinmint_actionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000011_spinmint_actionsUpSql,
+		"000011_spinmint_actions.up.sql",
+	)
+}
+
+func _000011_spinmint_actionsUpSql() (*asset, error) {
+	bytes, err := _000011_spinmint_actionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000011_spinmint_actions.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x20, 0x87, 0x6b, 0xcb, 0x43, 0x5c, 0xfe, 0xca, 0x6a, 0x22, 0x91, 0xa6, 0xc3, 0x54, 0x9f, 0x64, 0xfb, 0x96, 0x6d, 0xef, 0x9d, 0x56, 0x45, 0xf4, 0xfd, 0xec, 0x17, 0x45, 0x3e, 0xd7, 0xc7, 0xc3}}
+	return a, nil
+}
+
+var __000012_spinmint_regionDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x4f\x6b\xe3\x30\x14\xc4\xef\xfa\x14\x83\x4e\xf6\x62\x96\xdd\xb3\xc8\xb2\x8a\xfc\xd2\x18\x6c\x29\x48\x0a\xed\x2d\x38\x89\xda\x1a\x62\x27\x24\x2a\xf4\xe3\x97\xf8\x4f\xd3\x7f\x07\x81\x78\xbf\xd1\x68\xe6\xcd\xe9\xae\xd0\x82\x31\x47\x1e\xff\xf7\x5b\x5d\xb7\x01\x33\xe4\xd2\xcb\xb9\x74\x94\xa4\x62\x20\xb1\xde\x1e\xc2\x08\xb9\x3b\x35\x5d\xdb\x74\x91\x8f\x70\x77\x3c\xbc\xb4\xdd\x44\x6d\x78\x6a\x8e\xdd\xc4\x4e\xe7\x70\xaa\xcf\x61\xef\x62\x1d\x43\x1b\xba\x88\x19\x12\x47\x25\x29\x8f\x62\x91\x30\xe0\x7a\x80\x71\xa4\xcc\x5a\xfb\xe4\x57\x8a\x85\x35\x15\x0a\xbd\x30\xb6\x92\xbe\x30\x7a\xe3\xd4\x92\x2a\xf9\x5b\x99\x72\x5d\x69\xd7\xbf\xb9\x5f\x92\xa5\xfe\x06\x24\x7d\xc2\x4d\x37\x84\xb8\xe5\x4d\x47\x2e\x75\x3e\x69\x2e\xbb\xe7\xd0\xd6\x98\x4d\x7d\x3f\x49\x86\x2e\xef\x3e\xb7\x6a\x57\x55\x8a\x7f\xf8\x93\x31\x40\x19\xad\xa4\x4f\xb8\x2c\x3d\x59\x78\x39\x2f\x09\x3c\xfb\xf0\x6d\x06\x8e\xdc\x9a\x55\x3f\xbd\x99\x64\xe0\x82\xa7\x57\x07\x3e\x16\xfe\xcb\x59\x9a\x0a\xb6\xb2\xb4\x92\x96\x50\x1f\x62\x38\x17\x8f\xf4\xda\x5c\xe2\x65\x58\xc2\xf7\x15\x0a\x46\x0f\xa4\xd6\xfe\x8b\x5c\x30\x96\x93\x2c\x4b\xa3\xa4\x27\xfc\xe8\x28\x98\x32\x55\x55\x78\xc1\xde\x06\x00\x29\x15\x97\x60\xf8\x01\x00\x00")
+
+func _000012_spinmint_regionDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000012_spinmint_regionDownSql,
+		"000012_spinmint_region.down.sql",
+	)
+}
+
+func _000012_spinmint_regionDownSql() (*asset, error) {
+	bytes, err := _000012_spinmint_regionDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000012_spinmint_region.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x53, 0xe5, 0xc2, 0xad, 0x4b, 0x48, 0xa1, 0x29, 0x7a, 0x5d, 0x3a, 0x8b, 0x6, 0x1c, 0x22, 0xa5, 0x34, 0x73, 0x16, 0x5f, 0xc0, 0x11, 0x2b, 0xab, 0xc4, 0x67, 0x8b, 0x9, 0x7d, 0x57, 0x37, 0xa7}}
+	return a, nil
+}
+
+var __000012_spinmint_regionUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\xdf\xeb\xd3\x30\x14\xc5\xdf\xf3\x57\x5c\xf2\xf2\x6d\xa4\x88\x82\xf8\x12\x26\x66\xe9\xad\x2b\xa4\xc9\x68\x53\xf4\x6d\x64\x5b\x74\x85\xb5\x2b\x5d\x14\xfd\xef\xa5\xbf\xac\x63\x7c\x1f\x0a\xcd\xf9\xdc\x7b\xb8\xe7\x6c\xf1\x4b\xa6\x39\x21\x25\x5a\xf8\x7c\x3e\x6a\xd7\x78\xd8\x40\x22\xac\xd8\x8a\x12\x23\xc6\x27\x12\xdc\xf1\xea\x67\x48\xcb\xae\x6e\x9b\xba\x0d\x74\x86\xa7\xdb\xf5\x67\xd3\x2e\xb4\xf0\x3f\xea\x5b\xfb\xc8\xec\x9f\x6e\xb0\xa5\xbf\x5c\x7f\xba\xb8\x3e\xfa\xf8\x81\x81\x36\x16\x74\xa5\x14\x24\x98\x8a\x4a\x59\x78\x79\x59\x96\xba\xde\x77\xae\xf7\xe7\x32\xb8\xe0\x1b\xdf\x06\xd8\x40\x54\xa2\x42\x69\x21\x4b\x23\x02\x30\x7c\x00\xb3\x24\x4d\xa5\x6d\xf4\x86\x41\x5a\x98\x1c\x32\x9d\x9a\x22\x17\x36\x33\xfa\x50\xca\x1d\xe6\xe2\xad\x34\xaa\xca\x75\x39\xee\x7c\xdd\x61\x81\xe3\x1f\x40\x34\xc6\x3a\xb4\xd3\xe5\x6b\x48\x36\x73\xa1\x93\x65\xe6\x7e\xba\xf8\xc6\xc1\x66\x29\xe9\x61\x64\x0a\xf9\xcf\x67\xed\x63\x98\x62\xf0\x09\xde\xc5\x04\x80\xce\xe7\xbe\xa7\xc3\x4b\x1a\x2d\x85\x8d\xa8\x50\x16\x0b\xb0\x62\xab\x10\x68\xfc\xdf\x11\x31\x50\x10\x49\x32\x8a\xab\xe3\xa0\xae\xca\xd0\x6b\x0c\x94\x53\x46\x18\xe3\x64\x5f\xe0\x5e\x14\x08\xee\x1a\x7c\x9f\x7d\xd7\xb7\x80\xbf\xeb\x7b\xb8\x4f\xc5\x3c\xd7\xca\x09\x7e\x43\x59\xd9\xe7\x0d\x4e\x48\x82\x42\x29\x23\x85\x45\x78\xcd\x97\x13\x69\xf2\x3c\xb3\x9c\xfc\x1d\x00\x6c\x8a\xbe\x0b\x47\x02\x00\x00")
+
+func _000012_spinmint_regionUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000012_spinmint_regionUpSql,
+		"000012_spinmint_region.up.sql",
+	)
+}
+
+func _000012_spinmint_regionUpSql() (*asset, error) {
+	bytes, err := _000012_spinmint_regionUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000012_spinmint_region.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf8, 0xf6, 0xd7, 0x33, 0x1e, 0xde, 0xad, 0xc2, 0xef, 0x40, 0x47, 0xd8, 0xe0, 0x18, 0x9c, 0x5c, 0x61, 0x95, 0xfa, 0x50, 0x13, 0x7a, 0xb9, 0x60, 0x74, 0xb5, 0x75, 0xce, 0x8b, 0xed, 0xe9, 0x1}}
+	return a, nil
+}
+
+var __000013_systemsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x31\x00\xce\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x60\x53\x79\x73\x74\x65\x6d\x73\x60\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xdf\x7f\x64\x02\x31\x00\x00\x00")
+
+func _000013_systemsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000013_systemsDownSql,
+		"000013_systems.down.sql",
+	)
+}
+
+func _000013_systemsDownSql() (*asset, error) {
+	bytes, err := _000013_systemsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000013_systems.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7f, 0x85, 0xfb, 0xfc, 0x6a, 0xd3, 0x62, 0x1e, 0x12, 0x1, 0x4, 0xfb, 0x80, 0x8, 0xa7, 0x87, 0xe9, 0x19, 0x18, 0x37, 0x5e, 0x8d, 0x67, 0x2, 0x2, 0xad, 0x53, 0xb8, 0x9a, 0x21, 0xfa, 0x90}}
+	return a, nil
+}
+
+var __000013_systemsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x24\x8b\x41\x6b\x83\x30\x1c\x47\xef\xf9\x14\xbf\xa3\xc2\x8e\x32\x06\xe2\x21\xea\x5f\x17\x16\xe3\x48\xe2\x98\xb7\xa4\x25\xa5\x87\x6a\x41\x63\x69\xbf\x7d\xb1\x3d\x3e\xde\x7b\x25\xb5\x42\xe5\x8c\x55\x9a\xb8\x25\x58\x5e\x4a\x82\x68\xa0\x7a\x0b\xfa\x17\xc6\x1a\x38\xf3\x58\x63\x98\x56\x87\x84\x01\x4e\xf9\x29\x38\xdc\xfc\x72\x3c\xfb\x25\xf9\xcc\xd2\x57\xab\x06\x29\x3f\x76\xfd\xe7\x2f\x5b\x70\x88\xe1\x1e\x77\xfe\xd5\xa2\xe3\x7a\xc4\x0f\x8d\x48\xde\x6f\xca\x52\x90\x6a\x85\xa2\x42\xcc\xf3\xb5\x2e\x51\x53\xc3\x07\x69\x51\x7d\x73\x6d\xc8\x16\x5b\x3c\x7d\x4d\x87\x2c\x67\xac\xea\xbb\x4e\xd8\x9c\x3d\x07\x00\x58\xd6\x22\x6e\xa7\x00\x00\x00")
+
+func _000013_systemsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000013_systemsUpSql,
+		"000013_systems.up.sql",
+	)
+}
+
+func _000013_systemsUpSql() (*asset, error) {
+	bytes, err := _000013_systemsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000013_systems.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x9a, 0x6d, 0x4b, 0x8a, 0xe8, 0xc1, 0xb, 0x4d, 0x8d, 0x7b, 0xdc, 0x46, 0x22, 0x34, 0x1e, 0x27, 0x96, 0x95, 0x67, 0x9d, 0xd8, 0x29, 0x8d, 0x47, 0x6d, 0x8f, 0x1c, 0xa9, 0xc5, 0xcd, 0x51, 0x96}}
+	return a, nil
+}
+
+var __000014_spinmint_subdomainDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x5f\x6b\xc2\x30\x14\xc5\xdf\xf3\x29\x2e\x79\x6a\x46\x19\xdb\x73\x70\x2c\xa6\xd7\x59\x68\x13\x49\x22\xdb\x9b\x44\xcd\x58\xc1\x46\xd1\x08\xfb\xf8\xc3\xb6\xda\xfd\x7b\x08\x84\xfb\x3b\x39\x39\xe7\x4e\xf1\xa5\x54\x9c\x10\x8b\x0e\x9e\xb7\x6b\xe5\xdb\x00\x13\x28\x84\x13\x53\x61\x31\x63\xbc\x27\xc9\xaf\x77\x61\x80\xd4\x1e\x9a\xd8\x36\x31\xd1\x01\x6e\xf6\xbb\x73\x1b\x6f\xf4\xbc\xde\xee\x5b\xdf\xc4\x2b\x3e\x1c\xc3\xc1\x1f\xc3\xd6\x26\x9f\x42\x1b\x62\x82\x09\x64\x16\x2b\x94\x0e\xca\x59\x46\x00\x2e\x07\x60\x18\x49\xbd\x54\x2e\xbb\x63\x30\x33\xba\x86\x52\xcd\xb4\xa9\x85\x2b\xb5\x5a\x59\x39\xc7\x5a\xdc\x4b\x5d\x2d\x6b\x65\xbb\x37\xaf\x73\x34\xd8\xdd\x00\xb2\x2e\xe4\x2a\xf6\x39\xc6\xc8\x6c\xe0\x42\x15\x57\xcd\x69\xf3\x11\x5a\x0f\x93\x6b\xe5\x1f\x92\xbe\xce\xcd\x67\x6c\x77\x51\x31\x78\x82\x87\x9c\x00\x48\xad\xa4\x70\x19\x15\x95\x43\x03\x4e\x4c\x2b\x04\x9a\x7f\xfb\x36\x07\x0a\x85\xd1\x8b\x6e\x3a\x9a\xe4\x40\x39\x65\x17\x07\x3a\x14\x7e\xa4\x84\x31\x4e\x16\x06\x17\xc2\x20\xf8\x5d\x0a\xc7\xf2\x1d\x3f\x9b\x53\x3a\xf5\x4b\xf8\xbb\x42\x4e\xf0\x0d\xe5\xd2\xfd\x92\x73\x42\x0a\x14\x55\xa5\xa5\x70\x08\xff\x3a\x72\x22\x75\x5d\x97\x8e\x93\xaf\x01\x00\xe3\x80\xcb\x03\xfb\x01\x00\x00")
+
+func _000014_spinmint_subdomainDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000014_spinmint_subdomainDownSql,
+		"000014_spinmint_subdomain.down.sql",
+	)
+}
+
+func _000014_spinmint_subdomainDownSql() (*asset, error) {
+	bytes, err := _000014_spinmint_subdomainDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000014_spinmint_subdomain.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf1, 0xc0, 0x69, 0xa4, 0xf3, 0x2, 0x5f, 0x15, 0xed, 0x5c, 0x8, 0x79, 0xe5, 0x8e, 0x16, 0x25, 0xa2, 0x20, 0xf4, 0xb8, 0x77, 0x5c, 0x7d, 0x14, 0x19, 0xb3, 0xd4, 0xc0, 0x9b, 0x69, 0x7b, 0x87}}
+	return a, nil
+}
+
+var __000014_spinmint_subdomainUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\xcd\xea\xdb\x30\x10\xc4\xef\x7a\x8a\x45\x97\xbf\x55\x4c\x69\xa1\xf4\x22\x52\xaa\xc8\xeb\xc6\x20\x4b\xc1\x96\x69\x6f\x41\x49\x54\x62\x88\x1d\xe3\x28\xa5\x7d\xfb\xe2\x8f\xc4\x0d\xa1\x07\x83\x35\xbf\xdd\x61\x67\xd6\xf8\x2d\xd3\x9c\x90\x12\x2d\x7c\x3d\xee\xb5\x6b\x3c\xac\x20\x11\x56\xac\x45\x89\x11\xe3\x13\x09\x6e\x7f\xf6\x33\xa4\x65\x57\xb7\x4d\xdd\x06\x3a\xc3\xc3\xe5\x7c\x6b\xda\x07\xbd\xed\x8f\x97\xc6\xd5\xed\x33\xb6\x7f\xba\xc1\x99\xfe\x72\xfd\xe1\xe4\xfa\xe8\xf3\x27\x06\xda\x58\xd0\x95\x52\x90\x60\x2a\x2a\x65\xe1\xed\xed\xbe\xd4\xf5\xbe\x73\xbd\x3f\x96\xc1\x05\xdf\xf8\x36\xc0\x0a\xa2\x12\x15\x4a\x0b\x59\x1a\x11\x80\xe1\x03\x98\x25\x69\x2a\x6d\xa3\x77\x0c\xd2\xc2\xe4\x90\xe9\xd4\x14\xb9\xb0\x99\xd1\xbb\x52\x6e\x30\x17\xef\xa5\x51\x55\xae\xcb\x71\xe7\xfb\x06\x0b\x1c\xff\x00\xa2\x31\xd9\xae\x9d\x8e\x5f\x72\xb2\x99\x0b\x9d\xdc\x67\xae\x87\x93\x6f\x1c\xac\xee\x3d\x3d\x8d\x4c\x21\x1f\x3e\x4b\x25\xc3\x14\x83\x2f\xf0\x21\x26\x00\x74\x3e\xf7\x23\x1d\x5e\xd2\x68\x29\x6c\x44\x85\xb2\x58\x80\x15\x6b\x85\x40\xe3\x7f\x8e\x88\x81\x82\x48\x92\x51\x5c\x1c\x07\x75\x51\x86\x5e\x63\xa0\x9c\x32\xc2\x18\x27\xdb\x02\xb7\xa2\x40\x70\xe7\xe0\xfb\xec\xa7\xbe\x04\xfc\x5d\x5f\xc3\x75\x2a\xe6\xb5\x56\x4e\xf0\x07\xca\xca\xbe\x6e\x70\x42\x12\x14\x4a\x19\x29\x2c\xc2\xff\x7c\x39\x91\x26\xcf\x33\xcb\xc9\xdf\x01\x00\x1d\xcc\x4d\xd4\x4a\x02\x00\x00")
+
+func _000014_spinmint_subdomainUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000014_spinmint_subdomainUpSql,
+		"000014_spinmint_subdomain.up.sql",
+	)
+}
+
+func _000014_spinmint_subdomainUpSql() (*asset, error) {
+	bytes, err := _000014_spinmint_subdomainUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000014_spinmint_subdomain.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x46, 0xea, 0xd, 0x93, 0x5f, 0x6f, 0xce, 0xe0, 0x26, 0xf, 0xfb, 0x37, 0x27, 0x3, 0x28, 0x6d, 0xf0, 0x12, 0xd1, 0x52, 0xfa, 0xb5, 0xfc, 0x2b, 0xa1, 0x19, 0x2, 0x89, 0x9e, 0x4a, 0xd8, 0xb3}}
+	return a, nil
+}
+
+var __000015_spinmint_stateDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x5f\x6b\xc2\x30\x14\xc5\xdf\xf3\x29\x0e\x79\x6a\x46\x19\xdb\x73\x70\x2c\xa6\xd7\x59\x68\x13\x49\x23\xdb\x9b\x54\xcd\x98\x60\x3b\xd1\x0c\xf6\xf1\x87\x6d\xb5\xfb\xf7\x10\x08\xf7\x77\x72\x72\xce\x9d\xd2\x53\x6e\x24\x63\x15\x79\x3c\x6e\xd7\xa6\x6e\x02\x26\xc8\x94\x57\x53\x55\x51\x22\x64\x4f\x62\xbd\xde\x87\x01\xf2\xea\xb0\x6b\x9b\x5d\x1b\xf9\x00\x37\xef\xfb\x8f\xa6\xbd\xd2\x58\xc7\x70\x41\x87\x63\x38\xd4\xc7\xb0\xed\x86\x4d\x68\x23\x26\x48\x2a\x2a\x48\x7b\xe4\xb3\x84\x01\xe7\x03\x0c\x23\x6d\x97\xc6\x27\x37\x02\x33\x67\x4b\xe4\x66\x66\x5d\xa9\x7c\x6e\xcd\xaa\xd2\x73\x2a\xd5\xad\xb6\xc5\xb2\x34\x55\xf7\xe6\x79\x4e\x8e\xba\x1b\x90\x74\x01\x57\x6d\x9f\x61\x8c\x2b\x06\xae\x4c\x76\xd1\x9c\x36\x6f\xa1\xa9\x31\xb9\xd4\xfd\x21\xe9\xab\x5c\x7d\xc6\x66\x67\x95\xc0\x03\xee\x52\x06\x68\x6b\xb4\xf2\x09\x57\x85\x27\x07\xaf\xa6\x05\x81\xa7\xdf\xbe\x4d\xc1\x91\x39\xbb\xe8\xa6\xa3\x49\x0a\x2e\xb9\x38\x3b\xf0\xa1\xf0\x3d\x67\x42\x48\xb6\x70\xb4\x50\x8e\x50\xef\x63\x38\xe6\xaf\xf4\xb9\x3b\xc5\x53\xbf\x84\xbf\x2b\x94\x8c\x5e\x48\x2f\xfd\x2f\xb9\x64\x2c\x23\x55\x14\x56\x2b\x4f\xf8\xd7\x51\x32\x6d\xcb\x32\xf7\x92\x7d\x0d\x00\x47\xe8\x92\x64\xf7\x01\x00\x00")
+
+func _000015_spinmint_stateDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000015_spinmint_stateDownSql,
+		"000015_spinmint_state.down.sql",
+	)
+}
+
+func _000015_spinmint_stateDownSql() (*asset, error) {
+	bytes, err := _000015_spinmint_stateDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000015_spinmint_state.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x4f, 0x64, 0x80, 0xc0, 0xb9, 0x5b, 0x38, 0x93, 0xfc, 0x4c, 0x2, 0x59, 0xfa, 0xf7, 0x25, 0xd0, 0xda, 0xab, 0xc7, 0x50, 0xd7, 0xb8, 0xb3, 0x3c, 0x19, 0x34, 0xa9, 0x19, 0xcf, 0x29, 0xeb, 0xab}}
+	return a, nil
+}
+
+var __000015_spinmint_stateUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x4f\x8b\xdb\x30\x10\xc5\xef\xfa\x14\x83\x2e\xb1\x8a\x29\xfd\x73\x14\x29\x55\xe4\x71\x63\x90\xa5\x60\xcb\xb4\xb7\xa0\x24\x2a\x09\xc4\x8e\x71\xd4\xb2\xfb\xed\x17\xff\x49\xbc\x21\xec\xc1\x60\xbd\xdf\xcc\x63\xde\x5b\xe1\xaf\x4c\x73\x42\x4a\xb4\xf0\xf3\xb0\xd3\xae\xf6\xb0\x84\x44\x58\xb1\x12\x25\x46\x8c\x8f\x24\xb8\xdd\xd9\x4f\x90\x96\xed\xa9\xa9\x4f\x4d\xa0\x13\xdc\x5f\xce\xff\xea\xe6\x4e\x83\x0b\xfe\x11\xd9\xd7\xb6\x77\xa5\xff\x5d\xb7\x3f\xba\x2e\xfa\xfe\x8d\x81\x36\x16\x74\xa5\x14\x24\x98\x8a\x4a\x59\x58\x2c\x6e\x4b\x6d\xe7\x5b\xd7\xf9\xc3\xe0\x54\xfb\x26\xc0\x12\xa2\x12\x15\x4a\x0b\x59\x1a\x11\x80\xfe\x03\x98\x24\x69\x2a\x6d\xa3\x4f\x0c\xd2\xc2\xe4\x90\xe9\xd4\x14\xb9\xb0\x99\xd1\xdb\x52\xae\x31\x17\x9f\xa5\x51\x55\xae\xcb\x61\xe7\xf7\x1a\x0b\x1c\xfe\x00\xa2\x21\xd5\xb6\x19\x0f\x9f\x33\xb2\x89\x0b\x9d\xdc\x66\xae\xfb\xa3\xaf\x1d\x2c\x6f\x1d\x3d\x8c\x8c\x21\xef\x3e\x73\x1d\xfd\x14\x83\x1f\xf0\x25\x26\x00\x74\x3a\xf7\x2b\xed\x5f\xd2\x68\x29\x6c\x44\x85\xb2\x58\x80\x15\x2b\x85\x40\xe3\x77\x47\xc4\x40\x41\x24\xc9\x20\xce\x8e\xbd\x3a\x2b\x7d\xaf\x31\x50\x4e\x19\x61\x8c\x93\x4d\x81\x1b\x51\x20\xb8\x73\xf0\x5d\xf6\x57\x5f\x02\xbe\x9c\xae\xe1\x3a\x16\xf3\x5c\x2b\x27\xf8\x07\x65\x65\x9f\x37\x38\x21\x09\x0a\xa5\x8c\x14\x16\xe1\x23\x5f\x4e\xa4\xc9\xf3\xcc\x72\xf2\x36\x00\x19\xd3\x75\x5f\x46\x02\x00\x00")
+
+func _000015_spinmint_stateUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000015_spinmint_stateUpSql,
+		"000015_spinmint_state.up.sql",
+	)
+}
+
+func _000015_spinmint_stateUpSql() (*asset, error) {
+	bytes, err := _000015_spinmint_stateUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000015_spinmint_state.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x79, 0xdd, 0x2c, 0x2a, 0xf9, 0x6d, 0x1a, 0x80, 0x7c, 0x8, 0xf3, 0x2, 0xf7, 0x67, 0x6d, 0x61, 0xe9, 0x8c, 0x82, 0x2e, 0xff, 0x64, 0x5e, 0x3b, 0xb1, 0xaf, 0x83, 0xe2, 0x1c, 0x6e, 0x6f, 0x8e}}
+	return a, nil
+}
+
+var __000016_spinmint_credentials_commentDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\xcd\x6a\xc3\x30\x10\x84\xef\x7a\x8a\x45\x27\xbb\x98\xd2\x9e\x45\x4a\x15\x79\xd3\x18\x6c\x29\x48\x32\xed\x2d\x28\x89\x4a\x0d\xb6\x13\x6c\x15\xfa\xf8\xc5\x7f\x4d\xff\x0e\x02\xb1\xdf\x68\x34\xb3\x6b\x7c\xca\x24\x23\xc4\xa0\x85\xc7\xd3\x41\xba\xc6\xc3\x0a\x52\x6e\xf9\x9a\x1b\x8c\x62\x36\x91\xe0\x0e\xb5\x9f\x21\x35\x97\xaa\x6d\xaa\x36\xd0\x19\x1e\xcf\xf5\x7b\xd3\x2e\x54\x74\xfe\xe4\xdb\x50\xb9\xba\x17\xe7\xa6\xf1\x6d\x28\x75\xbe\x48\x2f\x9d\xbf\xb8\xce\x9f\x4c\x70\xc1\x0f\x0c\x56\x10\x19\xcc\x51\x58\xc8\x36\x11\x01\x18\x0e\xc0\x3c\x12\xaa\x94\x36\xba\x89\x61\xa3\x55\x01\x99\xdc\x28\x5d\x70\x9b\x29\xb9\x37\x62\x8b\x05\xbf\x15\x2a\x2f\x0b\x69\xc6\x37\xcf\x5b\xd4\x38\xde\x00\xa2\x31\xf0\xbe\x9d\x32\x5d\xe3\xc7\x33\xe7\x32\x5d\x34\xfd\xf1\xcd\x37\x0e\x56\x4b\xfd\x1f\x92\xa9\xda\x97\xcf\xb5\xe9\xa0\x8a\xe1\x01\xee\x12\x02\x20\x94\x14\xdc\x46\x94\xe7\x16\x35\x58\xbe\xce\x11\x68\xf2\xed\xdb\x04\x28\xa4\x5a\xed\xc6\xe9\xd5\x24\x01\xca\x68\x3c\x38\xd0\xb9\xf0\x3d\x25\x71\xcc\xc8\x4e\xe3\x8e\x6b\x04\x57\x07\xdf\x65\xaf\xf8\x51\xf5\xa1\x9f\x96\xf0\x77\x85\x8c\xe0\x0b\x8a\xd2\xfe\x92\x33\x42\x52\xe4\x79\xae\x04\xb7\x08\xff\x3a\x32\x22\x54\x51\x64\x96\x91\xcf\x01\x00\x7a\x29\x23\x22\x07\x02\x00\x00")
+
+func _000016_spinmint_credentials_commentDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000016_spinmint_credentials_commentDownSql,
+		"000016_spinmint_credentials_comment.down.sql",
+	)
+}
+
+func _000016_spinmint_credentials_commentDownSql() (*asset, error) {
+	bytes, err := _000016_spinmint_credentials_commentDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000016_spinmint_credentials_comment.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x88, 0xbb, 0xd2, 0x8e, 0x1f, 0x23, 0xe4, 0x46, 0xf8, 0x7b, 0xf8, 0x5b, 0x91, 0xcf, 0xaf, 0x60, 0x54, 0x94, 0x7f, 0x89, 0xae, 0xa8, 0x82, 0xea, 0x65, 0x7e, 0x45, 0xf9, 0xf1, 0xe9, 0x2, 0x8e}}
+	return a, nil
+}
+
+var __000016_spinmint_credentials_commentUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x50\x4d\x8b\xdb\x30\x14\xbc\xeb\x57\x3c\x74\x59\xab\x98\xd2\x16\xf6\x24\x52\xaa\xc8\xcf\x5d\x83\x2c\x2d\xb6\x4c\x7b\x5b\xb4\x89\x4a\x0c\xb6\x63\x6c\xb5\xb4\xff\xbe\xf8\xab\x6e\x08\x7b\x10\x48\x33\xf3\x46\x6f\xe6\x88\x5f\x33\xcd\x09\x29\xd1\xc2\x97\xf3\xab\x76\xad\x87\x03\x24\xc2\x8a\xa3\x28\x31\x62\x7c\x61\x82\x7b\x6d\xfc\x4a\xd2\xb2\xaf\xbb\xb6\xee\x02\x5d\xc9\xd3\xb5\xf9\xd9\x76\x1b\x2b\x07\x7f\xf6\x5d\xa8\x5d\x33\xca\x6b\xdb\xfa\x2e\x54\x85\xba\x95\xda\x3f\xfd\xf4\x0b\xfd\xe5\x86\xd3\xc5\x0d\xd1\xa7\xc7\x47\x06\xda\x58\xd0\x95\x52\x90\x60\x2a\x2a\x65\xe1\xe1\x61\x9b\xea\x07\xdf\xbb\xc1\x9f\xcb\xe0\x82\x9f\x1c\xe1\x00\x51\x89\x0a\xa5\x85\x2c\x8d\x08\xc0\x74\x00\x56\x48\x9a\x4a\xdb\xe8\x1d\x83\xb4\x30\x39\x64\x3a\x35\x45\x2e\x6c\x66\xf4\x4b\x29\x9f\x30\x17\xef\xa5\x51\x55\xae\xcb\x79\xe6\xdb\x13\x16\x38\xdf\x00\xa2\x39\xe6\x4b\xb7\x24\xd9\x43\xb3\x95\x17\x3a\xd9\x34\xe3\xe9\xe2\x5b\x07\x87\xad\xb4\x1b\xc9\x92\xf2\x9f\xcf\xde\xcf\xa4\x62\xf0\x19\x3e\xc4\x04\x80\xae\xeb\x7e\xa4\xd3\x4b\x1a\x2d\x85\x8d\xa8\x50\x16\x0b\xb0\xe2\xa8\x10\x68\xfc\xdf\x12\x31\x50\x10\x49\x32\x83\xbb\xe3\x84\xee\xc8\x54\x6c\x0c\x94\x53\x46\x18\xe3\xe4\xb9\xc0\x67\x51\x20\xb8\x26\xf8\x21\xfb\xa1\xaf\x01\x7f\xd7\x63\x18\x97\x62\xee\x6b\xe5\x04\xbf\xa3\xac\xec\xfd\x04\x27\x24\x41\xa1\x94\x91\xc2\x22\xbc\xe5\xcb\x89\x34\x79\x9e\x59\x4e\xfe\x0e\x00\x35\x0e\x9c\xac\x57\x02\x00\x00")
+
+func _000016_spinmint_credentials_commentUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000016_spinmint_credentials_commentUpSql,
+		"000016_spinmint_credentials_comment.up.sql",
+	)
+}
+
+func _000016_spinmint_credentials_commentUpSql() (*asset, error) {
+	bytes, err := _000016_spinmint_credentials_commentUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000016_spinmint_credentials_comment.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x46, 0x37, 0xc7, 0x39, 0x1b, 0xe, 0xb, 0xe2, 0x2b, 0xdd, 0x25, 0x37, 0x38, 0xd6, 0x56, 0x9a, 0x51, 0x97, 0x71, 0x9c, 0xa8, 0xd, 0x52, 0xd, 0x7c, 0x11, 0xca, 0x6f, 0x63, 0x97, 0x67, 0x13}}
+	return a, nil
+}
+
+var __000017_spinmint_state_changed_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x5f\x6b\xc2\x30\x14\xc5\xdf\xf3\x29\x0e\x79\x6a\x47\x19\xdb\x73\x70\x2c\xa6\xd7\x59\x68\x13\x49\x23\xdb\x9b\x44\xcd\xa6\x60\x3b\xd1\x0c\xf6\xf1\x87\x6d\xd5\xfd\x7b\x08\x84\xfb\x3b\x39\x39\xe7\x8e\xe9\xa9\xd0\x82\xb1\x9a\x1c\x1e\xd7\x4b\xed\x9b\x80\x11\x72\xe9\xe4\x58\xd6\x94\xa4\xa2\x27\xd1\x2f\x77\x61\x80\xbc\xde\x6f\xdb\x66\xdb\x46\x3e\xc0\xd5\xfb\xee\xa3\x69\x2f\x34\xfa\x18\xd4\xc6\xb7\x6f\x61\x2d\x2f\x9a\xfd\x21\xec\xfd\x21\xac\x3b\xda\x84\x36\x62\x84\xa4\xa6\x92\x94\x43\x31\x49\x18\x70\x3a\xc0\x30\x52\x66\xae\x5d\x72\x93\x62\x62\x4d\x85\x42\x4f\x8c\xad\xa4\x2b\x8c\x5e\xd4\x6a\x4a\x95\xbc\x55\xa6\x9c\x57\xba\xee\xde\x3c\x4f\xc9\x52\x77\x03\x92\x2e\xe9\xa2\xed\xc3\x5c\x73\xa7\x03\x97\x3a\x3f\x6b\x8e\xab\x4d\x68\x3c\x46\xe7\xde\x3f\x24\x7d\xa7\x8b\xcf\xb5\xe2\x49\x95\xe2\x01\x77\x19\x03\x94\xd1\x4a\xba\x84\xcb\xd2\x91\x85\x93\xe3\x92\xc0\xb3\x6f\xdf\x66\xe0\xc8\xad\x99\x75\xd3\xab\x49\x06\x2e\x78\x7a\x72\xe0\x43\xe1\x7b\xce\xd2\x54\xb0\x99\xa5\x99\xb4\x04\xbf\x8b\xe1\x50\xbc\xd2\xe7\xf6\x18\x8f\xfd\x12\xfe\xae\x50\x30\x7a\x21\x35\x77\xbf\xe4\x82\xb1\x9c\x64\x59\x1a\x25\x1d\xe1\x5f\x47\xc1\x94\xa9\xaa\xc2\x09\xf6\x35\x00\x6b\xde\xf1\xb7\x00\x02\x00\x00")
+
+func _000017_spinmint_state_changed_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000017_spinmint_state_changed_atDownSql,
+		"000017_spinmint_state_changed_at.down.sql",
+	)
+}
+
+func _000017_spinmint_state_changed_atDownSql() (*asset, error) {
+	bytes, err := _000017_spinmint_state_changed_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000017_spinmint_state_changed_at.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x30, 0xd2, 0x5d, 0x3f, 0xd1, 0x97, 0x8d, 0xba, 0x11, 0x7c, 0x48, 0x17, 0x3b, 0x8b, 0xb9, 0x29, 0xf, 0xcb, 0x41, 0xb6, 0x37, 0x74, 0xfc, 0xb7, 0x15, 0xa8, 0x70, 0xe8, 0xa6, 0x8f, 0xc5, 0xeb}}
+	return a, nil
+}
+
+var __000017_spinmint_state_changed_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x50\xcd\x6a\xf3\x30\x10\xbc\xeb\x29\x16\x9d\xec\x0f\xf3\xd1\x9e\x45\x4a\x15\x79\xdd\x18\x64\x29\xd8\x32\xed\x2d\x28\x89\xda\x18\x62\xc7\xc4\x2a\xb4\x6f\x5f\xfc\x93\xb8\x6d\xe8\x41\xa0\x9d\x99\x1d\x66\x67\x89\x4f\xa9\x62\x84\x14\x68\xe0\x71\xbf\x55\xb6\x76\xb0\x80\x98\x1b\xbe\xe4\x05\x06\x21\x1b\x19\x6f\xb7\x47\x37\x91\xb4\x68\xab\xa6\xae\x1a\x4f\x27\x72\x77\x3a\xbe\xd7\xcd\x95\xf5\xd6\x3b\x71\xb0\xcd\x9b\xdb\xf3\x5f\x1a\xf3\xd9\xf6\xf6\xd4\x57\xb5\xeb\xbc\xad\x5b\x50\xa5\x94\x10\x63\xc2\x4b\x69\x86\xe1\xb2\xd0\x9e\x5d\x6b\xcf\x6e\x3f\xd8\xd5\xae\xf1\xb0\x80\xa0\x40\x89\xc2\x40\x9a\x04\x04\xa0\x7f\x00\x13\x24\x74\xa9\x4c\xf0\x2f\x84\x24\xd7\x19\xa4\x2a\xd1\x79\xc6\x4d\xaa\xd5\xa6\x10\x2b\xcc\xf8\x7f\xa1\x65\x99\xa9\x62\xd8\x79\x5e\x61\x8e\xc3\x0f\x20\x18\x4e\xdb\x34\x63\xfa\xf9\xd0\x70\xe2\xb9\x8a\x2f\x9a\x6e\x77\x70\xb5\x85\xc5\xa5\xa8\x1f\x92\xb1\x84\xab\xcf\xdc\x49\xaf\x0a\xe1\x01\xee\x22\x02\x40\xa7\xb8\xf7\xb4\x9f\x84\x56\x82\x9b\x80\x72\x69\x30\x07\xc3\x97\x12\x81\x46\xdf\x42\x44\x40\x81\xc7\xf1\x00\xce\x8e\x3d\x3a\x23\x7d\xa7\x11\x50\x46\x43\x12\x86\x8c\xac\x73\x5c\xf3\x1c\xc1\x1e\xbd\x3b\xa7\xaf\xea\xe4\xf1\xa3\xea\x7c\x37\x16\x73\x5b\x2b\x23\xf8\x82\xa2\x34\xb7\x1b\x8c\x90\x18\xb9\x94\x5a\x70\x83\xf0\x97\x2f\x23\x42\x67\x59\x6a\x18\xf9\x1a\x00\xf1\xba\xc1\xea\x4b\x02\x00\x00")
+
+func _000017_spinmint_state_changed_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000017_spinmint_state_changed_atUpSql,
+		"000017_spinmint_state_changed_at.up.sql",
+	)
+}
+
+func _000017_spinmint_state_changed_atUpSql() (*asset, error) {
+	bytes, err := _000017_spinmint_state_changed_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000017_spinmint_state_changed_at.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x0, 0xee, 0xd3, 0xe7, 0x9a, 0x21, 0x64, 0x28, 0x49, 0x34, 0xfb, 0x9e, 0x35, 0x98, 0xd9, 0xf5, 0xf5, 0xd8, 0xa3, 0x62, 0xda, 0x7f, 0x43, 0x9f, 0x9b, 0x44, 0xc8, 0xba, 0xda, 0x25, 0x68, 0xe}}
+	return a, nil
+}
+
+var __000018_spinmint_unique_subdomainDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x52\x4d\x8b\xdb\x30\x14\xbc\xeb\x57\x3c\x74\xb2\x8b\x29\xed\xd9\xa4\x54\x91\x5f\x1a\x81\x2d\x05\x4b\xa1\xb9\x19\x25\xd6\xb2\x86\xd8\x09\xb1\xb2\xe4\xe7\x2f\xf1\x57\xb2\x1f\xa7\x85\xb0\x07\x83\xd1\xcc\x1b\xbd\x99\xd1\x1c\xff\x09\x19\x13\xa2\xd1\xc0\xdf\x72\x2b\x6d\xed\x60\x06\x09\x33\x6c\xce\x34\x06\x61\xdc\x23\xde\x6e\xf7\x6e\x00\xa9\x3e\x56\x4d\x5d\x35\x9e\x0e\x60\xd5\x94\xee\x32\x82\x55\x79\x29\xda\x81\x50\xd8\x9d\xaf\x5e\x5c\xd1\x9e\xb7\xe5\xa1\xb6\x55\x33\x4e\x1c\x4f\xee\x68\x4f\xae\xd4\xde\x7a\x57\xbb\xc6\xc3\x0c\x02\x8d\x29\x72\x03\x62\x11\x10\x80\xeb\x07\x30\x1c\x71\xb5\x96\x26\xf8\x11\xc2\x22\x57\x19\x08\xb9\x50\x79\xc6\x8c\x50\xb2\xd0\x7c\x89\x19\xfb\xa9\x0d\x33\x42\x1b\xc1\x75\x37\xf6\x7f\x89\x39\x76\x7f\x00\x41\xb7\x7a\xd1\xf4\xeb\xdd\x8c\x84\x03\xce\x64\x32\x72\xda\xdd\xb3\xab\x2d\xcc\xc6\x20\xde\x50\x3a\x93\x93\xcc\x64\xf9\xca\x09\xe1\x0f\xfc\x8a\x08\x00\x57\x92\x33\x13\x50\x96\x1a\xcc\xc1\xb0\x79\x8a\x40\xa3\xbb\x4b\x23\xa0\x90\xe4\x6a\x05\x42\x26\xb8\xe9\xb0\x49\x29\x02\x1a\xd3\xf0\x2a\x43\x07\xdb\xbf\x29\x09\xc3\x98\xac\x72\x5c\xb1\x1c\xc1\xee\xbd\x3b\x89\x27\xbc\x54\xad\x6f\xfb\x28\x3e\x06\x19\x13\xdc\x20\x5f\x9b\x77\xf4\x98\x24\xc8\xd2\x54\x71\x66\x10\x3e\x15\x1c\xdf\xc0\xee\xb0\x3f\xd7\xcd\xd8\x26\xeb\x0a\xd4\x8f\xee\x8f\xab\x74\x9d\xc9\xc7\x95\xd7\x9b\x9a\x74\x6e\x1e\xbf\x58\x1f\x8d\xee\x45\xbe\xbf\x39\xae\xb2\x4c\x98\x98\xbc\x0e\x00\x96\xbc\x68\xae\xce\x03\x00\x00")
+
+func _000018_spinmint_unique_subdomainDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000018_spinmint_unique_subdomainDownSql,
+		"000018_spinmint_unique_subdomain.down.sql",
+	)
+}
+
+func _000018_spinmint_unique_subdomainDownSql() (*asset, error) {
+	bytes, err := _000018_spinmint_unique_subdomainDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000018_spinmint_unique_subdomain.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x8e, 0x1c, 0x37, 0x39, 0x96, 0x3f, 0xd4, 0xa2, 0x5c, 0x83, 0xd8, 0x23, 0x57, 0xd5, 0x74, 0x4c, 0x1f, 0x56, 0x5d, 0xcb, 0x54, 0x4e, 0xe1, 0x7d, 0x11, 0xd7, 0xb8, 0x83, 0xec, 0x4e, 0xbf, 0x75}}
+	return a, nil
+}
+
+var __000018_spinmint_unique_subdomainUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x54\x4d\x6f\xdb\x38\x14\xbc\xeb\x57\xcc\xea\x62\x69\xa1\x04\x9b\x3d\x15\x10\x1c\x94\x91\xe8\x84\x85\x4c\xa5\x22\x8d\xa4\x27\x83\xb6\xd8\x58\x88\x25\xbb\x12\x1d\x24\xff\xbe\xa0\xac\x8f\x24\x6e\x6f\x29\x7a\xb3\x67\x86\xc3\xf7\xe6\x3d\xf1\x8a\x5e\x33\x1e\x3a\xce\xd9\x19\x32\x5d\xa9\x52\xe7\x68\xf6\x45\x55\x16\x95\x69\xd0\x6c\x54\x5d\x54\x0f\x50\x68\x0e\xab\x7c\x57\xaa\xa2\xc2\xc3\x0e\x2b\xb5\x7e\x84\xd9\xc1\x6c\x74\x51\xa3\xa8\x1a\xa3\xaa\xb5\x06\x8b\xd1\xb4\x20\x0e\x55\xf1\xe3\xa0\xf1\xa8\x5f\xb0\x56\x15\x56\x1a\x2a\xcf\x75\x7e\xee\x2c\x6e\x63\x22\x29\x44\x77\x03\xb6\xca\xe8\xda\x01\xbe\xa4\x8c\x8f\xa8\x56\xf5\xb6\x68\x71\x20\xe5\x47\xd1\x79\xa6\x1f\x8a\x5d\x85\x69\xcf\x76\x80\x15\x11\x1e\x77\x22\x31\x94\x39\xea\x06\xec\xad\x94\x75\x65\xb3\x1c\x97\x83\x76\x04\x7b\x71\xcf\xc4\x7a\xab\x8d\xce\x89\x01\x13\xe0\x8b\x24\x71\x04\x95\xbf\xb8\x75\x32\x71\xee\x6e\x68\x46\x4f\xa8\x7f\xa6\x98\x4c\x5e\xdd\x7f\x62\x18\x3a\xad\xe5\xe7\x7c\xc5\x55\xa9\x31\x45\x4c\x24\xb9\x22\x82\x7a\x7e\x78\x64\x8c\x5a\x6d\x75\x47\xba\x7d\x5a\x6e\x47\xae\x77\xdb\x43\x59\xf5\x2c\x59\x9b\xe2\x49\x0f\xb7\xbf\x15\xc9\x97\xbd\xf5\x77\x9f\x54\xbd\xde\xa8\xda\xbb\xf8\xff\x93\x8f\x6b\xca\x69\x46\x24\x8d\x41\x92\x3b\xf2\x4d\x80\x08\x78\x6c\xe6\x9d\xd4\x19\x80\xcd\xbc\xb1\x2f\xdb\x56\x80\x31\xb8\x00\x03\xe7\x07\xed\x01\xdf\x87\x90\x69\x46\xe3\xbe\x8a\x7d\xad\xf7\xaa\xd6\xb9\x30\xca\xe8\x52\x57\x06\x53\x78\x82\x26\x34\x92\xd6\xdb\x01\x3c\x1b\x3f\x3a\x28\x4a\x17\x5c\x7a\xff\xfa\x98\x65\xe9\x1c\x8c\xcf\xd2\x6c\x4e\x24\x4b\xf9\x52\x44\x37\x74\x4e\xce\xa3\x34\x59\xcc\xb9\x68\xcf\xb4\xe1\xb7\xbf\x00\xaf\x0d\x6c\x69\xb7\x1a\xd3\x57\xf1\xf9\x1d\x6f\x87\xd1\x69\x9a\xf5\x46\x97\xca\xaa\xf2\xd5\x89\xe4\x98\xda\xe0\x33\x26\x6d\x55\x3e\x2e\xf1\x5f\xe0\x00\x6e\x57\xee\x85\x6b\xff\x45\x29\x8f\x88\xf4\x5c\x92\x48\x9a\x41\x92\xab\x84\xc2\x0d\x5e\x15\x11\xc0\x05\x89\xe3\x16\x1c\x1d\x2d\x3a\x22\x76\x50\x01\xdc\xd0\xf5\x1d\xdf\x0f\x9d\xdb\x8c\xde\x92\x8c\x42\x6d\x8d\xae\xd9\x77\xbe\x33\xf4\xb9\x68\x4c\x73\x0c\xe6\x34\xd6\xd0\xa1\xf7\x34\x5a\xc8\xd3\x13\xa1\x13\x53\x92\x24\x69\x64\xbf\xc6\xdf\xd9\xf6\x0b\x59\x54\xb9\x7e\xee\x17\xab\xc8\x9f\x97\xfd\x03\xb1\x54\xed\x96\x2d\x9b\xf7\x6b\xf6\xd1\x03\x16\x92\x48\x26\x24\x8b\xfe\xdc\x8c\xdb\x26\x07\x9b\xa1\xe5\x8f\x98\xf0\x82\xb3\xaf\x0b\x0a\xc6\x63\x7a\xdf\x2a\x06\x77\xab\xf0\x8e\xef\x58\x80\x77\x9f\xac\xff\xf7\xa6\x1e\xa5\xf3\x39\x93\xa1\xf3\x73\x00\x7b\xfb\x0b\x29\x1c\x06\x00\x00")
+
+func _000018_spinmint_unique_subdomainUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000018_spinmint_unique_subdomainUpSql,
+		"000018_spinmint_unique_subdomain.up.sql",
+	)
+}
+
+func _000018_spinmint_unique_subdomainUpSql() (*asset, error) {
+	bytes, err := _000018_spinmint_unique_subdomainUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000018_spinmint_unique_subdomain.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7d, 0x31, 0x9c, 0x13, 0x75, 0x94, 0x53, 0x48, 0x72, 0xba, 0x35, 0x3f, 0x7, 0xfb, 0xcf, 0xa, 0x7, 0xc2, 0x54, 0x60, 0xf, 0xe1, 0x55, 0x9a, 0x51, 0xf0, 0xf5, 0x4b, 0xcf, 0x91, 0x21, 0x62}}
+	return a, nil
+}
+
+var __000019_spinmint_drop_variantDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x94\xcf\x8a\xdb\x30\x10\xc6\xef\x7e\x8a\xc1\x97\xb5\x8b\x29\x2d\x94\x5e\x4c\x4a\x15\x79\xd2\x08\x6c\x29\x95\xe5\x76\x6f\x46\x49\x54\x6a\x88\x1d\x63\x6b\xdb\xec\xdb\x17\xff\x4b\x9a\xee\x86\x76\x0b\xcb\x5e\x72\x08\x28\xf3\x8d\x06\xcd\xfc\x3e\xcf\x1c\x3f\x31\x1e\x3a\x4e\x8a\x0a\x3e\x6e\xd7\x5c\x97\x06\x66\x10\x11\x45\xe6\x24\x45\xcf\x0f\x07\xc5\xea\xf5\xce\x8c\xa2\x9b\xd6\x45\x55\x16\x95\x75\x47\x71\xb3\xdf\xdd\x95\xd5\xa4\x7e\xd1\x4d\xa1\xff\x14\xd5\x7d\x3d\x88\x44\xd2\x25\x91\xde\xfb\x77\x3e\x70\xa1\x80\x67\x71\x0c\x11\x2e\x48\x16\x2b\xb8\xb9\x99\x2e\xd5\x8d\xa9\x75\x63\xb6\xa9\xd5\xd6\x94\xa6\xb2\x30\x03\x2f\xc5\x18\xa9\x02\xb6\xf0\x1c\x80\xee\x07\x30\x86\xa8\xc8\xb8\xf2\x5e\xf9\xb0\x90\x22\x01\xc6\x17\x42\x26\x44\x31\xc1\xf3\x94\x2e\x31\x21\xaf\xa9\x88\xb3\x84\xa7\xfd\x9d\xaf\x4b\x94\xd8\x9f\x00\xbc\xbe\xaf\xbc\x1a\x9e\x7e\xea\xd2\x1f\x75\xc2\xa3\x29\xa7\xdd\x7c\x37\xa5\x86\xd9\x34\xa5\xb3\x94\xa1\xc9\x63\x9d\xd3\x40\xba\x2c\x1f\x3e\xc0\x9b\xc0\x01\x70\xc7\xe7\xbe\x75\xbb\x7f\x54\x70\x4a\x94\xe7\x92\x58\xa1\x04\x45\xe6\x31\x82\x1b\xfc\xf6\x88\x00\x5c\x20\x51\xd4\x07\x4f\x15\xbb\xe8\x29\xd2\xcd\x35\x00\x37\x74\x7d\xc7\xf7\x43\x67\x25\x71\x45\x24\x82\xde\x59\xd3\xb0\x6f\x7c\x6f\xf1\x50\xb4\xb6\x1d\x06\xf3\x70\xac\xa1\x83\xb7\x48\x33\xf5\xf0\x46\xe8\x44\x48\xe2\x58\x50\xa2\x10\x2e\x95\x9d\x7c\xf3\xa8\x3b\x96\x45\x6b\xf7\xcd\xfd\xd5\x24\x57\x93\x5c\x36\xc9\xc4\xb2\xa8\xb6\xe6\x30\x89\xc5\xf6\x90\xb7\x63\x42\xae\x37\xb6\xf8\x61\xf2\xba\x79\x2e\xec\xa9\x22\x8a\xa5\x8a\xd1\xe7\x23\xdf\x77\x77\x2c\x73\xec\xf5\x8c\xfb\x3f\x91\x8e\xa4\x58\x01\xe3\x11\xde\xf6\x78\x8f\x95\x86\x15\x70\x6e\x9f\xc7\x58\x3f\x11\xf4\xdf\x29\x5f\x11\x3f\x01\xf1\x7f\x7e\xda\x19\x67\x9f\x33\xbc\x80\x1d\x3c\x69\xea\xbd\xf8\x59\x99\x26\x80\xee\x38\xc4\xf9\x5d\xb9\xee\x22\xe3\x9e\x0d\x80\xf4\x33\xf6\x5f\x6e\x07\x50\x91\x24\x4c\x85\xce\xaf\x01\x00\xe2\xcd\x47\xd1\x71\x08\x00\x00")
+
+func _000019_spinmint_drop_variantDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000019_spinmint_drop_variantDownSql,
+		"000019_spinmint_drop_variant.down.sql",
+	)
+}
+
+func _000019_spinmint_drop_variantDownSql() (*asset, error) {
+	bytes, err := _000019_spinmint_drop_variantDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000019_spinmint_drop_variant.down.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7b, 0x91, 0xde, 0x45, 0x75, 0xc6, 0x23, 0x4b, 0x54, 0x1d, 0x79, 0xa2, 0x8a, 0x96, 0xf6, 0x7c, 0xed, 0x40, 0x0, 0x2, 0x6f, 0xf, 0xd6, 0xd9, 0xe8, 0x3b, 0x1e, 0xfb, 0xa7, 0xcd, 0xe2, 0x39}}
+	return a, nil
+}
+
+var __000019_spinmint_drop_variantUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x54\x5f\x4f\xdb\x3e\x14\x7d\xcf\xa7\x38\xbf\xbc\x90\xfc\x54\xd0\xf6\x1c\x75\x9a\x49\xcc\x88\x94\x3a\x5d\xe2\x0e\xde\x2a\xd3\x18\x6a\x91\x38\x9d\xe3\x02\xfd\xf6\x53\xfe\x95\x6e\x63\x88\x4d\x42\xda\x24\x1e\x22\x59\x3e\xc7\xc7\xf7\xdc\x73\x9d\x53\xfa\x29\x66\x81\xe3\x1c\x1f\x23\xdf\x28\x5d\x29\x6d\x71\x27\x8c\x12\xda\x36\x10\x46\x42\xd7\x28\x6b\x7d\x23\x0d\x56\x46\x0a\x2b\x8b\x13\x10\xbd\x43\x29\xaf\x6d\x87\x57\xc2\xdc\xca\x02\x85\x2c\xa5\x95\x05\x9a\x1a\x76\x2d\x2c\xec\x5a\x62\x63\x54\x25\xcc\xae\xd5\x6e\x46\x6d\xd5\x74\x50\xad\xcb\x1d\xc4\xca\xaa\x3b\x89\x5a\x4b\xd4\xd7\x50\xb6\xc1\x3c\x83\xd0\x45\xc7\xd8\x6a\xf5\x75\x2b\x71\x2b\x77\x58\x09\x8d\x9b\x1a\xf7\xca\xae\xeb\x6d\x2f\x3d\x94\x78\xe2\x2c\xe6\x11\xe1\x74\x5f\xbb\x93\x53\x8e\xa8\xaf\x85\x58\x4c\xc1\xd2\x0b\xcf\x77\x2e\xce\x69\x46\xf1\xa5\x3f\x84\xff\xa6\x38\x3a\x02\x61\xd1\x01\x33\xce\xc1\x16\x49\x12\x38\x9d\xc2\xc7\xe2\x8a\x89\x4a\x62\x8a\x88\x70\x72\x4a\x72\xea\xf9\x41\x8f\x58\x71\x55\xca\x01\x74\xc7\x6b\xdd\x01\x54\xba\x90\x0f\x23\xa8\x8a\x87\xe5\xe8\x7b\xd9\x7b\x5d\x6e\xcc\x48\xdd\x18\xb9\x11\x46\x16\xb9\x15\x56\x56\x52\xb7\xc5\x7a\x39\x4d\x68\xc8\x11\x9f\x79\x0e\xd0\x7e\xc0\xb0\x15\xa6\x0b\xc6\xbd\xff\x7d\x9c\x65\xe9\x0c\x31\x3b\x4b\xb3\x19\xe1\x71\xca\x96\x79\x78\x4e\x67\xe4\x24\xe7\x84\xc7\x39\x8f\xc3\xbc\x3b\xd6\x39\xee\x56\x80\xd7\xd5\xbc\xd4\x7d\x5d\x8f\x0e\xfc\x01\x6f\x3b\x31\x70\x9a\xd5\x5a\x56\x02\xd3\xb1\x03\xdf\x51\x3a\x77\x7b\x99\xbd\xd7\x96\xe3\xe3\x03\xde\x4d\x1c\x20\x4c\x59\x48\xb8\xe7\x92\x84\xd3\x0c\x9c\x9c\x26\x14\xee\xe4\xe0\xd2\x09\x5c\x44\x59\x3a\x47\xcc\x22\x7a\xd9\x61\x7b\xa5\x09\xdc\xc0\xf5\x5b\x19\x77\xb0\xfd\xde\x75\x7c\x3f\x70\xe6\x19\x9d\x93\x8c\x42\x94\x56\x9a\xf8\x9a\x3e\xa8\xc6\x36\x7d\x2b\x7e\x6e\x64\xe0\xd0\x4b\x1a\x2e\xf8\x0f\xf4\xc0\x89\x28\x49\x92\x34\x6c\xe7\xe5\x49\xc1\x31\xfc\xb7\x88\x9f\x8d\xf8\x31\x9b\x17\x07\x4e\xa2\x08\x0b\x16\x7f\x5e\xd0\x5f\xc4\x0e\x2f\x93\x9b\x3a\xbd\xd7\xd2\x4c\xd0\x2e\xfb\x7d\xb6\xad\xae\xda\x1d\xd2\xb5\xd6\x0f\x5c\xff\xa9\x71\x60\xb5\xfd\xcd\x89\xd8\x9f\x78\x6e\x28\x0e\x48\x2f\x98\x8b\x55\x5d\x6e\x2b\x3d\xa2\xc3\xaf\xe6\xb5\x26\x21\x4c\x93\xc5\x8c\xbd\xde\x18\xf4\x66\xf6\x3a\x8f\xde\xfe\xf0\xad\xbb\x93\x43\x91\xbf\xf9\x99\x9f\xab\xc6\xd6\x66\xf7\x96\xea\x3f\x98\x6a\x98\xce\x66\x31\x0f\x9c\x6f\x03\x00\x7b\x2e\x4b\xdb\xd4\x08\x00\x00")
+
+func _000019_spinmint_drop_variantUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__000019_spinmint_drop_variantUpSql,
+		"000019_spinmint_drop_variant.up.sql",
+	)
+}
+
+func _000019_spinmint_drop_variantUpSql() (*asset, error) {
+	bytes, err := _000019_spinmint_drop_variantUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "000019_spinmint_drop_variant.up.sql", size: 0, mode: os.FileMode(0644), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x87, 0xed, 0xe4, 0x3f, 0xe3, 0x8b, 0xa9, 0x9b, 0x8d, 0xad, 0xa3, 0x43, 0xbe, 0x51, 0x38, 0xe5, 0x20, 0x52, 0x60, 0x53, 0x43, 0xe5, 0xed, 0xda, 0x4f, 0x48, 0xa8, 0x88, 0x21, 0x8c, 0x7, 0x9b}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -243,10 +957,44 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"000001_base.down.sql":          _000001_baseDownSql,
-	"000001_base.up.sql":            _000001_baseUpSql,
-	"000002_add_milestone.down.sql": _000002_add_milestoneDownSql,
-	"000002_add_milestone.up.sql":   _000002_add_milestoneUpSql,
+	"000001_base.down.sql":                          _000001_baseDownSql,
+	"000001_base.up.sql":                            _000001_baseUpSql,
+	"000002_add_milestone.down.sql":                 _000002_add_milestoneDownSql,
+	"000002_add_milestone.up.sql":                   _000002_add_milestoneUpSql,
+	"000003_spinmint_created_at_timestamp.down.sql": _000003_spinmint_created_at_timestampDownSql,
+	"000003_spinmint_created_at_timestamp.up.sql":   _000003_spinmint_created_at_timestampUpSql,
+	"000004_spinmint_created_by.down.sql":           _000004_spinmint_created_byDownSql,
+	"000004_spinmint_created_by.up.sql":             _000004_spinmint_created_byUpSql,
+	"000005_spinmint_snapshot_id.down.sql":          _000005_spinmint_snapshot_idDownSql,
+	"000005_spinmint_snapshot_id.up.sql":            _000005_spinmint_snapshot_idUpSql,
+	"000006_spinmint_variant.down.sql":              _000006_spinmint_variantDownSql,
+	"000006_spinmint_variant.up.sql":                _000006_spinmint_variantUpSql,
+	"000007_spinmint_history.down.sql":              _000007_spinmint_historyDownSql,
+	"000007_spinmint_history.up.sql":                _000007_spinmint_historyUpSql,
+	"000008_spinmint_deleted_at.down.sql":           _000008_spinmint_deleted_atDownSql,
+	"000008_spinmint_deleted_at.up.sql":             _000008_spinmint_deleted_atUpSql,
+	"000009_spinmint_labels.down.sql":               _000009_spinmint_labelsDownSql,
+	"000009_spinmint_labels.up.sql":                 _000009_spinmint_labelsUpSql,
+	"000010_spinmint_unique_pr.down.sql":            _000010_spinmint_unique_prDownSql,
+	"000010_spinmint_unique_pr.up.sql":              _000010_spinmint_unique_prUpSql,
+	"000011_spinmint_actions.down.sql":              _000011_spinmint_actionsDownSql,
+	"000011_spinmint_actions.up.sql":                _000011_spinmint_actionsUpSql,
+	"000012_spinmint_region.down.sql":               _000012_spinmint_regionDownSql,
+	"000012_spinmint_region.up.sql":                 _000012_spinmint_regionUpSql,
+	"000013_systems.down.sql":                       _000013_systemsDownSql,
+	"000013_systems.up.sql":                         _000013_systemsUpSql,
+	"000014_spinmint_subdomain.down.sql":            _000014_spinmint_subdomainDownSql,
+	"000014_spinmint_subdomain.up.sql":              _000014_spinmint_subdomainUpSql,
+	"000015_spinmint_state.down.sql":                _000015_spinmint_stateDownSql,
+	"000015_spinmint_state.up.sql":                  _000015_spinmint_stateUpSql,
+	"000016_spinmint_credentials_comment.down.sql":  _000016_spinmint_credentials_commentDownSql,
+	"000016_spinmint_credentials_comment.up.sql":    _000016_spinmint_credentials_commentUpSql,
+	"000017_spinmint_state_changed_at.down.sql":     _000017_spinmint_state_changed_atDownSql,
+	"000017_spinmint_state_changed_at.up.sql":       _000017_spinmint_state_changed_atUpSql,
+	"000018_spinmint_unique_subdomain.down.sql":     _000018_spinmint_unique_subdomainDownSql,
+	"000018_spinmint_unique_subdomain.up.sql":       _000018_spinmint_unique_subdomainUpSql,
+	"000019_spinmint_drop_variant.down.sql":         _000019_spinmint_drop_variantDownSql,
+	"000019_spinmint_drop_variant.up.sql":           _000019_spinmint_drop_variantUpSql,
 }
 
 // AssetDebug is true if the assets were built with the debug flag enabled.
@@ -297,6 +1045,40 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"000001_base.up.sql": {_000001_baseUpSql, map[string]*bintree{}},
 	"000002_add_milestone.down.sql": {_000002_add_milestoneDownSql, map[string]*bintree{}},
 	"000002_add_milestone.up.sql": {_000002_add_milestoneUpSql, map[string]*bintree{}},
+	"000003_spinmint_created_at_timestamp.down.sql": {_000003_spinmint_created_at_timestampDownSql, map[string]*bintree{}},
+	"000003_spinmint_created_at_timestamp.up.sql": {_000003_spinmint_created_at_timestampUpSql, map[string]*bintree{}},
+	"000004_spinmint_created_by.down.sql": {_000004_spinmint_created_byDownSql, map[string]*bintree{}},
+	"000004_spinmint_created_by.up.sql": {_000004_spinmint_created_byUpSql, map[string]*bintree{}},
+	"000005_spinmint_snapshot_id.down.sql": {_000005_spinmint_snapshot_idDownSql, map[string]*bintree{}},
+	"000005_spinmint_snapshot_id.up.sql": {_000005_spinmint_snapshot_idUpSql, map[string]*bintree{}},
+	"000006_spinmint_variant.down.sql": {_000006_spinmint_variantDownSql, map[string]*bintree{}},
+	"000006_spinmint_variant.up.sql": {_000006_spinmint_variantUpSql, map[string]*bintree{}},
+	"000007_spinmint_history.down.sql": {_000007_spinmint_historyDownSql, map[string]*bintree{}},
+	"000007_spinmint_history.up.sql": {_000007_spinmint_historyUpSql, map[string]*bintree{}},
+	"000008_spinmint_deleted_at.down.sql": {_000008_spinmint_deleted_atDownSql, map[string]*bintree{}},
+	"000008_spinmint_deleted_at.up.sql": {_000008_spinmint_deleted_atUpSql, map[string]*bintree{}},
+	"000009_spinmint_labels.down.sql": {_000009_spinmint_labelsDownSql, map[string]*bintree{}},
+	"000009_spinmint_labels.up.sql": {_000009_spinmint_labelsUpSql, map[string]*bintree{}},
+	"000010_spinmint_unique_pr.down.sql": {_000010_spinmint_unique_prDownSql, map[string]*bintree{}},
+	"000010_spinmint_unique_pr.up.sql": {_000010_spinmint_unique_prUpSql, map[string]*bintree{}},
+	"000011_spinmint_actions.down.sql": {_000011_spinmint_actionsDownSql, map[string]*bintree{}},
+	"000011_spinmint_actions.up.sql": {_000011_spinmint_actionsUpSql, map[string]*bintree{}},
+	"000012_spinmint_region.down.sql": {_000012_spinmint_regionDownSql, map[string]*bintree{}},
+	"000012_spinmint_region.up.sql": {_000012_spinmint_regionUpSql, map[string]*bintree{}},
+	"000013_systems.down.sql": {_000013_systemsDownSql, map[string]*bintree{}},
+	"000013_systems.up.sql": {_000013_systemsUpSql, map[string]*bintree{}},
+	"000014_spinmint_subdomain.down.sql": {_000014_spinmint_subdomainDownSql, map[string]*bintree{}},
+	"000014_spinmint_subdomain.up.sql": {_000014_spinmint_subdomainUpSql, map[string]*bintree{}},
+	"000015_spinmint_state.down.sql": {_000015_spinmint_stateDownSql, map[string]*bintree{}},
+	"000015_spinmint_state.up.sql": {_000015_spinmint_stateUpSql, map[string]*bintree{}},
+	"000016_spinmint_credentials_comment.down.sql": {_000016_spinmint_credentials_commentDownSql, map[string]*bintree{}},
+	"000016_spinmint_credentials_comment.up.sql": {_000016_spinmint_credentials_commentUpSql, map[string]*bintree{}},
+	"000017_spinmint_state_changed_at.down.sql": {_000017_spinmint_state_changed_atDownSql, map[string]*bintree{}},
+	"000017_spinmint_state_changed_at.up.sql": {_000017_spinmint_state_changed_atUpSql, map[string]*bintree{}},
+	"000018_spinmint_unique_subdomain.down.sql": {_000018_spinmint_unique_subdomainDownSql, map[string]*bintree{}},
+	"000018_spinmint_unique_subdomain.up.sql": {_000018_spinmint_unique_subdomainUpSql, map[string]*bintree{}},
+	"000019_spinmint_drop_variant.down.sql": {_000019_spinmint_drop_variantDownSql, map[string]*bintree{}},
+	"000019_spinmint_drop_variant.up.sql": {_000019_spinmint_drop_variantUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
@@ -8,6 +8,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/mattermost/mattermost-mattermod/model"
 	reflect "reflect"
+	time "time"
 )
 
 // MockSpinmintStore is a mock of SpinmintStore interface
@@ -33,6 +34,35 @@ func (m *MockSpinmintStore) EXPECT() *MockSpinmintStoreMockRecorder {
 	return m.recorder
 }
 
+// Archive mocks base method
+func (m *MockSpinmintStore) Archive(arg0, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Archive indicates an expected call of Archive
+func (mr *MockSpinmintStoreMockRecorder) Archive(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockSpinmintStore)(nil).Archive), arg0, arg1, arg2)
+}
+
+// Create mocks base method
+func (m *MockSpinmintStore) Create(arg0 *model.Spinmint) (*model.Spinmint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(*model.Spinmint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockSpinmintStoreMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSpinmintStore)(nil).Create), arg0)
+}
+
 // Delete mocks base method
 func (m *MockSpinmintStore) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -62,6 +92,51 @@ func (mr *MockSpinmintStoreMockRecorder) Get(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSpinmintStore)(nil).Get), arg0, arg1)
 }
 
+// GetAll mocks base method
+func (m *MockSpinmintStore) GetAll(arg0 int, arg1 string) ([]*model.Spinmint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Spinmint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll
+func (mr *MockSpinmintStoreMockRecorder) GetAll(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockSpinmintStore)(nil).GetAll), arg0, arg1)
+}
+
+// GetAllIncludingDeleted mocks base method
+func (m *MockSpinmintStore) GetAllIncludingDeleted(arg0 int, arg1 string) ([]*model.Spinmint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllIncludingDeleted", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Spinmint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllIncludingDeleted indicates an expected call of GetAllIncludingDeleted
+func (mr *MockSpinmintStoreMockRecorder) GetAllIncludingDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllIncludingDeleted", reflect.TypeOf((*MockSpinmintStore)(nil).GetAllIncludingDeleted), arg0, arg1)
+}
+
+// GetIncludingDeleted mocks base method
+func (m *MockSpinmintStore) GetIncludingDeleted(arg0 int, arg1 string) (*model.Spinmint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncludingDeleted", arg0, arg1)
+	ret0, _ := ret[0].(*model.Spinmint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncludingDeleted indicates an expected call of GetIncludingDeleted
+func (mr *MockSpinmintStoreMockRecorder) GetIncludingDeleted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncludingDeleted", reflect.TypeOf((*MockSpinmintStore)(nil).GetIncludingDeleted), arg0, arg1)
+}
+
 // List mocks base method
 func (m *MockSpinmintStore) List() ([]*model.Spinmint, error) {
 	m.ctrl.T.Helper()
@@ -77,6 +152,65 @@ func (mr *MockSpinmintStoreMockRecorder) List() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSpinmintStore)(nil).List))
 }
 
+// ListActions mocks base method
+func (m *MockSpinmintStore) ListActions(arg0, arg1 string, arg2 int) ([]*model.SpinmintAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*model.SpinmintAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActions indicates an expected call of ListActions
+func (mr *MockSpinmintStoreMockRecorder) ListActions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActions", reflect.TypeOf((*MockSpinmintStore)(nil).ListActions), arg0, arg1, arg2)
+}
+
+// ListHistory mocks base method
+func (m *MockSpinmintStore) ListHistory(arg0 time.Time) ([]*model.SpinmintHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHistory", arg0)
+	ret0, _ := ret[0].([]*model.SpinmintHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHistory indicates an expected call of ListHistory
+func (mr *MockSpinmintStoreMockRecorder) ListHistory(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHistory", reflect.TypeOf((*MockSpinmintStore)(nil).ListHistory), arg0)
+}
+
+// ListIncludingDeleted mocks base method
+func (m *MockSpinmintStore) ListIncludingDeleted() ([]*model.Spinmint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIncludingDeleted")
+	ret0, _ := ret[0].([]*model.Spinmint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIncludingDeleted indicates an expected call of ListIncludingDeleted
+func (mr *MockSpinmintStoreMockRecorder) ListIncludingDeleted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIncludingDeleted", reflect.TypeOf((*MockSpinmintStore)(nil).ListIncludingDeleted))
+}
+
+// LogAction mocks base method
+func (m *MockSpinmintStore) LogAction(arg0 *model.SpinmintAction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogAction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogAction indicates an expected call of LogAction
+func (mr *MockSpinmintStoreMockRecorder) LogAction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogAction", reflect.TypeOf((*MockSpinmintStore)(nil).LogAction), arg0)
+}
+
 // Save mocks base method
 func (m *MockSpinmintStore) Save(arg0 *model.Spinmint) (*model.Spinmint, error) {
 	m.ctrl.T.Helper()
@@ -91,3 +225,73 @@ func (mr *MockSpinmintStoreMockRecorder) Save(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSpinmintStore)(nil).Save), arg0)
 }
+
+// SoftDelete mocks base method
+func (m *MockSpinmintStore) SoftDelete(arg0 string, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete
+func (mr *MockSpinmintStoreMockRecorder) SoftDelete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockSpinmintStore)(nil).SoftDelete), arg0, arg1)
+}
+
+// UpdateCreatedBy mocks base method
+func (m *MockSpinmintStore) UpdateCreatedBy(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCreatedBy", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCreatedBy indicates an expected call of UpdateCreatedBy
+func (mr *MockSpinmintStoreMockRecorder) UpdateCreatedBy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCreatedBy", reflect.TypeOf((*MockSpinmintStore)(nil).UpdateCreatedBy), arg0, arg1)
+}
+
+// UpdateCredentialsCommentURL mocks base method
+func (m *MockSpinmintStore) UpdateCredentialsCommentURL(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCredentialsCommentURL", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCredentialsCommentURL indicates an expected call of UpdateCredentialsCommentURL
+func (mr *MockSpinmintStoreMockRecorder) UpdateCredentialsCommentURL(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCredentialsCommentURL", reflect.TypeOf((*MockSpinmintStore)(nil).UpdateCredentialsCommentURL), arg0, arg1)
+}
+
+// UpdateState mocks base method
+func (m *MockSpinmintStore) UpdateState(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateState", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateState indicates an expected call of UpdateState
+func (mr *MockSpinmintStoreMockRecorder) UpdateState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateState", reflect.TypeOf((*MockSpinmintStore)(nil).UpdateState), arg0, arg1)
+}
+
+// UpdateSubdomain mocks base method
+func (m *MockSpinmintStore) UpdateSubdomain(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubdomain", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubdomain indicates an expected call of UpdateSubdomain
+func (mr *MockSpinmintStoreMockRecorder) UpdateSubdomain(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubdomain", reflect.TypeOf((*MockSpinmintStore)(nil).UpdateSubdomain), arg0, arg1)
+}
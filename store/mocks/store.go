@@ -98,3 +98,17 @@ func (mr *MockStoreMockRecorder) Spinmint() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Spinmint", reflect.TypeOf((*MockStore)(nil).Spinmint))
 }
+
+// System mocks base method
+func (m *MockStore) System() store.SystemStore {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "System")
+	ret0, _ := ret[0].(store.SystemStore)
+	return ret0
+}
+
+// System indicates an expected call of System
+func (mr *MockStoreMockRecorder) System() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "System", reflect.TypeOf((*MockStore)(nil).System))
+}
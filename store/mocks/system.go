@@ -0,0 +1,62 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-mattermod/store (interfaces: SystemStore)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockSystemStore is a mock of SystemStore interface
+type MockSystemStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSystemStoreMockRecorder
+}
+
+// MockSystemStoreMockRecorder is the mock recorder for MockSystemStore
+type MockSystemStoreMockRecorder struct {
+	mock *MockSystemStore
+}
+
+// NewMockSystemStore creates a new mock instance
+func NewMockSystemStore(ctrl *gomock.Controller) *MockSystemStore {
+	mock := &MockSystemStore{ctrl: ctrl}
+	mock.recorder = &MockSystemStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSystemStore) EXPECT() *MockSystemStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockSystemStore) Get(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockSystemStoreMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSystemStore)(nil).Get), arg0)
+}
+
+// Save mocks base method
+func (m *MockSystemStore) Save(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save
+func (mr *MockSystemStoreMockRecorder) Save(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSystemStore)(nil).Save), arg0, arg1)
+}
@@ -5,11 +5,21 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/mattermost/mattermost-mattermod/model"
 )
 
+// spinmintColumns lists the columns mapped to model.Spinmint. The generated
+// Active and ActiveSubdomain columns only back the unique keys and are never selected.
+const spinmintColumns = "InstanceId, RepoOwner, RepoName, Number, CreatedAt, CreatedBy, SnapshotId, DeletedAt, Labels, Region, Subdomain, State, StateChangedAt, CredentialsCommentURL"
+
+// mysqlErrDuplicateEntry is returned by MySQL when a unique key is violated.
+const mysqlErrDuplicateEntry = 1062
+
 type SQLSpinmintStore struct {
 	*SQLStore
 }
@@ -21,12 +31,13 @@ func NewSQLSpinmintStore(sqlStore *SQLStore) SpinmintStore {
 func (s SQLSpinmintStore) Save(spinmint *model.Spinmint) (*model.Spinmint, error) {
 	if _, err := s.dbx.NamedExec(
 		`INSERT INTO Spinmint
-			(InstanceId, RepoOwner, RepoName, Number, CreatedAt)
+			(InstanceId, RepoOwner, RepoName, Number, CreatedAt, CreatedBy, SnapshotId, DeletedAt, Labels, Region, Subdomain, State, StateChangedAt, CredentialsCommentURL)
 		VALUES
-			(:InstanceId, :RepoOwner, :RepoName, :Number, :CreatedAt)`, spinmint); err != nil {
+			(:InstanceId, :RepoOwner, :RepoName, :Number, :CreatedAt, :CreatedBy, :SnapshotId, :DeletedAt, :Labels, :Region, :Subdomain, :State, :StateChangedAt, :CredentialsCommentURL)`, spinmint); err != nil {
 		if _, err := s.dbx.NamedExec(
 			`UPDATE Spinmint
-			 SET RepoOwner = :RepoOwner, RepoName = :RepoName, Number = :Number, CreatedAt = :CreatedAt
+			 SET RepoOwner = :RepoOwner, RepoName = :RepoName, Number = :Number, CreatedAt = :CreatedAt, CreatedBy = :CreatedBy, SnapshotId = :SnapshotId,
+			   DeletedAt = :DeletedAt, Labels = :Labels, Region = :Region, Subdomain = :Subdomain, State = :State, StateChangedAt = :StateChangedAt, CredentialsCommentURL = :CredentialsCommentURL
 			 WHERE InstanceId = :InstanceId`, spinmint); err != nil {
 			return nil, fmt.Errorf("could not insert or update spinmint: instanceid=%v, owner=%v, name=%v, number=%v, err=%w",
 				spinmint.InstanceID, spinmint.RepoOwner, spinmint.RepoName, spinmint.Number, err)
@@ -35,26 +46,104 @@ func (s SQLSpinmintStore) Save(spinmint *model.Spinmint) (*model.Spinmint, error
 	return spinmint, nil
 }
 
+// Create saves a new spinmint unless its PR already has an active one,
+// in which case the existing spinmint is returned instead.
+func (s SQLSpinmintStore) Create(spinmint *model.Spinmint) (*model.Spinmint, error) {
+	tx, err := s.dbx.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var existing model.Spinmint
+	err = tx.Get(&existing,
+		`SELECT `+spinmintColumns+` FROM
+        Spinmint
+      WHERE
+        RepoOwner = ? AND RepoName = ? AND Number = ? AND DeletedAt IS NULL
+      FOR UPDATE`, spinmint.RepoOwner, spinmint.RepoName, spinmint.Number)
+	switch {
+	case err == nil:
+		return &existing, nil
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("could not get the spinmint: owner=%v, name=%v, number=%v, err=%w", spinmint.RepoOwner, spinmint.RepoName, spinmint.Number, err)
+	}
+
+	if _, err = tx.NamedExec(
+		`INSERT INTO Spinmint
+			(`+spinmintColumns+`)
+		VALUES
+			(:InstanceId, :RepoOwner, :RepoName, :Number, :CreatedAt, :CreatedBy, :SnapshotId, :DeletedAt, :Labels, :Region, :Subdomain, :State, :StateChangedAt, :CredentialsCommentURL)`, spinmint); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			// Another flow created the spinmint between our check and insert.
+			_ = tx.Rollback()
+			return s.Get(spinmint.Number, spinmint.RepoName)
+		}
+		return nil, fmt.Errorf("could not insert spinmint: instanceid=%v, owner=%v, name=%v, number=%v, err=%w",
+			spinmint.InstanceID, spinmint.RepoOwner, spinmint.RepoName, spinmint.Number, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit spinmint: instanceid=%v, err=%w", spinmint.InstanceID, err)
+	}
+	return spinmint, nil
+}
+
+// spinmintNotDeleted is the condition leaving out the soft deleted spinmints, unless includeDeleted is set.
+func spinmintNotDeleted(includeDeleted bool) string {
+	if includeDeleted {
+		return "TRUE"
+	}
+	return "DeletedAt IS NULL"
+}
+
 func (s SQLSpinmintStore) List() ([]*model.Spinmint, error) {
+	return s.list(false)
+}
+
+// ListIncludingDeleted returns all the spinmints, soft deleted ones included.
+func (s SQLSpinmintStore) ListIncludingDeleted() ([]*model.Spinmint, error) {
+	return s.list(true)
+}
+
+func (s SQLSpinmintStore) list(includeDeleted bool) ([]*model.Spinmint, error) {
 	spinmints := []*model.Spinmint{}
 	err := s.dbx.Select(&spinmints,
 		`SELECT
-        *
+        `+spinmintColumns+`
       FROM
-        Spinmint`)
+        Spinmint
+      WHERE
+        `+spinmintNotDeleted(includeDeleted))
 	if err != nil {
 		return nil, fmt.Errorf("could not list spinmints: %w", err)
 	}
 	return spinmints, nil
 }
 
+// Get returns the spinmint of a PR.
 func (s SQLSpinmintStore) Get(prNumber int, repoName string) (*model.Spinmint, error) {
+	return s.get(prNumber, repoName, false)
+}
+
+// GetIncludingDeleted returns the spinmint of a PR, or its last soft deleted one if it has none.
+func (s SQLSpinmintStore) GetIncludingDeleted(prNumber int, repoName string) (*model.Spinmint, error) {
+	return s.get(prNumber, repoName, true)
+}
+
+func (s SQLSpinmintStore) get(prNumber int, repoName string, includeDeleted bool) (*model.Spinmint, error) {
 	var spinmint model.Spinmint
 	if err := s.dbx.Get(&spinmint,
-		`SELECT * FROM
+		`SELECT `+spinmintColumns+` FROM
         Spinmint
       WHERE
-        Number = ? AND RepoName = ?`, prNumber, repoName); err != nil {
+        Number = ? AND RepoName = ? AND `+spinmintNotDeleted(includeDeleted)+`
+      ORDER BY
+        DeletedAt IS NULL DESC, DeletedAt DESC
+      LIMIT 1`, prNumber, repoName); err != nil {
 		if err != sql.ErrNoRows {
 			return nil, fmt.Errorf("could not get the spinmint: owner=%v, name=%v, number=%v, instanceid=%v, err=%w", spinmint.RepoOwner, spinmint.RepoName, spinmint.Number, spinmint.InstanceID, err)
 		}
@@ -63,6 +152,153 @@ func (s SQLSpinmintStore) Get(prNumber int, repoName string) (*model.Spinmint, e
 	return &spinmint, nil
 }
 
+func (s SQLSpinmintStore) GetAll(prNumber int, repoName string) ([]*model.Spinmint, error) {
+	return s.getAll(prNumber, repoName, false)
+}
+
+// GetAllIncludingDeleted returns all the spinmints of a PR, soft deleted ones included.
+func (s SQLSpinmintStore) GetAllIncludingDeleted(prNumber int, repoName string) ([]*model.Spinmint, error) {
+	return s.getAll(prNumber, repoName, true)
+}
+
+func (s SQLSpinmintStore) getAll(prNumber int, repoName string, includeDeleted bool) ([]*model.Spinmint, error) {
+	spinmints := []*model.Spinmint{}
+	if err := s.dbx.Select(&spinmints,
+		`SELECT `+spinmintColumns+` FROM
+        Spinmint
+      WHERE
+        Number = ? AND RepoName = ? AND `+spinmintNotDeleted(includeDeleted), prNumber, repoName); err != nil {
+		return nil, fmt.Errorf("could not list the spinmints: name=%v, number=%v, err=%w", repoName, prNumber, err)
+	}
+	return spinmints, nil
+}
+
+func (s SQLSpinmintStore) UpdateCreatedBy(instanceID, createdBy string) error {
+	if _, err := s.dbx.NamedExec(`UPDATE
+        Spinmint
+      SET
+        CreatedBy = :CreatedBy
+      WHERE
+        InstanceId = :InstanceID`, map[string]interface{}{"InstanceID": instanceID, "CreatedBy": createdBy}); err != nil {
+		return fmt.Errorf("could not update spinmint owner: instanceid=%v, createdby=%v, err=%w", instanceID, createdBy, err)
+	}
+	return nil
+}
+
+// UpdateSubdomain records the DNS name a spinmint was renamed to. It returns
+// ErrSpinmintSubdomainTaken if another active spinmint of the region uses it.
+func (s SQLSpinmintStore) UpdateSubdomain(instanceID, subdomain string) error {
+	if _, err := s.dbx.NamedExec(`UPDATE
+        Spinmint
+      SET
+        Subdomain = :Subdomain
+      WHERE
+        InstanceId = :InstanceID`, map[string]interface{}{"InstanceID": instanceID, "Subdomain": subdomain}); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return ErrSpinmintSubdomainTaken
+		}
+		return fmt.Errorf("could not update spinmint subdomain: instanceid=%v, subdomain=%v, err=%w", instanceID, subdomain, err)
+	}
+	return nil
+}
+
+// UpdateState records the state a spinmint moved to, and when.
+func (s SQLSpinmintStore) UpdateState(instanceID, state string) error {
+	if _, err := s.dbx.NamedExec(`UPDATE
+        Spinmint
+      SET
+        State = :State, StateChangedAt = :StateChangedAt
+      WHERE
+        InstanceId = :InstanceID`, map[string]interface{}{"InstanceID": instanceID, "State": state, "StateChangedAt": model.NowUTC()}); err != nil {
+		return fmt.Errorf("could not update spinmint state: instanceid=%v, state=%v, err=%w", instanceID, state, err)
+	}
+	return nil
+}
+
+// UpdateCredentialsCommentURL records the PR comment the credentials of a spinmint were posted in.
+func (s SQLSpinmintStore) UpdateCredentialsCommentURL(instanceID, url string) error {
+	if _, err := s.dbx.NamedExec(`UPDATE
+        Spinmint
+      SET
+        CredentialsCommentURL = :CredentialsCommentURL
+      WHERE
+        InstanceId = :InstanceID`, map[string]interface{}{"InstanceID": instanceID, "CredentialsCommentURL": url}); err != nil {
+		return fmt.Errorf("could not update spinmint credentials comment: instanceid=%v, err=%w", instanceID, err)
+	}
+	return nil
+}
+
+// SoftDelete marks a spinmint as deleted and keeps its row for auditing.
+func (s SQLSpinmintStore) SoftDelete(instanceID string, deletedAt time.Time) error {
+	if _, err := s.dbx.NamedExec(`UPDATE
+        Spinmint
+      SET
+        DeletedAt = :DeletedAt
+      WHERE
+        InstanceId = :InstanceID`, map[string]interface{}{"InstanceID": instanceID, "DeletedAt": deletedAt}); err != nil {
+		return fmt.Errorf("could not soft delete spinmint: instanceid=%v, err=%w", instanceID, err)
+	}
+	return nil
+}
+
+// Archive copies a spinmint to the history before it is deleted.
+func (s SQLSpinmintStore) Archive(instanceID, instanceType string, destroyedAt time.Time) error {
+	if _, err := s.dbx.Exec(
+		`INSERT IGNORE INTO SpinmintHistory
+			(InstanceId, RepoOwner, RepoName, Number, InstanceType, CreatedAt, DestroyedAt)
+		SELECT
+			InstanceId, RepoOwner, RepoName, Number, ?, CreatedAt, ?
+		FROM
+			Spinmint
+		WHERE
+			InstanceId = ?`, instanceType, destroyedAt, instanceID); err != nil {
+		return fmt.Errorf("could not archive spinmint: instanceid=%v, err=%w", instanceID, err)
+	}
+	return nil
+}
+
+// ListHistory returns the spinmints destroyed since the given time.
+func (s SQLSpinmintStore) ListHistory(since time.Time) ([]*model.SpinmintHistory, error) {
+	history := []*model.SpinmintHistory{}
+	if err := s.dbx.Select(&history,
+		`SELECT * FROM
+        SpinmintHistory
+      WHERE
+        DestroyedAt >= ?`, since); err != nil {
+		return nil, fmt.Errorf("could not list the spinmint history: since=%v, err=%w", since, err)
+	}
+	return history, nil
+}
+
+// LogAction adds an entry to the action log of a PR.
+func (s SQLSpinmintStore) LogAction(action *model.SpinmintAction) error {
+	if _, err := s.dbx.NamedExec(
+		`INSERT INTO SpinmintActions
+			(RepoOwner, RepoName, Number, Action, InstanceId, Sha, CreatedAt)
+		VALUES
+			(:RepoOwner, :RepoName, :Number, :Action, :InstanceId, :Sha, :CreatedAt)`, action); err != nil {
+		return fmt.Errorf("could not log spinmint action: owner=%v, name=%v, number=%v, action=%v, err=%w",
+			action.RepoOwner, action.RepoName, action.Number, action.Action, err)
+	}
+	return nil
+}
+
+// ListActions returns the action log of a PR, oldest first.
+func (s SQLSpinmintStore) ListActions(repoOwner, repoName string, number int) ([]*model.SpinmintAction, error) {
+	actions := []*model.SpinmintAction{}
+	if err := s.dbx.Select(&actions,
+		`SELECT * FROM
+        SpinmintActions
+      WHERE
+        RepoOwner = ? AND RepoName = ? AND Number = ?
+      ORDER BY
+        CreatedAt, Id`, repoOwner, repoName, number); err != nil {
+		return nil, fmt.Errorf("could not list the spinmint actions: owner=%v, name=%v, number=%v, err=%w", repoOwner, repoName, number, err)
+	}
+	return actions, nil
+}
+
 func (s SQLSpinmintStore) Delete(instanceID string) error {
 	if _, err := s.dbx.NamedExec(`DELETE FROM
         Spinmint
@@ -2,6 +2,7 @@ package store
 
 import (
 	"testing"
+	"time"
 
 	"github.com/mattermost/mattermost-mattermod/model"
 	"github.com/stretchr/testify/assert"
@@ -14,8 +15,10 @@ func TestSQLSpinmintStore(t *testing.T) {
 	sms := NewSQLSpinmintStore(ss)
 
 	sm := &model.Spinmint{
-		RepoName: "repo-name",
-		Number:   123,
+		RepoName:  "repo-name",
+		Number:    123,
+		CreatedAt: model.NowUTC(),
+		CreatedBy: "someone",
 	}
 
 	t.Run("no rows on Get", func(t *testing.T) {
@@ -31,6 +34,9 @@ func TestSQLSpinmintStore(t *testing.T) {
 
 	t.Run("should be able to upsert and modify", func(t *testing.T) {
 		sm.RepoOwner = "someone"
+		sm.SnapshotID = "snap-123"
+		sm.Labels = model.StringArray{"Setup Test Server"}
+		sm.Region = "us-west"
 		_, err := sms.Save(sm)
 		require.NoError(t, err)
 
@@ -40,6 +46,61 @@ func TestSQLSpinmintStore(t *testing.T) {
 		assert.Equal(t, sm, nsm)
 	})
 
+	t.Run("happy path UpdateCreatedBy", func(t *testing.T) {
+		err := sms.UpdateCreatedBy(sm.InstanceID, "new-owner")
+		require.NoError(t, err)
+
+		nsm, err := sms.Get(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, "new-owner", nsm.CreatedBy)
+	})
+
+	t.Run("happy path UpdateSubdomain", func(t *testing.T) {
+		err := sms.UpdateSubdomain(sm.InstanceID, "vanity")
+		require.NoError(t, err)
+
+		nsm, err := sms.Get(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, "vanity", nsm.Subdomain)
+		assert.Equal(t, "vanity", nsm.GetSubdomain())
+	})
+
+	t.Run("UpdateSubdomain taken in the region", func(t *testing.T) {
+		other := &model.Spinmint{InstanceID: "i-other", RepoName: "other-repo", Number: 456, CreatedAt: model.NowUTC(), Region: sm.Region}
+		_, err := sms.Save(other)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, sms.Delete(other.InstanceID))
+		}()
+
+		err = sms.UpdateSubdomain(other.InstanceID, "vanity")
+		assert.Equal(t, ErrSpinmintSubdomainTaken, err)
+	})
+
+	t.Run("happy path UpdateCredentialsCommentURL", func(t *testing.T) {
+		err := sms.UpdateCredentialsCommentURL(sm.InstanceID, "https://github.com/owner/repo/pull/1#issuecomment-1")
+		require.NoError(t, err)
+
+		nsm, err := sms.Get(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, "https://github.com/owner/repo/pull/1#issuecomment-1", nsm.CredentialsCommentURL)
+	})
+
+	t.Run("happy path UpdateState", func(t *testing.T) {
+		err := sms.UpdateState(sm.InstanceID, model.SpinmintStateFailed)
+		require.NoError(t, err)
+
+		nsm, err := sms.Get(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, model.SpinmintStateFailed, nsm.State)
+		require.NotNil(t, nsm.StateChangedAt)
+		assert.WithinDuration(t, time.Now(), *nsm.StateChangedAt, time.Minute)
+	})
+
 	t.Run("happy path Get", func(t *testing.T) {
 		nsm, err := sms.Get(sm.Number, sm.RepoName)
 		require.NoError(t, err)
@@ -54,6 +115,116 @@ func TestSQLSpinmintStore(t *testing.T) {
 		assert.Len(t, list, 1)
 	})
 
+	t.Run("soft deleted spinmints are excluded", func(t *testing.T) {
+		// The PR already has an active spinmint, so the other one is saved as deleted.
+		deletedAt := model.NowUTC()
+		deleted := &model.Spinmint{
+			InstanceID: "i-deleted",
+			RepoName:   sm.RepoName,
+			Number:     sm.Number,
+			CreatedAt:  model.NowUTC(),
+			DeletedAt:  &deletedAt,
+		}
+		_, err := sms.Save(deleted)
+		require.NoError(t, err)
+		require.NoError(t, sms.SoftDelete(deleted.InstanceID, model.NowUTC()))
+
+		nsm, err := sms.Get(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, sm.InstanceID, nsm.InstanceID)
+
+		all, err := sms.GetAll(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		assert.Len(t, all, 1)
+
+		list, err := sms.List()
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+
+		list, err = sms.ListIncludingDeleted()
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+
+		all, err = sms.GetAllIncludingDeleted(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		// The live spinmint of the PR wins over its soft deleted ones.
+		nsm, err = sms.GetIncludingDeleted(sm.Number, sm.RepoName)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, sm.InstanceID, nsm.InstanceID)
+
+		require.NoError(t, sms.Delete(deleted.InstanceID))
+	})
+
+	t.Run("Create returns the existing spinmint of the PR", func(t *testing.T) {
+		duplicate := &model.Spinmint{
+			InstanceID: "i-duplicate",
+			RepoOwner:  sm.RepoOwner,
+			RepoName:   sm.RepoName,
+			Number:     sm.Number,
+			CreatedAt:  model.NowUTC(),
+		}
+		nsm, err := sms.Create(duplicate)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, sm.InstanceID, nsm.InstanceID)
+
+		duplicate.Number = sm.Number + 1
+		nsm, err = sms.Create(duplicate)
+		require.NoError(t, err)
+		require.NotNil(t, nsm)
+		assert.Equal(t, "i-duplicate", nsm.InstanceID)
+
+		require.NoError(t, sms.Delete(duplicate.InstanceID))
+	})
+
+	t.Run("happy path Archive", func(t *testing.T) {
+		destroyedAt := model.NowUTC()
+		err := sms.Archive(sm.InstanceID, "m1.medium", destroyedAt)
+		require.NoError(t, err)
+
+		history, err := sms.ListHistory(destroyedAt.Add(-time.Hour))
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, sm.RepoName, history[0].RepoName)
+		assert.Equal(t, "m1.medium", history[0].InstanceType)
+		assert.Equal(t, destroyedAt, history[0].DestroyedAt)
+
+		history, err = sms.ListHistory(destroyedAt.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Len(t, history, 0)
+	})
+
+	t.Run("action log", func(t *testing.T) {
+		createdAt := model.NowUTC()
+		for _, action := range []string{"created", "upgraded"} {
+			err := sms.LogAction(&model.SpinmintAction{
+				RepoOwner:  "owner",
+				RepoName:   sm.RepoName,
+				Number:     sm.Number,
+				Action:     action,
+				InstanceID: "i-123",
+				Sha:        "abcdef",
+				CreatedAt:  createdAt,
+			})
+			require.NoError(t, err)
+		}
+
+		actions, err := sms.ListActions("owner", sm.RepoName, sm.Number)
+		require.NoError(t, err)
+		require.Len(t, actions, 2)
+		assert.Equal(t, "created", actions[0].Action)
+		assert.Equal(t, "upgraded", actions[1].Action)
+		assert.Equal(t, "i-123", actions[1].InstanceID)
+
+		actions, err = sms.ListActions("owner", sm.RepoName, sm.Number+1)
+		require.NoError(t, err)
+		assert.Len(t, actions, 0)
+	})
+
 	t.Run("happy path Delete", func(t *testing.T) {
 		nsm, err := sms.Get(sm.Number, sm.RepoName)
 		require.NoError(t, err)
@@ -30,6 +30,7 @@ type SQLStore struct {
 	pullRequest   PullRequestStore
 	issue         IssueStore
 	spinmint      SpinmintStore
+	system        SystemStore
 	SchemaVersion string
 }
 
@@ -64,6 +65,7 @@ func NewSQLStore(driverName, dataSource string) Store {
 	sqlStore.pullRequest = NewSQLPullRequestStore(sqlStore)
 	sqlStore.issue = NewSQLIssueStore(sqlStore)
 	sqlStore.spinmint = NewSQLSpinmintStore(sqlStore)
+	sqlStore.system = NewSQLSystemStore(sqlStore)
 
 	runMigrations(sqlStore.db)
 
@@ -87,8 +89,12 @@ func (ss *SQLStore) Spinmint() SpinmintStore {
 	return ss.spinmint
 }
 
+func (ss *SQLStore) System() SystemStore {
+	return ss.system
+}
+
 func (ss *SQLStore) DropAllTables() {
-	tbls := []string{"Issues", "PullRequests", "Spinmint"}
+	tbls := []string{"Issues", "PullRequests", "Spinmint", "SpinmintHistory", "SpinmintActions", "Systems"}
 	for _, t := range tbls {
 		_, err := ss.dbx.Exec("TRUNCATE TABLE " + t)
 		if err != nil {
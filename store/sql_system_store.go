@@ -0,0 +1,41 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type SQLSystemStore struct {
+	*SQLStore
+}
+
+func NewSQLSystemStore(sqlStore *SQLStore) SystemStore {
+	return &SQLSystemStore{sqlStore}
+}
+
+// Get returns the value saved under name, or an empty string if there is none.
+func (s SQLSystemStore) Get(name string) (string, error) {
+	var value string
+	if err := s.dbx.Get(&value, `SELECT Value FROM Systems WHERE Name = ?`, name); err != nil {
+		if err != sql.ErrNoRows {
+			return "", fmt.Errorf("could not get the system value: name=%v, err=%w", name, err)
+		}
+		return "", nil // row not found.
+	}
+	return value, nil
+}
+
+func (s SQLSystemStore) Save(name, value string) error {
+	if _, err := s.dbx.Exec(
+		`INSERT INTO Systems
+			(Name, Value)
+		VALUES
+			(?, ?)
+		ON DUPLICATE KEY UPDATE Value = VALUES(Value)`, name, value); err != nil {
+		return fmt.Errorf("could not save the system value: name=%v, err=%w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemStore(t *testing.T) {
+	store := getTestSQLStore(t)
+	systemStore := NewSQLSystemStore(store)
+	defer cleanSystemsTable(t, store)
+
+	t.Run("Should return an empty value if none was saved", func(t *testing.T) {
+		value, err := systemStore.Get("missing")
+		require.NoError(t, err)
+		require.Empty(t, value)
+	})
+
+	t.Run("Should save and overwrite a value", func(t *testing.T) {
+		require.NoError(t, systemStore.Save("key", "one"))
+		value, err := systemStore.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, "one", value)
+
+		require.NoError(t, systemStore.Save("key", "two"))
+		value, err = systemStore.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, "two", value)
+	})
+}
+
+func cleanSystemsTable(t *testing.T, store *SQLStore) {
+	if _, err := store.dbx.Exec("TRUNCATE TABLE Systems;"); err != nil {
+		require.Fail(t, "Systems table cleaning failed", err.Error())
+	}
+}
@@ -4,13 +4,20 @@
 package store
 
 import (
+	"errors"
+	"time"
+
 	"github.com/mattermost/mattermost-mattermod/model"
 )
 
+// ErrSpinmintSubdomainTaken is returned when a spinmint is renamed to the subdomain of another one.
+var ErrSpinmintSubdomainTaken = errors.New("the subdomain is used by another spinmint")
+
 type Store interface {
 	PullRequest() PullRequestStore
 	Issue() IssueStore
 	Spinmint() SpinmintStore
+	System() SystemStore
 	Close()
 	DropAllTables()
 }
@@ -28,7 +35,27 @@ type IssueStore interface {
 
 type SpinmintStore interface {
 	Save(spinmint *model.Spinmint) (*model.Spinmint, error)
+	Create(spinmint *model.Spinmint) (*model.Spinmint, error)
 	Delete(instanceID string) error
+	SoftDelete(instanceID string, deletedAt time.Time) error
 	Get(prNumber int, repoName string) (*model.Spinmint, error)
+	GetIncludingDeleted(prNumber int, repoName string) (*model.Spinmint, error)
+	GetAll(prNumber int, repoName string) ([]*model.Spinmint, error)
+	GetAllIncludingDeleted(prNumber int, repoName string) ([]*model.Spinmint, error)
 	List() ([]*model.Spinmint, error)
+	ListIncludingDeleted() ([]*model.Spinmint, error)
+	UpdateCreatedBy(instanceID, createdBy string) error
+	UpdateSubdomain(instanceID, subdomain string) error
+	UpdateState(instanceID, state string) error
+	UpdateCredentialsCommentURL(instanceID, url string) error
+	Archive(instanceID, instanceType string, destroyedAt time.Time) error
+	ListHistory(since time.Time) ([]*model.SpinmintHistory, error)
+	LogAction(action *model.SpinmintAction) error
+	ListActions(repoOwner, repoName string, number int) ([]*model.SpinmintAction, error)
+}
+
+// SystemStore keeps named runtime values that must survive restarts.
+type SystemStore interface {
+	Get(name string) (string, error)
+	Save(name, value string) error
 }